@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/gcp"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// auditToolVersion is reported in SARIF output
+const auditToolVersion = "1.0.0"
+
+var (
+	auditProjectID   string
+	auditOutput      string
+	auditOutputFile  string
+	auditMinSeverity string
+	auditFailOn      string
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit Workload Identity Federation security across a project",
+	Long: `Scan every workload identity pool, provider and service account binding in a
+project and report risky configurations.
+
+Checks performed:
+• WIF001 - Providers without any attribute condition
+• WIF002 - Provider conditions without a repository or owner restriction
+• WIF003 - principalSet bindings on attribute.repository_owner (every repo of an owner)
+• WIF004 - Conditions matching mutable names instead of repository_id/repository_owner_id
+• WIF005 - Disabled providers or pools that still have service account bindings
+• WIF006 - Federated service accounts that still have user-managed keys
+• WIF007 - Overly broad project roles held by federated identities
+
+Output Formats:
+• table - Human-readable table sorted by severity (default)
+• json  - Machine-readable JSON report
+• sarif - SARIF 2.1.0 for GitHub code scanning and other security tools
+
+Examples:
+  # Audit a project
+  gcp-wif audit --project-id my-project
+
+  # Only show high and critical findings
+  gcp-wif audit --project-id my-project --min-severity high
+
+  # Upload results to GitHub code scanning
+  gcp-wif audit --project-id my-project --output sarif --output-file wif-audit.sarif
+
+  # Fail a CI job when critical findings exist
+  gcp-wif audit --project-id my-project --fail-on critical`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAuditCommand(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.Flags().StringVar(&auditProjectID, "project-id", "", "Google Cloud Project ID (default: project from configuration)")
+	auditCmd.Flags().StringVarP(&auditOutput, "output", "o", "table", "Output format: table, json, sarif")
+	auditCmd.Flags().StringVar(&auditOutputFile, "output-file", "", "Write the report to a file instead of stdout")
+	auditCmd.Flags().StringVar(&auditMinSeverity, "min-severity", "info", "Minimum severity to report: critical, high, medium, low, info")
+	auditCmd.Flags().StringVar(&auditFailOn, "fail-on", "", "Exit with an error when findings at or above this severity exist")
+}
+
+func runAuditCommand(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "audit")
+
+	projectID := auditProjectID
+	if projectID == "" {
+		if cfg, err := loadConfigWithFallback(); err == nil {
+			projectID = cfg.Project.ID
+		}
+	}
+	if projectID == "" {
+		return errors.NewValidationError(
+			"Project ID is required",
+			"Specify the project with --project-id",
+			"Or run from a directory containing wif-config.json")
+	}
+
+	minSeverity, err := gcp.ParseAuditSeverity(auditMinSeverity)
+	if err != nil {
+		return err
+	}
+
+	var failOn gcp.AuditSeverity
+	if auditFailOn != "" {
+		if failOn, err = gcp.ParseAuditSeverity(auditFailOn); err != nil {
+			return err
+		}
+	}
+
+	format := strings.ToLower(auditOutput)
+	if format != "table" && format != "json" && format != "sarif" {
+		return errors.NewValidationError(
+			fmt.Sprintf("Invalid output format: %s", auditOutput),
+			"Valid formats are: table, json, sarif")
+	}
+
+	logger.Info("Starting workload identity audit", "project_id", projectID)
+
+	client, err := gcp.NewClient(context.Background(), projectID)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := client.CollectAuditSnapshot()
+	if err != nil {
+		return err
+	}
+
+	report := gcp.EvaluateAudit(snapshot)
+	report.FilterBySeverity(minSeverity)
+
+	var data []byte
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(report, "", "  ")
+	case "sarif":
+		data, err = report.ToSARIF(auditToolVersion)
+	}
+	if err != nil {
+		return errors.WrapError(err, errors.ErrorTypeInternal, "AUDIT_REPORT_MARSHAL_FAILED",
+			"Failed to serialize audit report")
+	}
+
+	switch {
+	case format == "table" && auditOutputFile == "":
+		displayAuditTable(report, snapshot)
+	case format == "table":
+		return errors.NewValidationError(
+			"Table output cannot be written to a file",
+			"Use --output json or --output sarif with --output-file")
+	case auditOutputFile != "":
+		if err := os.WriteFile(auditOutputFile, data, 0644); err != nil {
+			return errors.WrapError(err, errors.ErrorTypeFileSystem, "AUDIT_REPORT_WRITE_FAILED",
+				fmt.Sprintf("Failed to write audit report: %s", auditOutputFile))
+		}
+		fmt.Printf("💾 Audit report saved to: %s (%d findings)\n", auditOutputFile, len(report.Findings))
+	default:
+		fmt.Println(string(data))
+	}
+
+	logger.Info("Workload identity audit completed", "project_id", projectID, "findings", len(report.Findings))
+
+	if failOn != "" && report.HasFindingsAtOrAbove(failOn) {
+		return errors.NewValidationError(
+			fmt.Sprintf("Audit found issues at or above severity '%s'", failOn),
+			"Review the findings above and apply the recommended fixes")
+	}
+
+	return nil
+}
+
+// displayAuditTable prints the audit report as a severity-ranked table
+func displayAuditTable(report *gcp.AuditReport, snapshot *gcp.AuditSnapshot) {
+	fmt.Println("🔒 Workload Identity Federation Security Audit")
+	fmt.Println("==============================================")
+	fmt.Printf("📁 Project: %s\n", report.ProjectID)
+	providerCount := 0
+	for _, providers := range snapshot.Providers {
+		providerCount += len(providers)
+	}
+	fmt.Printf("🔍 Scanned: %d pool(s), %d provider(s), %d federated service account(s)\n",
+		len(snapshot.Pools), providerCount, len(snapshot.ServiceAccounts))
+
+	if len(report.Findings) == 0 {
+		fmt.Println("\n✅ No findings")
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tRULE\tRESOURCE\tFINDING")
+	fmt.Fprintln(w, "--------\t----\t--------\t-------")
+	for _, finding := range report.Findings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			strings.ToUpper(string(finding.Severity)), finding.RuleID, finding.Resource, finding.Message)
+	}
+	w.Flush()
+
+	fmt.Println("\n📊 Summary:")
+	for _, severity := range []gcp.AuditSeverity{gcp.AuditSeverityCritical, gcp.AuditSeverityHigh, gcp.AuditSeverityMedium, gcp.AuditSeverityLow, gcp.AuditSeverityInfo} {
+		if count := report.Summary[severity]; count > 0 {
+			fmt.Printf("   %s: %d\n", strings.ToUpper(string(severity)), count)
+		}
+	}
+
+	fmt.Println("\n💡 Recommendations:")
+	seen := make(map[string]bool)
+	for _, finding := range report.Findings {
+		if seen[finding.RuleID] {
+			continue
+		}
+		seen[finding.RuleID] = true
+		fmt.Printf("   • %s: %s\n", finding.RuleID, finding.Recommendation)
+	}
+}
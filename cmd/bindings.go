@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/config"
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/gcp"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bindingsProjectID      string
+	bindingsServiceAccount string
+	bindingsRepository     string
+	bindingsExpiringWithin time.Duration
+	bindingsShowPermanent  bool
+	bindingsExpires        string
+)
+
+// bindingsCmd represents the bindings command
+var bindingsCmd = &cobra.Command{
+	Use:   "bindings",
+	Short: "Manage workload identity service account bindings",
+	Long: `Inspect and manage the IAM bindings that let GitHub workflows impersonate
+service accounts through Workload Identity Federation.
+
+Time-bound bindings are created with 'gcp-wif setup --expires 72h' and stop granting
+access after their expiration time. Use these commands to find bindings that are about
+to expire and to extend them, e.g. for contractors or temporary preview environments.
+
+Available subcommands:
+• list   - List bindings and their expiration times
+• extend - Move the expiration of time-bound bindings
+
+Examples:
+  # List bindings that expire within the next week
+  gcp-wif bindings list --expiring-within 168h
+
+  # Extend a contractor's access by three days
+  gcp-wif bindings extend --service-account deployer@my-project.iam.gserviceaccount.com --expires 72h`,
+}
+
+// bindingsListCmd represents the bindings list command
+var bindingsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workload identity bindings and upcoming expirations",
+	Long: `List workload identity bindings on the project's service accounts, sorted by
+expiration time. By default only time-bound bindings are shown.
+
+Examples:
+  # List all time-bound bindings in the configured project
+  gcp-wif bindings list
+
+  # Only bindings expiring in the next 24 hours
+  gcp-wif bindings list --expiring-within 24h
+
+  # Include bindings without an expiration
+  gcp-wif bindings list --all`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBindingsList(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+// bindingsExtendCmd represents the bindings extend command
+var bindingsExtendCmd = &cobra.Command{
+	Use:   "extend",
+	Short: "Extend the expiration of time-bound bindings",
+	Long: `Move the expiration of every time-bound workload identity binding on a service
+account. Bindings without an expiration are left unchanged.
+
+The new expiration is either a duration from now (72h, 7d) or an RFC3339 timestamp.
+
+Examples:
+  # Extend the configured service account's bindings by a week
+  gcp-wif bindings extend --expires 7d
+
+  # Extend only the bindings of one repository until a fixed date
+  gcp-wif bindings extend --service-account preview@my-project.iam.gserviceaccount.com \
+    --repository myorg/preview-app --expires 2025-12-31T00:00:00Z`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBindingsExtend(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bindingsCmd)
+	bindingsCmd.AddCommand(bindingsListCmd)
+	bindingsCmd.AddCommand(bindingsExtendCmd)
+
+	bindingsCmd.PersistentFlags().StringVar(&bindingsProjectID, "project-id", "", "Google Cloud Project ID (default: project from configuration)")
+	bindingsCmd.PersistentFlags().StringVar(&bindingsServiceAccount, "service-account", "", "Service account email (default: all service accounts for list, configured service account for extend)")
+
+	bindingsListCmd.Flags().DurationVar(&bindingsExpiringWithin, "expiring-within", 0, "Only show bindings expiring within this duration (e.g. 168h)")
+	bindingsListCmd.Flags().BoolVar(&bindingsShowPermanent, "all", false, "Include bindings without an expiration")
+
+	bindingsExtendCmd.Flags().StringVar(&bindingsExpires, "expires", "", "New expiration: duration from now (72h, 7d) or RFC3339 timestamp (required)")
+	bindingsExtendCmd.Flags().StringVar(&bindingsRepository, "repository", "", "Only extend bindings for this repository (owner/name)")
+	bindingsExtendCmd.MarkFlagRequired("expires")
+}
+
+// bindingsListEntry is a workload identity binding together with its service account
+type bindingsListEntry struct {
+	serviceAccount string
+	binding        gcp.WorkloadIdentityBinding
+}
+
+func runBindingsList(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "bindings_list")
+
+	projectID, _, err := resolveBindingsTarget()
+	if err != nil {
+		return err
+	}
+
+	client, err := gcp.NewClient(context.Background(), projectID)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	accounts := []string{bindingsServiceAccount}
+	if bindingsServiceAccount == "" {
+		serviceAccounts, err := client.ListServiceAccounts()
+		if err != nil {
+			return err
+		}
+		accounts = accounts[:0]
+		for _, account := range serviceAccounts {
+			accounts = append(accounts, account.Email)
+		}
+	}
+
+	now := time.Now()
+	var entries []bindingsListEntry
+	for _, email := range accounts {
+		bindings, err := client.ListServiceAccountWorkloadIdentityBindings(email)
+		if err != nil {
+			logger.Warn("Failed to read service account bindings", "service_account", email, "error", err)
+			continue
+		}
+		for _, binding := range bindings {
+			if binding.ExpiresAt == nil {
+				if bindingsShowPermanent && bindingsExpiringWithin == 0 {
+					entries = append(entries, bindingsListEntry{serviceAccount: email, binding: binding})
+				}
+				continue
+			}
+			if bindingsExpiringWithin > 0 && binding.ExpiresAt.After(now.Add(bindingsExpiringWithin)) {
+				continue
+			}
+			entries = append(entries, bindingsListEntry{serviceAccount: email, binding: binding})
+		}
+	}
+
+	// Soonest expiration first, permanent bindings last
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].binding.ExpiresAt, entries[j].binding.ExpiresAt
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Before(*b)
+	})
+
+	fmt.Printf("⏰ Workload Identity Bindings in project %s\n", projectID)
+	if len(entries) == 0 {
+		fmt.Println("\n✅ No matching bindings found")
+		return nil
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SERVICE ACCOUNT\tROLE\tREPOSITORY\tEXPIRES\tSTATUS")
+	fmt.Fprintln(w, "---------------\t----\t----------\t-------\t------")
+	expired := 0
+	for _, entry := range entries {
+		repository := entry.binding.Repository
+		if repository == "" && entry.binding.Condition != nil {
+			repository = gcp.ParseAttributeCondition(entry.binding.Condition.Expression).Repository
+		}
+		if repository == "" {
+			repository = "-"
+		}
+
+		expires, status := "never", "permanent"
+		if entry.binding.ExpiresAt != nil {
+			expires = entry.binding.ExpiresAt.Format(time.RFC3339)
+			remaining := entry.binding.ExpiresAt.Sub(now)
+			if remaining <= 0 {
+				status = "❌ expired"
+				expired++
+			} else {
+				status = fmt.Sprintf("expires in %s", remaining.Round(time.Minute))
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			entry.serviceAccount, entry.binding.Role, repository, expires, status)
+	}
+	w.Flush()
+
+	if expired > 0 {
+		fmt.Printf("\n⚠️  %d expired binding(s) no longer grant access\n", expired)
+		fmt.Println("💡 Extend them with 'gcp-wif bindings extend --expires <duration>' or remove them")
+	}
+
+	logger.Info("Bindings listed", "project_id", projectID, "count", len(entries), "expired", expired)
+	return nil
+}
+
+func runBindingsExtend(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "bindings_extend")
+
+	projectID, serviceAccountEmail, err := resolveBindingsTarget()
+	if err != nil {
+		return err
+	}
+	if serviceAccountEmail == "" {
+		return errors.NewValidationError(
+			"Service account email is required",
+			"Specify the service account with --service-account",
+			"Or run from a directory containing wif-config.json")
+	}
+
+	expiresAt, err := gcp.ParseBindingExpiration(bindingsExpires, time.Now())
+	if err != nil {
+		return err
+	}
+
+	client, err := gcp.NewClient(context.Background(), projectID)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	fmt.Printf("⏰ Extending bindings on %s until %s...\n", serviceAccountEmail, expiresAt.Format(time.RFC3339))
+
+	updated, err := client.ExtendWorkloadIdentityBindingExpiration(serviceAccountEmail, bindingsRepository, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		fmt.Println("⚠️  No time-bound bindings found to extend")
+		fmt.Println("💡 Bindings without an expiration never expire; recreate them with 'gcp-wif setup --expires' to make them time-bound")
+		return nil
+	}
+
+	fmt.Printf("✅ Extended %d binding(s)\n", updated)
+	logger.Info("Bindings extended", "service_account", serviceAccountEmail, "count", updated)
+	return nil
+}
+
+// resolveBindingsTarget returns the project and service account from flags, falling back
+// to the configuration file
+func resolveBindingsTarget() (string, string, error) {
+	projectID := bindingsProjectID
+	serviceAccountEmail := bindingsServiceAccount
+
+	var cfg *config.Config
+	if projectID == "" || serviceAccountEmail == "" {
+		if loaded, err := loadConfigWithFallback(); err == nil {
+			cfg = loaded
+		}
+	}
+	if projectID == "" && cfg != nil {
+		projectID = cfg.Project.ID
+	}
+	if serviceAccountEmail == "" && cfg != nil && cfg.ServiceAccount.Name != "" {
+		serviceAccountEmail = cfg.GetServiceAccountEmail()
+	}
+
+	if projectID == "" {
+		return "", "", errors.NewValidationError(
+			"Project ID is required",
+			"Specify the project with --project-id",
+			"Or run from a directory containing wif-config.json")
+	}
+
+	return projectID, serviceAccountEmail, nil
+}
@@ -396,7 +396,7 @@ func displayCleanupPlan(cfg *config.Config, scope *CleanupScope) error {
 
 	if scope.ConfigFiles {
 		fmt.Println("   • Configuration Files:")
-		fmt.Println("     - wif-config.json")
+		fmt.Println("     - wif-config.json, .yaml, .yml or .toml")
 		fmt.Println("     - .gcp-wif.json")
 		resourceCount++
 	}
@@ -404,7 +404,7 @@ func displayCleanupPlan(cfg *config.Config, scope *CleanupScope) error {
 	if scope.BackupFiles {
 		fmt.Println("   • Backup Files:")
 		fmt.Println("     - All *.backup files")
-		fmt.Println("     - All *-backup-*.json files")
+		fmt.Println("     - All *-backup-* configuration files (.json, .yaml, .yml, .toml)")
 		resourceCount++
 	}
 
@@ -600,12 +600,7 @@ type CleanupOperation struct {
 
 // Individual cleanup operation functions
 func cleanupIAMBindingsOp(client *gcp.Client, cfg *config.Config, result *CleanupResult) error {
-	workloadIdentityConfig := &gcp.WorkloadIdentityConfig{
-		PoolID:              cfg.WorkloadIdentity.PoolID,
-		ProviderID:          cfg.WorkloadIdentity.ProviderID,
-		Repository:          cfg.GetRepoFullName(),
-		ServiceAccountEmail: cfg.GetServiceAccountEmail(),
-	}
+	workloadIdentityConfig := serviceAccountBindingConfig(cfg)
 
 	err := client.RemoveServiceAccountWorkloadIdentityBinding(workloadIdentityConfig)
 	if err != nil {
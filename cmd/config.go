@@ -9,6 +9,8 @@ import (
 
 	"github.com/Fordjour12/gcp-wif/internal/config"
 	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/gcp"
+	"github.com/Fordjour12/gcp-wif/internal/github"
 	"github.com/Fordjour12/gcp-wif/internal/logging"
 	"github.com/Fordjour12/gcp-wif/internal/ui"
 	"github.com/spf13/cobra"
@@ -18,9 +20,20 @@ var (
 	// Flags for config subcommands
 	outputFormat string
 	templateFile string
+	fromWorkflow string
 	backupDir    string
 	force        bool
 	validate     bool
+
+	// Flags for config migrate
+	migrateRepoID      int64
+	migrateRepoOwnerID int64
+	migrateApply       bool
+	migrateDryRun      bool
+
+	// Flags for config convert
+	convertTo    string
+	convertForce bool
 )
 
 // configCmd represents the config command
@@ -36,7 +49,13 @@ Available subcommands:
 - init: Create a new configuration file interactively
 - validate: Validate an existing configuration file
 - show: Display current configuration settings
-- backup: Create backup copies of configuration files`,
+- backup: Create backup copies of configuration files
+- migrate: Migrate a configuration file and pin it to the repository's IDs
+- convert: Convert a configuration file between JSON, YAML and TOML
+
+Configuration files may be JSON, YAML or TOML, chosen by their extension
+(.json, .yaml or .yml, .toml). Comments in YAML files are kept when the
+tool updates them.`,
 }
 
 // configInitCmd creates a new configuration file
@@ -51,7 +70,8 @@ all the necessary settings for Workload Identity Federation.
 Examples:
   gcp-wif config init                    # Interactive creation with default name
   gcp-wif config init my-config.json     # Interactive creation with custom name
-  gcp-wif config init --template app.json  # Create from template file`,
+  gcp-wif config init --template app.json  # Create from template file
+  gcp-wif config init --from-workflow .github/workflows/deploy.yml  # Bootstrap from an existing workflow`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runConfigInit(cmd, args); err != nil {
@@ -69,9 +89,15 @@ var configValidateCmd = &cobra.Command{
 This command checks that the configuration file has valid JSON syntax,
 contains all required fields, and passes validation rules.
 
+Organization policies are enforced as well when --policy is given or a
+.gcp-wif/policies directory exists. Policy rules select configuration or
+workflow fields by path and fail validation at error severity unless an
+unexpired waiver covers them.
+
 Examples:
   gcp-wif config validate                 # Validate default config file
-  gcp-wif config validate my-config.json  # Validate specific file`,
+  gcp-wif config validate my-config.json  # Validate specific file
+  gcp-wif config validate --policy org-policy.yaml  # Also enforce a policy file`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runConfigValidate(cmd, args); err != nil {
@@ -93,6 +119,7 @@ Examples:
   gcp-wif config show                     # Show default config file
   gcp-wif config show my-config.json      # Show specific file
   gcp-wif config show --format json       # Show as formatted JSON
+  gcp-wif config show --format yaml       # Show as YAML
   gcp-wif config show --format summary    # Show as summary`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -122,6 +149,55 @@ Examples:
 	},
 }
 
+// configMigrateCmd migrates a configuration file to the latest format
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [config-file]",
+	Short: "Migrate a configuration file and pin it to the repository's IDs",
+	Long: `Migrate a configuration file to the latest format.
+
+Conditions that match the repository and its owner by name are pinned to
+their immutable repository_id and repository_owner_id claims, so a renamed
+or deleted repository whose name is registered again cannot authenticate.
+The IDs are looked up through the GitHub API (GITHUB_TOKEN or a GitHub App)
+unless --repo-id and --repo-owner-id are given. A backup of the file is kept.
+
+With --apply the existing workload identity providers are pinned as well, and
+service accounts bound to the repository by name are bound to its ID instead.
+
+Examples:
+  gcp-wif config migrate                          # Migrate the default config file
+  gcp-wif config migrate --dry-run                # Show the pinned conditions only
+  gcp-wif config migrate --repo-id 123 --repo-owner-id 456
+  gcp-wif config migrate --apply                  # Also update the providers in GCP`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigMigrate(cmd, args); err != nil {
+			HandleError(err)
+		}
+	},
+}
+
+// configConvertCmd converts a configuration file between formats
+var configConvertCmd = &cobra.Command{
+	Use:   "convert <config-file> [output-file]",
+	Short: "Convert a configuration file between JSON, YAML and TOML",
+	Long: `Convert a configuration file between JSON, YAML and TOML.
+
+The formats are chosen by the file extensions. Without an output file the
+configuration is written next to the input with the extension of --to.
+
+Examples:
+  gcp-wif config convert wif-config.json wif-config.yaml
+  gcp-wif config convert wif-config.json --to toml
+  gcp-wif config convert wif-config.yaml wif-config.json --force`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigConvert(cmd, args); err != nil {
+			HandleError(err)
+		}
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 
@@ -130,19 +206,33 @@ func init() {
 	configCmd.AddCommand(configValidateCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configBackupCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configConvertCmd)
 
 	// Flags for config init
 	configInitCmd.Flags().StringVar(&templateFile, "template", "", "Template file to use for initialization")
 	configInitCmd.Flags().BoolVar(&force, "force", false, "Overwrite existing configuration file")
+	configInitCmd.Flags().StringVar(&fromWorkflow, "from-workflow", "", "Existing workflow file to bootstrap the configuration from")
 
 	// Flags for config show
-	configShowCmd.Flags().StringVar(&outputFormat, "format", "summary", "Output format (json, yaml, summary)")
+	configShowCmd.Flags().StringVar(&outputFormat, "format", "summary", "Output format (json, yaml, toml, summary)")
 
 	// Flags for config backup
 	configBackupCmd.Flags().StringVar(&backupDir, "dir", "./backups", "Directory for backup files")
 
 	// Flags for config validate
 	configValidateCmd.Flags().BoolVar(&validate, "strict", false, "Enable strict validation mode")
+	addPolicyFlag(configValidateCmd)
+
+	// Flags for config migrate
+	configMigrateCmd.Flags().Int64Var(&migrateRepoID, "repo-id", 0, "Numeric ID of the GitHub repository")
+	configMigrateCmd.Flags().Int64Var(&migrateRepoOwnerID, "repo-owner-id", 0, "Numeric ID of the repository's owner")
+	configMigrateCmd.Flags().BoolVar(&migrateApply, "apply", false, "Also pin the existing workload identity providers")
+	configMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Show the migrated conditions without saving")
+
+	// Flags for config convert
+	configConvertCmd.Flags().StringVar(&convertTo, "to", "", "Output format when no output file is given (json, yaml, toml)")
+	configConvertCmd.Flags().BoolVar(&convertForce, "force", false, "Overwrite an existing output file")
 }
 
 // runConfigInit handles the config init command
@@ -182,6 +272,10 @@ func runConfigInit(cmd *cobra.Command, args []string) error {
 		logger.Info("Using default configuration template")
 	}
 
+	if fromWorkflow != "" {
+		return initConfigFromWorkflow(cfg, configFile)
+	}
+
 	// Run interactive configuration
 	fmt.Println("🚀 Initializing new configuration file...")
 	fmt.Printf("📁 Target file: %s\n\n", configFile)
@@ -203,6 +297,34 @@ func runConfigInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// initConfigFromWorkflow bootstraps a configuration file from an existing workflow,
+// reporting the workflow constructs the configuration cannot represent
+func initConfigFromWorkflow(cfg *config.Config, configFile string) error {
+	logger := logging.WithField("command", "config_init")
+	logger.Info("Importing workflow", "workflow", fromWorkflow)
+
+	imported, err := github.ImportWorkflowFile(fromWorkflow)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrorTypeConfiguration, "WORKFLOW_IMPORT_FAILED",
+			fmt.Sprintf("Failed to import workflow file: %s", fromWorkflow))
+	}
+	cfg.ApplyImportedWorkflow(imported.Config)
+
+	if err := cfg.SaveToFile(configFile); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Configuration file created from %s: %s\n", fromWorkflow, configFile)
+	if len(imported.Unrecognized) > 0 {
+		fmt.Println()
+		displayUnrecognizedWorkflow(imported.Unrecognized)
+	}
+	fmt.Printf("\n💡 Review the settings with 'gcp-wif config validate %s'\n", configFile)
+
+	logger.Info("Configuration initialization completed", "file", configFile, "unrecognized", len(imported.Unrecognized))
+	return nil
+}
+
 // runConfigValidate handles the config validate command
 func runConfigValidate(cmd *cobra.Command, args []string) error {
 	logger := logging.WithField("command", "config_validate")
@@ -256,6 +378,10 @@ func runConfigValidate(cmd *cobra.Command, args []string) error {
 			"Use 'gcp-wif config show' to view current settings")
 	}
 
+	if err := enforcePolicies(cfg); err != nil {
+		return err
+	}
+
 	logger.Info("Configuration validation completed", "valid", result.Valid, "errors", len(result.Errors))
 	return nil
 }
@@ -276,13 +402,14 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	fmt.Printf("📋 Configuration: %s\n\n", configFile)
 
 	switch outputFormat {
-	case "json":
-		jsonStr, err := cfg.ToJSON()
+	case "json", "yaml", "toml":
+		format, _ := config.ParseFormat(outputFormat)
+		data, err := cfg.MarshalFormat(format)
 		if err != nil {
-			return errors.WrapError(err, errors.ErrorTypeInternal, "JSON_FORMAT_FAILED",
-				"Failed to format configuration as JSON")
+			return errors.WrapError(err, errors.ErrorTypeInternal, "CONFIG_FORMAT_FAILED",
+				fmt.Sprintf("Failed to format configuration as %s", strings.ToUpper(outputFormat)))
 		}
-		fmt.Println(jsonStr)
+		fmt.Println(strings.TrimRight(string(data), "\n"))
 
 	case "summary":
 		displayDetailedConfigSummary(cfg)
@@ -290,7 +417,7 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	default:
 		return errors.NewValidationError(
 			fmt.Sprintf("Unsupported output format: %s", outputFormat),
-			"Supported formats: json, summary")
+			"Supported formats: json, yaml, toml, summary")
 	}
 
 	logger.Info("Configuration displayed successfully", "format", outputFormat)
@@ -320,7 +447,8 @@ func runConfigBackup(cmd *cobra.Command, args []string) error {
 	// Generate backup filename with timestamp
 	timestamp := time.Now().Format("20060102-150405")
 	basename := strings.TrimSuffix(filepath.Base(configFile), filepath.Ext(configFile))
-	backupFile := filepath.Join(backupDir, fmt.Sprintf("%s-backup-%s.json", basename, timestamp))
+	extension := config.FormatFromPath(configFile).Extension()
+	backupFile := filepath.Join(backupDir, fmt.Sprintf("%s-backup-%s%s", basename, timestamp, extension))
 
 	// Copy file
 	data, err := os.ReadFile(configFile)
@@ -340,6 +468,166 @@ func runConfigBackup(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runConfigMigrate handles the config migrate command
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "config_migrate")
+	configFile := getConfigFilePath(args)
+
+	cfg, err := config.LoadFromFile(configFile)
+	if err != nil {
+		return err
+	}
+	migrated, err := cfg.MigrateToLatestVersion()
+	if err != nil {
+		return err
+	}
+
+	if migrateRepoID != 0 {
+		migrated.Repository.ID = migrateRepoID
+	}
+	if migrateRepoOwnerID != 0 {
+		migrated.Repository.OwnerID = migrateRepoOwnerID
+	}
+	if err := resolveRepositoryIDs(migrated); err != nil {
+		return errors.NewConfigurationError(
+			fmt.Sprintf("Failed to look up the IDs of %s: %v", migrated.GetRepoFullName(), err),
+			"Set GITHUB_TOKEN, or configure a GitHub App, to look them up through the GitHub API",
+			"Pass --repo-id and --repo-owner-id to set them directly")
+	}
+
+	fmt.Printf("📦 Repository: %s (ID %d, owner ID %d)\n",
+		migrated.GetRepoFullName(), migrated.Repository.ID, migrated.Repository.OwnerID)
+	for i, condition := range migrated.WorkloadIdentity.Conditions {
+		if i < len(cfg.WorkloadIdentity.Conditions) && cfg.WorkloadIdentity.Conditions[i] != condition {
+			fmt.Printf("   - %s\n", cfg.WorkloadIdentity.Conditions[i])
+			fmt.Printf("   + %s\n", condition)
+		}
+	}
+
+	if migrateDryRun {
+		fmt.Println("🔍 Dry run: configuration not saved")
+		return nil
+	}
+	if err := migrated.SaveWithBackup(configFile); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Configuration migrated: %s\n", configFile)
+	logger.Info("Configuration migrated", "file", configFile,
+		"repository_id", migrated.Repository.ID, "owner_id", migrated.Repository.OwnerID)
+
+	if !migrateApply {
+		fmt.Println("💡 Run 'gcp-wif config migrate --apply' to pin the existing providers too")
+		return nil
+	}
+	client, err := initializeGCPClient(migrated)
+	if err != nil {
+		return err
+	}
+	if err := pinWorkloadIdentityProviders(client, migrated); err != nil {
+		return err
+	}
+	return migrateServiceAccountBindings(client, migrated)
+}
+
+// runConfigConvert handles the config convert command
+func runConfigConvert(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "config_convert")
+	source := args[0]
+
+	destination := ""
+	if len(args) > 1 {
+		destination = args[1]
+	} else {
+		if convertTo == "" {
+			return errors.NewValidationError("An output file or --to is required",
+				"Example: gcp-wif config convert wif-config.json wif-config.yaml",
+				"Example: gcp-wif config convert wif-config.json --to yaml")
+		}
+		format, err := config.ParseFormat(convertTo)
+		if err != nil {
+			return err
+		}
+		destination = strings.TrimSuffix(source, filepath.Ext(source)) + format.Extension()
+	}
+
+	sourceFormat, destinationFormat := config.FormatFromPath(source), config.FormatFromPath(destination)
+	if filepath.Clean(source) == filepath.Clean(destination) {
+		return errors.NewValidationError(fmt.Sprintf("%s is already %s", source, strings.ToUpper(string(sourceFormat))),
+			"Choose an output file with another extension")
+	}
+	if !convertForce {
+		if _, err := os.Stat(destination); err == nil {
+			return errors.NewConfigurationError(
+				fmt.Sprintf("Output file already exists: %s", destination),
+				"Use --force to overwrite the existing file")
+		}
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return errors.WrapError(err, errors.ErrorTypeFileSystem, "CONFIG_READ_FAILED",
+			fmt.Sprintf("Failed to read configuration file: %s", source))
+	}
+	// The configuration is converted as written, without the defaults loading applies
+	cfg, err := config.UnmarshalFormat(data, sourceFormat)
+	if err != nil {
+		return errors.NewConfigurationError(
+			fmt.Sprintf("Invalid %s in configuration file: %s: %v", strings.ToUpper(string(sourceFormat)), source, err))
+	}
+	if err := cfg.SaveToFile(destination); err != nil {
+		return err
+	}
+
+	if sourceFormat == config.FormatYAML {
+		fmt.Println("💡 Comments in the YAML file are not carried over")
+	}
+	fmt.Printf("✅ Converted %s (%s) to %s (%s)\n", source, sourceFormat, destination, destinationFormat)
+	logger.Info("Configuration converted", "source", source, "destination", destination)
+	return nil
+}
+
+// pinWorkloadIdentityProviders pins every provider the configuration creates to the
+// repository's IDs
+func pinWorkloadIdentityProviders(client *gcp.Client, cfg *config.Config) error {
+	providerIDs := []string{cfg.WorkloadIdentity.ProviderID}
+	if cfg.Workflow.IsTerraform() {
+		providerIDs = append(providerIDs, cfg.GetTerraformPlanProviderID())
+	}
+	for _, name := range pipelineEnvironments(cfg) {
+		env := cfg.Environments[name]
+		providerIDs = append(providerIDs, cfg.GetEnvironmentProviderID(&env))
+	}
+
+	for _, providerID := range providerIDs {
+		before, after, err := client.PinWorkloadIdentityProvider(cfg.WorkloadIdentity.PoolID, providerID,
+			cfg.GetRepoFullName(), cfg.Repository.ID, cfg.Repository.OwnerID)
+		if err != nil {
+			return err
+		}
+		if before == after {
+			fmt.Printf("   ✅ %s condition already pinned\n", providerID)
+			continue
+		}
+		fmt.Printf("   ✅ %s pinned: %s\n", providerID, after)
+	}
+	return nil
+}
+
+// migrateServiceAccountBindings moves the service account bindings that select the
+// repository by name to the principal set of its ID, once the providers map it
+func migrateServiceAccountBindings(client *gcp.Client, cfg *config.Config) error {
+	for _, binding := range serviceAccountBindingConfigs(cfg) {
+		migrated, err := client.MigrateServiceAccountBindingToRepositoryID(binding)
+		if err != nil {
+			return err
+		}
+		if migrated {
+			fmt.Printf("   ✅ %s bound to %s\n", binding.ServiceAccountEmail, gcp.RepositoryMember(binding.Repository, binding.RepositoryID))
+		}
+	}
+	return nil
+}
+
 // getConfigFilePath determines the configuration file path from args or default
 func getConfigFilePath(args []string) string {
 	if len(args) > 0 {
@@ -348,7 +636,7 @@ func getConfigFilePath(args []string) string {
 	if cfgFile != "" {
 		return cfgFile
 	}
-	return "./wif-config.json"
+	return config.DefaultConfigFile()
 }
 
 // displayDetailedConfigSummary displays a human-readable configuration summary
@@ -43,6 +43,12 @@ Environment Types:
 • development - Development environment for feature development
 • testing     - Dedicated testing environment for automated tests
 
+Generated workflows deploy every enabled environment in its own job, in promotion order
+(development, testing, staging, production), each waiting for the previous one. Each job
+runs in its GitHub environment, so approvals configured there gate the deployment, and
+authenticates with the environment's own service account and provider, which setup
+creates from the environment's name suffixes.
+
 Examples:
   # List all environments
   gcp-wif env list
@@ -212,7 +218,7 @@ func init() {
 	envCreateCmd.Flags().StringVar(&envDescription, "description", "", "Environment description")
 	envCreateCmd.Flags().StringVar(&envRegion, "region", "us-central1", "GCP region for the environment")
 	envCreateCmd.Flags().BoolVar(&envEnabled, "enabled", true, "Enable the environment")
-	envCreateCmd.Flags().StringVar(&envTemplate, "template", "", "Workflow template: production, staging, development")
+	envCreateCmd.Flags().StringVar(&envTemplate, "template", "", "Workflow template: production, staging, development, or a catalog template")
 	envCreateCmd.MarkFlagRequired("name")
 
 	// Output formatting flags
@@ -646,5 +652,5 @@ func getEnvConfigFilePath() string {
 	}
 
 	// Default fallback
-	return "wif-config.json"
+	return config.DefaultConfigFile()
 }
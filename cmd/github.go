@@ -0,0 +1,621 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/config"
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/gcp"
+	"github.com/Fordjour12/gcp-wif/internal/github"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	githubConfigFile        string
+	githubAPIURL            string
+	githubAppID             string
+	githubAppInstallationID string
+	githubAppPrivateKeyFile string
+	githubEnvironment       string
+	githubSecrets           []string
+	githubDryRun            bool
+	githubCheck             bool
+	githubFormat            string
+	githubSubjectClaims     []string
+	githubSubjectDefault    bool
+	githubSubjectSave       bool
+)
+
+// githubCmd represents the github command
+var githubCmd = &cobra.Command{
+	Use:   "github",
+	Short: "Manage GitHub repository settings through the GitHub API",
+	Long: `Write the Workload Identity Federation outputs into the GitHub repository, so they
+no longer need to be copied into the repository settings by hand.
+
+Requests authenticate with GITHUB_TOKEN (or GH_TOKEN), or as a GitHub App with
+--app-id and --app-private-key-file (GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID and
+GITHUB_APP_PRIVATE_KEY_FILE). Use --api-url (GITHUB_API_URL) for GitHub Enterprise
+Server, e.g. https://github.example.com/api/v3.
+
+Available subcommands:
+• variables    - Write the WIF outputs to GitHub Actions variables
+• environments - Create and protect the GitHub deployment environments, or report drift
+• oidc-subject - Show or customize the OIDC subject claim of the repository's tokens
+
+Examples:
+  # Write the project ID, provider and service account to repository variables
+  GITHUB_TOKEN=... gcp-wif github variables
+
+  # Reference the variables from the workflow instead of writing the values out
+  gcp-wif workflow generate --use-variables --overwrite`,
+}
+
+// githubVariablesCmd represents the github variables command
+var githubVariablesCmd = &cobra.Command{
+	Use:   "variables",
+	Short: "Write the WIF outputs to GitHub Actions variables",
+	Long: `Write the project ID, workload identity provider and service account to the
+repository's Actions variables PROJECT_ID, WORKLOAD_IDENTITY_PROVIDER and SERVICE_ACCOUNT.
+Environments of the pipeline that deploy with their own identity get environment-level
+WORKLOAD_IDENTITY_PROVIDER and SERVICE_ACCOUNT variables, which take precedence in jobs
+deploying to them. Variables that already hold the value are left untouched.
+
+Secrets are written with --secret NAME, which reads the value from the environment
+variable NAME so it never appears on the command line.
+
+Examples:
+  gcp-wif github variables
+  gcp-wif github variables --environment production
+  DB_PASSWORD=... gcp-wif github variables --secret DB_PASSWORD --environment staging
+  gcp-wif github variables --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runGitHubVariables(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+// githubEnvironmentsCmd represents the github environments command
+var githubEnvironmentsCmd = &cobra.Command{
+	Use:   "environments",
+	Short: "Create and protect the GitHub deployment environments",
+	Long: `Create or update the GitHub deployment environments of the workflow configuration
+with their protection rules: required reviewers, wait timer, prevention of self-review and
+the deployment branch policy.
+
+Reviewers are written as @user, org/team or @org/team. A bare @name that is not a user is
+looked up as a team of the repository owner.
+
+With --check, nothing is changed: the settings that differ between the configuration and
+GitHub are reported, and the command fails when there is drift.
+
+Examples:
+  gcp-wif github environments
+  gcp-wif github environments --environment production
+  gcp-wif github environments --check --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runGitHubEnvironments(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+// githubOIDCSubjectCmd represents the github oidc-subject command
+var githubOIDCSubjectCmd = &cobra.Command{
+	Use:   "oidc-subject",
+	Short: "Show or customize the OIDC subject claim of the repository's tokens",
+	Long: `Show the template GitHub builds the sub claim of the repository's OIDC tokens from,
+and check the workload identity conditions of the configuration against it. The default
+subject is repo:OWNER/REPO followed by the job's context: its environment, ref or
+pull_request. Repositories and organizations can customize it to include claims such as
+job_workflow_ref, repository_id or environment instead.
+
+With --set the repository's template is customized, and with --use-default it reverts to
+the organization's template or GitHub's default. Both, and --save, record the template
+in the configuration so providers map the subject claims and conditions are validated
+against them.
+
+Examples:
+  gcp-wif github oidc-subject
+  gcp-wif github oidc-subject --set repo,context,job_workflow_ref
+  gcp-wif github oidc-subject --use-default
+  gcp-wif github oidc-subject --save`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runGitHubOIDCSubject(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(githubCmd)
+	githubCmd.AddCommand(githubVariablesCmd)
+	githubCmd.AddCommand(githubEnvironmentsCmd)
+	githubCmd.AddCommand(githubOIDCSubjectCmd)
+
+	githubCmd.PersistentFlags().StringVarP(&githubConfigFile, "config", "c", "", "Configuration file path")
+	githubCmd.PersistentFlags().StringVar(&githubAPIURL, "api-url", "", "GitHub REST API URL (default: GITHUB_API_URL or "+github.DefaultGitHubAPIURL+")")
+	githubCmd.PersistentFlags().StringVar(&githubAppID, "app-id", "", "Authenticate as this GitHub App (default: GITHUB_APP_ID)")
+	githubCmd.PersistentFlags().StringVar(&githubAppInstallationID, "app-installation-id", "", "GitHub App installation ID (default: looked up for the repository)")
+	githubCmd.PersistentFlags().StringVar(&githubAppPrivateKeyFile, "app-private-key-file", "", "GitHub App private key file (default: GITHUB_APP_PRIVATE_KEY_FILE)")
+
+	githubVariablesCmd.Flags().StringVar(&githubEnvironment, "environment", "", "Write only to this GitHub environment")
+	githubVariablesCmd.Flags().StringSliceVar(&githubSecrets, "secret", nil, "Also write this secret, read from the environment variable of the same name (repeatable)")
+	githubVariablesCmd.Flags().BoolVar(&githubDryRun, "dry-run", false, "Show the variables that would be written without calling the API")
+
+	githubEnvironmentsCmd.Flags().StringVar(&githubEnvironment, "environment", "", "Only apply or check this GitHub environment")
+	githubEnvironmentsCmd.Flags().BoolVar(&githubCheck, "check", false, "Report drift between the configuration and GitHub without making changes")
+	githubEnvironmentsCmd.Flags().StringVar(&githubFormat, "format", "summary", "Output format (summary, json)")
+
+	githubOIDCSubjectCmd.Flags().StringSliceVar(&githubSubjectClaims, "set", nil, "Customize the subject to these claims, in order (e.g. repo,context,job_workflow_ref)")
+	githubOIDCSubjectCmd.Flags().BoolVar(&githubSubjectDefault, "use-default", false, "Revert the repository to the organization's or GitHub's default subject")
+	githubOIDCSubjectCmd.Flags().BoolVar(&githubSubjectSave, "save", false, "Record the repository's current subject template in the configuration")
+}
+
+// loadGitHubConfig loads the configuration with its environments applied to the workflow
+func loadGitHubConfig() (*config.Config, error) {
+	var cfg *config.Config
+	var err error
+	if githubConfigFile != "" {
+		cfg, err = config.LoadFromFile(githubConfigFile)
+	} else {
+		cfg, err = config.LoadFromFileWithDiscovery("")
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg.SetDefaults()
+	cfg.ApplyEnvironmentPipeline()
+	return cfg, nil
+}
+
+func runGitHubVariables(cmd *cobra.Command, args []string) error {
+	cfg, err := loadGitHubConfig()
+	if err != nil {
+		return err
+	}
+
+	if err := pushActionsVariables(cfg, githubEnvironment, githubSecrets, githubDryRun); err != nil {
+		return err
+	}
+	if !cfg.Workflow.UseActionsVariables && !githubDryRun {
+		fmt.Println("\n💡 Regenerate the workflow with 'gcp-wif workflow generate --use-variables' to read these variables")
+	}
+	return nil
+}
+
+// actionsVariableWrite is a set of variables written to one scope
+type actionsVariableWrite struct {
+	scope     github.VariableScope
+	variables map[string]string
+}
+
+// pushActionsVariables writes the WIF outputs, and the named secrets, to the repository's
+// Actions variables, or only to one environment's
+func pushActionsVariables(cfg *config.Config, environment string, secrets []string, dryRun bool) error {
+	logger := logging.WithField("function", "pushActionsVariables")
+
+	if cfg.Repository.Owner == "" || cfg.Repository.Name == "" {
+		return errors.NewConfigurationError("Repository owner and name are required to write Actions variables",
+			"Set repository.owner and repository.name in the configuration")
+	}
+	workflow := &cfg.Workflow
+	if workflow.ProjectID == "" || workflow.WorkloadIdentityProvider == "" || workflow.ServiceAccountEmail == "" {
+		return errors.NewConfigurationError("The project ID, workload identity provider and service account must be configured",
+			"Run 'gcp-wif setup' or complete the configuration first")
+	}
+
+	repository := github.VariableScope{Owner: cfg.Repository.Owner, Repo: cfg.Repository.Name}
+	var writes []actionsVariableWrite
+	secretScope := repository
+	if environment != "" {
+		serviceAccount, provider := workflow.GetEnvironmentIdentity(environment)
+		secretScope.Environment = environment
+		writes = append(writes, actionsVariableWrite{scope: secretScope, variables: map[string]string{
+			github.VariableWorkloadIdentityProvider: provider,
+			github.VariableServiceAccount:           serviceAccount,
+		}})
+	} else {
+		writes = append(writes, actionsVariableWrite{scope: repository, variables: workflow.ActionsVariables()})
+		environments := workflow.EnvironmentActionsVariables()
+		names := make([]string, 0, len(environments))
+		for name := range environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			scope := repository
+			scope.Environment = name
+			writes = append(writes, actionsVariableWrite{scope: scope, variables: environments[name]})
+		}
+	}
+
+	secretValues := make(map[string]string)
+	for _, name := range secrets {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return errors.NewValidationError(fmt.Sprintf("Environment variable %s is not set", name),
+				fmt.Sprintf("Export %s with the secret value before running the command", name))
+		}
+		secretValues[name] = value
+	}
+
+	if dryRun {
+		fmt.Println("🔍 Dry run - the following would be written:")
+		for _, write := range writes {
+			fmt.Printf("\n📦 %s\n", write.scope)
+			names := make([]string, 0, len(write.variables))
+			for name := range write.variables {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("   • variable %s = %s\n", name, write.variables[name])
+			}
+		}
+		for _, name := range secrets {
+			fmt.Printf("   • secret %s in %s\n", name, secretScope)
+		}
+		return nil
+	}
+
+	client, err := newGitHubAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	var changes []github.VariableChange
+	for _, write := range writes {
+		written, err := client.SetVariables(ctx, write.scope, write.variables)
+		changes = append(changes, written...)
+		if err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(secretValues))
+	for name := range secretValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		change, err := client.SetSecret(ctx, secretScope, name, secretValues[name])
+		if err != nil {
+			return err
+		}
+		changes = append(changes, change)
+	}
+
+	fmt.Printf("📤 GitHub Actions variables of %s\n\n", repository)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SCOPE\tNAME\tRESULT")
+	fmt.Fprintln(w, "-----\t----\t------")
+	for _, change := range changes {
+		scope := "repository"
+		if change.Scope.Environment != "" {
+			scope = "environment " + change.Scope.Environment
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", scope, change.Name, change.Action)
+	}
+	w.Flush()
+
+	logger.Info("GitHub Actions variables written", "repository", repository.String(), "count", len(changes))
+	return nil
+}
+
+// newGitHubAPIClient creates a GitHub API client from the environment and the github
+// command's flags, which take precedence
+func newGitHubAPIClient() (*github.APIClient, error) {
+	apiConfig, err := github.APIClientConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if githubAPIURL != "" {
+		apiConfig.BaseURL = githubAPIURL
+	}
+	if githubAppID != "" {
+		apiConfig.AppID = githubAppID
+	}
+	if githubAppInstallationID != "" {
+		apiConfig.AppInstallationID = githubAppInstallationID
+	}
+	if githubAppPrivateKeyFile != "" {
+		key, err := os.ReadFile(githubAppPrivateKeyFile)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "GITHUB_APP_KEY_READ_FAILED",
+				fmt.Sprintf("Failed to read GitHub App private key %s", githubAppPrivateKeyFile))
+		}
+		apiConfig.AppPrivateKey = key
+	}
+	return github.NewAPIClient(apiConfig)
+}
+
+// resolveRepositoryIDs pins the configuration to the immutable IDs of the repository and
+// its owner, looking up the ones that are not configured through the GitHub API
+func resolveRepositoryIDs(cfg *config.Config) error {
+	repositoryID, ownerID := cfg.Repository.ID, cfg.Repository.OwnerID
+	if repositoryID == 0 || ownerID == 0 {
+		if cfg.Repository.Owner == "" || cfg.Repository.Name == "" {
+			return errors.NewConfigurationError("Repository owner and name are required to look up the repository IDs")
+		}
+		client, err := newGitHubAPIClient()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		repository, err := client.GetRepository(ctx, cfg.Repository.Owner, cfg.Repository.Name)
+		if err != nil {
+			return err
+		}
+		if repositoryID == 0 {
+			repositoryID = repository.ID
+		}
+		if ownerID == 0 {
+			ownerID = repository.OwnerID
+		}
+	}
+	cfg.PinRepositoryIDs(repositoryID, ownerID)
+	return nil
+}
+
+func runGitHubEnvironments(cmd *cobra.Command, args []string) error {
+	cfg, err := loadGitHubConfig()
+	if err != nil {
+		return err
+	}
+	if githubFormat != "summary" && githubFormat != "json" {
+		return errors.NewValidationError(fmt.Sprintf("Invalid format '%s'", githubFormat), "Use summary or json")
+	}
+
+	results, err := applyGitHubEnvironments(cfg, githubEnvironment, githubCheck)
+	if err != nil {
+		return err
+	}
+
+	var drifts []github.EnvironmentDrift
+	for _, result := range results {
+		drifts = append(drifts, result.Drift...)
+	}
+	if githubFormat == "json" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return errors.NewInternalError("Failed to encode environment report", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		displayEnvironmentResults(results, githubCheck)
+	}
+
+	if githubCheck && len(drifts) > 0 {
+		return errors.NewGitHubError(fmt.Sprintf("%d setting(s) of the GitHub environments differ from the configuration", len(drifts)),
+			"Run 'gcp-wif github environments' to apply the configuration",
+			"Or update the configuration to match the settings changed in GitHub")
+	}
+	return nil
+}
+
+// environmentResult is what applying or checking one GitHub environment found and did
+type environmentResult struct {
+	Environment string                    `json:"environment"`
+	Action      string                    `json:"action"` // created, updated, unchanged or drifted (with --check)
+	Drift       []github.EnvironmentDrift `json:"drift,omitempty"`
+}
+
+// applyGitHubEnvironments creates or updates the GitHub environments of the workflow
+// configuration, or only one of them. With check set the drift is reported without changes.
+func applyGitHubEnvironments(cfg *config.Config, environment string, check bool) ([]environmentResult, error) {
+	logger := logging.WithField("function", "applyGitHubEnvironments")
+
+	if cfg.Repository.Owner == "" || cfg.Repository.Name == "" {
+		return nil, errors.NewConfigurationError("Repository owner and name are required to manage GitHub environments",
+			"Set repository.owner and repository.name in the configuration")
+	}
+	if err := cfg.Workflow.ValidateEnvironments(); err != nil {
+		return nil, errors.NewValidationError(err.Error(), "Fix the environment protection settings in the configuration")
+	}
+
+	environments := cfg.Workflow.Advanced.Environments
+	names := make([]string, 0, len(environments))
+	for name := range environments {
+		if environment == "" || name == environment {
+			names = append(names, name)
+		}
+	}
+	if environment != "" && len(names) == 0 {
+		return nil, errors.NewConfigurationError(fmt.Sprintf("Environment '%s' is not configured", environment),
+			"Add it with 'gcp-wif setup --env-names' or to workflow.advanced.environments")
+	}
+	sort.Strings(names)
+
+	client, err := newGitHubAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	owner, repo := cfg.Repository.Owner, cfg.Repository.Name
+	var results []environmentResult
+	for _, name := range names {
+		env := environments[name]
+		env.Name = name
+
+		remote, err := client.GetEnvironment(ctx, owner, repo, name)
+		if err != nil {
+			return results, err
+		}
+		result := environmentResult{Environment: name, Action: "unchanged", Drift: github.CompareEnvironment(owner, env, remote)}
+		switch {
+		case len(result.Drift) == 0:
+		case check:
+			result.Action = "drifted"
+		default:
+			if err := client.ApplyEnvironment(ctx, owner, repo, env); err != nil {
+				return results, err
+			}
+			result.Action = "updated"
+			if remote == nil {
+				result.Action = "created"
+			}
+		}
+		results = append(results, result)
+		logger.Info("GitHub environment processed", "environment", name, "action", result.Action, "drift", len(result.Drift))
+	}
+	return results, nil
+}
+
+// displayEnvironmentResults prints the environments and the drift found in them
+func displayEnvironmentResults(results []environmentResult, check bool) {
+	if len(results) == 0 {
+		fmt.Println("ℹ️  No environments are configured")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ENVIRONMENT\tRESULT")
+	fmt.Fprintln(w, "-----------\t------")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\n", result.Environment, result.Action)
+	}
+	w.Flush()
+
+	var drifted bool
+	for _, result := range results {
+		drifted = drifted || len(result.Drift) > 0
+	}
+	if !drifted {
+		fmt.Println("\n✅ GitHub environments match the configuration")
+		return
+	}
+
+	if check {
+		fmt.Println("\n⚠️  Drift between the configuration and GitHub:")
+	} else {
+		fmt.Println("\n🔧 Settings applied:")
+	}
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ENVIRONMENT\tSETTING\tCONFIGURED\tGITHUB")
+	fmt.Fprintln(w, "-----------\t-------\t----------\t------")
+	for _, result := range results {
+		for _, drift := range result.Drift {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", drift.Environment, drift.Field, valueOrNone(drift.Expected), valueOrNone(drift.Actual))
+		}
+	}
+	w.Flush()
+}
+
+// valueOrNone returns the value, or "(none)" when it is empty
+func valueOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+func runGitHubOIDCSubject(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("function", "runGitHubOIDCSubject")
+
+	if len(githubSubjectClaims) > 0 && githubSubjectDefault {
+		return errors.NewValidationError("--set and --use-default cannot be combined")
+	}
+	if err := gcp.ValidateSubjectClaimKeys(githubSubjectClaims); err != nil {
+		return err
+	}
+	cfg, err := loadGitHubConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Repository.Owner == "" || cfg.Repository.Name == "" {
+		return errors.NewConfigurationError("Repository owner and name are required to manage the OIDC subject",
+			"Set repository.owner and repository.name in the configuration")
+	}
+
+	// Check the configured conditions before changing the template under them
+	setting := len(githubSubjectClaims) > 0 || githubSubjectDefault
+	if setting {
+		cfg.WorkloadIdentity.SubjectClaimKeys = githubSubjectClaims
+		if err := cfg.GetClaimsMapping().ValidateSubjectConditions(cfg.WorkloadIdentity.Conditions...); err != nil {
+			return err
+		}
+	}
+
+	client, err := newGitHubAPIClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	owner, repo := cfg.Repository.Owner, cfg.Repository.Name
+	if setting {
+		if err := client.SetOIDCSubjectTemplate(ctx, owner, repo, githubSubjectClaims); err != nil {
+			return err
+		}
+		logger.Info("OIDC subject template updated", "repository", cfg.GetRepoFullName(), "claims", githubSubjectClaims)
+	}
+	template, err := client.GetOIDCSubjectTemplate(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+
+	claims := template.ClaimKeys
+	if len(claims) == 0 {
+		claims = gcp.DefaultSubjectClaimKeys
+	}
+	fmt.Printf("🪪 OIDC subject of %s\n\n", cfg.GetRepoFullName())
+	fmt.Printf("   • Claims: %s\n", strings.Join(claims, ", "))
+	fmt.Printf("   • Set by: %s\n", template.Source)
+
+	cfg.WorkloadIdentity.SubjectClaimKeys = template.ClaimKeys
+	claimsMapping := cfg.GetClaimsMapping()
+	if claimsMapping.SubjectMayExceedLimit() {
+		fmt.Printf("\n⚠️  Subjects built from these claims may exceed the %d character google.subject limit\n", gcp.MaxSubjectLength)
+	}
+	if err := claimsMapping.ValidateSubjectConditions(cfg.WorkloadIdentity.Conditions...); err != nil {
+		fmt.Printf("\n⚠️  %v\n", err)
+	} else {
+		fmt.Println("\n✅ The workload identity conditions hold for this subject")
+	}
+
+	if setting || githubSubjectSave {
+		return saveSubjectClaimKeys(template.ClaimKeys)
+	}
+	return nil
+}
+
+// saveSubjectClaimKeys records the subject template in the configuration file, loaded
+// without defaults so only the template changes
+func saveSubjectClaimKeys(claimKeys []string) error {
+	configFile := githubConfigFile
+	if configFile == "" {
+		discovered, err := config.AutoDiscoverConfigFile()
+		if err != nil {
+			return err
+		}
+		configFile = discovered
+	}
+	cfg, err := config.LoadFromFile(configFile)
+	if err != nil {
+		return err
+	}
+	cfg.WorkloadIdentity.SubjectClaimKeys = claimKeys
+	if err := cfg.SaveToFile(configFile); err != nil {
+		return err
+	}
+	fmt.Printf("💾 Subject template recorded in %s\n", configFile)
+	return nil
+}
@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/config"
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/gcp"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importProjectID      string
+	importPoolID         string
+	importProviderID     string
+	importServiceAccount string
+	importOutput         string
+	importAll            bool
+	importDryRun         bool
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import existing Workload Identity Federation resources into a configuration file",
+	Long: `Reverse-engineer a configuration file from workload identity pools and providers
+that were created outside of this tool.
+
+The import reads each provider's attribute condition, attribute mapping and allowed
+audiences, looks up service accounts bound to the pool, and infers the repository,
+allowed branches, tags and pull request policy from the CEL condition. The resulting
+configuration can then be managed with the other gcp-wif commands.
+
+When more than one provider is imported, one file is written per provider named
+<output>-<pool>-<provider>, as provider IDs are only unique within a pool
+(e.g. wif-config-github-pool-github-provider.json).
+
+Examples:
+  # Import a single provider
+  gcp-wif import --project-id my-project --pool-id github-pool --provider-id github-provider
+
+  # Import every provider in a pool
+  gcp-wif import --project-id my-project --pool-id github-pool
+
+  # Discover and import all pools and providers in the project
+  gcp-wif import --project-id my-project --all
+
+  # Only consider bindings on a specific service account
+  gcp-wif import --project-id my-project --pool-id github-pool --service-account deployer@my-project.iam.gserviceaccount.com
+
+  # Preview the generated configuration without writing files
+  gcp-wif import --project-id my-project --all --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runImportCommand(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importProjectID, "project-id", "", "Google Cloud Project ID")
+	importCmd.Flags().StringVar(&importPoolID, "pool-id", "", "Workload identity pool ID to import")
+	importCmd.Flags().StringVar(&importProviderID, "provider-id", "", "Workload identity provider ID to import (default: all providers in the pool)")
+	importCmd.Flags().StringVar(&importServiceAccount, "service-account", "", "Service account email to inspect for bindings (default: all service accounts in the project)")
+	importCmd.Flags().StringVarP(&importOutput, "output", "o", "wif-config.json", "Output configuration file")
+	importCmd.Flags().BoolVar(&importAll, "all", false, "Discover and import all pools and providers in the project")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Print the imported configuration without writing files")
+}
+
+// importTarget identifies a provider discovered for import
+type importTarget struct {
+	PoolID   string
+	Pool     *gcp.WorkloadIdentityPoolInfo
+	Provider *gcp.WorkloadIdentityProviderInfo
+}
+
+// importBinding associates a workload identity binding with the service account that carries it
+type importBinding struct {
+	ServiceAccountEmail string
+	Binding             gcp.WorkloadIdentityBinding
+}
+
+func runImportCommand(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "import")
+	logger.Info("Starting workload identity import")
+
+	if importProjectID == "" {
+		return errors.NewValidationError(
+			"Project ID is required",
+			"Specify the project with --project-id")
+	}
+
+	if !importAll && importPoolID == "" {
+		return errors.NewValidationError(
+			"No import scope specified",
+			"Use --pool-id to import a specific pool",
+			"Use --all to discover all pools in the project")
+	}
+
+	if importProviderID != "" && importPoolID == "" {
+		return errors.NewValidationError(
+			"--provider-id requires --pool-id",
+			"Example: gcp-wif import --project-id my-project --pool-id my-pool --provider-id my-provider")
+	}
+
+	fmt.Println("📥 Importing Workload Identity Federation resources")
+	fmt.Println("===================================================")
+
+	client, err := gcp.NewClient(context.Background(), importProjectID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔍 Discovering providers in project %s...\n", importProjectID)
+	targets, err := discoverImportTargets(client)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No workload identity providers found to import.")
+		return nil
+	}
+
+	fmt.Printf("🔍 Collecting service account bindings...\n")
+	bindings, err := collectImportBindings(client)
+	if err != nil {
+		return err
+	}
+
+	imported := 0
+	for _, target := range targets {
+		providerID := path.Base(target.Provider.Name)
+		fmt.Printf("\n📋 %s/%s\n", target.PoolID, providerID)
+
+		cfg, warnings, err := buildImportedConfig(client, target, bindings)
+		if err != nil {
+			fmt.Printf("   ❌ Skipped: %v\n", err)
+			logger.Warn("Skipping provider", "pool_id", target.PoolID, "provider_id", providerID, "error", err)
+			continue
+		}
+
+		for _, warning := range warnings {
+			fmt.Printf("   ⚠️  %s\n", warning)
+		}
+
+		fmt.Printf("   Repository: %s\n", cfg.GetRepoFullName())
+		if len(cfg.Repository.Branches) > 0 {
+			fmt.Printf("   Branches: %s\n", strings.Join(cfg.Repository.Branches, ", "))
+		}
+		if len(cfg.Repository.Tags) > 0 {
+			fmt.Printf("   Tags: %s\n", strings.Join(cfg.Repository.Tags, ", "))
+		}
+		fmt.Printf("   Pull Requests: %t\n", cfg.Repository.PullRequest)
+		fmt.Printf("   Service Account: %s\n", cfg.GetServiceAccountEmail())
+
+		if importDryRun {
+			data, err := cfg.ToJSON()
+			if err != nil {
+				return errors.WrapError(err, errors.ErrorTypeInternal, "CONFIG_MARSHAL_FAILED",
+					"Failed to serialize imported configuration")
+			}
+			fmt.Println(data)
+			imported++
+			continue
+		}
+
+		outputPath := importOutputPath(importOutput, target.PoolID, providerID, len(targets) > 1)
+		if err := cfg.SaveWithBackup(outputPath); err != nil {
+			fmt.Printf("   ❌ Failed to save configuration: %v\n", err)
+			logger.Warn("Failed to save imported configuration", "file", outputPath, "error", err)
+			continue
+		}
+		fmt.Printf("   ✅ Configuration written: %s\n", outputPath)
+		imported++
+
+		logger.Info("Provider imported", "pool_id", target.PoolID, "provider_id", providerID, "file", outputPath)
+	}
+
+	fmt.Printf("\n📊 Imported %d of %d provider(s)\n", imported, len(targets))
+	if imported == 0 {
+		return errors.NewConfigurationError(
+			"No providers could be imported",
+			"Check that provider conditions reference assertion.repository",
+			"Run with --verbose for more details")
+	}
+
+	if !importDryRun {
+		fmt.Println("\n💡 Review the imported configuration, then manage it with:")
+		fmt.Println("   gcp-wif config validate <file>")
+		fmt.Println("   gcp-wif setup --config <file> --dry-run")
+	}
+
+	logger.Info("Workload identity import completed", "imported", imported, "discovered", len(targets))
+	return nil
+}
+
+// discoverImportTargets resolves the pools and providers selected by the import flags
+func discoverImportTargets(client *gcp.Client) ([]importTarget, error) {
+	var pools []*gcp.WorkloadIdentityPoolInfo
+
+	if importPoolID != "" {
+		pool, err := client.GetWorkloadIdentityPoolInfo(importPoolID)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.Exists {
+			return nil, errors.NewGCPError(
+				fmt.Sprintf("Workload identity pool %s not found in project %s", importPoolID, importProjectID),
+				"List available pools with: gcloud iam workload-identity-pools list --location global")
+		}
+		pools = append(pools, pool)
+	} else {
+		discovered, err := client.ListWorkloadIdentityPools()
+		if err != nil {
+			return nil, err
+		}
+		pools = discovered
+	}
+
+	var targets []importTarget
+	for _, pool := range pools {
+		poolID := path.Base(pool.Name)
+
+		if importProviderID != "" {
+			provider, err := client.GetWorkloadIdentityProviderInfo(poolID, importProviderID)
+			if err != nil {
+				return nil, err
+			}
+			if !provider.Exists {
+				return nil, errors.NewGCPError(
+					fmt.Sprintf("Workload identity provider %s not found in pool %s", importProviderID, poolID))
+			}
+			targets = append(targets, importTarget{PoolID: poolID, Pool: pool, Provider: provider})
+			continue
+		}
+
+		providers, err := client.ListWorkloadIdentityProviders(poolID)
+		if err != nil {
+			return nil, err
+		}
+		for _, provider := range providers {
+			targets = append(targets, importTarget{PoolID: poolID, Pool: pool, Provider: provider})
+		}
+	}
+
+	return targets, nil
+}
+
+// collectImportBindings gathers workload identity bindings from the selected service accounts
+func collectImportBindings(client *gcp.Client) ([]importBinding, error) {
+	logger := logging.WithField("function", "collectImportBindings")
+
+	var emails []string
+	if importServiceAccount != "" {
+		emails = append(emails, importServiceAccount)
+	} else {
+		accounts, err := client.ListServiceAccounts()
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range accounts {
+			emails = append(emails, account.Email)
+		}
+	}
+
+	var bindings []importBinding
+	for _, email := range emails {
+		saBindings, err := client.ListServiceAccountWorkloadIdentityBindings(email)
+		if err != nil {
+			// A single unreadable policy should not abort the whole import
+			logger.Warn("Failed to read service account bindings", "service_account", email, "error", err)
+			continue
+		}
+		for _, binding := range saBindings {
+			bindings = append(bindings, importBinding{ServiceAccountEmail: email, Binding: binding})
+		}
+	}
+
+	logger.Debug("Collected workload identity bindings", "service_accounts", len(emails), "bindings", len(bindings))
+	return bindings, nil
+}
+
+// buildImportedConfig converts a discovered provider and its bindings into a configuration
+func buildImportedConfig(client *gcp.Client, target importTarget, bindings []importBinding) (*config.Config, []string, error) {
+	provider := target.Provider
+	providerID := path.Base(provider.Name)
+	parsed := gcp.ParseAttributeCondition(provider.AttributeCondition)
+
+	var warnings []string
+	if provider.Disabled {
+		warnings = append(warnings, "Provider is disabled")
+	}
+	for _, clause := range parsed.Unrecognized {
+		warnings = append(warnings, fmt.Sprintf("Condition clause not mapped to configuration: %s", clause))
+	}
+
+	// Find bindings that reference this pool (and provider, when the member is provider-scoped)
+	var matched []importBinding
+	for _, b := range bindings {
+		if b.Binding.PoolID != target.PoolID {
+			continue
+		}
+		if b.Binding.ProviderID != "" && b.Binding.ProviderID != providerID {
+			continue
+		}
+		matched = append(matched, b)
+	}
+
+	// Fall back to the repository in a principalSet member when the condition has none
+	repository := parsed.Repository
+	if repository == "" {
+		for _, b := range matched {
+			if b.Binding.Repository != "" {
+				repository = b.Binding.Repository
+				warnings = append(warnings, "Repository inferred from IAM binding member; provider condition does not restrict repository")
+				break
+			}
+		}
+	}
+	if repository == "" {
+		return nil, nil, errors.NewValidationError(
+			fmt.Sprintf("Could not infer repository for provider %s", providerID),
+			"The provider condition does not reference assertion.repository",
+			"Add a repository condition to the provider, then import again")
+	}
+	parsed.Repository = repository
+
+	owner, name := parsed.RepositoryParts()
+	if name == "" {
+		return nil, nil, errors.NewValidationError(
+			fmt.Sprintf("Inferred repository '%s' is not in owner/name format", repository))
+	}
+
+	cfg := config.NewConfig(importProjectID, owner, name)
+	if projectInfo := client.GetProjectInfo(); projectInfo != nil {
+		cfg.Project.Number = projectInfo.ProjectNumber
+	}
+
+	cfg.Repository.ID = parsed.RepositoryID
+	cfg.Repository.OwnerID = parsed.RepositoryOwnerID
+	cfg.Repository.Branches = parsed.AllowedBranches
+	cfg.Repository.Tags = parsed.AllowedTags
+	cfg.Repository.PullRequest = parsed.AllowPullRequests
+
+	cfg.WorkloadIdentity.PoolID = target.PoolID
+	cfg.WorkloadIdentity.PoolName = importDisplayName(target.Pool.DisplayName, target.PoolID)
+	cfg.WorkloadIdentity.ProviderID = providerID
+	cfg.WorkloadIdentity.ProviderName = importDisplayName(provider.DisplayName, providerID)
+	cfg.WorkloadIdentity.AllowedAudiences = provider.AllowedAudiences
+	if len(provider.AttributeMapping) > 0 {
+		cfg.WorkloadIdentity.AttributeMapping = provider.AttributeMapping
+	}
+	if provider.AttributeCondition != "" {
+		cfg.WorkloadIdentity.Conditions = []string{provider.AttributeCondition}
+	}
+
+	// Resolve the bound service account
+	var serviceAccounts []string
+	for _, b := range matched {
+		if !envContains(serviceAccounts, b.ServiceAccountEmail) {
+			serviceAccounts = append(serviceAccounts, b.ServiceAccountEmail)
+		}
+	}
+	switch len(serviceAccounts) {
+	case 0:
+		warnings = append(warnings, "No service account bindings found for this provider; using generated service account name")
+	default:
+		if len(serviceAccounts) > 1 {
+			warnings = append(warnings, fmt.Sprintf("Multiple bound service accounts found (%s); using the first", strings.Join(serviceAccounts, ", ")))
+		}
+		email := serviceAccounts[0]
+		parts := strings.SplitN(email, "@", 2)
+		cfg.ServiceAccount.Name = parts[0]
+		cfg.ServiceAccount.CreateNew = false
+		if len(parts) == 2 && parts[1] != fmt.Sprintf("%s.iam.gserviceaccount.com", importProjectID) {
+			warnings = append(warnings, fmt.Sprintf("Service account %s belongs to a different project", email))
+		}
+		if roles, err := client.GetServiceAccountProjectRoles(email); err == nil && len(roles) > 0 {
+			cfg.ServiceAccount.Roles = roles
+		}
+	}
+
+	// Preserve the expiration of time-bound bindings
+	for _, b := range matched {
+		if b.Binding.ExpiresAt != nil && b.ServiceAccountEmail == cfg.GetServiceAccountEmail() {
+			cfg.WorkloadIdentity.BindingExpiration = b.Binding.ExpiresAt.UTC().Format(time.RFC3339)
+			break
+		}
+	}
+
+	cfg.SetDefaults()
+
+	result := cfg.ValidateSchema()
+	if !result.Valid {
+		return nil, nil, formatValidationErrors(result)
+	}
+
+	return cfg, warnings, nil
+}
+
+// importOutputPath returns the output file for a provider, adding a pool and provider suffix
+// when importing several, as provider IDs are only unique within a pool
+func importOutputPath(output, poolID, providerID string, multiple bool) string {
+	if !multiple {
+		return output
+	}
+	ext := filepath.Ext(output)
+	return fmt.Sprintf("%s-%s-%s%s", strings.TrimSuffix(output, ext), poolID, providerID, ext)
+}
+
+// importDisplayName returns the display name reported by gcloud or a fallback when unset
+func importDisplayName(displayName, fallback string) string {
+	if displayName == "" || displayName == "<nil>" {
+		return fallback
+	}
+	return displayName
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import "testing"
+
+func TestImportOutputPath(t *testing.T) {
+	if path := importOutputPath("wif-config.yaml", "github-pool", "github", false); path != "wif-config.yaml" {
+		t.Errorf("Expected the output unchanged for a single provider, got %s", path)
+	}
+
+	first := importOutputPath("wif-config.yaml", "github-pool", "github", true)
+	second := importOutputPath("wif-config.yaml", "other-pool", "github", true)
+	if first != "wif-config-github-pool-github.yaml" {
+		t.Errorf("Expected wif-config-github-pool-github.yaml, got %s", first)
+	}
+	if first == second {
+		t.Errorf("Expected providers with the same ID in different pools to get different files, both got %s", first)
+	}
+}
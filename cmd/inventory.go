@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/gcp"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inventoryProjects    []string
+	inventoryFolder      string
+	inventoryRecursive   bool
+	inventoryParallelism int
+	inventoryOutput      string
+	inventoryOutputFile  string
+)
+
+// inventoryCmd represents the inventory command
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Build a Workload Identity Federation inventory across projects",
+	Long: `Scan several projects, or every project under a folder, and build a consolidated
+inventory showing which GitHub repository can reach which project and service account.
+
+For each project the inventory lists:
+• Workload identity pools and providers
+• Provider OIDC issuers
+• Repositories trusted by provider conditions and principal bindings
+• Service accounts bound to each pool or provider, including cross-project bindings
+
+Projects are scanned concurrently with bounded parallelism. Projects that cannot be
+scanned are reported with their error instead of aborting the inventory.
+
+Output Formats:
+• table - Human-readable table grouped by repository (default)
+• json  - Machine-readable JSON inventory
+• csv   - One row per repository, provider and service account path
+
+Examples:
+  # Inventory specific projects
+  gcp-wif inventory --projects proj-a,proj-b,proj-c
+
+  # Inventory every project under a folder, including sub-folders
+  gcp-wif inventory --folder 123456789012
+
+  # Export a CSV inventory scanning 8 projects at a time
+  gcp-wif inventory --folder 123456789012 --parallelism 8 --output csv --output-file wif-inventory.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runInventoryCommand(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+
+	inventoryCmd.Flags().StringSliceVar(&inventoryProjects, "projects", []string{}, "Comma-separated list of project IDs to scan")
+	inventoryCmd.Flags().StringVar(&inventoryFolder, "folder", "", "Folder ID whose projects should be scanned")
+	inventoryCmd.Flags().BoolVar(&inventoryRecursive, "recursive", true, "Include projects in sub-folders when using --folder")
+	inventoryCmd.Flags().IntVar(&inventoryParallelism, "parallelism", gcp.DefaultInventoryParallelism, "Maximum number of projects scanned concurrently")
+	inventoryCmd.Flags().StringVarP(&inventoryOutput, "output", "o", "table", "Output format: table, json, csv")
+	inventoryCmd.Flags().StringVar(&inventoryOutputFile, "output-file", "", "Write the inventory to a file instead of stdout")
+}
+
+func runInventoryCommand(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "inventory")
+
+	format := strings.ToLower(inventoryOutput)
+	if format != "table" && format != "json" && format != "csv" {
+		return errors.NewValidationError(
+			fmt.Sprintf("Invalid output format: %s", inventoryOutput),
+			"Valid formats are: table, json, csv")
+	}
+	if format == "table" && inventoryOutputFile != "" {
+		return errors.NewValidationError(
+			"Table output cannot be written to a file",
+			"Use --output json or --output csv with --output-file")
+	}
+	if inventoryParallelism < 1 {
+		return errors.NewValidationError(
+			fmt.Sprintf("Invalid parallelism: %d", inventoryParallelism),
+			"Parallelism must be at least 1")
+	}
+
+	projectIDs := make([]string, 0, len(inventoryProjects))
+	for _, projectID := range inventoryProjects {
+		if projectID = strings.TrimSpace(projectID); projectID != "" && !envContains(projectIDs, projectID) {
+			projectIDs = append(projectIDs, projectID)
+		}
+	}
+
+	if inventoryFolder != "" {
+		if format == "table" {
+			fmt.Printf("🔍 Discovering projects in folder %s...\n", inventoryFolder)
+		}
+		folderProjects, err := gcp.ListFolderProjects(inventoryFolder, inventoryRecursive)
+		if err != nil {
+			return err
+		}
+		for _, projectID := range folderProjects {
+			if !envContains(projectIDs, projectID) {
+				projectIDs = append(projectIDs, projectID)
+			}
+		}
+	}
+
+	if len(projectIDs) == 0 {
+		return errors.NewValidationError(
+			"No projects to scan",
+			"Specify projects with --projects proj-a,proj-b",
+			"Or scan a folder with --folder <folder-id>")
+	}
+
+	logger.Info("Starting fleet inventory", "projects", len(projectIDs), "parallelism", inventoryParallelism)
+	if format == "table" {
+		fmt.Printf("🔍 Scanning %d project(s) with parallelism %d...\n", len(projectIDs), inventoryParallelism)
+	}
+
+	inventory := gcp.CollectFleetInventory(context.Background(), projectIDs, inventoryParallelism)
+
+	var data []byte
+	var err error
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(inventory, "", "  ")
+	case "csv":
+		data, err = inventory.ToCSV()
+	}
+	if err != nil {
+		return errors.WrapError(err, errors.ErrorTypeInternal, "INVENTORY_MARSHAL_FAILED",
+			"Failed to serialize inventory")
+	}
+
+	switch {
+	case format == "table":
+		displayInventoryTable(inventory)
+	case inventoryOutputFile != "":
+		if err := os.WriteFile(inventoryOutputFile, data, 0644); err != nil {
+			return errors.WrapError(err, errors.ErrorTypeFileSystem, "INVENTORY_WRITE_FAILED",
+				fmt.Sprintf("Failed to write inventory: %s", inventoryOutputFile))
+		}
+		fmt.Printf("💾 Inventory saved to: %s (%d entries)\n", inventoryOutputFile, len(inventory.Entries))
+	default:
+		fmt.Print(string(data))
+		if format == "json" {
+			fmt.Println()
+		}
+	}
+
+	logger.Info("Fleet inventory completed", "projects", len(inventory.Projects), "entries", len(inventory.Entries))
+	return nil
+}
+
+// displayInventoryTable prints the per-project scan summary and the reachability table
+func displayInventoryTable(inventory *gcp.FleetInventory) {
+	fmt.Println("\n📋 Workload Identity Federation Inventory")
+	fmt.Println("=========================================")
+
+	failed, partial := 0, 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tPOOLS\tPROVIDERS\tFEDERATED SAs\tSTATUS")
+	fmt.Fprintln(w, "-------\t-----\t---------\t-------------\t------")
+	for _, project := range inventory.Projects {
+		status := "✅ scanned"
+		if project.Error != "" {
+			status = "❌ " + project.Error
+			failed++
+		} else if project.BindingsError != "" {
+			status = "⚠️  " + project.BindingsError
+			partial++
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n",
+			project.ProjectID, project.Pools, project.Providers, project.ServiceAccounts, status)
+	}
+	w.Flush()
+
+	if len(inventory.Entries) == 0 {
+		fmt.Println("\n✅ No workload identity providers found")
+		return
+	}
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tPROJECT\tPOOL/PROVIDER\tISSUER\tSERVICE ACCOUNT\tROLE")
+	fmt.Fprintln(w, "----------\t-------\t-------------\t------\t---------------\t----")
+	for _, entry := range inventory.Entries {
+		provider := entry.PoolID
+		if entry.ProviderID != "" {
+			provider = entry.PoolID + "/" + entry.ProviderID
+		}
+		if entry.Disabled {
+			provider += " (disabled)"
+		}
+		serviceAccount := entry.ServiceAccount
+		if serviceAccount == "" {
+			serviceAccount = "-"
+		}
+		role := entry.Role
+		if role == "" {
+			role = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.TrustedRepository, entry.ProjectID, provider, entry.Issuer, serviceAccount, role)
+	}
+	w.Flush()
+
+	fmt.Printf("\n📊 Summary: %d project(s) scanned, %d with incomplete bindings, %d failed, %d access path(s)\n",
+		len(inventory.Projects)-failed, partial, failed, len(inventory.Entries))
+	if partial > 0 {
+		fmt.Println("⚠️  Providers of projects with incomplete bindings may be reachable through service accounts that are not listed")
+	}
+	fmt.Println("💡 Repositories shown as '*' or 'owner/*' are not restricted to a single repository; run 'gcp-wif audit' for details")
+}
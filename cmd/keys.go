@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/gcp"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// defaultKeyRevocationStateFile records when keys were disabled between revoke runs
+const defaultKeyRevocationStateFile = ".gcp-wif-key-revocations.json"
+
+var (
+	keysProjectID      string
+	keysServiceAccount string
+	keysGracePeriod    time.Duration
+	keysStateFile      string
+	keysDryRun         bool
+	keysYes            bool
+)
+
+// keysCmd represents the keys command
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Find and remove long-lived service account keys",
+	Long: `Workload Identity Federation replaces long-lived service account keys with
+short-lived tokens. Keys left on a federated service account remain a credential
+leak risk and should be removed once workflows authenticate through WIF.
+
+Available subcommands:
+• list   - List user-managed keys on a service account
+• revoke - Disable keys, then delete them after a grace period
+
+Examples:
+  # List keys on the configured service account
+  gcp-wif keys list
+
+  # Disable all keys now and delete them after the default 7 day grace period
+  gcp-wif keys revoke --service-account deployer@my-project.iam.gserviceaccount.com`,
+}
+
+// keysListCmd represents the keys list command
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List user-managed keys on a service account",
+	Long: `List the user-managed keys of a service account. System-managed keys are rotated
+by Google and are not shown.
+
+Examples:
+  gcp-wif keys list
+  gcp-wif keys list --service-account deployer@my-project.iam.gserviceaccount.com`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runKeysList(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+// keysRevokeCmd represents the keys revoke command
+var keysRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Disable and then delete user-managed keys",
+	Long: `Revoke the user-managed keys of a service account in two phases:
+
+1. Active keys are disabled immediately. Anything still using a key starts failing
+   but the key can be re-enabled with 'gcloud iam service-accounts keys enable'.
+2. Keys that have been disabled for the grace period are deleted permanently.
+
+The time each key was disabled is recorded in a state file, so run the command again
+after the grace period to complete the deletion. Use --grace-period 0 to delete keys
+immediately.
+
+Examples:
+  # Disable keys now, delete them on a run at least 7 days later
+  gcp-wif keys revoke
+
+  # Use a 48 hour grace period
+  gcp-wif keys revoke --grace-period 48h
+
+  # Delete immediately without confirmation
+  gcp-wif keys revoke --grace-period 0 --yes
+
+  # Preview the actions
+  gcp-wif keys revoke --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runKeysRevoke(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysListCmd)
+	keysCmd.AddCommand(keysRevokeCmd)
+
+	keysCmd.PersistentFlags().StringVar(&keysProjectID, "project-id", "", "Google Cloud Project ID (default: project from configuration)")
+	keysCmd.PersistentFlags().StringVar(&keysServiceAccount, "service-account", "", "Service account email (default: service account from configuration)")
+
+	keysRevokeCmd.Flags().DurationVar(&keysGracePeriod, "grace-period", gcp.DefaultKeyRevocationGracePeriod, "How long keys stay disabled before deletion (0 deletes immediately)")
+	keysRevokeCmd.Flags().StringVar(&keysStateFile, "state-file", defaultKeyRevocationStateFile, "File recording when keys were disabled")
+	keysRevokeCmd.Flags().BoolVar(&keysDryRun, "dry-run", false, "Show what would be disabled and deleted without making changes")
+	keysRevokeCmd.Flags().BoolVarP(&keysYes, "yes", "y", false, "Skip the confirmation prompt")
+}
+
+func runKeysList(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "keys_list")
+
+	client, serviceAccountEmail, err := initializeKeysClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	keys, err := client.ListServiceAccountKeys(serviceAccountEmail)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔑 User-managed keys for %s\n", serviceAccountEmail)
+	if len(keys) == 0 {
+		fmt.Println("\n✅ No user-managed keys found")
+		return nil
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "KEY ID\tSTATUS\tCREATED\tEXPIRES")
+	fmt.Fprintln(w, "------\t------\t-------\t-------")
+	for _, key := range keys {
+		status := "active"
+		if key.Disabled {
+			status = "disabled"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key.KeyID, status,
+			formatKeyTime(key.ValidAfter), formatKeyTime(key.ValidBefore))
+	}
+	w.Flush()
+
+	warnServiceAccountKeys(keys, serviceAccountEmail)
+
+	logger.Info("Service account keys listed", "service_account", serviceAccountEmail, "count", len(keys))
+	return nil
+}
+
+func runKeysRevoke(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "keys_revoke")
+
+	if keysGracePeriod < 0 {
+		return errors.NewValidationError(
+			fmt.Sprintf("Invalid grace period: %s", keysGracePeriod),
+			"Grace period must be zero or positive, e.g. --grace-period 168h")
+	}
+
+	client, serviceAccountEmail, err := initializeKeysClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	keys, err := client.ListServiceAccountKeys(serviceAccountEmail)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		fmt.Printf("✅ %s has no user-managed keys\n", serviceAccountEmail)
+		return nil
+	}
+
+	state, err := loadKeyRevocationState(keysStateFile)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	plan := gcp.PlanKeyRevocation(keys, state, now, keysGracePeriod)
+
+	fmt.Printf("🔑 Revoking keys for %s (grace period: %s)\n\n", serviceAccountEmail, keysGracePeriod)
+	for _, key := range plan.Disable {
+		fmt.Printf("   • Disable %s\n", key.KeyID)
+	}
+	for _, key := range plan.Delete {
+		fmt.Printf("   • Delete %s\n", key.KeyID)
+	}
+	for _, key := range plan.Pending {
+		fmt.Printf("   • Keep %s disabled until %s\n", key.KeyID, plan.DeleteAfter[key.Name].Format(time.RFC3339))
+	}
+
+	if keysDryRun {
+		fmt.Println("\n🔍 Dry run mode - no keys were changed")
+		return nil
+	}
+
+	if len(plan.Disable) == 0 && len(plan.Delete) == 0 {
+		// Still record newly seen disabled keys so their grace period starts now
+		for _, key := range plan.Pending {
+			if _, ok := state[key.Name]; !ok {
+				state[key.Name] = now
+			}
+		}
+		if err := saveKeyRevocationState(keysStateFile, state); err != nil {
+			return err
+		}
+		fmt.Println("\n⏳ Nothing to do until the grace period elapses")
+		return nil
+	}
+
+	if !keysYes {
+		fmt.Println("\n❓ Do you want to proceed? (y/N)")
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Key revocation cancelled by user")
+			return nil
+		}
+	}
+
+	fmt.Println()
+	for _, key := range plan.Disable {
+		if err := client.DisableServiceAccountKey(key.Name); err != nil {
+			return err
+		}
+		state[key.Name] = now
+		fmt.Printf("   ✅ Disabled %s\n", key.KeyID)
+	}
+	for _, key := range plan.Pending {
+		if _, ok := state[key.Name]; !ok {
+			state[key.Name] = now
+		}
+	}
+
+	// Persist disable times before deleting so a failed deletion can be retried
+	if err := saveKeyRevocationState(keysStateFile, state); err != nil {
+		return err
+	}
+
+	for _, key := range plan.Delete {
+		if err := client.DeleteServiceAccountKey(key.Name); err != nil {
+			return err
+		}
+		delete(state, key.Name)
+		fmt.Printf("   🗑️  Deleted %s\n", key.KeyID)
+	}
+
+	if err := saveKeyRevocationState(keysStateFile, state); err != nil {
+		return err
+	}
+
+	remaining := len(plan.Pending)
+	if keysGracePeriod > 0 {
+		remaining += len(plan.Disable)
+	}
+	if remaining > 0 {
+		fmt.Printf("\n⏳ %d key(s) disabled and awaiting deletion\n", remaining)
+		fmt.Printf("💡 Run 'gcp-wif keys revoke' again after %s to delete them\n", now.Add(keysGracePeriod).Format(time.RFC3339))
+	} else {
+		fmt.Println("\n✅ All user-managed keys removed")
+	}
+
+	logger.Info("Service account keys revoked",
+		"service_account", serviceAccountEmail,
+		"disabled", len(plan.Disable),
+		"deleted", len(plan.Delete),
+		"pending", remaining)
+	return nil
+}
+
+// initializeKeysClient resolves the project and service account and creates a GCP client
+func initializeKeysClient() (*gcp.Client, string, error) {
+	projectID := keysProjectID
+	serviceAccountEmail := keysServiceAccount
+
+	if projectID == "" || serviceAccountEmail == "" {
+		if cfg, err := loadConfigWithFallback(); err == nil {
+			if projectID == "" {
+				projectID = cfg.Project.ID
+			}
+			if serviceAccountEmail == "" && cfg.ServiceAccount.Name != "" {
+				serviceAccountEmail = cfg.GetServiceAccountEmail()
+			}
+		}
+	}
+
+	if serviceAccountEmail == "" {
+		return nil, "", errors.NewValidationError(
+			"Service account email is required",
+			"Specify the service account with --service-account",
+			"Or run from a directory containing wif-config.json")
+	}
+	if projectID == "" {
+		// Service account emails carry their project
+		if parts := strings.SplitN(serviceAccountEmail, "@", 2); len(parts) == 2 {
+			projectID = strings.TrimSuffix(parts[1], ".iam.gserviceaccount.com")
+		}
+	}
+
+	client, err := gcp.NewClient(context.Background(), projectID)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, serviceAccountEmail, nil
+}
+
+// warnServiceAccountKeys prints a warning when a federated service account still has active keys
+func warnServiceAccountKeys(keys []gcp.ServiceAccountKeyInfo, serviceAccountEmail string) {
+	active := gcp.ActiveServiceAccountKeys(keys)
+	if len(active) == 0 {
+		return
+	}
+	fmt.Printf("   ⚠️  %s still has %d active user-managed key(s)\n", serviceAccountEmail, len(active))
+	fmt.Printf("   💡 Keys are not needed with Workload Identity Federation; remove them with 'gcp-wif keys revoke --service-account %s'\n", serviceAccountEmail)
+}
+
+// formatKeyTime formats a key validity time, treating the far-future sentinel as no expiry
+func formatKeyTime(t time.Time) string {
+	if t.IsZero() || t.Year() >= 9999 {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// loadKeyRevocationState reads the key disable times recorded by earlier revoke runs
+func loadKeyRevocationState(path string) (map[string]time.Time, error) {
+	state := make(map[string]time.Time)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "KEY_STATE_READ_FAILED",
+			fmt.Sprintf("Failed to read key revocation state: %s", path))
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "KEY_STATE_PARSE_FAILED",
+			fmt.Sprintf("Failed to parse key revocation state: %s", path))
+	}
+	return state, nil
+}
+
+// saveKeyRevocationState writes the key disable times, removing the file when empty
+func saveKeyRevocationState(path string, state map[string]time.Time) error {
+	if len(state) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.WrapError(err, errors.ErrorTypeFileSystem, "KEY_STATE_WRITE_FAILED",
+				fmt.Sprintf("Failed to remove key revocation state: %s", path))
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.WrapError(err, errors.ErrorTypeInternal, "KEY_STATE_MARSHAL_FAILED",
+			"Failed to serialize key revocation state")
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.WrapError(err, errors.ErrorTypeFileSystem, "KEY_STATE_WRITE_FAILED",
+			fmt.Sprintf("Failed to write key revocation state: %s", path))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/config"
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"github.com/Fordjour12/gcp-wif/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+// policyFiles holds the --policy flag shared by the commands that evaluate policies
+var policyFiles []string
+
+// addPolicyFlag registers the --policy flag on a command
+func addPolicyFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&policyFiles, "policy", nil,
+		fmt.Sprintf("Policy files or directories to enforce (default: %s if present)", policy.DefaultPolicyDir))
+}
+
+// loadPolicySet loads the policies requested with --policy or found in the default policy
+// directory. It returns nil when there are no policies to enforce.
+func loadPolicySet() (*policy.PolicySet, error) {
+	paths := policy.DiscoverPolicyPaths(policyFiles)
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	return policy.LoadPolicies(paths)
+}
+
+// enforcePolicies evaluates the loaded policies against the configuration, prints the
+// result and returns an error when unwaived error-severity violations remain
+func enforcePolicies(cfg *config.Config) error {
+	logger := logging.WithField("function", "enforcePolicies")
+
+	set, err := loadPolicySet()
+	if err != nil || set == nil {
+		return err
+	}
+
+	result, err := set.EvaluateConfig(cfg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	displayPolicyResult(set, result)
+	logger.Info("Policies evaluated",
+		"rules", result.RulesEvaluated,
+		"violations", len(result.Active()),
+		"waived", len(result.Waived()))
+
+	if result.HasErrors() {
+		return errors.NewValidationError(
+			"Configuration violates organization policy",
+			"Fix the violations listed above",
+			"Or add a waiver with a justification and an expiry date to the policy file")
+	}
+	return nil
+}
+
+// displayPolicyResult prints policy violations, waivers and expired waivers
+func displayPolicyResult(set *policy.PolicySet, result *policy.Result) {
+	fmt.Printf("\n📜 Policy check (%d policies, %d rules)\n", len(set.Policies), set.RuleCount())
+
+	active := result.Active()
+	if len(active) == 0 {
+		fmt.Println("✅ No policy violations")
+	}
+	for _, violation := range active {
+		icon := "❌"
+		switch violation.Severity {
+		case policy.SeverityWarning:
+			icon = "⚠️ "
+		case policy.SeverityInfo:
+			icon = "💡"
+		}
+		fmt.Printf("%s [%s] %s\n", icon, violation.RuleID, violation.Message)
+		if violation.Resource != "" {
+			fmt.Printf("   Resource: %s\n", violation.Resource)
+		}
+		if violation.Remediation != "" {
+			fmt.Printf("   💡 %s\n", violation.Remediation)
+		}
+	}
+
+	for _, violation := range result.Waived() {
+		fmt.Printf("🔕 [%s] waived until %s: %s\n",
+			violation.RuleID, violation.Waiver.ExpiresAt().Format("2006-01-02"), violation.Waiver.Justification)
+		if violation.Resource != "" {
+			fmt.Printf("   Resource: %s\n", violation.Resource)
+		}
+	}
+
+	for _, waiver := range result.ExpiredWaivers {
+		fmt.Printf("⏰ Waiver for [%s] expired on %s and no longer applies\n",
+			waiver.Rule, waiver.ExpiresAt().Format("2006-01-02"))
+	}
+
+	if len(result.Skipped) > 0 {
+		fmt.Printf("⏭️  Skipped %d rule(s) without an evaluation target\n", len(result.Skipped))
+	}
+}
@@ -285,12 +285,8 @@ func findMostRecentBackup() (string, error) {
 	var backupFiles []string
 
 	for _, searchPath := range searchPaths {
-		// Look for different backup patterns
-		patterns := []string{
-			"*-backup-*.json",
-			"*backup*.json",
-			"*.backup",
-		}
+		// Look for different backup patterns, in every configuration format
+		patterns := backupPatterns()
 
 		for _, pattern := range patterns {
 			globPattern := filepath.Join(searchPath, pattern)
@@ -307,8 +303,8 @@ func findMostRecentBackup() (string, error) {
 			"No backup files found",
 			"No backup files were found in the current directory or common backup locations.",
 			"Searched patterns:",
-			"  *-backup-*.json",
-			"  *backup*.json", 
+			"  *-backup-*.{json,yaml,yml,toml}",
+			"  *backup*.{json,yaml,yml,toml}",
 			"  *.backup",
 			"",
 			"Create a backup first using: gcp-wif config backup")
@@ -330,6 +326,18 @@ func findMostRecentBackup() (string, error) {
 	return mostRecent, nil
 }
 
+// backupPatterns returns the glob patterns of backup files, for every configuration format
+func backupPatterns() []string {
+	var patterns []string
+	for _, extension := range config.SupportedExtensions() {
+		patterns = append(patterns, "*-backup-*"+extension)
+	}
+	for _, extension := range config.SupportedExtensions() {
+		patterns = append(patterns, "*backup*"+extension)
+	}
+	return append(patterns, "*.backup")
+}
+
 // extractTimestampFromBackup extracts timestamp from backup filename
 func extractTimestampFromBackup(backupPath string) (time.Time, error) {
 	filename := filepath.Base(backupPath)
@@ -338,7 +346,7 @@ func extractTimestampFromBackup(backupPath string) (time.Time, error) {
 	if strings.Contains(filename, "-backup-") {
 		parts := strings.Split(filename, "-backup-")
 		if len(parts) >= 2 {
-			timestampPart := strings.TrimSuffix(parts[1], ".json")
+			timestampPart := strings.TrimSuffix(parts[1], filepath.Ext(parts[1]))
 			// Try to parse YYYYMMDD-HHMMSS format
 			if len(timestampPart) >= 15 {
 				timeStr := timestampPart[:8] + "T" + timestampPart[9:11] + ":" + timestampPart[11:13] + ":" + timestampPart[13:15] + "Z"
@@ -654,8 +662,12 @@ func restoreIAMBindings(client *gcp.Client, target *config.Config) error {
 
 func restoreFilesFromBackup(target *config.Config, source *RollbackSource) error {
 	// This would restore workflow files and save the target configuration
-	fmt.Printf("      • Restoring configuration to: wif-config.json\n")
-	if err := target.SaveToFile("wif-config.json"); err != nil {
+	configFile := cfgFile
+	if configFile == "" {
+		configFile = config.DefaultConfigFile()
+	}
+	fmt.Printf("      • Restoring configuration to: %s\n", configFile)
+	if err := target.SaveToFile(configFile); err != nil {
 		return err
 	}
 	
@@ -3,8 +3,12 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Fordjour12/gcp-wif/internal/config"
 	"github.com/Fordjour12/gcp-wif/internal/errors"
@@ -37,6 +41,8 @@ var (
 	repoBranches []string
 	repoTags     []string
 	repoPR       bool
+	repoID       int64
+	repoOwnerID  int64
 
 	// Service Account flags
 	saDisplayName string
@@ -50,6 +56,7 @@ var (
 	wiProviderName string
 	wiProviderID   string
 	wiConditions   []string
+	wiExpires      string
 
 	// Cloud Run flags
 	crImage        string
@@ -67,6 +74,27 @@ var (
 	wfEnvironment string
 	wfDockerImage string
 
+	// Deployment target flags
+	deployTarget       string
+	gkeCluster         string
+	gkeNamespace       string
+	functionRuntime    string
+	functionEntryPoint string
+
+	// Terraform workflow flags
+	workflowKind       string
+	tfStateBucket      string
+	tfStatePrefix      string
+	tfWorkingDir       string
+	tfApplyEnvironment string
+
+	// Supply-chain security flags
+	generateSBOM       bool
+	signImages         bool
+	slsaProvenance     bool
+	binauthzAttestor   string
+	binauthzKeyVersion string
+
 	// Environment and secrets flags
 	envNames          []string
 	envVariables      []string // format: "env:key=value"
@@ -91,6 +119,9 @@ var (
 	cleanupOnFailure bool
 	enableAPIs       []string
 	timeout          string
+	pushVariables    bool
+	githubEnvs       bool
+	openPR           bool
 )
 
 // setupCmd represents the setup command
@@ -114,13 +145,19 @@ Comprehensive flag support includes:
 - Project: --project-id, --project-number, --project-region
 - Repository: --repo-owner, --repo-name, --repo-branches, --repo-tags
 - Service Account: --service-account, --sa-display-name, --sa-roles
-- Workload Identity: --wi-pool-id, --wi-provider-id, --wi-conditions
+- Workload Identity: --wi-pool-id, --wi-provider-id, --wi-conditions, --expires
 - Cloud Run: --cr-image, --cr-port, --cr-cpu-limit, --cr-memory-limit
+- Target: --target (cloud-run, gke, cloud-functions, app-engine, firebase-hosting), --gke-cluster,
+  --gke-namespace, --function-runtime, --function-entry-point
+- Terraform: --kind terraform, --tf-state-bucket, --tf-state-prefix, --tf-working-dir, --tf-apply-environment
+  (creates a read-only plan service account and provider for pull requests next to the apply ones)
 - Workflow: --wf-name, --wf-filename, --wf-triggers, --wf-environment
 - Environments: --env-names, --env-variables, --env-secrets, --env-protection, --create-standard-env
 - Secrets: --global-secrets, --build-secrets
 - Health Checks: --health-checks, --create-default-health, --health-check-timeout, --health-check-retries, --health-check-wait-time
 - Advanced: --dry-run, --skip-validation, --force-update, --timeout
+- Policy: --policy (organization policy files; .gcp-wif/policies is used when present)
+- GitHub: --github-environments, --push-variables, --open-pr (require GITHUB_TOKEN or a GitHub App)
 
 Use --help to see all available flags with detailed descriptions.`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -153,6 +190,8 @@ func init() {
 	setupCmd.Flags().StringSliceVar(&repoBranches, "repo-branches", []string{}, "GitHub repository branches")
 	setupCmd.Flags().StringSliceVar(&repoTags, "repo-tags", []string{}, "GitHub repository tags")
 	setupCmd.Flags().BoolVar(&repoPR, "repo-pr", false, "GitHub repository is a pull request")
+	setupCmd.Flags().Int64Var(&repoID, "repo-id", 0, "GitHub repository ID to pin conditions to (default: looked up with GITHUB_TOKEN)")
+	setupCmd.Flags().Int64Var(&repoOwnerID, "repo-owner-id", 0, "GitHub repository owner ID to pin conditions to (default: looked up with GITHUB_TOKEN)")
 	setupCmd.Flags().StringVar(&saDisplayName, "sa-display-name", "", "Service account display name")
 	setupCmd.Flags().StringVar(&saDescription, "sa-description", "", "Service account description")
 	setupCmd.Flags().StringSliceVar(&saRoles, "sa-roles", []string{}, "Service account IAM roles")
@@ -162,6 +201,7 @@ func init() {
 	setupCmd.Flags().StringVar(&wiProviderName, "wi-provider-name", "", "Workload Identity Provider name")
 	setupCmd.Flags().StringVar(&wiProviderID, "wi-provider-id", "", "Workload Identity Provider ID")
 	setupCmd.Flags().StringSliceVar(&wiConditions, "wi-conditions", []string{}, "Workload Identity conditions")
+	setupCmd.Flags().StringVar(&wiExpires, "expires", "", "Expire service account bindings after a duration (72h, 7d) or at an RFC3339 timestamp")
 	setupCmd.Flags().StringVar(&crImage, "cr-image", "", "Cloud Run image")
 	setupCmd.Flags().IntVar(&crPort, "cr-port", 0, "Cloud Run port")
 	setupCmd.Flags().StringVar(&crCPULimit, "cr-cpu-limit", "", "Cloud Run CPU limit")
@@ -174,6 +214,23 @@ func init() {
 	setupCmd.Flags().StringSliceVar(&wfTriggers, "wf-triggers", []string{}, "Workflow triggers")
 	setupCmd.Flags().StringVar(&wfEnvironment, "wf-environment", "", "Workflow environment")
 	setupCmd.Flags().StringVar(&wfDockerImage, "wf-docker-image", "", "Workflow Docker image")
+	setupCmd.Flags().StringVar(&deployTarget, "target", "", "Deployment target: "+strings.Join(github.DeploymentTargetNames(), ", ")+" (default: cloud-run)")
+	setupCmd.Flags().StringVar(&gkeCluster, "gke-cluster", "", "GKE cluster name (gke target)")
+	setupCmd.Flags().StringVar(&gkeNamespace, "gke-namespace", "", "Kubernetes namespace (gke target, default: default)")
+	setupCmd.Flags().StringVar(&functionRuntime, "function-runtime", "", "Cloud Functions runtime, e.g. nodejs20 (cloud-functions target)")
+	setupCmd.Flags().StringVar(&functionEntryPoint, "function-entry-point", "", "Cloud Functions entry point (cloud-functions target)")
+	setupCmd.Flags().StringVar(&workflowKind, "kind", "", "Workflow kind: deploy or terraform (default: deploy)")
+	setupCmd.Flags().StringVar(&tfStateBucket, "tf-state-bucket", "", "GCS bucket holding the terraform state (terraform kind)")
+	setupCmd.Flags().StringVar(&tfStatePrefix, "tf-state-prefix", "", "Object prefix of the terraform state (terraform kind, default: terraform/state)")
+	setupCmd.Flags().StringVar(&tfWorkingDir, "tf-working-dir", "", "Directory of the terraform configuration (terraform kind, default: .)")
+	setupCmd.Flags().StringVar(&tfApplyEnvironment, "tf-apply-environment", "", "GitHub environment gating terraform apply (terraform kind, default: production)")
+
+	// Supply-chain security flags
+	setupCmd.Flags().BoolVar(&generateSBOM, "sbom", false, "Generate an SPDX SBOM of the pushed image")
+	setupCmd.Flags().BoolVar(&signImages, "sign-images", false, "Sign the pushed image digest with cosign keyless signing")
+	setupCmd.Flags().BoolVar(&slsaProvenance, "slsa-provenance", false, "Generate SLSA provenance for the pushed image with the official generator")
+	setupCmd.Flags().StringVar(&binauthzAttestor, "binauthz-attestor", "", "Create a Binary Authorization attestation for the pushed image with this attestor")
+	setupCmd.Flags().StringVar(&binauthzKeyVersion, "binauthz-key-version", "", "Cloud KMS key version resource name that signs Binary Authorization attestations")
 
 	// Environment and secrets flags
 	setupCmd.Flags().StringSliceVar(&envNames, "env-names", []string{}, "Environment names to create")
@@ -198,6 +255,10 @@ func init() {
 	setupCmd.Flags().BoolVar(&cleanupOnFailure, "cleanup-on-failure", false, "Cleanup on failure")
 	setupCmd.Flags().StringSliceVar(&enableAPIs, "enable-apis", []string{}, "Enable APIs")
 	setupCmd.Flags().StringVar(&timeout, "timeout", "", "Timeout")
+	setupCmd.Flags().BoolVar(&githubEnvs, "github-environments", false, "Create the GitHub deployment environments with their protection rules (requires GITHUB_TOKEN or a GitHub App)")
+	setupCmd.Flags().BoolVar(&pushVariables, "push-variables", false, "Write the WIF outputs to GitHub Actions variables and reference them from the workflow (requires GITHUB_TOKEN or a GitHub App)")
+	setupCmd.Flags().BoolVar(&openPR, "open-pr", false, "Commit the workflow and configuration to a new branch and open a pull request (requires GITHUB_TOKEN or a GitHub App)")
+	addPolicyFlag(setupCmd)
 }
 
 func runSetup(cmd *cobra.Command, args []string) error {
@@ -231,6 +292,14 @@ func runSetup(cmd *cobra.Command, args []string) error {
 		logger.Info("Interactive configuration completed successfully")
 	}
 
+	// Pin the conditions to the repository's immutable IDs. Without them the conditions
+	// still match the repository by name, so setup continues.
+	if err := resolveRepositoryIDs(cfg); err != nil {
+		logger.Warn("Failed to resolve repository IDs", "error", err)
+		fmt.Printf("ℹ️  Conditions will match %s by name. Set GITHUB_TOKEN, or pass --repo-id and --repo-owner-id,\n", cfg.GetRepoFullName())
+		fmt.Println("   to pin them to the repository's immutable IDs")
+	}
+
 	// Validate configuration
 	result := cfg.ValidateSchema()
 	if !result.Valid {
@@ -243,6 +312,11 @@ func runSetup(cmd *cobra.Command, args []string) error {
 		logger.Warn("Configuration warning", "field", warning.Field, "message", warning.Message)
 	}
 
+	// Enforce organization policies before any resources are created
+	if err := enforcePolicies(cfg); err != nil {
+		return err
+	}
+
 	// Display configuration summary
 	displayConfigSummary(cfg)
 
@@ -396,6 +470,16 @@ func applyFlagsToConfig(cfg *config.Config) error {
 		logger.Debug("Applied repository name from flag", "name", repoName)
 	}
 
+	// Apply repository IDs
+	if repoID != 0 {
+		cfg.Repository.ID = repoID
+		logger.Debug("Applied repository ID from flag", "id", repoID)
+	}
+	if repoOwnerID != 0 {
+		cfg.Repository.OwnerID = repoOwnerID
+		logger.Debug("Applied repository owner ID from flag", "owner_id", repoOwnerID)
+	}
+
 	// Apply repository reference
 	if repoRef != "" {
 		cfg.Repository.Ref = repoRef
@@ -468,6 +552,16 @@ func applyFlagsToConfig(cfg *config.Config) error {
 		logger.Debug("Applied workload identity conditions from flag", "conditions", strings.Join(wiConditions, ", "))
 	}
 
+	// Apply binding expiration, resolving relative durations against the current time
+	if wiExpires != "" {
+		expiresAt, err := gcp.ParseBindingExpiration(wiExpires, time.Now())
+		if err != nil {
+			return err
+		}
+		cfg.WorkloadIdentity.BindingExpiration = expiresAt.Format(time.RFC3339)
+		logger.Debug("Applied binding expiration from flag", "expires_at", cfg.WorkloadIdentity.BindingExpiration)
+	}
+
 	// Apply Cloud Run image
 	if crImage != "" {
 		cfg.CloudRun.Image = crImage
@@ -587,6 +681,24 @@ func applyFlagsToConfig(cfg *config.Config) error {
 		logger.Debug("Applied workflow Docker image (as ServiceName) from flag", "service_name_for_image", wfDockerImage)
 	}
 
+	// Apply deployment target
+	if err := applyTargetFlags(cfg); err != nil {
+		return err
+	}
+
+	// Apply terraform workflow kind
+	if err := applyTerraformFlags(cfg); err != nil {
+		return err
+	}
+
+	// Apply supply-chain attestations and the roles they need
+	applySupplyChainFlags(cfg)
+
+	// The workflow reads the values pushed to GitHub Actions variables
+	if pushVariables {
+		cfg.Workflow.UseActionsVariables = true
+	}
+
 	// Apply dry run
 	if dryRun {
 		cfg.Advanced.DryRun = dryRun
@@ -642,6 +754,154 @@ func applyFlagsToConfig(cfg *config.Config) error {
 	// Apply defaults after flag overrides
 	cfg.SetDefaults()
 
+	// Deploy each configured environment in its own job with its own identity
+	cfg.ApplyEnvironmentPipeline()
+
+	return nil
+}
+
+// applyTargetFlags applies the deployment target and its settings. Selecting a target
+// replaces the default service account roles and workflow name with the target's own,
+// unless they were customized.
+func applyTargetFlags(cfg *config.Config) error {
+	logger := logging.WithField("function", "applyTargetFlags")
+
+	if deployTarget != "" {
+		target, err := github.GetDeploymentTarget(deployTarget)
+		if err != nil {
+			return err
+		}
+		cfg.Workflow.Target = target.Name()
+		logger.Debug("Applied deployment target from flag", "target", target.Name())
+
+		if len(saRoles) == 0 && (len(cfg.ServiceAccount.Roles) == 0 ||
+			strings.Join(cfg.ServiceAccount.Roles, ",") == strings.Join(config.DefaultRoles(), ",")) {
+			cfg.ServiceAccount.Roles = target.Roles()
+			logger.Debug("Applied deployment target roles", "roles", strings.Join(target.Roles(), ", "))
+		}
+		if wfName == "" && cfg.Workflow.Name == github.DefaultWorkflowConfig().Name {
+			cfg.Workflow.Name = fmt.Sprintf("Deploy to %s with WIF", target.DisplayName())
+		}
+	}
+
+	if gkeCluster != "" {
+		cfg.Workflow.GKE.Cluster = gkeCluster
+		logger.Debug("Applied GKE cluster from flag", "cluster", gkeCluster)
+	}
+	if gkeNamespace != "" {
+		cfg.Workflow.GKE.Namespace = gkeNamespace
+		logger.Debug("Applied GKE namespace from flag", "namespace", gkeNamespace)
+	}
+	if functionRuntime != "" {
+		cfg.Workflow.CloudFunctions.Runtime = functionRuntime
+		logger.Debug("Applied Cloud Functions runtime from flag", "runtime", functionRuntime)
+	}
+	if functionEntryPoint != "" {
+		cfg.Workflow.CloudFunctions.EntryPoint = functionEntryPoint
+		logger.Debug("Applied Cloud Functions entry point from flag", "entry_point", functionEntryPoint)
+	}
+
+	return nil
+}
+
+// applySupplyChainFlags enables the supply-chain attestations of the pushed image. The
+// roles the enabled attestations need are added to the service accounts, unless the roles
+// were set with --roles.
+func applySupplyChainFlags(cfg *config.Config) {
+	logger := logging.WithField("function", "applySupplyChainFlags")
+
+	security := &cfg.Workflow.Security
+	if generateSBOM {
+		security.GenerateSBOM = true
+	}
+	if signImages {
+		security.SignImages = true
+	}
+	if slsaProvenance {
+		security.SLSAProvenance = true
+	}
+	if binauthzAttestor != "" {
+		security.BinaryAuthorization.Enabled = true
+		security.BinaryAuthorization.Attestor = binauthzAttestor
+	}
+	if binauthzKeyVersion != "" {
+		security.BinaryAuthorization.KeyVersion = binauthzKeyVersion
+	}
+
+	roles := security.SupplyChainRoles()
+	if len(roles) == 0 || len(saRoles) > 0 {
+		return
+	}
+	if len(cfg.ServiceAccount.Roles) == 0 {
+		cfg.ServiceAccount.Roles = config.DefaultRoles()
+	}
+	cfg.ServiceAccount.Roles = appendMissingRoles(cfg.ServiceAccount.Roles, roles)
+	for name, env := range cfg.Environments {
+		if len(env.Resources.ServiceAccount.Roles) > 0 {
+			env.Resources.ServiceAccount.Roles = appendMissingRoles(env.Resources.ServiceAccount.Roles, roles)
+			cfg.Environments[name] = env
+		}
+	}
+	logger.Debug("Applied supply-chain roles", "roles", strings.Join(roles, ", "))
+}
+
+// appendMissingRoles adds the roles not yet in a role list
+func appendMissingRoles(current, roles []string) []string {
+	for _, role := range roles {
+		if !envContains(current, role) {
+			current = append(current, role)
+		}
+	}
+	return current
+}
+
+// applyTerraformFlags applies the workflow kind and terraform settings. Selecting the
+// terraform kind gives the service account the apply roles and renames the default workflow,
+// unless they were customized.
+func applyTerraformFlags(cfg *config.Config) error {
+	logger := logging.WithField("function", "applyTerraformFlags")
+
+	if workflowKind != "" {
+		if workflowKind != github.WorkflowKindDeploy && workflowKind != github.WorkflowKindTerraform {
+			return errors.NewValidationError(fmt.Sprintf("Unknown workflow kind: %s", workflowKind),
+				fmt.Sprintf("Use --kind %s or --kind %s", github.WorkflowKindDeploy, github.WorkflowKindTerraform))
+		}
+		cfg.Workflow.Kind = workflowKind
+		logger.Debug("Applied workflow kind from flag", "kind", workflowKind)
+	}
+	if !cfg.Workflow.IsTerraform() {
+		return nil
+	}
+
+	if len(saRoles) == 0 && (len(cfg.ServiceAccount.Roles) == 0 ||
+		strings.Join(cfg.ServiceAccount.Roles, ",") == strings.Join(config.DefaultRoles(), ",")) {
+		cfg.ServiceAccount.Roles = github.TerraformApplyRoles()
+		logger.Debug("Applied terraform apply roles", "roles", strings.Join(cfg.ServiceAccount.Roles, ", "))
+	}
+	if wfName == "" && cfg.Workflow.Name == github.DefaultWorkflowConfig().Name {
+		cfg.Workflow.Name = "Terraform plan and apply with WIF"
+	}
+	if wfFilename == "" && cfg.Workflow.Filename == github.DefaultWorkflowConfig().Filename {
+		cfg.Workflow.Filename = "terraform-wif.yml"
+	}
+
+	if tfStateBucket != "" {
+		cfg.Workflow.Terraform.StateBucket = tfStateBucket
+		logger.Debug("Applied terraform state bucket from flag", "bucket", tfStateBucket)
+	}
+	if tfStatePrefix != "" {
+		cfg.Workflow.Terraform.StatePrefix = tfStatePrefix
+		logger.Debug("Applied terraform state prefix from flag", "prefix", tfStatePrefix)
+	}
+	if tfWorkingDir != "" {
+		cfg.Workflow.Terraform.WorkingDirectory = tfWorkingDir
+		logger.Debug("Applied terraform working directory from flag", "working_directory", tfWorkingDir)
+	}
+	if tfApplyEnvironment != "" {
+		cfg.Workflow.Terraform.ApplyEnvironment = tfApplyEnvironment
+		logger.Debug("Applied terraform apply environment from flag", "environment", tfApplyEnvironment)
+	}
+
 	return nil
 }
 
@@ -772,7 +1032,7 @@ func parseAndApplyEnvironmentProtection(cfg *config.Config, envProt string) erro
 		env = github.Environment{Name: envName}
 	}
 
-	// Parse protection rules (format: "reviewers=@team1,@team2,wait=5,prevent_self=true")
+	// Parse protection rules (format: "reviewers=@team1 @team2,wait=5,prevent_self=true,branches=main release/*")
 	rules := strings.Split(protectionRules, ",")
 	for _, rule := range rules {
 		rule = strings.TrimSpace(rule)
@@ -798,6 +1058,12 @@ func parseAndApplyEnvironmentProtection(cfg *config.Config, envProt string) erro
 			env.Protection.PreventSelfReview = true
 		} else if rule == "prevent_self=false" {
 			env.Protection.PreventSelfReview = false
+		} else if strings.HasPrefix(rule, "branches=") {
+			env.Protection.DeploymentBranches = strings.Fields(strings.TrimPrefix(rule, "branches="))
+		} else if rule == "protected_branches=true" {
+			env.Protection.ProtectedBranches = true
+		} else if rule == "protected_branches=false" {
+			env.Protection.ProtectedBranches = false
 		} else {
 			return fmt.Errorf("unknown protection rule '%s'", rule)
 		}
@@ -1022,9 +1288,18 @@ func displayConfigSummary(cfg *config.Config) {
 	// Workload Identity information
 	fmt.Printf("🔗 Workload Identity Pool: %s\n", cfg.WorkloadIdentity.PoolID)
 	fmt.Printf("🔌 Workload Identity Provider: %s\n", cfg.WorkloadIdentity.ProviderID)
-
-	// Cloud Run information (if configured)
-	if cfg.CloudRun.ServiceName != "" {
+	if cfg.WorkloadIdentity.BindingExpiration != "" {
+		fmt.Printf("⏰ Binding Expires: %s\n", cfg.WorkloadIdentity.BindingExpiration)
+	}
+
+	// Deployment target information; Cloud Run details are shown for the default target
+	if cfg.Workflow.IsTerraform() {
+		settings := cfg.Workflow.TerraformSettings()
+		fmt.Printf("🧱 Terraform: %s (state gs://%s/%s)\n", settings.WorkingDirectory, settings.StateBucket, settings.StatePrefix)
+		fmt.Printf("👁️  Plan Service Account: %s\n", cfg.GetTerraformPlanServiceAccountEmail())
+	} else if target, err := cfg.Workflow.GetDeploymentTarget(); err == nil && target.Name() != github.TargetCloudRun {
+		fmt.Printf("🎯 Deployment Target: %s (%s)\n", target.DisplayName(), cfg.Workflow.ServiceName)
+	} else if cfg.CloudRun.ServiceName != "" {
 		fmt.Printf("☁️  Cloud Run Service: %s\n", cfg.CloudRun.ServiceName)
 		fmt.Printf("🌍 Cloud Run Region: %s\n", cfg.CloudRun.Region)
 		if cfg.GetCloudRunURL() != "" {
@@ -1063,7 +1338,8 @@ func displayConfigSummary(cfg *config.Config) {
 	for _, s := range cfg.Workflow.Triggers.Schedule {
 		triggerDisplayStrings = append(triggerDisplayStrings, fmt.Sprintf("Schedule (%s)", s.Cron))
 	}
-	if len(triggerDisplayStrings) > 0 {
+	// Terraform workflows derive their triggers from the apply branch
+	if len(triggerDisplayStrings) > 0 && !cfg.Workflow.IsTerraform() {
 		fmt.Printf("🎯 Triggers: %s\n", strings.Join(triggerDisplayStrings, "; "))
 	}
 
@@ -1125,6 +1401,14 @@ func runDryRunMode(cfg *config.Config) error {
 	fmt.Printf("   • Display Name: %s\n", cfg.ServiceAccount.DisplayName)
 	fmt.Printf("   • Description: %s\n", cfg.ServiceAccount.Description)
 	fmt.Printf("   • Roles to Grant: %s\n", strings.Join(cfg.ServiceAccount.Roles, ", "))
+	if cfg.Workflow.IsTerraform() {
+		fmt.Printf("   • Terraform Plan Account: %s\n", cfg.GetTerraformPlanServiceAccountEmail())
+		fmt.Printf("   • Plan Roles to Grant: %s\n", strings.Join(github.TerraformPlanRoles(), ", "))
+	}
+	for _, name := range pipelineEnvironments(cfg) {
+		env := cfg.Environments[name]
+		fmt.Printf("   • %s Account: %s\n", name, cfg.GetEnvironmentServiceAccountEmail(&env))
+	}
 
 	// 2. Workload Identity Pool
 	fmt.Printf("\n2. 🏊 Workload Identity Pool Creation:\n")
@@ -1138,12 +1422,32 @@ func runDryRunMode(cfg *config.Config) error {
 	fmt.Printf("   • Provider Name: %s\n", cfg.WorkloadIdentity.ProviderName)
 	fmt.Printf("   • GitHub OIDC Issuer: https://token.actions.githubusercontent.com\n")
 	fmt.Printf("   • Conditions: %s\n", strings.Join(cfg.WorkloadIdentity.Conditions, "; "))
+	if len(cfg.WorkloadIdentity.SubjectClaimKeys) > 0 {
+		fmt.Printf("   • OIDC Subject Claims: %s\n", strings.Join(cfg.WorkloadIdentity.SubjectClaimKeys, ", "))
+	}
+	if cfg.Workflow.IsTerraform() {
+		fmt.Printf("   • Apply Provider accepts: refs/heads/%s\n", cfg.Workflow.TerraformSettings().ApplyBranch)
+		fmt.Printf("   • Terraform Plan Provider ID: %s (accepts pull requests)\n", cfg.GetTerraformPlanProviderID())
+	}
+	for _, name := range pipelineEnvironments(cfg) {
+		envConfig := environmentProviderConfig(cfg, name)
+		fmt.Printf("   • %s Provider ID: %s (accepts the %s GitHub environment)\n", name, envConfig.ProviderID, envConfig.AllowedEnvironments[0])
+	}
 
 	// 4. IAM Bindings
 	fmt.Printf("\n4. 🔐 IAM Policy Bindings:\n")
 	fmt.Printf("   • Bind service account to workload identity\n")
 	fmt.Printf("   • Grant roles/iam.serviceAccountTokenCreator\n")
 	fmt.Printf("   • Apply security conditions for repository: %s\n", cfg.GetRepoFullName())
+	if cfg.Workflow.IsTerraform() {
+		fmt.Printf("   • Bind the apply service account only to identities of the %s branch\n", cfg.Workflow.TerraformSettings().ApplyBranch)
+	}
+	if environments := pipelineEnvironments(cfg); len(environments) > 0 {
+		fmt.Printf("   • Bind each environment service account only to identities of its GitHub environment\n")
+	}
+	if cfg.WorkloadIdentity.BindingExpiration != "" {
+		fmt.Printf("   • Bindings expire at: %s\n", cfg.WorkloadIdentity.BindingExpiration)
+	}
 
 	// 5. Workflow Generation
 	fmt.Printf("\n5. 📄 GitHub Actions Workflow Generation:\n")
@@ -1151,6 +1455,41 @@ func runDryRunMode(cfg *config.Config) error {
 	fmt.Printf("   • Path: %s\n", cfg.Workflow.Path)
 	fmt.Printf("   • Full Path: %s\n", cfg.Workflow.GetWorkflowFilePath())
 	fmt.Printf("   • Template: %s\n", cfg.Workflow.Name)
+	if cfg.Workflow.IsTerraform() {
+		settings := cfg.Workflow.TerraformSettings()
+		fmt.Printf("   • Kind: terraform (plan on pull requests, apply on %s)\n", settings.ApplyBranch)
+		fmt.Printf("   • State: gs://%s/%s\n", settings.StateBucket, settings.StatePrefix)
+		fmt.Printf("   • Apply Environment: %s\n", settings.ApplyEnvironment)
+	} else if target, err := cfg.Workflow.GetDeploymentTarget(); err == nil {
+		fmt.Printf("   • Deployment Target: %s\n", target.DisplayName())
+	}
+	if len(cfg.Workflow.Advanced.Pipeline) > 0 {
+		fmt.Printf("   • Deploy Jobs: %s\n", strings.Join(cfg.Workflow.Advanced.Pipeline, " → "))
+	}
+
+	// GitHub repository configuration
+	if githubEnvs || pushVariables || openPR {
+		fmt.Printf("\n🐙 GitHub Repository Configuration:\n")
+	}
+	if githubEnvs {
+		names := make([]string, 0, len(cfg.Workflow.Advanced.Environments))
+		for name := range cfg.Workflow.Advanced.Environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			protection := cfg.Workflow.Advanced.Environments[name].Protection
+			fmt.Printf("   • Environment %s: %d reviewer(s), wait %d min, deploys from %s\n", name,
+				len(protection.RequiredReviewers), protection.WaitTimer,
+				protection.DeploymentBranchPolicy())
+		}
+	}
+	if pushVariables {
+		fmt.Printf("   • Write PROJECT_ID, WORKLOAD_IDENTITY_PROVIDER and SERVICE_ACCOUNT to Actions variables\n")
+	}
+	if openPR {
+		fmt.Printf("   • Open a pull request with %s and %s\n", cfg.Workflow.GetWorkflowFilePath(), setupConfigFile())
+	}
 
 	// 6. Summary
 	fmt.Printf("\n📊 Summary:\n")
@@ -1211,8 +1550,43 @@ func runOrchestration(cfg *config.Config) error {
 		return fmt.Errorf("configuration save failed: %w", err)
 	}
 
-	// Step 7: Display Success Summary
-	displaySuccessSummary(cfg)
+	// Step 7: Configure the GitHub Repository. The GCP resources exist at this point, so
+	// failures are reported without failing the setup. Environments are created first so
+	// environment-level variables can be written to them.
+	if githubEnvs || pushVariables {
+		fmt.Println("\n7. 🐙 Configuring GitHub Repository...")
+	}
+	if githubEnvs {
+		if results, err := applyGitHubEnvironments(cfg, "", false); err != nil {
+			logger.Warn("Failed to configure GitHub environments", "error", err)
+			fmt.Printf("⚠️  Failed to configure GitHub environments: %v\n", err)
+			fmt.Println("   Retry with 'gcp-wif github environments' once the credentials are fixed")
+		} else {
+			displayEnvironmentResults(results, false)
+		}
+	}
+	if pushVariables {
+		if err := pushActionsVariables(cfg, "", nil, false); err != nil {
+			logger.Warn("Failed to write GitHub Actions variables", "error", err)
+			fmt.Printf("⚠️  Failed to write GitHub Actions variables: %v\n", err)
+			fmt.Println("   Retry with 'gcp-wif github variables' once the credentials are fixed")
+		}
+	}
+
+	// Step 8: Propose the workflow and configuration in a pull request. Like step 7, a
+	// failure leaves the files in the working tree to be committed by hand.
+	var pullRequest *github.PullRequestInfo
+	if openPR {
+		fmt.Println("\n8. 📬 Opening Pull Request...")
+		if pullRequest, err = orchestratePullRequest(cfg); err != nil {
+			logger.Warn("Failed to open pull request", "error", err)
+			fmt.Printf("⚠️  Failed to open a pull request: %v\n", err)
+			fmt.Println("   Commit the workflow and configuration yourself once the problem is fixed")
+		}
+	}
+
+	// Step 9: Display Success Summary
+	displaySuccessSummary(cfg, pullRequest)
 
 	return nil
 }
@@ -1308,6 +1682,111 @@ func orchestrateServiceAccount(client *gcp.Client, cfg *config.Config) error {
 
 	fmt.Printf("   ✅ Service account created: %s\n", serviceAccountInfo.Email)
 
+	if cfg.Workflow.IsTerraform() {
+		if err := orchestrateTerraformPlanServiceAccount(client, cfg); err != nil {
+			return err
+		}
+	}
+	if err := orchestrateEnvironmentServiceAccounts(client, cfg); err != nil {
+		return err
+	}
+
+	// Existing service accounts may still carry keys that federation makes unnecessary
+	keys, err := client.ListServiceAccountKeys(serviceAccountInfo.Email)
+	if err != nil {
+		logging.WithField("function", "orchestrateServiceAccount").Warn("Failed to list service account keys",
+			"service_account", serviceAccountInfo.Email, "error", err)
+		return nil
+	}
+	warnServiceAccountKeys(keys, serviceAccountInfo.Email)
+
+	return nil
+}
+
+// orchestrateTerraformPlanServiceAccount creates the read-only service account terraform
+// workflows plan with
+func orchestrateTerraformPlanServiceAccount(client *gcp.Client, cfg *config.Config) error {
+	serviceAccountConfig := &gcp.ServiceAccountConfig{
+		Name:        cfg.GetTerraformPlanServiceAccountName(),
+		DisplayName: fmt.Sprintf("Terraform plan SA for %s", cfg.GetRepoFullName()),
+		Description: "Read-only service account for terraform plans on pull requests",
+		Roles:       github.TerraformPlanRoles(),
+		CreateNew:   cfg.ServiceAccount.CreateNew,
+	}
+
+	fmt.Printf("   • Creating terraform plan service account: %s\n", serviceAccountConfig.Name)
+
+	serviceAccountInfo, err := client.CreateServiceAccount(serviceAccountConfig)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("   ✅ Terraform plan service account created: %s\n", serviceAccountInfo.Email)
+	return nil
+}
+
+// pipelineEnvironments returns the environments that get their own deploy job and identity
+func pipelineEnvironments(cfg *config.Config) []string {
+	if cfg.Workflow.IsTerraform() {
+		return nil
+	}
+	return cfg.GetPipelineEnvironments()
+}
+
+// environmentProviderConfig returns the provider and binding settings of an environment:
+// its own provider, accepting only jobs that run in the environment's GitHub environment,
+// and its own service account, bound only to the identities of those jobs, as every
+// environment's provider shares the pool. Every pipeline job runs for the same ref, so the
+// environment's protection rules rather than branches separate the identities.
+func environmentProviderConfig(cfg *config.Config, name string) *gcp.WorkloadIdentityConfig {
+	env := cfg.Environments[name]
+	if env.Name == "" {
+		env.Name = name
+	}
+	return &gcp.WorkloadIdentityConfig{
+		PoolID:              cfg.WorkloadIdentity.PoolID,
+		ProviderName:        fmt.Sprintf("%s (%s)", cfg.WorkloadIdentity.ProviderName, name),
+		ProviderID:          cfg.GetEnvironmentProviderID(&env),
+		Repository:          cfg.GetRepoFullName(),
+		RepositoryID:        cfg.Repository.ID,
+		RepositoryOwnerID:   cfg.Repository.OwnerID,
+		ServiceAccountEmail: cfg.GetEnvironmentServiceAccountEmail(&env),
+		AllowedBranches:     cfg.Repository.Branches,
+		AllowedTags:         cfg.Repository.Tags,
+		AllowedEnvironments: []string{env.GetGitHubEnvironment()},
+		PrincipalSets:       []string{gcp.RepositoryEnvironmentMember(cfg.GetRepoFullName(), env.GetGitHubEnvironment())},
+		ClaimsMapping:       cfg.GetClaimsMapping(),
+		ExpirationTime:      cfg.WorkloadIdentity.BindingExpiration,
+		CreateNew:           true,
+	}
+}
+
+// orchestrateEnvironmentServiceAccounts creates the service account each pipeline
+// environment deploys with
+func orchestrateEnvironmentServiceAccounts(client *gcp.Client, cfg *config.Config) error {
+	for _, name := range pipelineEnvironments(cfg) {
+		env := cfg.Environments[name]
+		roles := env.Resources.ServiceAccount.Roles
+		if len(roles) == 0 {
+			roles = cfg.ServiceAccount.Roles
+		}
+		serviceAccountConfig := &gcp.ServiceAccountConfig{
+			Name:        cfg.GetEnvironmentServiceAccountName(&env),
+			DisplayName: fmt.Sprintf("GitHub Actions SA for %s (%s)", cfg.GetRepoFullName(), name),
+			Description: fmt.Sprintf("Deploys %s to the %s environment", cfg.GetRepoFullName(), name),
+			Roles:       roles,
+			CreateNew:   cfg.ServiceAccount.CreateNew,
+		}
+
+		fmt.Printf("   • Creating %s service account: %s\n", name, serviceAccountConfig.Name)
+
+		serviceAccountInfo, err := client.CreateServiceAccount(serviceAccountConfig)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("   ✅ %s service account created: %s\n", name, serviceAccountInfo.Email)
+	}
 	return nil
 }
 
@@ -1338,13 +1817,30 @@ func orchestrateWorkloadIdentityProvider(client *gcp.Client, cfg *config.Config)
 		ProviderName:        cfg.WorkloadIdentity.ProviderName,
 		ProviderID:          cfg.WorkloadIdentity.ProviderID,
 		Repository:          cfg.GetRepoFullName(),
+		RepositoryID:        cfg.Repository.ID,
+		RepositoryOwnerID:   cfg.Repository.OwnerID,
 		ServiceAccountEmail: cfg.GetServiceAccountEmail(),
 		AllowedBranches:     cfg.Repository.Branches,
 		AllowedTags:         cfg.Repository.Tags,
 		AllowPullRequests:   cfg.Repository.PullRequest,
+		ClaimsMapping:       cfg.GetClaimsMapping(),
 		CreateNew:           true, // Always create new for orchestration
 	}
 
+	// Preserve audiences of imported providers instead of the repository defaults
+	if len(cfg.WorkloadIdentity.AllowedAudiences) > 0 {
+		workloadIdentityConfig.GitHubOIDC = gcp.GetGitHubRepositorySpecificOIDCConfig(cfg.GetRepoFullName())
+		workloadIdentityConfig.GitHubOIDC.AllowedAudiences = cfg.WorkloadIdentity.AllowedAudiences
+	}
+
+	// Terraform workflows apply through a provider that only accepts the apply branch, and
+	// plan through a second provider that only accepts pull requests
+	if cfg.Workflow.IsTerraform() {
+		workloadIdentityConfig.AllowedBranches = []string{cfg.Workflow.TerraformSettings().ApplyBranch}
+		workloadIdentityConfig.AllowedTags = nil
+		workloadIdentityConfig.AllowPullRequests = false
+	}
+
 	fmt.Printf("   • Creating workload identity provider: %s\n", cfg.WorkloadIdentity.ProviderID)
 
 	providerInfo, err := client.CreateWorkloadIdentityProvider(workloadIdentityConfig)
@@ -1353,25 +1849,130 @@ func orchestrateWorkloadIdentityProvider(client *gcp.Client, cfg *config.Config)
 	}
 
 	fmt.Printf("   ✅ Workload identity provider created: %s\n", providerInfo.Name)
+
+	if cfg.Workflow.IsTerraform() {
+		planConfig := *workloadIdentityConfig
+		planConfig.ProviderID = cfg.GetTerraformPlanProviderID()
+		planConfig.ProviderName = fmt.Sprintf("%s plan", cfg.WorkloadIdentity.ProviderName)
+		planConfig.ServiceAccountEmail = cfg.GetTerraformPlanServiceAccountEmail()
+		planConfig.AllowedBranches = nil
+		planConfig.AllowPullRequests = true
+
+		fmt.Printf("   • Creating terraform plan provider: %s\n", planConfig.ProviderID)
+
+		planInfo, err := client.CreateWorkloadIdentityProvider(&planConfig)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("   ✅ Terraform plan provider created: %s\n", planInfo.Name)
+	}
+
+	for _, name := range pipelineEnvironments(cfg) {
+		envConfig := environmentProviderConfig(cfg, name)
+		fmt.Printf("   • Creating %s provider: %s\n", name, envConfig.ProviderID)
+
+		envInfo, err := client.CreateWorkloadIdentityProvider(envConfig)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("   ✅ %s provider created: %s\n", name, envInfo.Name)
+	}
+
+	// Existing providers are reused as they are; those created before the repository was
+	// pinned neither map nor check its IDs, so bindings by ID would never match
+	if cfg.Repository.ID > 0 || cfg.Repository.OwnerID > 0 {
+		if err := pinWorkloadIdentityProviders(client, cfg); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// orchestrateServiceAccountBinding handles service account to workload identity binding
-func orchestrateServiceAccountBinding(client *gcp.Client, cfg *config.Config) error {
+// serviceAccountBindingConfig returns the binding of the main service account. Terraform's
+// apply service account is bound only to the apply branch's identities, so pull request
+// tokens accepted by the plan provider in the same pool cannot impersonate it.
+func serviceAccountBindingConfig(cfg *config.Config) *gcp.WorkloadIdentityConfig {
 	workloadIdentityConfig := &gcp.WorkloadIdentityConfig{
 		PoolID:              cfg.WorkloadIdentity.PoolID,
 		ProviderID:          cfg.WorkloadIdentity.ProviderID,
 		Repository:          cfg.GetRepoFullName(),
+		RepositoryID:        cfg.Repository.ID,
+		RepositoryOwnerID:   cfg.Repository.OwnerID,
 		ServiceAccountEmail: cfg.GetServiceAccountEmail(),
+		ExpirationTime:      cfg.WorkloadIdentity.BindingExpiration,
+	}
+	if cfg.Workflow.IsTerraform() {
+		applyBranch := cfg.Workflow.TerraformSettings().ApplyBranch
+		workloadIdentityConfig.AllowedBranches = []string{applyBranch}
+		workloadIdentityConfig.PrincipalSets = []string{gcp.RepositoryRefMember(cfg.GetRepoFullName(), "refs/heads/"+applyBranch)}
+	}
+	return workloadIdentityConfig
+}
+
+// terraformPlanBindingConfig returns the binding of the read-only terraform plan service
+// account, which any of the repository's identities may use
+func terraformPlanBindingConfig(cfg *config.Config) *gcp.WorkloadIdentityConfig {
+	return &gcp.WorkloadIdentityConfig{
+		PoolID:              cfg.WorkloadIdentity.PoolID,
+		ProviderID:          cfg.GetTerraformPlanProviderID(),
+		Repository:          cfg.GetRepoFullName(),
+		RepositoryID:        cfg.Repository.ID,
+		RepositoryOwnerID:   cfg.Repository.OwnerID,
+		ServiceAccountEmail: cfg.GetTerraformPlanServiceAccountEmail(),
+		AllowPullRequests:   true,
+		ExpirationTime:      cfg.WorkloadIdentity.BindingExpiration,
 	}
+}
+
+// serviceAccountBindingConfigs returns the bindings of every service account setup binds
+func serviceAccountBindingConfigs(cfg *config.Config) []*gcp.WorkloadIdentityConfig {
+	bindings := []*gcp.WorkloadIdentityConfig{serviceAccountBindingConfig(cfg)}
+	if cfg.Workflow.IsTerraform() {
+		bindings = append(bindings, terraformPlanBindingConfig(cfg))
+	}
+	for _, name := range pipelineEnvironments(cfg) {
+		bindings = append(bindings, environmentProviderConfig(cfg, name))
+	}
+	return bindings
+}
+
+// orchestrateServiceAccountBinding handles service account to workload identity binding
+func orchestrateServiceAccountBinding(client *gcp.Client, cfg *config.Config) error {
+	workloadIdentityConfig := serviceAccountBindingConfig(cfg)
 
 	fmt.Printf("   • Binding service account to workload identity\n")
+	if workloadIdentityConfig.ExpirationTime != "" {
+		fmt.Printf("   • Binding expires at: %s\n", workloadIdentityConfig.ExpirationTime)
+	}
 
 	if err := client.BindServiceAccountToWorkloadIdentity(workloadIdentityConfig); err != nil {
 		return err
 	}
 
 	fmt.Printf("   ✅ Service account bound successfully\n")
+
+	if cfg.Workflow.IsTerraform() {
+		fmt.Printf("   • Binding terraform plan service account to the plan provider\n")
+
+		if err := client.BindServiceAccountToWorkloadIdentity(terraformPlanBindingConfig(cfg)); err != nil {
+			return err
+		}
+
+		fmt.Printf("   ✅ Terraform plan service account bound successfully\n")
+	}
+
+	// Environment service accounts are only reachable from jobs in their GitHub environments
+	for _, name := range pipelineEnvironments(cfg) {
+		fmt.Printf("   • Binding %s service account to the %s provider\n", name, name)
+
+		if err := client.BindServiceAccountToWorkloadIdentity(environmentProviderConfig(cfg, name)); err != nil {
+			return err
+		}
+
+		fmt.Printf("   ✅ %s service account bound successfully\n", name)
+	}
 	return nil
 }
 
@@ -1397,7 +1998,7 @@ func orchestrateWorkflowGeneration(cfg *config.Config) error {
 
 // orchestrateConfigurationSave handles saving the final configuration
 func orchestrateConfigurationSave(cfg *config.Config) error {
-	configFile := "wif-config.json"
+	configFile := setupConfigFile()
 
 	fmt.Printf("   • Saving configuration to: %s\n", configFile)
 
@@ -1410,8 +2011,140 @@ func orchestrateConfigurationSave(cfg *config.Config) error {
 	return nil
 }
 
+// setupConfigFile returns the file setup saves the configuration to: the one given with
+// --config, in its format, or the default configuration file
+func setupConfigFile() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	return config.DefaultConfigFile()
+}
+
+// orchestratePullRequest commits the generated workflow and configuration to a new branch
+// and opens a pull request into the default branch. A local checkout of the repository
+// commits and pushes with git, from its current HEAD; otherwise the files are committed
+// through the contents API.
+func orchestratePullRequest(cfg *config.Config) (*github.PullRequestInfo, error) {
+	owner, repo := cfg.Repository.Owner, cfg.Repository.Name
+	if owner == "" || repo == "" {
+		return nil, errors.NewConfigurationError("Repository owner and name are required to open a pull request",
+			"Set repository.owner and repository.name in the configuration")
+	}
+
+	client, err := newGitHubAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	base, err := client.DefaultBranch(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	pr := &github.PullRequest{
+		Owner:         owner,
+		Repo:          repo,
+		Branch:        fmt.Sprintf("gcp-wif/setup-%s", time.Now().Format("20060102-150405")),
+		Base:          base,
+		Title:         fmt.Sprintf("Deploy to %s with Workload Identity Federation", cfg.Project.ID),
+		CommitMessage: "Add Workload Identity Federation workflow and configuration",
+	}
+
+	checkout := github.FindLocalCheckout(".", owner, repo)
+	for _, path := range []string{cfg.Workflow.GetWorkflowFilePath(), setupConfigFile()} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "FILE_READ_FAILED",
+				fmt.Sprintf("Failed to read %s", path))
+		}
+		repoPath, err := pullRequestFilePath(checkout, path)
+		if err != nil {
+			return nil, err
+		}
+		pr.Files = append(pr.Files, github.PullRequestFile{Path: repoPath, Content: content})
+	}
+	pr.Body = setupPullRequestBody(cfg, pr.Files)
+
+	method := "api"
+	if checkout != nil {
+		method = "git"
+		fmt.Printf("   • Committing to branch %s in %s\n", pr.Branch, checkout.Dir)
+		err = checkout.CommitAndPush(pr)
+	} else {
+		fmt.Printf("   • Committing to branch %s through the GitHub API\n", pr.Branch)
+		err = client.CommitFiles(ctx, pr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := client.CreatePullRequest(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	info.Method = method
+	fmt.Printf("   ✅ Pull request #%d opened: %s\n", info.Number, info.URL)
+	return info, nil
+}
+
+// pullRequestFilePath returns the path of a file within the repository: relative to the root
+// of the local checkout, or without one, relative to the current directory, which is taken
+// as the repository root. Paths outside the repository are rejected.
+func pullRequestFilePath(checkout *github.LocalCheckout, path string) (string, error) {
+	if checkout != nil {
+		return checkout.RelativePath(path)
+	}
+	if !filepath.IsLocal(path) {
+		return "", errors.NewValidationError(
+			fmt.Sprintf("%s is outside the repository and cannot be committed through the GitHub API", path),
+			"Run setup from the repository root with the workflow and configuration file paths relative to it")
+	}
+	return filepath.ToSlash(filepath.Clean(path)), nil
+}
+
+// setupPullRequestBody describes the resources setup created for the pull request
+func setupPullRequestBody(cfg *config.Config, files []github.PullRequestFile) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Adds a GitHub Actions workflow that authenticates to the Google Cloud project `%s` ", cfg.Project.ID)
+	body.WriteString("through Workload Identity Federation, without service account keys.\n\n")
+
+	body.WriteString("### Google Cloud resources\n\n")
+	body.WriteString("| Resource | Name |\n|---|---|\n")
+	fmt.Fprintf(&body, "| Workload Identity Pool | `%s` |\n", cfg.WorkloadIdentity.PoolID)
+	fmt.Fprintf(&body, "| Workload Identity Provider | `%s` |\n", cfg.GetWorkloadIdentityProviderName())
+	fmt.Fprintf(&body, "| Service Account | `%s` |\n", cfg.GetServiceAccountEmail())
+	if cfg.Workflow.IsTerraform() {
+		fmt.Fprintf(&body, "| Terraform Plan Provider | `%s` |\n", cfg.GetTerraformPlanProviderName())
+		fmt.Fprintf(&body, "| Terraform Plan Service Account | `%s` |\n", cfg.GetTerraformPlanServiceAccountEmail())
+	}
+	for _, name := range pipelineEnvironments(cfg) {
+		env := cfg.Environments[name]
+		fmt.Fprintf(&body, "| %s Provider | `%s` |\n", name, cfg.GetEnvironmentProviderID(&env))
+		fmt.Fprintf(&body, "| %s Service Account | `%s` |\n", name, cfg.GetEnvironmentServiceAccountEmail(&env))
+	}
+
+	if len(cfg.WorkloadIdentity.Conditions) > 0 {
+		body.WriteString("\n### Provider conditions\n\nOnly tokens matching these conditions can impersonate the service account:\n\n```\n")
+		for _, condition := range cfg.WorkloadIdentity.Conditions {
+			body.WriteString(condition + "\n")
+		}
+		body.WriteString("```\n")
+	}
+	if cfg.WorkloadIdentity.BindingExpiration != "" {
+		fmt.Fprintf(&body, "\nThe bindings expire at %s.\n", cfg.WorkloadIdentity.BindingExpiration)
+	}
+
+	body.WriteString("\n### Files\n\n")
+	for _, file := range files {
+		fmt.Fprintf(&body, "- `%s`\n", file.Path)
+	}
+	body.WriteString("\nGenerated by `gcp-wif setup`.\n")
+	return body.String()
+}
+
 // displaySuccessSummary shows the final success summary
-func displaySuccessSummary(cfg *config.Config) {
+func displaySuccessSummary(cfg *config.Config, pullRequest *github.PullRequestInfo) {
 	fmt.Println("\n🎉 Setup Complete!")
 	fmt.Println("=================")
 
@@ -1419,41 +2152,73 @@ func displaySuccessSummary(cfg *config.Config) {
 	fmt.Printf("✅ Repository: %s\n", cfg.GetRepoFullName())
 	fmt.Printf("✅ Service Account: %s\n", cfg.GetServiceAccountEmail())
 	fmt.Printf("✅ Workload Identity Provider: %s\n", cfg.GetWorkloadIdentityProviderName())
+	if cfg.Workflow.IsTerraform() {
+		fmt.Printf("✅ Terraform Plan Service Account: %s\n", cfg.GetTerraformPlanServiceAccountEmail())
+		fmt.Printf("✅ Terraform Plan Provider: %s\n", cfg.GetTerraformPlanProviderName())
+	}
+	for _, name := range pipelineEnvironments(cfg) {
+		env := cfg.Environments[name]
+		fmt.Printf("✅ %s: %s via %s\n", name, cfg.GetEnvironmentServiceAccountEmail(&env), cfg.GetEnvironmentProviderID(&env))
+	}
 	fmt.Printf("✅ GitHub Actions Workflow: %s\n", cfg.Workflow.GetWorkflowFilePath())
+	if pullRequest != nil {
+		fmt.Printf("✅ Pull Request: %s\n", pullRequest.URL)
+	}
 
 	fmt.Println("\n📋 Next Steps:")
 	fmt.Println("==============")
-	fmt.Println("1. 📤 Commit and push the generated workflow file to your repository")
+	if pullRequest != nil {
+		fmt.Printf("1. 📤 Review and merge pull request #%d: %s\n", pullRequest.Number, pullRequest.URL)
+	} else {
+		fmt.Println("1. 📤 Commit and push the generated workflow file to your repository")
+	}
 	fmt.Println("2. 🔐 Set up any required GitHub secrets in your repository settings")
 	fmt.Println("3. 🚀 Push changes to trigger the workflow and test deployment")
 	fmt.Println("4. 🔍 Monitor the workflow execution in the GitHub Actions tab")
 
-	fmt.Printf("\n💡 Workflow file location: %s\n", cfg.Workflow.GetWorkflowFilePath())
-	fmt.Printf("💡 Configuration saved to: wif-config.json\n")
+	if cfg.Workflow.IsTerraform() {
+		fmt.Printf("\n💡 Add required reviewers to the '%s' environment so terraform apply waits for approval\n",
+			cfg.Workflow.TerraformSettings().ApplyEnvironment)
+	}
+
+	fmt.Printf("💡 Workflow file location: %s\n", cfg.Workflow.GetWorkflowFilePath())
+	fmt.Printf("💡 Configuration saved to: %s\n", setupConfigFile())
 
 	fmt.Println("\n🔗 Useful commands:")
-	fmt.Println("   • gcp-wif workflow validate --config wif-config.json")
-	fmt.Println("   • gcp-wif workflow preview --config wif-config.json")
-	fmt.Printf("   • git add %s wif-config.json && git commit -m \"Add WIF workflow\"\n", cfg.Workflow.GetWorkflowFilePath())
+	fmt.Printf("   • gcp-wif workflow validate --config %s\n", setupConfigFile())
+	fmt.Printf("   • gcp-wif workflow preview --config %s\n", setupConfigFile())
+	if pullRequest == nil {
+		fmt.Printf("   • git add %s %s && git commit -m \"Add WIF workflow\"\n", cfg.Workflow.GetWorkflowFilePath(), setupConfigFile())
+	}
 }
 
 // Cleanup Helper Functions
 
 // cleanupServiceAccountBindings removes IAM bindings
 func cleanupServiceAccountBindings(client *gcp.Client, cfg *config.Config) error {
-	workloadIdentityConfig := &gcp.WorkloadIdentityConfig{
-		PoolID:              cfg.WorkloadIdentity.PoolID,
-		ProviderID:          cfg.WorkloadIdentity.ProviderID,
-		Repository:          cfg.GetRepoFullName(),
-		ServiceAccountEmail: cfg.GetServiceAccountEmail(),
+	for _, binding := range serviceAccountBindingConfigs(cfg) {
+		if err := client.RemoveServiceAccountWorkloadIdentityBinding(binding); err != nil {
+			return err
+		}
 	}
-
-	return client.RemoveServiceAccountWorkloadIdentityBinding(workloadIdentityConfig)
+	return nil
 }
 
 // cleanupWorkloadIdentityProvider removes the workload identity provider
 func cleanupWorkloadIdentityProvider(client *gcp.Client, cfg *config.Config) error {
-	return client.DeleteWorkloadIdentityProvider(cfg.WorkloadIdentity.PoolID, cfg.WorkloadIdentity.ProviderID)
+	if err := client.DeleteWorkloadIdentityProvider(cfg.WorkloadIdentity.PoolID, cfg.WorkloadIdentity.ProviderID); err != nil {
+		return err
+	}
+	if cfg.Workflow.IsTerraform() {
+		return client.DeleteWorkloadIdentityProvider(cfg.WorkloadIdentity.PoolID, cfg.GetTerraformPlanProviderID())
+	}
+	for _, name := range pipelineEnvironments(cfg) {
+		env := cfg.Environments[name]
+		if err := client.DeleteWorkloadIdentityProvider(cfg.WorkloadIdentity.PoolID, cfg.GetEnvironmentProviderID(&env)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // cleanupWorkloadIdentityPool removes the workload identity pool
@@ -1463,5 +2228,17 @@ func cleanupWorkloadIdentityPool(client *gcp.Client, cfg *config.Config) error {
 
 // cleanupServiceAccount removes the service account
 func cleanupServiceAccount(client *gcp.Client, cfg *config.Config) error {
-	return client.DeleteServiceAccount(cfg.ServiceAccount.Name)
+	if err := client.DeleteServiceAccount(cfg.ServiceAccount.Name); err != nil {
+		return err
+	}
+	if cfg.Workflow.IsTerraform() {
+		return client.DeleteServiceAccount(cfg.GetTerraformPlanServiceAccountName())
+	}
+	for _, name := range pipelineEnvironments(cfg) {
+		env := cfg.Environments[name]
+		if err := client.DeleteServiceAccount(cfg.GetEnvironmentServiceAccountName(&env)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Fordjour12/gcp-wif/internal/config"
+	"github.com/Fordjour12/gcp-wif/internal/github"
+)
+
+func TestTerraformBindingPrincipalSets(t *testing.T) {
+	cfg := config.NewConfig("test-project-123", "acme", "app")
+	cfg.Workflow.Kind = github.WorkflowKindTerraform
+	cfg.Workflow.Terraform.ApplyBranch = "release"
+
+	apply := serviceAccountBindingConfig(cfg)
+	if apply.ServiceAccountEmail != cfg.GetServiceAccountEmail() ||
+		!reflect.DeepEqual(apply.PrincipalSets, []string{"attribute.repository_ref/acme/app@refs/heads/release"}) {
+		t.Errorf("Expected the apply service account bound to the apply branch only, got %s: %v", apply.ServiceAccountEmail, apply.PrincipalSets)
+	}
+
+	plan := terraformPlanBindingConfig(cfg)
+	if plan.ServiceAccountEmail != cfg.GetTerraformPlanServiceAccountEmail() || len(plan.PrincipalSets) != 0 {
+		t.Errorf("Expected the read-only plan service account bound to the repository, got %s: %v", plan.ServiceAccountEmail, plan.PrincipalSets)
+	}
+
+	cfg.Workflow.Kind = ""
+	if deploy := serviceAccountBindingConfig(cfg); len(deploy.PrincipalSets) != 0 {
+		t.Errorf("Expected deploy workflows to bind the repository, got %v", deploy.PrincipalSets)
+	}
+}
+
+func TestEnvironmentBindingPrincipalSets(t *testing.T) {
+	cfg := config.NewConfig("test-project-123", "acme", "app")
+	cfg.Environments = map[string]config.EnvironmentConfig{
+		"dev":  {Name: "dev", Type: config.EnvironmentDevelopment, Enabled: true},
+		"prod": {Name: "prod", Type: config.EnvironmentProduction, Enabled: true, Workflow: config.EnvWorkflowConfig{Environment: "production"}},
+	}
+	cfg.ApplyEnvironmentPipeline()
+
+	members := map[string][]string{}
+	for _, name := range pipelineEnvironments(cfg) {
+		envConfig := environmentProviderConfig(cfg, name)
+		members[envConfig.ServiceAccountEmail] = envConfig.PrincipalSets
+	}
+	dev, prod := cfg.Environments["dev"], cfg.Environments["prod"]
+	want := map[string][]string{
+		cfg.GetEnvironmentServiceAccountEmail(&dev):  {"attribute.repository_environment/acme/app@dev"},
+		cfg.GetEnvironmentServiceAccountEmail(&prod): {"attribute.repository_environment/acme/app@production"},
+	}
+	if !reflect.DeepEqual(members, want) {
+		t.Errorf("Expected each environment service account bound to its GitHub environment only, got %v", members)
+	}
+}
+
+func TestPullRequestFilePath(t *testing.T) {
+	for path, expected := range map[string]string{
+		".github/workflows/deploy.yml": ".github/workflows/deploy.yml",
+		"./wif-config.yaml":            "wif-config.yaml",
+		"config/../wif-config.json":    "wif-config.json",
+		"../wif-config.json":           "",
+		"config/../../wif-config.json": "",
+		"/etc/gcp-wif/wif-config.json": "",
+	} {
+		got, err := pullRequestFilePath(nil, path)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("Expected %s to be rejected, got %s", path, got)
+			}
+			continue
+		}
+		if err != nil || got != expected {
+			t.Errorf("pullRequestFilePath(%s) = %q, %v; expected %s", path, got, err, expected)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Fordjour12/gcp-wif/internal/errors"
 	"github.com/Fordjour12/gcp-wif/internal/gcp"
@@ -26,6 +27,10 @@ var (
 	testIAMShowBindings   bool
 	testIAMCleanup        bool
 	testIAMValidateOnly   bool
+	testIAMExpires        string
+
+	// testIAMExpirationTime is the resolved RFC3339 form of --expires
+	testIAMExpirationTime string
 )
 
 // testIAMBindingsCmd represents the test-iam-bindings command
@@ -90,6 +95,7 @@ func init() {
 	testIAMBindingsCmd.Flags().BoolVar(&testIAMShowBindings, "show-bindings", false, "Show detailed binding information")
 	testIAMBindingsCmd.Flags().BoolVar(&testIAMCleanup, "cleanup", false, "Clean up test bindings after testing")
 	testIAMBindingsCmd.Flags().BoolVar(&testIAMValidateOnly, "validate-only", false, "Only run validation tests (no GCP API calls)")
+	testIAMBindingsCmd.Flags().StringVar(&testIAMExpires, "expires", "", "Expire created bindings after a duration (72h, 7d) or at an RFC3339 timestamp")
 
 	testIAMBindingsCmd.MarkFlagRequired("project")
 }
@@ -109,6 +115,15 @@ func runTestIAMBindings(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println()
 
+	if testIAMExpires != "" {
+		expiresAt, err := gcp.ParseBindingExpiration(testIAMExpires, time.Now())
+		if err != nil {
+			return err
+		}
+		testIAMExpirationTime = expiresAt.Format(time.RFC3339)
+		fmt.Printf("⏰ Bindings expire at: %s\n\n", testIAMExpirationTime)
+	}
+
 	// Handle validation-only mode
 	if testIAMValidateOnly {
 		return runValidationOnlyTests()
@@ -212,6 +227,7 @@ func testBasicIAMBindings(client *gcp.Client) error {
 		Repository:          testIAMRepository,
 		ServiceAccountEmail: testIAMServiceAccount,
 		GitHubOIDC:          gcp.GetDefaultGitHubOIDCConfig(),
+		ExpirationTime:      testIAMExpirationTime,
 	}
 
 	fmt.Printf("   Service Account: %s\n", testIAMServiceAccount)
@@ -264,6 +280,7 @@ func testEnhancedSecurityConditions(client *gcp.Client) error {
 		AllowedTags:         testIAMTags,
 		AllowPullRequests:   testIAMAllowPR,
 		GitHubOIDC:          oidcConfig,
+		ExpirationTime:      testIAMExpirationTime,
 	}
 
 	fmt.Printf("   Service Account: %s\n", testIAMServiceAccount)
@@ -317,6 +334,7 @@ func testGitHubSecurityFeatures(client *gcp.Client) error {
 		Repository:          testIAMRepository,
 		ServiceAccountEmail: testIAMServiceAccount,
 		GitHubOIDC:          oidcConfig,
+		ExpirationTime:      testIAMExpirationTime,
 	}
 
 	fmt.Printf("   Repository: %s\n", testIAMRepository)
@@ -373,7 +391,7 @@ func testGitHubSecurityFeatures(client *gcp.Client) error {
 
 func testComprehensiveIAMBindings(client *gcp.Client) error {
 	fmt.Println("🚀 Testing Comprehensive IAM Bindings...")
-	fmt.Println("   Running comprehensive test suite...\n")
+	fmt.Print("   Running comprehensive test suite...\n\n")
 
 	// Run all test modes in sequence (simplified to avoid conflicts)
 	phases := []struct {
@@ -474,6 +492,7 @@ func testBindingLifecycle(client *gcp.Client) error {
 		AllowedTags:         []string{"v*"},
 		AllowPullRequests:   true,
 		GitHubOIDC:          gcp.GetDefaultGitHubOIDCConfig(),
+		ExpirationTime:      testIAMExpirationTime,
 	}
 
 	fmt.Printf("   Service Account: %s\n", testIAMServiceAccount)
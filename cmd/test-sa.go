@@ -201,6 +201,14 @@ func getServiceAccountInfo(client *gcp.Client, name string) error {
 		fmt.Printf("   Project Roles: None\n")
 	}
 
+	keys, err := client.ListServiceAccountKeys(info.Email)
+	if err != nil {
+		fmt.Printf("   User-Managed Keys: unable to list (%v)\n", err)
+		return nil
+	}
+	fmt.Printf("   User-Managed Keys: %d (%d active)\n", len(keys), len(gcp.ActiveServiceAccountKeys(keys)))
+	warnServiceAccountKeys(keys, info.Email)
+
 	return nil
 }
 
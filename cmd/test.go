@@ -70,6 +70,9 @@ Test Categories:
 • performance - Performance and efficiency validation
 • resilience - Error handling and resilience testing
 
+When organization policies are loaded (--policy, or the .gcp-wif/policies
+directory), a Policy suite runs one test per policy rule.
+
 Output Formats:
 • summary - High-level test results summary (default)
 • detailed - Detailed test execution information
@@ -99,7 +102,10 @@ Examples:
   gcp-wif test --skip-categories performance,resilience
 
   # Run with custom timeout and fail-fast
-  gcp-wif test --timeout 10m --fail-fast`,
+  gcp-wif test --timeout 10m --fail-fast
+
+  # Enforce organization policies alongside the security tests
+  gcp-wif test --security-only --policy ./policies`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runTestCommand(cmd, args); err != nil {
 			HandleError(err)
@@ -140,6 +146,7 @@ func init() {
 	testCmd.Flags().BoolVar(&testShowDetails, "show-details", false, "Show detailed test information")
 	testCmd.Flags().BoolVar(&testQuiet, "quiet", false, "Suppress non-essential output")
 	testCmd.Flags().BoolVar(&testJSONOutput, "json", false, "Output results in JSON format")
+	addPolicyFlag(testCmd)
 }
 
 func runTestCommand(cmd *cobra.Command, args []string) error {
@@ -158,6 +165,15 @@ func runTestCommand(cmd *cobra.Command, args []string) error {
 	// Create test framework
 	testFramework := validation.NewTestFramework(cfg)
 
+	// Enable the Policy suite when organization policies are available
+	policies, err := loadPolicySet()
+	if err != nil {
+		return err
+	}
+	if policies != nil {
+		testFramework.WithPolicies(policies)
+	}
+
 	// Build test options
 	options, err := buildTestOptions()
 	if err != nil {
@@ -318,7 +334,7 @@ func applyTestFiltering(options *validation.TestOptions) error {
 			case "workflow":
 				suitesToRun = append(suitesToRun, "Workflow")
 			case "security":
-				suitesToRun = append(suitesToRun, "Security")
+				suitesToRun = append(suitesToRun, "Security", "Policy")
 			case "integration":
 				suitesToRun = append(suitesToRun, "Integration")
 			case "performance":
@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/Fordjour12/gcp-wif/internal/config"
+	"github.com/Fordjour12/gcp-wif/internal/errors"
 	"github.com/Fordjour12/gcp-wif/internal/github"
 	"github.com/Fordjour12/gcp-wif/internal/logging"
 	"github.com/spf13/cobra"
@@ -13,16 +18,31 @@ import (
 
 var (
 	// Workflow command flags
-	workflowConfigFile string
-	workflowOutputPath string
-	workflowFilename   string
-	workflowPreview    bool
-	workflowValidate   bool
-	workflowOverwrite  bool
-	workflowBackup     bool
-	workflowDryRun     bool
-	workflowFormat     string
-	workflowTemplate   string
+	workflowConfigFile  string
+	workflowOutputPath  string
+	workflowFilename    string
+	workflowPreview     bool
+	workflowValidate    bool
+	workflowOverwrite   bool
+	workflowBackup      bool
+	workflowDryRun      bool
+	workflowFormat      string
+	workflowTemplate    string
+	workflowLintDir     string
+	workflowLintFormat  string
+	workflowPinActions  bool
+	workflowPinUpdate   bool
+	workflowPinLock     string
+	workflowPinFiles    []string
+	workflowShowSource  bool
+	workflowMatrix      []string
+	workflowCanary      []int
+	workflowUseVars     bool
+	workflowMerge       bool
+	workflowInteractive bool
+	workflowNotifyURL   string
+	workflowNotifyType  string
+	workflowNotifyEvent string
 )
 
 // workflowCmd represents the workflow command
@@ -43,6 +63,9 @@ Available subcommands:
 - preview:  Preview workflow without writing
 - validate: Validate workflow configuration and content
 - info:     Show workflow file information
+- lint:     Scan existing workflows for security problems
+- pin:      Resolve actions to commit SHAs and record them in a lock file
+- templates: List and inspect workflow templates and presets
 
 Examples:
   # Generate workflow from config file
@@ -97,7 +120,16 @@ Examples:
   gcp-wif workflow generate --config config.json --output-path .github/workflows --filename custom-deploy.yml
 
   # Dry run to see what would be generated
-  gcp-wif workflow generate --config config.json --dry-run`,
+  gcp-wif workflow generate --config config.json --dry-run
+
+  # Deploy to several regions, one matrix entry per region
+  gcp-wif workflow generate --config config.json --matrix region=us-central1,europe-west1
+
+  # Shift Cloud Run traffic to the new revision in steps, rolling back on failure
+  gcp-wif workflow generate --config config.json --canary 10,50,100
+
+  # Regenerate, keeping edits made to the workflow file since it was generated
+  gcp-wif workflow generate --config config.json --merge`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runWorkflowGenerate(cmd, args); err != nil {
 			HandleError(err)
@@ -169,7 +201,7 @@ Examples:
 
 // workflowInfoCmd represents the workflow info command
 var workflowInfoCmd = &cobra.Command{
-	Use:   "info",
+	Use:   "info [workflow-file]",
 	Short: "Show workflow file information",
 	Long: `Display information about workflow files and configuration.
 
@@ -179,12 +211,22 @@ This command shows detailed information about:
 - File system information
 - Template information
 
+Given a workflow file, generated or hand-written, the settings are read from the
+file itself: triggers, env, the authentication identity, deployment target and
+build settings, health checks and security gates. Constructs the configuration
+cannot represent are listed; bootstrap a configuration from the file with
+'gcp-wif config init --from-workflow'.
+
 Examples:
   # Show workflow info
   gcp-wif workflow info --config config.json
 
   # Show info for specific workflow file
-  gcp-wif workflow info --output-path .github/workflows --filename deploy.yml`,
+  gcp-wif workflow info --output-path .github/workflows --filename deploy.yml
+
+  # Read the settings of an existing workflow
+  gcp-wif workflow info .github/workflows/deploy.yml`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runWorkflowInfo(cmd, args); err != nil {
 			HandleError(err)
@@ -193,6 +235,167 @@ Examples:
 	},
 }
 
+// workflowLintCmd represents the workflow lint command
+var workflowLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Scan existing workflows for security problems",
+	Long: `Statically analyze every workflow under .github/workflows for insecure patterns,
+including workflows that were not generated by this tool.
+
+Checks performed:
+• script-injection - Untrusted github.event.* values interpolated into run scripts
+• pull-request-target-checkout - pull_request_target workflows checking out the PR head
+• id-token-permission - Missing or overly broad 'id-token: write' permissions
+• long-lived-credentials - Service account keys passed via credentials_json
+• provider-mismatch / service-account-mismatch - google-github-actions/auth inputs
+  that differ from the provider and service account in the configuration
+
+Provider and service account checks run when a configuration file is found.
+The command fails when any error-severity finding is reported.
+
+Examples:
+  # Lint the repository's workflows
+  gcp-wif workflow lint
+
+  # Lint another directory and emit JSON for CI annotations
+  gcp-wif workflow lint --dir ./other/.github/workflows --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWorkflowLint(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+// workflowPinCmd represents the workflow pin command
+var workflowPinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Pin third-party actions to commit SHAs",
+	Long: `Resolve every third-party action used by the generated workflow to the commit SHA its
+tag currently points at, and record the result in an action lock file
+(default: .gcp-wif/actions.lock.json).
+
+With pinning enabled (workflow generate --pin-actions, or "pin_actions": true in the
+workflow security configuration) generation renders references such as
+  uses: actions/checkout@<sha> # v4
+using only the lock file, so output is reproducible and works offline.
+
+Actions missing from the lock are resolved through the GitHub API; set GITHUB_TOKEN to
+avoid anonymous rate limits. --update re-resolves every entry, picking up tags that have
+moved. --file pins existing workflow files in place instead of the generated workflow.
+
+Examples:
+  # Record SHAs for the actions used by the generated workflow
+  gcp-wif workflow pin
+
+  # Refresh the lock after upstream releases
+  gcp-wif workflow pin --update
+
+  # Pin an existing hand-written workflow in place
+  gcp-wif workflow pin --file .github/workflows/ci.yml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWorkflowPin(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+// workflowNotifyTestCmd represents the workflow notify-test command
+var workflowNotifyTestCmd = &cobra.Command{
+	Use:   "notify-test",
+	Short: "Send a sample notification to a webhook",
+	Long: `Post the sample payload a notification hook would send to a webhook URL, to check the
+URL and the receiving channel before storing the URL as a repository secret.
+
+Notification hooks are configured under "notification_hooks" in the advanced workflow
+configuration. Each hook has a type (slack, teams, discord or webhook), the repository
+secret holding its URL (default: SLACK_WEBHOOK_URL, TEAMS_WEBHOOK_URL,
+DISCORD_WEBHOOK_URL or NOTIFICATION_WEBHOOK_URL) and the events to notify (start,
+success, failure; default: success and failure).
+
+Flags:
+  --url      Webhook URL to post to (required)
+  --type     Hook type that shapes the payload (default: webhook)
+  --event    Event of the sample message (default: success)
+
+Examples:
+  # Check a Slack incoming webhook
+  gcp-wif workflow notify-test --type slack --url https://hooks.slack.com/services/...
+
+  # Post a generic payload to a local endpoint
+  gcp-wif workflow notify-test --url http://localhost:8080/hook --event failure`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWorkflowNotifyTest(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+// workflowTemplatesCmd represents the workflow templates command
+var workflowTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List and inspect workflow templates",
+	Long: `List and inspect the workflow templates available for generation.
+
+Templates are loaded from a search path, first match wins:
+  1. .gcp-wif/templates in the repository
+  2. gcp-wif/templates in the user config directory (e.g. ~/.config/gcp-wif/templates)
+  3. Built-in templates
+Set "template_search_path" in the workflow configuration to use other directories.
+
+A template is a *.tmpl file with optional YAML front matter declaring its name,
+description, parent template ("extends") and inputs. Templates that extend another
+only define the blocks they override, for example just the deploy job:
+
+  ---
+  name: gke
+  extends: standard
+  inputs:
+    - name: cluster
+      required: true
+  ---
+  {{ define "deploy-job" }}  deploy:
+    ...{{ .Inputs.cluster }}...
+  {{ end }}
+
+Select a template with "template" and supply inputs with "template_inputs" in the
+workflow configuration, or with --template on the workflow commands.
+
+Examples:
+  # List templates and presets
+  gcp-wif workflow templates list
+
+  # Show a template's inputs, blocks and source
+  gcp-wif workflow templates show standard --source`,
+}
+
+// workflowTemplatesListCmd represents the workflow templates list command
+var workflowTemplatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workflow templates and presets",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWorkflowTemplatesList(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
+// workflowTemplatesShowCmd represents the workflow templates show command
+var workflowTemplatesShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a workflow template's metadata, inputs and blocks",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWorkflowTemplatesShow(cmd, args); err != nil {
+			HandleError(err)
+			return
+		}
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(workflowCmd)
 
@@ -201,28 +404,61 @@ func init() {
 	workflowCmd.AddCommand(workflowPreviewCmd)
 	workflowCmd.AddCommand(workflowValidateCmd)
 	workflowCmd.AddCommand(workflowInfoCmd)
+	workflowCmd.AddCommand(workflowLintCmd)
+	workflowCmd.AddCommand(workflowPinCmd)
+	workflowCmd.AddCommand(workflowNotifyTestCmd)
+	workflowCmd.AddCommand(workflowTemplatesCmd)
+	workflowTemplatesCmd.AddCommand(workflowTemplatesListCmd)
+	workflowTemplatesCmd.AddCommand(workflowTemplatesShowCmd)
 
 	// Persistent flags for all workflow commands
 	workflowCmd.PersistentFlags().StringVarP(&workflowConfigFile, "config", "c", "", "Configuration file path")
 	workflowCmd.PersistentFlags().StringVar(&workflowOutputPath, "output-path", "", "Workflow output directory path (default: .github/workflows)")
 	workflowCmd.PersistentFlags().StringVar(&workflowFilename, "filename", "", "Workflow filename (default: from config)")
-	workflowCmd.PersistentFlags().StringVar(&workflowTemplate, "template", "", "Workflow template type (default, production, staging, development)")
+	workflowCmd.PersistentFlags().StringVar(&workflowTemplate, "template", "", "Workflow preset (default, production, staging, development), configured preset, or catalog template")
 
 	// Generate command flags
 	workflowGenerateCmd.Flags().BoolVar(&workflowOverwrite, "overwrite", false, "Overwrite existing workflow file")
 	workflowGenerateCmd.Flags().BoolVar(&workflowBackup, "backup", true, "Create backup of existing file")
 	workflowGenerateCmd.Flags().BoolVar(&workflowDryRun, "dry-run", false, "Show what would be done without writing files")
 	workflowGenerateCmd.Flags().BoolVar(&workflowValidate, "validate", true, "Validate workflow content before writing")
+	workflowGenerateCmd.Flags().BoolVar(&workflowMerge, "merge", false, "Keep edits made to the existing workflow file, merging them into the regenerated workflow")
+	workflowGenerateCmd.Flags().BoolVar(&workflowInteractive, "interactive", false, "With --merge, choose how to resolve each conflicting hunk instead of writing conflict markers")
+	workflowGenerateCmd.Flags().BoolVar(&workflowPinActions, "pin-actions", false, "Pin actions to the commit SHAs recorded in the action lock file")
+	workflowGenerateCmd.Flags().StringArrayVar(&workflowMatrix, "matrix", nil, "Matrix variable for the deploy job as key=value1,value2 (region and service fan deployments out; repeatable)")
+	workflowGenerateCmd.Flags().IntSliceVar(&workflowCanary, "canary", nil, "Roll out to Cloud Run as a canary, shifting traffic in these percentage steps (e.g. 10,50,100)")
+	workflowGenerateCmd.Flags().BoolVar(&workflowUseVars, "use-variables", false, "Read the project ID and identity from GitHub Actions variables (see 'gcp-wif github variables')")
 
 	// Preview command flags
 	workflowPreviewCmd.Flags().StringVar(&workflowFormat, "format", "summary", "Preview format (summary, full, json)")
 	workflowPreviewCmd.Flags().BoolVar(&workflowValidate, "validate", true, "Validate workflow content during preview")
+	workflowPreviewCmd.Flags().StringArrayVar(&workflowMatrix, "matrix", nil, "Matrix variable for the deploy job as key=value1,value2 (repeatable)")
+	workflowPreviewCmd.Flags().IntSliceVar(&workflowCanary, "canary", nil, "Canary traffic steps in percent (e.g. 10,50,100)")
+	workflowPreviewCmd.Flags().BoolVar(&workflowUseVars, "use-variables", false, "Read the project ID and identity from GitHub Actions variables")
 
 	// Validate command flags
 	workflowValidateCmd.Flags().BoolVar(&workflowPreview, "preview", false, "Include workflow content validation")
 
+	// Notify-test command flags
+	workflowNotifyTestCmd.Flags().StringVar(&workflowNotifyURL, "url", "", "Webhook URL to post the sample notification to")
+	workflowNotifyTestCmd.Flags().StringVar(&workflowNotifyType, "type", github.NotificationWebhook, "Notification type (slack, teams, discord, webhook)")
+	workflowNotifyTestCmd.Flags().StringVar(&workflowNotifyEvent, "event", github.NotificationEventSuccess, "Event of the sample notification (start, success, failure)")
+
 	// Info command flags
 	workflowInfoCmd.Flags().StringVar(&workflowFormat, "format", "summary", "Info format (summary, json)")
+
+	// Lint command flags
+	workflowLintCmd.Flags().StringVar(&workflowLintDir, "dir", ".github/workflows", "Directory containing workflow files")
+	workflowLintCmd.Flags().StringVar(&workflowLintFormat, "format", "summary", "Output format (summary, json)")
+
+	// Pin command flags
+	workflowPinCmd.Flags().BoolVar(&workflowPinUpdate, "update", false, "Re-resolve every action, refreshing existing lock entries")
+	workflowPinCmd.Flags().StringVar(&workflowPinLock, "lock-file", "", "Action lock file (default: from config or "+github.DefaultActionLockFile+")")
+	workflowPinCmd.Flags().StringSliceVar(&workflowPinFiles, "file", nil, "Existing workflow files to pin in place")
+
+	// Templates command flags
+	workflowTemplatesListCmd.Flags().StringVar(&workflowFormat, "format", "summary", "Output format (summary, json)")
+	workflowTemplatesShowCmd.Flags().BoolVar(&workflowShowSource, "source", false, "Print the template source")
 }
 
 // runWorkflowGenerate handles the workflow generate command
@@ -247,11 +483,28 @@ func runWorkflowGenerate(cmd *cobra.Command, args []string) error {
 		OverwriteExisting: workflowOverwrite,
 		DryRun:            workflowDryRun,
 		Validate:          workflowValidate,
+		Merge:             workflowMerge,
+	}
+	if workflowInteractive {
+		if !workflowMerge {
+			return errors.NewValidationError("--interactive requires --merge")
+		}
+		writeOptions.ResolveConflict = promptMergeConflict
 	}
 
 	// Generate and write workflow
 	fmt.Println("🔧 Generating GitHub Actions workflow...")
 	if err := cfg.Workflow.GenerateAndWriteWorkflowWithOptions(writeOptions); err != nil {
+		var conflicts *github.WorkflowMergeConflictError
+		if stderrors.As(err, &conflicts) {
+			fmt.Printf("⚠️  Workflow written with %d merge conflict(s): %s\n", len(conflicts.Conflicts), conflicts.Path)
+			for _, conflict := range conflicts.Conflicts {
+				fmt.Printf("   • line %d: %d edited line(s) vs %d generated line(s)\n", conflict.Line, len(conflict.Edited), len(conflict.Generated))
+			}
+			return errors.NewValidationError(conflicts.Error(),
+				"Resolve the conflict markers in the workflow file, then regenerate with --merge",
+				"Use --interactive to choose edited or generated lines for each conflict")
+		}
 		return fmt.Errorf("failed to generate workflow: %w", err)
 	}
 
@@ -363,6 +616,10 @@ func runWorkflowInfo(cmd *cobra.Command, args []string) error {
 	logger := logging.WithField("command", "workflow.info")
 	logger.Info("Starting workflow info")
 
+	if len(args) == 1 {
+		return runWorkflowFileInfo(args[0])
+	}
+
 	// Load configuration
 	cfg, err := loadWorkflowConfig()
 	if err != nil {
@@ -397,6 +654,312 @@ func runWorkflowInfo(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runWorkflowFileInfo shows the settings read back from an existing workflow file
+func runWorkflowFileInfo(path string) error {
+	imported, err := github.ImportWorkflowFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch workflowFormat {
+	case "summary":
+		fileInfo, err := imported.Config.GetWorkflowFileInfo()
+		if err != nil {
+			return fmt.Errorf("failed to get workflow file info: %w", err)
+		}
+		cfg := config.DefaultConfig()
+		cfg.ApplyImportedWorkflow(imported.Config)
+		displayWorkflowInfoSummary(cfg, fileInfo)
+		displayImportedWorkflowSettings(imported.Config)
+		if len(imported.Unrecognized) > 0 {
+			fmt.Println()
+			displayUnrecognizedWorkflow(imported.Unrecognized)
+		}
+	case "json":
+		if jsonBytes, err := json.MarshalIndent(imported, "", "  "); err == nil {
+			fmt.Println(string(jsonBytes))
+		} else {
+			return fmt.Errorf("failed to marshal workflow import to JSON: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid format: %s (use: summary, json)", workflowFormat)
+	}
+	return nil
+}
+
+// runWorkflowLint handles the workflow lint command
+func runWorkflowLint(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "workflow.lint")
+	logger.Info("Starting workflow lint", "dir", workflowLintDir)
+
+	// The configuration is optional; without it the mismatch checks are skipped
+	var expectations github.LintExpectations
+	cfg, err := loadWorkflowConfig()
+	if err != nil {
+		if workflowConfigFile != "" {
+			return err
+		}
+		logger.Debug("No configuration found, skipping provider checks", "error", err)
+	} else {
+		expectations = buildLintExpectations(cfg)
+	}
+
+	report, err := github.LintWorkflowDir(workflowLintDir, expectations)
+	if err != nil {
+		return err
+	}
+
+	if workflowLintFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal lint report: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		displayLintReport(report, cfg != nil)
+	}
+
+	if report.HasErrors() {
+		return errors.NewValidationError(
+			"Workflow lint found security problems",
+			"Fix the error-severity findings listed above",
+			"Run 'gcp-wif workflow generate' to produce a workflow that follows these practices")
+	}
+	return nil
+}
+
+// runWorkflowPin handles the workflow pin command
+func runWorkflowNotifyTest(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "workflow.notify-test")
+
+	if workflowNotifyURL == "" {
+		return errors.NewValidationError("--url is required", "Pass the webhook URL to test, for example --url https://hooks.slack.com/services/...")
+	}
+	hook := github.NotificationHook{Type: workflowNotifyType, Events: []string{workflowNotifyEvent}}
+	probe := github.WorkflowConfig{Advanced: github.AdvancedWorkflowConfig{NotificationHooks: []github.NotificationHook{hook}}}
+	if err := probe.ValidateNotificationHooks(); err != nil {
+		return err
+	}
+
+	payload, err := github.BuildNotificationPayload(hook, github.SampleNotificationMessage(workflowNotifyEvent, "sample-service"))
+	if err != nil {
+		return err
+	}
+	logger.Debug("Sending sample notification", "type", hook.Type, "event", workflowNotifyEvent)
+
+	fmt.Printf("📨 Sending %s %s notification...\n", hook.Type, workflowNotifyEvent)
+	fmt.Printf("   Payload: %s\n", payload)
+	status, err := github.SendNotification(nil, workflowNotifyURL, payload)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Notification delivered (HTTP %d)\n", status)
+	return nil
+}
+
+func runWorkflowPin(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "workflow.pin")
+
+	var cfg *config.Config
+	if len(workflowPinFiles) == 0 {
+		loaded, err := loadWorkflowConfig()
+		if err != nil {
+			return err
+		}
+		if err := applyWorkflowFlags(loaded); err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+
+	lockPath := workflowPinLock
+	if lockPath == "" {
+		lockPath = github.DefaultActionLockFile
+		if cfg != nil {
+			lockPath = cfg.Workflow.GetActionLockFile()
+		}
+	}
+
+	lock, err := github.LoadActionLock(lockPath)
+	if err != nil {
+		return err
+	}
+	previous := make(map[string]string, len(lock.Actions))
+	for key, locked := range lock.Actions {
+		previous[key] = locked.SHA
+	}
+
+	resolver := &github.RecordingResolver{
+		Lock:     lock,
+		Fallback: github.NewGitHubActionResolver(),
+		Refresh:  workflowPinUpdate,
+	}
+
+	var refs []github.ActionReference
+	if cfg != nil {
+		// Collect references from the unpinned template output
+		workflow := cfg.Workflow
+		workflow.Security.PinActions = false
+		content, err := workflow.GenerateWorkflow()
+		if err != nil {
+			return err
+		}
+		refs = github.UniqueActionReferences(github.ExtractActionReferences(content))
+		for _, ref := range refs {
+			if _, err := resolver.Resolve(ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, file := range workflowPinFiles {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return errors.WrapError(err, errors.ErrorTypeFileSystem, "WORKFLOW_READ_FAILED",
+				fmt.Sprintf("Failed to read workflow file: %s", file))
+		}
+		pinnedContent, pinned, err := github.PinWorkflowActions(string(content), resolver)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, pinned...)
+		if len(pinned) > 0 {
+			if err := os.WriteFile(file, []byte(pinnedContent), 0644); err != nil {
+				return errors.WrapError(err, errors.ErrorTypeFileSystem, "WORKFLOW_WRITE_FAILED",
+					fmt.Sprintf("Failed to write workflow file: %s", file))
+			}
+			fmt.Printf("📌 Pinned %d action reference(s) in %s\n", len(pinned), file)
+		}
+	}
+	refs = github.UniqueActionReferences(refs)
+
+	// Refresh entries recorded by earlier runs that are not referenced here
+	if workflowPinUpdate {
+		for key := range previous {
+			at := strings.LastIndex(key, "@")
+			if at < 0 {
+				continue
+			}
+			if _, err := resolver.Resolve(github.ActionReference{Action: key[:at], Ref: key[at+1:]}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := lock.Save(lockPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("🔒 Action lock: %s\n\n", lockPath)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tREF\tSHA\tSTATUS")
+	fmt.Fprintln(w, "------\t---\t---\t------")
+	changed := 0
+	for _, ref := range refs {
+		sha := lock.Actions[ref.Key()].SHA
+		status := "unchanged"
+		switch old, ok := previous[ref.Key()]; {
+		case !ok:
+			status, changed = "added", changed+1
+		case old != sha:
+			status, changed = "updated", changed+1
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", ref.Action, ref.Ref, sha[:12], status)
+	}
+	w.Flush()
+
+	fmt.Printf("\n✅ %d action(s) locked, %d added or updated\n", len(refs), changed)
+	if cfg != nil && !cfg.Workflow.Security.PinActions {
+		fmt.Println("💡 Render pinned actions with 'gcp-wif workflow generate --pin-actions' or set pin_actions in the workflow security configuration")
+	}
+
+	logger.Info("Actions pinned", "lock_file", lockPath, "actions", len(refs), "changed", changed)
+	return nil
+}
+
+// buildLintExpectations collects the provider and service account a workflow should use
+func buildLintExpectations(cfg *config.Config) github.LintExpectations {
+	expectations := github.LintExpectations{
+		PoolID:      cfg.WorkloadIdentity.PoolID,
+		ProviderIDs: []string{cfg.WorkloadIdentity.ProviderID},
+	}
+	projects := []string{cfg.Project.ID, cfg.Project.Number, cfg.Workflow.ProjectNumber}
+	if parts := strings.Split(cfg.Workflow.WorkloadIdentityProvider, "/"); len(parts) > 1 && parts[0] == "projects" {
+		projects = append(projects, parts[1])
+	}
+	for _, project := range projects {
+		if project != "" && !envContains(expectations.Projects, project) {
+			expectations.Projects = append(expectations.Projects, project)
+		}
+	}
+	if cfg.ServiceAccount.Name != "" && cfg.Project.ID != "" {
+		expectations.ServiceAccounts = append(expectations.ServiceAccounts, cfg.GetServiceAccountEmail())
+	}
+	if email := cfg.Workflow.ServiceAccountEmail; email != "" && !envContains(expectations.ServiceAccounts, email) {
+		expectations.ServiceAccounts = append(expectations.ServiceAccounts, email)
+	}
+	// Terraform workflows also plan with a read-only identity
+	if cfg.Workflow.IsTerraform() {
+		expectations.ProviderIDs = append(expectations.ProviderIDs, cfg.GetTerraformPlanProviderID())
+		expectations.ServiceAccounts = append(expectations.ServiceAccounts, cfg.GetTerraformPlanServiceAccountEmail())
+	}
+	// Pipeline environments deploy with their own identities
+	for _, name := range pipelineEnvironments(cfg) {
+		env := cfg.Environments[name]
+		expectations.ProviderIDs = append(expectations.ProviderIDs, cfg.GetEnvironmentProviderID(&env))
+		expectations.ServiceAccounts = append(expectations.ServiceAccounts, cfg.GetEnvironmentServiceAccountEmail(&env))
+	}
+	return expectations
+}
+
+// displayLintReport prints lint findings grouped by file
+func displayLintReport(report *github.LintReport, configChecked bool) {
+	fmt.Printf("🔍 Linted %d workflow file(s), %d google-github-actions/auth step(s)\n", len(report.Files), report.AuthUses)
+	if !configChecked {
+		fmt.Println("💡 No configuration found; provider and service account checks were skipped")
+	}
+
+	if len(report.Findings) == 0 {
+		fmt.Println("\n✅ No security problems found")
+		return
+	}
+
+	counts := map[string]int{}
+	currentFile := ""
+	for _, finding := range report.Findings {
+		if finding.File != currentFile {
+			currentFile = finding.File
+			fmt.Printf("\n📄 %s\n", currentFile)
+		}
+		counts[finding.Severity]++
+
+		icon := "❌"
+		switch finding.Severity {
+		case github.LintSeverityWarning:
+			icon = "⚠️ "
+		case github.LintSeverityInfo:
+			icon = "💡"
+		}
+		location := fmt.Sprintf("%d", finding.Line)
+		if finding.Column > 0 {
+			location = fmt.Sprintf("%d:%d", finding.Line, finding.Column)
+		}
+		fmt.Printf("   %s %s [%s] %s\n", icon, location, finding.Rule, finding.Message)
+		if finding.Job != "" {
+			context := "job " + finding.Job
+			if finding.Step != "" {
+				context += ", step \"" + finding.Step + "\""
+			}
+			fmt.Printf("      %s\n", context)
+		}
+		if finding.Remediation != "" {
+			fmt.Printf("      💡 %s\n", finding.Remediation)
+		}
+	}
+
+	fmt.Printf("\n📊 %d error(s), %d warning(s)\n", counts[github.LintSeverityError], counts[github.LintSeverityWarning])
+}
+
 // loadWorkflowConfig loads workflow configuration from file or creates default
 func loadWorkflowConfig() (*config.Config, error) {
 	var cfg *config.Config
@@ -418,6 +981,34 @@ func loadWorkflowConfig() (*config.Config, error) {
 	return cfg, nil
 }
 
+// promptMergeConflict asks how to resolve a hunk changed both in the workflow file and by
+// the generator
+func promptMergeConflict(conflict github.MergeConflict) github.ConflictResolution {
+	fmt.Printf("\n⚠️  Conflict at line %d\n", conflict.Line)
+	fmt.Println("--- edited")
+	for _, line := range conflict.Edited {
+		fmt.Printf("  %s\n", strings.TrimSuffix(line, "\n"))
+	}
+	fmt.Println("+++ generated")
+	for _, line := range conflict.Generated {
+		fmt.Printf("  %s\n", strings.TrimSuffix(line, "\n"))
+	}
+
+	for {
+		fmt.Printf("❓ Keep [e]dited, use [g]enerated or write [m]arkers? (e/g/m): ")
+		var response string
+		fmt.Scanln(&response)
+		switch strings.ToLower(response) {
+		case "e", "edited":
+			return github.ResolveKeepEdited
+		case "g", "generated":
+			return github.ResolveUseGenerated
+		case "m", "markers", "":
+			return github.ResolveWithMarkers
+		}
+	}
+}
+
 // applyWorkflowFlags applies command-line flags to workflow configuration
 func applyWorkflowFlags(cfg *config.Config) error {
 	// Apply output path override
@@ -446,13 +1037,58 @@ func applyWorkflowFlags(cfg *config.Config) error {
 			defaultConfig := github.DefaultWorkflowConfig()
 			cfg.Workflow = *defaultConfig
 		default:
-			return fmt.Errorf("invalid template: %s (use: default, production, staging, development)", workflowTemplate)
+			// Other names select a preset from the configuration or a catalog template
+			if _, ok := cfg.Templates.Presets[workflowTemplate]; ok {
+				if err := cfg.ApplyPreset(workflowTemplate); err != nil {
+					return err
+				}
+				break
+			}
+			catalog, err := github.LoadTemplateCatalog(cfg.Workflow.GetTemplateSearchPath())
+			if err != nil {
+				return err
+			}
+			if _, err := catalog.Get(workflowTemplate); err != nil {
+				return err
+			}
+			cfg.Workflow.Template = workflowTemplate
 		}
 	}
 
+	if workflowPinActions {
+		cfg.Workflow.Security.PinActions = true
+	}
+
+	for _, variable := range workflowMatrix {
+		name, values, ok := strings.Cut(variable, "=")
+		if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(values) == "" {
+			return errors.NewValidationError(fmt.Sprintf("Invalid --matrix value '%s'", variable),
+				"Use key=value1,value2, for example --matrix region=us-central1,europe-west1")
+		}
+		var items []string
+		for _, value := range strings.Split(values, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				items = append(items, value)
+			}
+		}
+		cfg.Workflow.SetMatrixVariable(strings.TrimSpace(name), items)
+	}
+
+	if len(workflowCanary) > 0 {
+		cfg.Workflow.Advanced.Canary.Enabled = true
+		cfg.Workflow.Advanced.Canary.Steps = workflowCanary
+	}
+
+	if workflowUseVars {
+		cfg.Workflow.UseActionsVariables = true
+	}
+
 	// Ensure required fields are populated
 	cfg.SetDefaults()
 
+	// Deploy each configured environment in its own job with its own identity
+	cfg.ApplyEnvironmentPipeline()
+
 	return nil
 }
 
@@ -516,7 +1152,281 @@ func displayWorkflowInfoSummary(cfg *config.Config, fileInfo *github.WorkflowFil
 		fmt.Printf("🔍 Health Checks: %d configured\n", len(cfg.Workflow.Advanced.HealthChecks))
 	}
 
+	if canary := cfg.Workflow.Advanced.Canary; canary.Enabled {
+		fmt.Printf("🐤 Canary: %v%% traffic steps every %s (tag %s)\n", canary.GetSteps(), canary.GetInterval(), canary.GetTag())
+	}
+
 	fmt.Printf("🏗️  Project: %s\n", cfg.Workflow.ProjectID)
 	fmt.Printf("🌍 Region: %s\n", cfg.Workflow.Region)
 	fmt.Printf("☁️  Service: %s\n", cfg.Workflow.ServiceName)
 }
+
+// displayImportedWorkflowSettings displays the settings read back from a workflow file
+// that the configuration summary does not show
+func displayImportedWorkflowSettings(workflow *github.WorkflowConfig) {
+	if workflow.IsTerraform() {
+		fmt.Printf("🎯 Target: terraform\n")
+	} else if target, err := workflow.GetDeploymentTarget(); err == nil {
+		fmt.Printf("🎯 Target: %s\n", target.Name())
+	}
+	if workflow.WorkloadIdentityProvider != "" {
+		fmt.Printf("🔑 Provider: %s\n", workflow.WorkloadIdentityProvider)
+	}
+	if workflow.ServiceAccountEmail != "" {
+		fmt.Printf("👤 Service Account: %s\n", workflow.ServiceAccountEmail)
+	}
+	if workflow.UseActionsVariables {
+		fmt.Printf("🔑 Identity: read from GitHub Actions variables\n")
+	}
+	if len(workflow.EnvVars) > 0 || len(workflow.Secrets) > 0 {
+		fmt.Printf("📦 Env: %d variable(s), %d secret(s)\n", len(workflow.EnvVars), len(workflow.Secrets))
+	}
+	if len(workflow.BuildArgs) > 0 || len(workflow.Platforms) > 0 {
+		fmt.Printf("🐳 Build: %d build arg(s), platforms %s\n", len(workflow.BuildArgs), strings.Join(workflow.Platforms, ", "))
+	}
+
+	var gates []string
+	if workflow.Security.BlockForkedRepos {
+		gates = append(gates, "fork blocking")
+	}
+	if workflow.Security.RequireSignedCommits {
+		gates = append(gates, "signed commits")
+	}
+	if len(workflow.Security.AllowedActors) > 0 {
+		gates = append(gates, "allowed actors")
+	}
+	if workflow.Security.RequireApproval {
+		gates = append(gates, "approval")
+	}
+	if workflow.Security.GenerateSBOM {
+		gates = append(gates, "SBOM")
+	}
+	if workflow.Security.SignImages {
+		gates = append(gates, "cosign signing")
+	}
+	if workflow.Security.SLSAProvenance {
+		gates = append(gates, "SLSA provenance")
+	}
+	if workflow.Security.BinaryAuthorization.Enabled {
+		gates = append(gates, "Binary Authorization")
+	}
+	if len(gates) > 0 {
+		fmt.Printf("🛡️  Security: %s\n", strings.Join(gates, ", "))
+	}
+}
+
+// displayUnrecognizedWorkflow lists the workflow constructs the configuration cannot
+// represent, which regenerating the workflow drops
+func displayUnrecognizedWorkflow(issues []github.WorkflowIssue) {
+	fmt.Printf("⚠️  Not imported (%d), regenerating the workflow drops:\n", len(issues))
+	for _, issue := range issues {
+		location := issue.Path
+		if issue.Line > 0 {
+			location = fmt.Sprintf("line %d, %s", issue.Line, issue.Path)
+		}
+		fmt.Printf("   • %s: %s\n", location, issue.Message)
+	}
+}
+
+// builtinWorkflowPresets describes the presets accepted by --template
+var builtinWorkflowPresets = [][2]string{
+	{"default", "Push to main, manual dispatch, Cloud Run defaults"},
+	{"production", "Approval gates, signed commits, restricted branches"},
+	{"staging", "Deploys from main, develop and staging branches"},
+	{"development", "Relaxed security for feature branches"},
+}
+
+// loadTemplateCatalog loads the template catalog using the configured search path when a
+// configuration is available
+func loadTemplateCatalog() (*github.TemplateCatalog, *config.Config, error) {
+	logger := logging.WithField("function", "loadTemplateCatalog")
+
+	searchPath := github.DefaultTemplateSearchPath()
+	cfg, err := loadWorkflowConfig()
+	if err != nil {
+		if workflowConfigFile != "" {
+			return nil, nil, err
+		}
+		logger.Debug("No configuration found, using the default template search path", "error", err)
+		cfg = nil
+	} else {
+		searchPath = cfg.Workflow.GetTemplateSearchPath()
+	}
+
+	catalog, err := github.LoadTemplateCatalog(searchPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return catalog, cfg, nil
+}
+
+// runWorkflowTemplatesList handles the workflow templates list command
+func runWorkflowTemplatesList(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "workflow.templates.list")
+
+	catalog, cfg, err := loadTemplateCatalog()
+	if err != nil {
+		return err
+	}
+	templates := catalog.List()
+	logger.Debug("Loaded template catalog", "templates", len(templates))
+
+	if workflowFormat == "json" {
+		output := map[string]interface{}{
+			"search_path": catalog.SearchPath,
+			"templates":   templates,
+		}
+		if cfg != nil && len(cfg.Templates.Presets) > 0 {
+			output["presets"] = cfg.Templates.Presets
+		}
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal template catalog: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println("📚 Workflow Templates")
+	fmt.Println("====================")
+	fmt.Println("Search path:")
+	for i, dir := range catalog.SearchPath {
+		fmt.Printf("   %d. %s\n", i+1, dir)
+	}
+	fmt.Printf("   %d. (%s)\n", len(catalog.SearchPath)+1, github.TemplateSourceBuiltin)
+	fmt.Println()
+
+	selected := github.DefaultTemplateName
+	if cfg != nil {
+		selected = cfg.Workflow.GetTemplateName()
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tEXTENDS\tINPUTS\tDESCRIPTION")
+	fmt.Fprintln(w, "----\t------\t-------\t------\t-----------")
+	for _, tmpl := range templates {
+		name := tmpl.Name
+		if name == selected {
+			name += " *"
+		}
+		extends := tmpl.Extends
+		if extends == "" {
+			extends = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", name, tmpl.Source, extends, len(tmpl.Inputs), tmpl.Description)
+	}
+	w.Flush()
+	fmt.Println("\n* selected by the current configuration")
+
+	for _, tmpl := range templates {
+		for _, hidden := range catalog.Shadowed(tmpl.Name) {
+			fmt.Printf("⚠️  %s from %s is shadowed by %s\n", tmpl.Name, hidden.Source, tmpl.Source)
+		}
+	}
+
+	fmt.Println("\n🎛️  Presets (--template)")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tTYPE\tDESCRIPTION")
+	fmt.Fprintln(w, "----\t------\t----\t-----------")
+	for _, preset := range builtinWorkflowPresets {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", preset[0], github.TemplateSourceBuiltin, "-", preset[1])
+	}
+	if cfg != nil {
+		names := make([]string, 0, len(cfg.Templates.Presets))
+		for name := range cfg.Templates.Presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			preset := cfg.Templates.Presets[name]
+			presetType := preset.Type
+			if presetType == "" {
+				presetType = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, "config", presetType, preset.Description)
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+// runWorkflowTemplatesShow handles the workflow templates show command
+func runWorkflowTemplatesShow(cmd *cobra.Command, args []string) error {
+	logger := logging.WithField("command", "workflow.templates.show")
+	logger.Debug("Showing template", "name", args[0])
+
+	catalog, _, err := loadTemplateCatalog()
+	if err != nil {
+		return err
+	}
+	chain, err := catalog.Resolve(args[0])
+	if err != nil {
+		return err
+	}
+	tmpl := chain[len(chain)-1]
+
+	fmt.Printf("📄 Template: %s\n", tmpl.Name)
+	if tmpl.Description != "" {
+		fmt.Printf("   %s\n", tmpl.Description)
+	}
+	if tmpl.Path != "" {
+		fmt.Printf("📍 Path: %s\n", tmpl.Path)
+	} else {
+		fmt.Printf("📍 Source: %s\n", tmpl.Source)
+	}
+
+	names := make([]string, len(chain))
+	for i, t := range chain {
+		names[i] = t.Name
+	}
+	fmt.Printf("🧬 Inheritance: %s\n", strings.Join(names, " → "))
+
+	// Later templates override the blocks and inputs of earlier ones
+	blockOwner := make(map[string]string)
+	var blocks []string
+	inputs := make(map[string]github.TemplateInput)
+	var inputNames []string
+	for _, t := range chain {
+		for _, block := range t.Blocks() {
+			if _, ok := blockOwner[block]; !ok {
+				blocks = append(blocks, block)
+			}
+			blockOwner[block] = t.Name
+		}
+		for _, input := range t.Inputs {
+			if _, ok := inputs[input.Name]; !ok {
+				inputNames = append(inputNames, input.Name)
+			}
+			inputs[input.Name] = input
+		}
+	}
+
+	fmt.Println("\n🧱 Blocks:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "BLOCK\tDEFINED BY")
+	fmt.Fprintln(w, "-----\t----------")
+	for _, block := range blocks {
+		fmt.Fprintf(w, "%s\t%s\n", block, blockOwner[block])
+	}
+	w.Flush()
+
+	fmt.Println("\n🔧 Inputs:")
+	if len(inputNames) == 0 {
+		fmt.Println("   (none)")
+	} else {
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tREQUIRED\tDEFAULT\tDESCRIPTION")
+		fmt.Fprintln(w, "----\t--------\t-------\t-----------")
+		for _, name := range inputNames {
+			input := inputs[name]
+			fmt.Fprintf(w, "%s\t%t\t%s\t%s\n", input.Name, input.Required, input.Default, input.Description)
+		}
+		w.Flush()
+	}
+
+	if workflowShowSource {
+		fmt.Println("\n📝 Source:")
+		fmt.Println(tmpl.Body)
+	}
+	return nil
+}
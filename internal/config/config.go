@@ -1,4 +1,4 @@
-// Package config handles configuration structure and JSON file operations
+// Package config handles configuration structure and JSON, YAML and TOML file operations
 // for the GCP Workload Identity Federation CLI tool.
 package config
 
@@ -9,10 +9,12 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/gcp"
 	"github.com/Fordjour12/gcp-wif/internal/github"
 	"github.com/Fordjour12/gcp-wif/internal/logging"
 )
@@ -62,7 +64,9 @@ type ProjectConfig struct {
 type RepositoryConfig struct {
 	Owner       string   `json:"owner" validate:"required"`
 	Name        string   `json:"name" validate:"required"`
-	Ref         string   `json:"ref,omitempty"` // Optional branch/tag filter
+	ID          int64    `json:"id,omitempty"`       // Immutable repository ID conditions are pinned to
+	OwnerID     int64    `json:"owner_id,omitempty"` // Immutable owner ID conditions are pinned to
+	Ref         string   `json:"ref,omitempty"`      // Optional branch/tag filter
 	Branches    []string `json:"branches,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
 	PullRequest bool     `json:"pull_request,omitempty"`
@@ -85,6 +89,12 @@ type WorkloadIdentityConfig struct {
 	ProviderID       string            `json:"provider_id" validate:"required"`
 	AttributeMapping map[string]string `json:"attribute_mapping,omitempty"`
 	Conditions       []string          `json:"conditions,omitempty"`
+	AllowedAudiences []string          `json:"allowed_audiences,omitempty"`
+	// BindingExpiration is an RFC3339 time after which service account bindings stop granting access
+	BindingExpiration string `json:"binding_expiration,omitempty"`
+	// SubjectClaimKeys is the repository's customized GitHub OIDC subject template, empty
+	// for GitHub's default repo:OWNER/REPO:CONTEXT subject
+	SubjectClaimKeys []string `json:"subject_claim_keys,omitempty"`
 }
 
 // CloudRunConfig holds Cloud Run service configuration
@@ -142,14 +152,20 @@ type ValidationInfo struct {
 
 // Regular expressions for validation
 var (
-	gcpProjectIDRegex     = regexp.MustCompile(`^[a-z][-a-z0-9]{4,28}[a-z0-9]$`)
-	serviceAccountRegex   = regexp.MustCompile(`^[a-z][-a-z0-9]{4,28}[a-z0-9]$`)
-	githubOwnerRegex      = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
-	githubRepoRegex       = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
-	workloadIdentityRegex = regexp.MustCompile(`^[a-z][-a-z0-9]{2,30}[a-z0-9]$`)
-	cloudRunServiceRegex  = regexp.MustCompile(`^[a-z][-a-z0-9]{0,61}[a-z0-9]$`)
+	gcpProjectIDRegex        = regexp.MustCompile(`^[a-z][-a-z0-9]{4,28}[a-z0-9]$`)
+	serviceAccountRegex      = regexp.MustCompile(`^[a-z][-a-z0-9]{4,28}[a-z0-9]$`)
+	githubOwnerRegex         = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+	githubRepoRegex          = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	workloadIdentityRegex    = regexp.MustCompile(`^[a-z][-a-z0-9]{2,30}[a-z0-9]$`)
+	cloudRunServiceRegex     = regexp.MustCompile(`^[a-z][-a-z0-9]{0,61}[a-z0-9]$`)
+	providerResourceRegex    = regexp.MustCompile(`^projects/([^/]+)/locations/global/workloadIdentityPools/([^/]+)/providers/([^/]+)$`)
+	serviceAccountEmailRegex = regexp.MustCompile(`^([^@]+)@([^.]+)\.iam\.gserviceaccount\.com$`)
 )
 
+// TerraformPlanSuffix is appended to the service account name and provider ID to name the
+// read-only identity terraform workflows plan with
+const TerraformPlanSuffix = "-plan"
+
 // DefaultRoles returns the default IAM roles for the service account
 func DefaultRoles() []string {
 	return []string{
@@ -221,7 +237,8 @@ func NewConfig(projectID, repoOwner, repoName string) *Config {
 	return config
 }
 
-// LoadFromFile loads configuration from a JSON file with validation
+// LoadFromFile loads configuration from a JSON, YAML or TOML file, by its extension, with
+// validation
 func LoadFromFile(filePath string) (*Config, error) {
 	logger := logging.WithField("config_file", filePath)
 	logger.Debug("Loading configuration from file")
@@ -239,15 +256,18 @@ func LoadFromFile(filePath string) (*Config, error) {
 			fmt.Sprintf("Failed to read configuration file: %s", filePath))
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	format := FormatFromPath(filePath)
+	parsed, err := UnmarshalFormat(data, format)
+	if err != nil {
+		name := strings.ToUpper(string(format))
 		return nil, errors.NewConfigurationError(
-			fmt.Sprintf("Invalid JSON in configuration file: %s", filePath),
-			"Check the JSON syntax in your configuration file",
+			fmt.Sprintf("Invalid %s in configuration file: %s: %v", name, filePath, err),
+			fmt.Sprintf("Check the %s syntax in your configuration file", name),
 			"Ensure all brackets and quotes are properly closed",
-			"Use a JSON validator to check your configuration",
+			fmt.Sprintf("Use a %s validator to check your configuration", name),
 			"Run with --interactive flag to recreate the configuration")
 	}
+	config := *parsed
 
 	logger.Info("Configuration loaded successfully", "version", config.Version)
 
@@ -272,7 +292,8 @@ func LoadFromFile(filePath string) (*Config, error) {
 	return &config, nil
 }
 
-// SaveToFile saves configuration to a JSON file
+// SaveToFile saves configuration to a JSON, YAML or TOML file, by its extension. Comments
+// in an existing YAML file are kept on the keys they describe.
 func (c *Config) SaveToFile(filePath string) error {
 	logger := logging.WithField("config_file", filePath)
 	logger.Debug("Saving configuration to file")
@@ -295,10 +316,15 @@ func (c *Config) SaveToFile(filePath string) error {
 			"Failed to create directory for configuration file")
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	format := FormatFromPath(filePath)
+	var previous []byte
+	if format == FormatYAML {
+		previous, _ = os.ReadFile(filePath)
+	}
+	data, err := c.marshalFormat(format, previous)
 	if err != nil {
 		return errors.WrapError(err, errors.ErrorTypeInternal, "CONFIG_MARSHAL_FAILED",
-			"Failed to serialize configuration to JSON")
+			fmt.Sprintf("Failed to serialize configuration to %s", strings.ToUpper(string(format))))
 	}
 
 	if err := os.WriteFile(filePath, data, 0644); err != nil {
@@ -338,17 +364,19 @@ func (c *Config) SetDefaults() {
 	// Set default workload identity attribute mapping
 	if c.WorkloadIdentity.AttributeMapping == nil {
 		c.WorkloadIdentity.AttributeMapping = map[string]string{
-			"google.subject":             "assertion.sub",
-			"attribute.actor":            "assertion.actor",
-			"attribute.repository":       "assertion.repository",
-			"attribute.repository_owner": "assertion.repository_owner",
+			"google.subject":                "assertion.sub",
+			"attribute.actor":               "assertion.actor",
+			"attribute.repository":          "assertion.repository",
+			"attribute.repository_owner":    "assertion.repository_owner",
+			"attribute.repository_id":       "assertion.repository_id",
+			"attribute.repository_owner_id": "assertion.repository_owner_id",
 		}
 	}
 
 	// Set default repository conditions
 	if len(c.WorkloadIdentity.Conditions) == 0 {
 		c.WorkloadIdentity.Conditions = []string{
-			fmt.Sprintf("assertion.repository=='%s'", c.GetRepoFullName()),
+			gcp.RepositoryCondition(c.GetRepoFullName(), c.Repository.ID),
 		}
 	}
 
@@ -392,6 +420,10 @@ func (c *Config) SetDefaults() {
 	c.Workflow.WorkloadIdentityProvider = c.GetWorkloadIdentityProviderName()
 	c.Workflow.Repository = c.GetRepoFullName()
 	c.Workflow.Region = c.Project.Region
+	if c.Workflow.IsTerraform() {
+		c.Workflow.Terraform.PlanServiceAccountEmail = c.GetTerraformPlanServiceAccountEmail()
+		c.Workflow.Terraform.PlanWorkloadIdentityProvider = c.GetTerraformPlanProviderName()
+	}
 	if c.CloudRun.ServiceName != "" {
 		c.Workflow.ServiceName = c.CloudRun.ServiceName
 		c.Workflow.Registry = c.CloudRun.Registry
@@ -541,6 +573,13 @@ func (c *Config) validateRepository(result *ValidationResult) {
 			})
 		}
 	}
+
+	if c.Repository.Owner != "" && c.Repository.Name != "" && (c.Repository.ID == 0 || c.Repository.OwnerID == 0) {
+		result.Info = append(result.Info, ValidationInfo{
+			Field:   "repository.id",
+			Message: "Conditions match the repository by name; pin them to its immutable IDs with 'gcp-wif config migrate'",
+		})
+	}
 }
 
 // validateServiceAccount validates service account configuration
@@ -595,6 +634,78 @@ func (c *Config) validateWorkloadIdentity(result *ValidationResult) {
 			Code:    "INVALID_FORMAT",
 		})
 	}
+
+	if c.WorkloadIdentity.BindingExpiration != "" {
+		expiresAt, err := time.Parse(time.RFC3339, c.WorkloadIdentity.BindingExpiration)
+		if err != nil {
+			result.Errors = append(result.Errors, ValidationError{
+				Field: "workload_identity.binding_expiration", Value: c.WorkloadIdentity.BindingExpiration,
+				Message: "Binding expiration must be an RFC3339 timestamp (e.g. 2025-12-31T00:00:00Z)",
+				Code:    "INVALID_FORMAT",
+			})
+		} else if !expiresAt.After(time.Now()) {
+			result.Warnings = append(result.Warnings, ValidationWarning{
+				Field:   "workload_identity.binding_expiration",
+				Message: "Binding expiration is in the past; bindings will not grant access",
+			})
+		}
+	}
+
+	if err := gcp.ValidateSubjectClaimKeys(c.WorkloadIdentity.SubjectClaimKeys); err != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Field: "workload_identity.subject_claim_keys", Value: strings.Join(c.WorkloadIdentity.SubjectClaimKeys, ","),
+			Message: err.Error(), Code: "INVALID_VALUE",
+		})
+		return
+	}
+
+	// Conditions and mappings comparing the subject only match claims the template includes
+	claimsMapping := c.GetClaimsMapping()
+	expressions := append([]string{}, c.WorkloadIdentity.Conditions...)
+	for _, expression := range c.WorkloadIdentity.AttributeMapping {
+		expressions = append(expressions, expression)
+	}
+	if err := claimsMapping.ValidateSubjectConditions(expressions...); err != nil {
+		result.Errors = append(result.Errors, ValidationError{
+			Field: "workload_identity.conditions", Value: strings.Join(c.WorkloadIdentity.Conditions, " && "),
+			Message: err.Error(), Code: "SUBJECT_CLAIM_MISMATCH",
+		})
+	}
+	if claimsMapping.SubjectMayExceedLimit() {
+		result.Warnings = append(result.Warnings, ValidationWarning{
+			Field: "workload_identity.subject_claim_keys",
+			Message: fmt.Sprintf("The OIDC subject includes long claims and may exceed the %d character google.subject limit",
+				gcp.MaxSubjectLength),
+		})
+	}
+}
+
+// PinRepositoryIDs records the repository and owner IDs and pins the conditions and
+// attribute mapping to them. It returns whether the configuration changed.
+func (c *Config) PinRepositoryIDs(repositoryID, ownerID int64) bool {
+	changed := c.Repository.ID != repositoryID || c.Repository.OwnerID != ownerID
+	c.Repository.ID, c.Repository.OwnerID = repositoryID, ownerID
+	if repositoryID == 0 && ownerID == 0 {
+		return changed
+	}
+
+	for i, condition := range c.WorkloadIdentity.Conditions {
+		pinned := gcp.PinRepositoryCondition(condition, c.GetRepoFullName(), repositoryID, ownerID)
+		changed = changed || pinned != condition
+		c.WorkloadIdentity.Conditions[i] = pinned
+	}
+	if c.WorkloadIdentity.AttributeMapping != nil && gcp.PinAttributeMapping(c.WorkloadIdentity.AttributeMapping) {
+		changed = true
+	}
+	return changed
+}
+
+// GetClaimsMapping returns the GitHub claims mapping of the provider, with the repository's
+// OIDC subject template
+func (c *Config) GetClaimsMapping() *gcp.GitHubClaimsMapping {
+	claimsMapping := gcp.GetDefaultGitHubClaimsMapping()
+	claimsMapping.SubjectClaimKeys = c.WorkloadIdentity.SubjectClaimKeys
+	return claimsMapping
 }
 
 // validateCloudRun validates Cloud Run configuration
@@ -635,6 +746,46 @@ func (c *Config) validateWorkflow(result *ValidationResult) {
 		})
 	}
 
+	// The derived plan identity of terraform workflows must be valid resource IDs too
+	if c.Workflow.IsTerraform() {
+		if name := c.GetTerraformPlanServiceAccountName(); !serviceAccountRegex.MatchString(name) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field: "service_account.name", Value: name,
+				Message: fmt.Sprintf("Terraform plan service account name '%s' must be 6-30 characters; shorten the service account name", name),
+				Code:    "INVALID_FORMAT",
+			})
+		}
+		if id := c.GetTerraformPlanProviderID(); !workloadIdentityRegex.MatchString(id) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field: "workload_identity.provider_id", Value: id,
+				Message: fmt.Sprintf("Terraform plan provider ID '%s' must be 3-32 characters; shorten the provider ID", id),
+				Code:    "INVALID_FORMAT",
+			})
+		}
+	}
+
+	// Each environment deployed by the pipeline has its own derived identity
+	for _, name := range c.GetPipelineEnvironments() {
+		if c.Workflow.IsTerraform() {
+			break
+		}
+		env := c.Environments[name]
+		if account := c.GetEnvironmentServiceAccountName(&env); !serviceAccountRegex.MatchString(account) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field: fmt.Sprintf("environments.%s.resources.service_account.name_suffix", name), Value: account,
+				Message: fmt.Sprintf("Service account name '%s' of environment '%s' is invalid; use a lowercase name suffix", account, name),
+				Code:    "INVALID_FORMAT",
+			})
+		}
+		if id := c.GetEnvironmentProviderID(&env); !workloadIdentityRegex.MatchString(id) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field: fmt.Sprintf("environments.%s.resources.workload_identity.provider_suffix", name), Value: id,
+				Message: fmt.Sprintf("Provider ID '%s' of environment '%s' is invalid; use a lowercase provider suffix", id, name),
+				Code:    "INVALID_FORMAT",
+			})
+		}
+	}
+
 	// Delegate to the comprehensive validation within github.WorkflowConfig
 	if err := c.Workflow.ValidateConfig(); err != nil {
 		// Attempt to cast to errors.CustomError to extract details
@@ -690,6 +841,28 @@ func (c *Config) GetWorkloadIdentityProviderName() string {
 	return fmt.Sprintf("%s/providers/%s", c.GetWorkloadIdentityPoolName(), c.WorkloadIdentity.ProviderID)
 }
 
+// GetTerraformPlanServiceAccountName returns the name of the read-only service account
+// terraform workflows plan with
+func (c *Config) GetTerraformPlanServiceAccountName() string {
+	return c.ServiceAccount.Name + TerraformPlanSuffix
+}
+
+// GetTerraformPlanServiceAccountEmail returns the email of the terraform plan service account
+func (c *Config) GetTerraformPlanServiceAccountEmail() string {
+	return fmt.Sprintf("%s@%s.iam.gserviceaccount.com", c.GetTerraformPlanServiceAccountName(), c.Project.ID)
+}
+
+// GetTerraformPlanProviderID returns the ID of the provider that accepts pull request
+// tokens for terraform plans
+func (c *Config) GetTerraformPlanProviderID() string {
+	return c.WorkloadIdentity.ProviderID + TerraformPlanSuffix
+}
+
+// GetTerraformPlanProviderName returns the full name of the terraform plan provider
+func (c *Config) GetTerraformPlanProviderName() string {
+	return fmt.Sprintf("%s/providers/%s", c.GetWorkloadIdentityPoolName(), c.GetTerraformPlanProviderID())
+}
+
 // GetCloudRunURL returns the Cloud Run service URL
 func (c *Config) GetCloudRunURL() string {
 	if c.CloudRun.ServiceName == "" || c.CloudRun.Region == "" {
@@ -726,21 +899,23 @@ func FromJSON(jsonStr string) (*Config, error) {
 func AutoDiscoverConfigFile() (string, error) {
 	logger := logging.WithField("function", "AutoDiscoverConfigFile")
 
-	// List of possible configuration file names in order of preference
+	// List of possible configuration file names in order of preference, each in any format
 	candidates := []string{
-		"wif-config.json",
-		"gcp-wif.json",
-		".gcp-wif.json",
-		"config/wif.json",
-		"config/gcp-wif.json",
-		".wif/config.json",
-		"examples/config.json",
+		"wif-config",
+		"gcp-wif",
+		".gcp-wif",
+		"config/wif",
+		"config/gcp-wif",
+		".wif/config",
+		"examples/config",
 	}
 
 	for _, candidate := range candidates {
-		if _, err := os.Stat(candidate); err == nil {
-			logger.Info("Configuration file auto-discovered", "file", candidate)
-			return candidate, nil
+		for _, extension := range configExtensions {
+			if info, err := os.Stat(candidate + extension); err == nil && !info.IsDir() {
+				logger.Info("Configuration file auto-discovered", "file", candidate+extension)
+				return candidate + extension, nil
+			}
 		}
 	}
 
@@ -830,6 +1005,12 @@ func (c *Config) MergeConfig(other *Config) error {
 	if len(other.WorkloadIdentity.Conditions) > 0 {
 		c.WorkloadIdentity.Conditions = other.WorkloadIdentity.Conditions
 	}
+	if len(other.WorkloadIdentity.AllowedAudiences) > 0 {
+		c.WorkloadIdentity.AllowedAudiences = other.WorkloadIdentity.AllowedAudiences
+	}
+	if other.WorkloadIdentity.BindingExpiration != "" {
+		c.WorkloadIdentity.BindingExpiration = other.WorkloadIdentity.BindingExpiration
+	}
 
 	// Merge Cloud Run configuration
 	if other.CloudRun.ServiceName != "" {
@@ -1082,6 +1263,12 @@ func (c *Config) MigrateToLatestVersion() (*Config, error) {
 		logger.Info("Migration to version 1.0 completed")
 	}
 
+	// Conditions written before the repository IDs were recorded match by name only
+	if migrated.PinRepositoryIDs(migrated.Repository.ID, migrated.Repository.OwnerID) {
+		logger.Info("Pinned conditions to repository IDs",
+			"repository_id", migrated.Repository.ID, "owner_id", migrated.Repository.OwnerID)
+	}
+
 	// Future version migrations can be added here
 	// if c.Version == "1.0" && targetVersion == "1.1" { ... }
 
@@ -1117,8 +1304,12 @@ func (c *Config) SaveWithBackup(filePath string) error {
 	if _, err := os.Stat(filePath); err == nil {
 		backupDir := filepath.Dir(filePath)
 		timestamp := time.Now().Format("20060102-150405")
+		extension := filepath.Ext(filePath)
+		if extension == "" {
+			extension = FormatJSON.Extension()
+		}
 		basename := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
-		backupFile := filepath.Join(backupDir, fmt.Sprintf("%s-backup-%s.json", basename, timestamp))
+		backupFile := filepath.Join(backupDir, fmt.Sprintf("%s-backup-%s%s", basename, timestamp, extension))
 
 		// Read existing file
 		data, err := os.ReadFile(filePath)
@@ -1209,7 +1400,9 @@ type TemplateConfig struct {
 	Presets map[string]PresetConfig `json:"presets,omitempty" yaml:"presets,omitempty"`
 }
 
-// PresetConfig defines a configuration preset for quick setup
+// PresetConfig defines a configuration preset for quick setup. Config holds workflow
+// settings, using the same keys as the workflow section, that are applied on top of the
+// configured workflow when the preset is selected.
 type PresetConfig struct {
 	Name        string                 `json:"name" yaml:"name"`
 	Description string                 `json:"description" yaml:"description"`
@@ -1354,34 +1547,143 @@ func (c *Config) GenerateResourceName(resourceType, baseName string) string {
 	// Add environment suffix if current environment is set
 	if c.CurrentEnv != "" {
 		if env, err := c.GetCurrentEnvironment(); err == nil {
-			switch resourceType {
-			case "service-account":
-				if env.Resources.ServiceAccount.NameSuffix != "" {
-					parts = append(parts, env.Resources.ServiceAccount.NameSuffix)
-				} else {
-					parts = append(parts, env.Name)
-				}
-			case "workload-identity-pool":
-				if env.Resources.WorkloadIdentity.PoolSuffix != "" {
-					parts = append(parts, env.Resources.WorkloadIdentity.PoolSuffix)
-				} else {
-					parts = append(parts, env.Name, "pool")
-				}
-			case "workload-identity-provider":
-				if env.Resources.WorkloadIdentity.ProviderSuffix != "" {
-					parts = append(parts, env.Resources.WorkloadIdentity.ProviderSuffix)
-				} else {
-					parts = append(parts, env.Name, "provider")
-				}
-			default:
-				parts = append(parts, env.Name)
-			}
+			parts = append(parts, environmentNameSuffix(env, resourceType))
 		}
 	}
 
 	return strings.Join(parts, "-")
 }
 
+// environmentNameSuffix returns the suffix an environment adds to the names of its resources
+func environmentNameSuffix(env *EnvironmentConfig, resourceType string) string {
+	switch resourceType {
+	case "service-account":
+		if env.Resources.ServiceAccount.NameSuffix != "" {
+			return env.Resources.ServiceAccount.NameSuffix
+		}
+	case "workload-identity-pool":
+		if env.Resources.WorkloadIdentity.PoolSuffix != "" {
+			return env.Resources.WorkloadIdentity.PoolSuffix
+		}
+		return env.Name + "-pool"
+	case "workload-identity-provider":
+		if env.Resources.WorkloadIdentity.ProviderSuffix != "" {
+			return env.Resources.WorkloadIdentity.ProviderSuffix
+		}
+		return env.Name + "-provider"
+	}
+	return env.Name
+}
+
+// GetGitHubEnvironment returns the GitHub environment the environment deploys in
+func (e *EnvironmentConfig) GetGitHubEnvironment() string {
+	if e.Workflow.Environment != "" {
+		return e.Workflow.Environment
+	}
+	return e.Name
+}
+
+// environmentResourceID appends an environment suffix to a base resource ID, shortening the
+// base so the result fits within maxLength
+func environmentResourceID(base, suffix string, maxLength int) string {
+	if keep := maxLength - len(suffix) - 1; len(base) > keep && keep > 0 {
+		base = strings.TrimRight(base[:keep], "-")
+	}
+	return base + "-" + suffix
+}
+
+// GetEnvironmentServiceAccountName returns the name of the service account an environment
+// deploys with: the base service account name followed by the environment's suffix
+func (c *Config) GetEnvironmentServiceAccountName(env *EnvironmentConfig) string {
+	return environmentResourceID(c.ServiceAccount.Name, environmentNameSuffix(env, "service-account"), 30)
+}
+
+// GetEnvironmentServiceAccountEmail returns the email of an environment's service account
+func (c *Config) GetEnvironmentServiceAccountEmail(env *EnvironmentConfig) string {
+	return fmt.Sprintf("%s@%s.iam.gserviceaccount.com", c.GetEnvironmentServiceAccountName(env), c.Project.ID)
+}
+
+// GetEnvironmentProviderID returns the ID of the workload identity provider an environment
+// authenticates through. Providers are created in the configured pool.
+func (c *Config) GetEnvironmentProviderID(env *EnvironmentConfig) string {
+	return environmentResourceID(c.WorkloadIdentity.ProviderID, environmentNameSuffix(env, "workload-identity-provider"), 32)
+}
+
+// GetEnvironmentProviderName returns the full name of an environment's provider
+func (c *Config) GetEnvironmentProviderName(env *EnvironmentConfig) string {
+	return fmt.Sprintf("%s/providers/%s", c.GetWorkloadIdentityPoolName(), c.GetEnvironmentProviderID(env))
+}
+
+// environmentTypeOrder ranks environment types in promotion order
+var environmentTypeOrder = map[string]int{
+	EnvironmentDevelopment: 0,
+	EnvironmentTesting:     1,
+	EnvironmentStaging:     2,
+	EnvironmentProduction:  3,
+}
+
+// GetPipelineEnvironments returns the enabled environments in promotion order: development,
+// testing, staging, then production, by name within a type
+func (c *Config) GetPipelineEnvironments() []string {
+	var names []string
+	for name, env := range c.Environments {
+		if env.Enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := environmentTypeOrder[c.Environments[names[i]].Type], environmentTypeOrder[c.Environments[names[j]].Type]
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// ApplyEnvironmentPipeline deploys each enabled environment in its own workflow job, in
+// promotion order, with the environment's region, identity and variables. Workflow
+// environments keep their URL, secrets and protection rules. Workflows with a configured
+// pipeline, and terraform workflows, are left unchanged.
+func (c *Config) ApplyEnvironmentPipeline() {
+	if len(c.Workflow.Advanced.Pipeline) > 0 || c.Workflow.IsTerraform() {
+		return
+	}
+
+	for _, name := range c.GetPipelineEnvironments() {
+		env := c.Environments[name]
+		if env.Name == "" {
+			env.Name = name
+		}
+		githubName := env.GetGitHubEnvironment()
+
+		environment, _ := c.Workflow.GetEnvironment(githubName)
+		environment.Region = env.Region
+		environment.ServiceAccountEmail = c.GetEnvironmentServiceAccountEmail(&env)
+		environment.WorkloadIdentityProvider = c.GetEnvironmentProviderName(&env)
+		if environment.Variables == nil {
+			environment.Variables = make(map[string]string)
+		}
+		// Branches the environment is restricted to become its deployment branch policy
+		if len(env.Security.RestrictBranches) > 0 && !environment.Protection.ProtectedBranches && len(environment.Protection.DeploymentBranches) == 0 {
+			environment.Protection.DeploymentBranches = env.Security.RestrictBranches
+		}
+		// Empty values are skipped so they do not blank out workflow-level settings
+		for _, variables := range []map[string]string{env.Variables, env.Workflow.Variables} {
+			for key, value := range variables {
+				if value != "" {
+					environment.Variables[key] = value
+				}
+			}
+		}
+
+		c.Workflow.AddEnvironment(githubName, environment)
+		if !contains(c.Workflow.Advanced.Pipeline, githubName) {
+			c.Workflow.Advanced.Pipeline = append(c.Workflow.Advanced.Pipeline, githubName)
+		}
+	}
+}
+
 // GetEffectiveConfig returns the effective configuration for the current environment
 func (c *Config) GetEffectiveConfig() (*Config, error) {
 	if c.CurrentEnv == "" {
@@ -1409,6 +1711,9 @@ func (c *Config) GetEffectiveConfig() (*Config, error) {
 		case "development":
 			devConfig := github.DefaultDevelopmentWorkflowConfig()
 			effective.Workflow = *devConfig
+		default:
+			// Any other name refers to a template in the workflow template catalog
+			effective.Workflow.Template = env.Workflow.Template
 		}
 	}
 
@@ -1448,6 +1753,63 @@ func (c *Config) GetEffectiveConfig() (*Config, error) {
 	return &effective, nil
 }
 
+// ApplyPreset applies a preset from the templates section on top of the workflow configuration
+func (c *Config) ApplyPreset(name string) error {
+	preset, ok := c.Templates.Presets[name]
+	if !ok {
+		return fmt.Errorf("preset %s not found", name)
+	}
+
+	data, err := json.Marshal(preset.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encode preset %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, &c.Workflow); err != nil {
+		return fmt.Errorf("preset %s has invalid workflow settings: %w", name, err)
+	}
+	return nil
+}
+
+// ApplyImportedWorkflow bootstraps the configuration from a workflow read back with
+// github.ImportWorkflow: the workflow settings are taken as is, and the project, repository,
+// service account and workload identity settings are derived from them
+func (c *Config) ApplyImportedWorkflow(workflow *github.WorkflowConfig) {
+	c.Workflow = *workflow
+
+	if workflow.ProjectID != "" {
+		c.Project.ID = workflow.ProjectID
+	}
+	if workflow.ProjectNumber != "" {
+		c.Project.Number = workflow.ProjectNumber
+	}
+	if workflow.Region != "" {
+		c.Project.Region = workflow.Region
+		c.CloudRun.Region = workflow.Region
+	}
+	if workflow.ServiceName != "" {
+		c.CloudRun.ServiceName = workflow.ServiceName
+	}
+	if owner, name, ok := strings.Cut(workflow.Repository, "/"); ok {
+		c.Repository.Owner = owner
+		c.Repository.Name = name
+	}
+
+	if match := serviceAccountEmailRegex.FindStringSubmatch(workflow.ServiceAccountEmail); match != nil {
+		c.ServiceAccount.Name = match[1]
+		if c.Project.ID == "" {
+			c.Project.ID = match[2]
+		}
+	}
+	if match := providerResourceRegex.FindStringSubmatch(workflow.WorkloadIdentityProvider); match != nil {
+		// Provider resource names usually carry the project number rather than the ID
+		if c.Project.Number == "" && strings.Trim(match[1], "0123456789") == "" {
+			c.Project.Number = match[1]
+		}
+		c.WorkloadIdentity.PoolID = match[2]
+		c.WorkloadIdentity.ProviderID = match[3]
+	}
+}
+
 // Helper function to check if slice contains string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
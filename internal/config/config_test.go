@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/Fordjour12/gcp-wif/internal/github"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -97,6 +100,56 @@ func TestValidateSchema_InvalidProjectID(t *testing.T) {
 	}
 }
 
+func TestValidateSchema_SubjectClaimKeys(t *testing.T) {
+	config := NewConfig("test-project-123", "testowner", "test-repo")
+	config.WorkloadIdentity.SubjectClaimKeys = []string{"repository_owner_id", "repository_id", "job_workflow_ref"}
+	config.WorkloadIdentity.Conditions = []string{"assertion.sub.startsWith('repo:testowner/test-repo:ref:refs/heads/main')"}
+
+	result := config.ValidateSchema()
+
+	found := false
+	for _, err := range result.Errors {
+		found = found || (err.Field == "workload_identity.conditions" && err.Code == "SUBJECT_CLAIM_MISMATCH")
+	}
+	if !found {
+		t.Errorf("Expected a condition on a dropped subject claim to be rejected, got %v", result.Errors)
+	}
+	if len(result.Warnings) == 0 {
+		t.Error("Expected a warning that a job_workflow_ref subject may be too long")
+	}
+
+	config.WorkloadIdentity.Conditions = []string{"assertion.repository=='testowner/test-repo'"}
+	if result := config.ValidateSchema(); !result.Valid {
+		t.Errorf("Expected conditions on other claims to hold, got %v", result.Errors)
+	}
+}
+
+func TestPinRepositoryIDs(t *testing.T) {
+	config := NewConfig("test-project-123", "testowner", "test-repo")
+	config.WorkloadIdentity.Conditions = []string{"assertion.repository=='testowner/test-repo'"}
+
+	found := false
+	for _, info := range config.ValidateSchema().Info {
+		found = found || info.Field == "repository.id"
+	}
+	if !found {
+		t.Error("Expected a hint to pin conditions that match the repository by name")
+	}
+
+	if !config.PinRepositoryIDs(123, 456) {
+		t.Fatal("Expected pinning to change the configuration")
+	}
+	if got := config.WorkloadIdentity.Conditions[0]; got != "assertion.repository=='testowner/test-repo' && assertion.repository_id=='123'" {
+		t.Errorf("Unexpected pinned condition: %s", got)
+	}
+	if config.WorkloadIdentity.AttributeMapping["attribute.repository_owner_id"] != "assertion.repository_owner_id" {
+		t.Error("Expected the owner ID claim to be mapped")
+	}
+	if config.PinRepositoryIDs(123, 456) {
+		t.Error("Expected pinning twice to leave the configuration unchanged")
+	}
+}
+
 func TestValidateSchema_InvalidRepositoryOwner(t *testing.T) {
 	config := DefaultConfig()
 	config.Project.ID = "test-project-123"
@@ -195,7 +248,6 @@ func TestSaveAndLoadConfig(t *testing.T) {
 	// Create and save config
 	originalConfig := NewConfig("test-project-123", "testowner", "test-repo")
 	originalConfig.CloudRun.ServiceName = "test-service"
-	originalConfig.Workflow.Environment = "production"
 
 	err := originalConfig.SaveToFile(configPath)
 	if err != nil {
@@ -321,3 +373,74 @@ func TestDefaultRoles(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyEnvironmentPipeline(t *testing.T) {
+	config := NewConfig("test-project-123", "testowner", "test-repo")
+	prod := EnvironmentConfig{Name: "prod", Type: EnvironmentProduction, Enabled: true, Region: "us-east1"}
+	prod.Resources.ServiceAccount.NameSuffix = "production-deployer"
+	prod.Security.RestrictBranches = []string{"main"}
+	config.Environments = map[string]EnvironmentConfig{
+		"prod":    prod,
+		"staging": {Name: "staging", Type: EnvironmentStaging, Enabled: true, Variables: map[string]string{"DEBUG": "false"}},
+		"dev":     {Name: "dev", Type: EnvironmentDevelopment, Enabled: true, Workflow: EnvWorkflowConfig{Environment: "development"}},
+		"old":     {Name: "old", Type: EnvironmentTesting},
+	}
+
+	config.ApplyEnvironmentPipeline()
+
+	if got := strings.Join(config.Workflow.Advanced.Pipeline, ","); got != "development,staging,prod" {
+		t.Fatalf("Expected enabled environments in promotion order, got %s", got)
+	}
+	environment, _ := config.Workflow.GetEnvironment("prod")
+	if environment.Region != "us-east1" {
+		t.Errorf("Expected the prod region, got %q", environment.Region)
+	}
+	if got := strings.Join(environment.Protection.DeploymentBranches, ","); got != "main" {
+		t.Errorf("Expected the restricted branches to become the deployment branches, got %q", got)
+	}
+	if environment.ServiceAccountEmail != config.GetEnvironmentServiceAccountEmail(&prod) {
+		t.Errorf("Unexpected prod service account %s", environment.ServiceAccountEmail)
+	}
+	if name := config.GetEnvironmentServiceAccountName(&prod); len(name) > 30 || !strings.HasSuffix(name, "-production-deployer") {
+		t.Errorf("Expected a shortened service account name with the environment suffix, got %s", name)
+	}
+	if !strings.HasPrefix(environment.WorkloadIdentityProvider, config.GetWorkloadIdentityPoolName()+"/providers/") {
+		t.Errorf("Expected the prod provider in the configured pool, got %s", environment.WorkloadIdentityProvider)
+	}
+	if staging, _ := config.Workflow.GetEnvironment("staging"); staging.Variables["DEBUG"] != "false" {
+		t.Errorf("Expected staging variables to be carried over, got %v", staging.Variables)
+	}
+
+	// An explicit pipeline is left alone
+	config.Workflow.Advanced.Pipeline = []string{"staging"}
+	config.ApplyEnvironmentPipeline()
+	if len(config.Workflow.Advanced.Pipeline) != 1 {
+		t.Errorf("Expected the configured pipeline to be kept, got %v", config.Workflow.Advanced.Pipeline)
+	}
+}
+
+func TestApplyImportedWorkflow(t *testing.T) {
+	config := DefaultConfig()
+	workflow := github.DefaultWorkflowConfig()
+	workflow.Repository = "acme/app"
+	workflow.ProjectID = "acme-prod"
+	workflow.Region = "europe-west1"
+	workflow.ServiceName = "api"
+	workflow.ServiceAccountEmail = "deployer@acme-prod.iam.gserviceaccount.com"
+	workflow.WorkloadIdentityProvider = "projects/123456789/locations/global/workloadIdentityPools/github/providers/acme-app"
+
+	config.ApplyImportedWorkflow(workflow)
+
+	if config.GetRepoFullName() != "acme/app" || config.Project.ID != "acme-prod" || config.Project.Number != "123456789" {
+		t.Errorf("Unexpected repository %s or project %+v", config.GetRepoFullName(), config.Project)
+	}
+	if config.GetServiceAccountEmail() != workflow.ServiceAccountEmail {
+		t.Errorf("Expected service account %s, got %s", workflow.ServiceAccountEmail, config.GetServiceAccountEmail())
+	}
+	if config.WorkloadIdentity.PoolID != "github" || config.WorkloadIdentity.ProviderID != "acme-app" {
+		t.Errorf("Unexpected workload identity %+v", config.WorkloadIdentity)
+	}
+	if config.CloudRun.ServiceName != "api" || config.Workflow.Region != "europe-west1" {
+		t.Errorf("Expected the workflow settings to be applied, got %+v", config.CloudRun)
+	}
+}
@@ -0,0 +1,355 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the file format of a configuration file
+type Format string
+
+// Supported configuration file formats
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// configExtensions lists the extensions of configuration files in order of preference
+var configExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// SupportedExtensions returns the extensions of configuration files in order of preference
+func SupportedExtensions() []string {
+	return append([]string(nil), configExtensions...)
+}
+
+// DefaultConfigFileName is the name, without extension, of the default configuration file
+const DefaultConfigFileName = "wif-config"
+
+// DefaultConfigFile returns the default configuration file in the first format it exists
+// in, or as JSON when there is none
+func DefaultConfigFile() string {
+	for _, extension := range configExtensions {
+		if info, err := os.Stat(DefaultConfigFileName + extension); err == nil && !info.IsDir() {
+			return DefaultConfigFileName + extension
+		}
+	}
+	return DefaultConfigFileName + FormatJSON.Extension()
+}
+
+// FormatFromPath returns the format of a configuration file from its extension. Files
+// without a known extension are JSON.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// ParseFormat returns the format with the given name
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	default:
+		return "", errors.NewValidationError(fmt.Sprintf("Unsupported configuration format: %s", name),
+			"Supported formats: json, yaml, toml")
+	}
+}
+
+// Extension returns the file extension of the format
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// UnmarshalFormat decodes a configuration in the given format. Keys are the same in every
+// format: YAML and TOML documents are decoded through their JSON form.
+func UnmarshalFormat(data []byte, format Format) (*Config, error) {
+	var value interface{}
+	switch format {
+	case FormatYAML:
+		var document yaml.Node
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		var err error
+		if value, err = yamlNodeValue(&document, reflect.TypeOf(Config{})); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		table, err := parseTOML(data)
+		if err != nil {
+			return nil, err
+		}
+		value = table
+	default:
+		var config Config
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, err
+		}
+		return &config, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// MarshalFormat encodes the configuration in the given format, with the keys in the order
+// of its JSON form
+func (c *Config) MarshalFormat(format Format) ([]byte, error) {
+	return c.marshalFormat(format, nil)
+}
+
+// marshalFormat encodes the configuration, carrying the comments and styles of a previous
+// YAML version of the file over to the matching keys
+func (c *Config) marshalFormat(format Format, previous []byte) ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil || format == FormatJSON {
+		return data, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := jsonToYAMLNode(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatTOML {
+		return encodeTOML(node)
+	}
+
+	document := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{node}}
+	if len(previous) > 0 {
+		var existing yaml.Node
+		if yaml.Unmarshal(previous, &existing) == nil {
+			mergeYAMLComments(document, &existing)
+		}
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonToYAMLNode converts the next JSON value into a YAML node, keeping the order of keys
+func jsonToYAMLNode(decoder *json.Decoder) (*yaml.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if value == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		for decoder.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := jsonToYAMLNode(decoder)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		// Consume the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(value.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprintf("%t", value)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+// yamlNodeValue converts a YAML node into the value encoding/json marshals into the target
+// type. Plain scalars read as numbers or booleans, such as version: 1.0 or a branch named
+// 2024, stay strings where the target is a string, as do timestamps.
+func yamlNodeValue(node *yaml.Node, target reflect.Type) (interface{}, error) {
+	for target != nil && target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return map[string]interface{}{}, nil
+		}
+		return yamlNodeValue(node.Content[0], target)
+	case yaml.AliasNode:
+		return yamlNodeValue(node.Alias, target)
+	case yaml.MappingNode:
+		mapping := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			value, err := yamlNodeValue(node.Content[i+1], jsonFieldType(target, key))
+			if err != nil {
+				return nil, err
+			}
+			mapping[key] = value
+		}
+		return mapping, nil
+	case yaml.SequenceNode:
+		var elem reflect.Type
+		if target != nil && (target.Kind() == reflect.Slice || target.Kind() == reflect.Array) {
+			elem = target.Elem()
+		}
+		sequence := make([]interface{}, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := yamlNodeValue(child, elem)
+			if err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, value)
+		}
+		return sequence, nil
+	}
+
+	var value interface{}
+	switch tag := node.ShortTag(); {
+	case tag == "!!null":
+		return nil, nil
+	case target != nil && target.Kind() == reflect.String:
+		return node.Value, nil
+	case tag == "!!int" || tag == "!!float" || tag == "!!bool":
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return node.Value, nil
+	}
+}
+
+// jsonFieldType returns the type of the value stored under a key of a struct, by its JSON
+// name, or of a map. It returns nil when the key is unknown.
+func jsonFieldType(target reflect.Type, key string) reflect.Type {
+	for target != nil && target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	if target == nil {
+		return nil
+	}
+	switch target.Kind() {
+	case reflect.Map:
+		return target.Elem()
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" && field.Anonymous {
+			if fieldType := jsonFieldType(field.Type, key); fieldType != nil {
+				return fieldType
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.EqualFold(name, key) {
+			return field.Type
+		}
+	}
+	return nil
+}
+
+// mergeYAMLComments copies the comments of the previous version of a YAML document onto
+// the nodes of the new one that have the same path, along with the style of unchanged
+// values
+func mergeYAMLComments(node, previous *yaml.Node) {
+	if previous.Kind == yaml.DocumentNode && node.Kind == yaml.DocumentNode {
+		copyYAMLComments(node, previous)
+		if len(node.Content) > 0 && len(previous.Content) > 0 {
+			mergeYAMLComments(node.Content[0], previous.Content[0])
+		}
+		return
+	}
+	if node.Kind != previous.Kind {
+		return
+	}
+	copyYAMLComments(node, previous)
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		previousKeys := make(map[string]int, len(previous.Content)/2)
+		for i := 0; i+1 < len(previous.Content); i += 2 {
+			previousKeys[previous.Content[i].Value] = i
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if j, ok := previousKeys[node.Content[i].Value]; ok {
+				copyYAMLComments(node.Content[i], previous.Content[j])
+				mergeYAMLComments(node.Content[i+1], previous.Content[j+1])
+			}
+		}
+	case yaml.SequenceNode:
+		for i := 0; i < len(node.Content) && i < len(previous.Content); i++ {
+			mergeYAMLComments(node.Content[i], previous.Content[i])
+		}
+	case yaml.ScalarNode:
+		if node.Value == previous.Value {
+			node.Style = previous.Style
+		}
+	}
+}
+
+// copyYAMLComments copies the comments of a node, and the flow style of a collection
+func copyYAMLComments(node, previous *yaml.Node) {
+	node.HeadComment = previous.HeadComment
+	node.LineComment = previous.LineComment
+	node.FootComment = previous.FootComment
+	if node.Kind != yaml.ScalarNode {
+		node.Style = previous.Style
+	}
+}
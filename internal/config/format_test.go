@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalFormatRoundTrip(t *testing.T) {
+	config := NewConfig("test-project-123", "testowner", "test-repo")
+	config.Repository.ID = 123
+	config.Repository.Branches = []string{"main", "release/*"}
+	config.WorkloadIdentity.Conditions = []string{`assertion.repository=='testowner/test-repo' && assertion.ref=="refs/heads/main"`}
+	config.Regions = []RegionConfig{{Name: "us-central1", Enabled: true, Settings: map[string]string{"tier": "primary"}}, {Name: "europe-west1"}}
+
+	for _, format := range []Format{FormatJSON, FormatYAML, FormatTOML} {
+		data, err := config.MarshalFormat(format)
+		if err != nil {
+			t.Fatalf("Failed to marshal %s: %v", format, err)
+		}
+		decoded, err := UnmarshalFormat(data, format)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v\n%s", format, err, data)
+		}
+		if !reflect.DeepEqual(decoded, config) {
+			t.Errorf("Expected the %s round trip to keep the configuration, got\n%s", format, data)
+		}
+	}
+	// Hand-written YAML leaves numbers in string fields unquoted
+	decoded, err := UnmarshalFormat([]byte(`
+version: 1.0
+project: {id: 123456, number: 123}
+repository:
+  owner: testowner
+  name: test-repo
+  id: 42
+  branches: [main, 2024]
+  pull_request: true
+`), FormatYAML)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal hand-written YAML: %v", err)
+	}
+	if decoded.Version != "1.0" || decoded.Project.ID != "123456" || decoded.Project.Number != "123" {
+		t.Errorf("Expected unquoted numbers to load as written, got %q, %q, %q", decoded.Version, decoded.Project.ID, decoded.Project.Number)
+	}
+	if !reflect.DeepEqual(decoded.Repository.Branches, []string{"main", "2024"}) || decoded.Repository.ID != 42 || !decoded.Repository.PullRequest {
+		t.Errorf("Unexpected repository: %+v", decoded.Repository)
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	table, err := parseTOML([]byte(`
+# Hand-written configuration
+version = "1.0"
+project = { id = "test-project-123", region = 'us-east1' }
+
+[repository]
+owner = "testowner"
+name = "test-repo"
+branches = [
+  "main", # trailing comments in arrays
+  "release",
+]
+
+[workload_identity.attribute_mapping]
+"google.subject" = "assertion.sub"
+
+[[regions]]
+name = "us-central1"
+[regions.settings]
+tier = "primary"
+
+[[regions]]
+name = "europe-west1"
+settings.tier = "secondary" # dotted key
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse TOML: %v", err)
+	}
+	repository := table["repository"].(map[string]interface{})
+	if repository["owner"] != "testowner" || repository["name"] != "test-repo" || len(repository["branches"].([]interface{})) != 2 {
+		t.Errorf("Unexpected repository table: %v", repository)
+	}
+	regions := table["regions"].([]interface{})
+	if len(regions) != 2 || regions[1].(map[string]interface{})["settings"].(map[string]interface{})["tier"] != "secondary" {
+		t.Errorf("Expected each array table to hold its own sub-table, got %v", regions)
+	}
+
+	if _, err := parseTOML([]byte("[project]\nid = \"a\"\n[project]\nid = \"b\"\n")); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected a table defined twice to be rejected with its line, got %v", err)
+	}
+
+	// Numbers and dates follow the TOML specification
+	table, err = parseTOML([]byte("mode = 0o755\nflags = 0b101\nhex = 0xff\ncreated = 2024-01-02\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse TOML: %v", err)
+	}
+	if table["mode"] != int64(0755) || table["flags"] != int64(5) || table["hex"] != int64(255) {
+		t.Errorf("Unexpected integers: %v", table)
+	}
+	if _, ok := table["created"].(string); ok {
+		t.Errorf("Expected a date to decode as a date, got %q", table["created"])
+	}
+
+	for _, malformed := range []string{
+		"mode = 0755\n",
+		"id = \"unterminated\n",
+		"id = \"a\"\nid = \"b\"\n",
+		"id =\n",
+		"[project\nid = \"a\"\n",
+		"ids = [\"a\" \"b\"]\n",
+	} {
+		if _, err := parseTOML([]byte(malformed)); err == nil || !strings.Contains(err.Error(), "line ") {
+			t.Errorf("Expected %q to be rejected with its position, got %v", malformed, err)
+		}
+	}
+}
+
+func TestSaveToFileKeepsYAMLComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wif-config.yaml")
+	config := NewConfig("test-project-123", "testowner", "test-repo")
+	if err := config.SaveToFile(path); err != nil {
+		t.Fatalf("Failed to save YAML: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	commented := strings.Replace(string(data), "project:\n", "# Production project\nproject:\n", 1)
+	commented = strings.Replace(commented, "  id: test-project-123\n", "  id: test-project-123 # billing account A\n", 1)
+	if err := os.WriteFile(path, []byte(commented), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load YAML: %v", err)
+	}
+	loaded.Project.Region = "europe-west1"
+	if err := loaded.SaveToFile(path); err != nil {
+		t.Fatalf("Failed to save YAML: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	for _, want := range []string{"# Production project\nproject:", "id: test-project-123 # billing account A", "region: europe-west1"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected the saved YAML to contain %q, got\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// parseTOML decodes a TOML document into the generic values encoding/json marshals.
+// Syntax errors report their line and column.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	table := make(map[string]interface{})
+	if err := toml.Unmarshal(data, &table); err != nil {
+		var decodeErr *toml.DecodeError
+		if stderrors.As(err, &decodeErr) {
+			row, column := decodeErr.Position()
+			return nil, fmt.Errorf("line %d, column %d: %s", row, column, decodeErr.Error())
+		}
+		return nil, err
+	}
+	return table, nil
+}
+
+// encodeTOML writes a mapping node as a TOML document, keeping the order of its keys.
+// Null values are left out, as TOML has no null.
+func encodeTOML(node *yaml.Node) ([]byte, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("TOML documents must be tables, got %s", node.ShortTag())
+	}
+	value, err := orderedTOMLValue(node)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// orderedTOMLValue converts a node into the value the TOML encoder writes. The encoder
+// sorts the keys of maps but keeps the order of struct fields, so mappings become structs
+// whose fields are tagged with the mapping's keys, which therefore cannot hold commas.
+func orderedTOMLValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var fields []reflect.StructField
+		var values []reflect.Value
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i+1].ShortTag() == "!!null" {
+				continue
+			}
+			key := node.Content[i].Value
+			if strings.Contains(key, ",") {
+				return nil, fmt.Errorf("TOML key %q cannot contain a comma", key)
+			}
+			value, err := orderedTOMLValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			// Empty tables are written inline
+			tag := key
+			if node.Content[i+1].Kind == yaml.MappingNode && len(node.Content[i+1].Content) == 0 {
+				tag += ",inline"
+			}
+			fields = append(fields, reflect.StructField{
+				Name: fmt.Sprintf("Field%d", len(fields)),
+				Type: reflect.TypeOf(value),
+				Tag:  reflect.StructTag(fmt.Sprintf("toml:%q", tag)),
+			})
+			values = append(values, reflect.ValueOf(value))
+		}
+		table := reflect.New(reflect.StructOf(fields)).Elem()
+		for i, value := range values {
+			table.Field(i).Set(value)
+		}
+		return table.Interface(), nil
+	case yaml.SequenceNode:
+		array := make([]interface{}, 0, len(node.Content))
+		for _, element := range node.Content {
+			if element.ShortTag() == "!!null" {
+				continue
+			}
+			value, err := orderedTOMLValue(element)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
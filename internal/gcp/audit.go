@@ -0,0 +1,579 @@
+package gcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// AuditSeverity represents the severity of an audit finding
+type AuditSeverity string
+
+const (
+	AuditSeverityCritical AuditSeverity = "critical"
+	AuditSeverityHigh     AuditSeverity = "high"
+	AuditSeverityMedium   AuditSeverity = "medium"
+	AuditSeverityLow      AuditSeverity = "low"
+	AuditSeverityInfo     AuditSeverity = "info"
+)
+
+// Rank returns a numeric rank for ordering severities, higher is more severe
+func (s AuditSeverity) Rank() int {
+	switch s {
+	case AuditSeverityCritical:
+		return 4
+	case AuditSeverityHigh:
+		return 3
+	case AuditSeverityMedium:
+		return 2
+	case AuditSeverityLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ParseAuditSeverity parses a severity name
+func ParseAuditSeverity(value string) (AuditSeverity, error) {
+	severity := AuditSeverity(strings.ToLower(strings.TrimSpace(value)))
+	switch severity {
+	case AuditSeverityCritical, AuditSeverityHigh, AuditSeverityMedium, AuditSeverityLow, AuditSeverityInfo:
+		return severity, nil
+	}
+	return "", errors.NewValidationError(
+		fmt.Sprintf("Invalid severity: %s", value),
+		"Valid severities are: critical, high, medium, low, info")
+}
+
+// AuditRule describes a check performed by the workload identity audit
+type AuditRule struct {
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	Description    string        `json:"description"`
+	Severity       AuditSeverity `json:"severity"`
+	Recommendation string        `json:"recommendation"`
+}
+
+// Audit rule identifiers
+const (
+	AuditRuleProviderNoCondition   = "WIF001"
+	AuditRuleProviderNoRepository  = "WIF002"
+	AuditRuleOwnerPrincipalSet     = "WIF003"
+	AuditRuleMutableNameCondition  = "WIF004"
+	AuditRuleDisabledProviderBound = "WIF005"
+	AuditRuleUserManagedKeys       = "WIF006"
+	AuditRuleBroadProjectRole      = "WIF007"
+)
+
+// AuditRules lists all rules evaluated by EvaluateAudit
+var AuditRules = []AuditRule{
+	{
+		ID:             AuditRuleProviderNoCondition,
+		Name:           "provider-without-condition",
+		Description:    "Workload identity provider has no attribute condition, so any GitHub repository can exchange tokens",
+		Severity:       AuditSeverityCritical,
+		Recommendation: "Add an attribute condition restricting assertion.repository or assertion.repository_owner",
+	},
+	{
+		ID:             AuditRuleProviderNoRepository,
+		Name:           "provider-without-repository-condition",
+		Description:    "Workload identity provider condition does not restrict the repository or repository owner",
+		Severity:       AuditSeverityHigh,
+		Recommendation: "Include assertion.repository or assertion.repository_owner in the attribute condition",
+	},
+	{
+		ID:             AuditRuleOwnerPrincipalSet,
+		Name:           "owner-wide-principal-set",
+		Description:    "Service account is bound to every repository of a GitHub owner via attribute.repository_owner",
+		Severity:       AuditSeverityHigh,
+		Recommendation: "Bind to attribute.repository or a provider-scoped principalSet with a repository condition",
+	},
+	{
+		ID:             AuditRuleMutableNameCondition,
+		Name:           "mutable-name-condition",
+		Description:    "Condition matches repository or owner by name, which can be re-registered after a rename or deletion",
+		Severity:       AuditSeverityMedium,
+		Recommendation: "Match assertion.repository_id and assertion.repository_owner_id instead of names",
+	},
+	{
+		ID:             AuditRuleDisabledProviderBound,
+		Name:           "disabled-provider-still-bound",
+		Description:    "Service account bindings still reference a disabled workload identity provider or pool",
+		Severity:       AuditSeverityLow,
+		Recommendation: "Remove the stale IAM bindings or delete the provider",
+	},
+	{
+		ID:             AuditRuleUserManagedKeys,
+		Name:           "federated-sa-with-keys",
+		Description:    "Service account used through workload identity federation still has user-managed keys",
+		Severity:       AuditSeverityHigh,
+		Recommendation: "Revoke the keys with 'gcp-wif keys revoke' once all consumers use federation",
+	},
+	{
+		ID:             AuditRuleBroadProjectRole,
+		Name:           "overly-broad-project-role",
+		Description:    "Federated identity holds a primitive or administrative role on the project",
+		Severity:       AuditSeverityHigh,
+		Recommendation: "Replace the role with narrowly scoped predefined roles",
+	},
+}
+
+// GetAuditRule returns the rule with the given ID
+func GetAuditRule(id string) *AuditRule {
+	for i := range AuditRules {
+		if AuditRules[i].ID == id {
+			return &AuditRules[i]
+		}
+	}
+	return nil
+}
+
+// broadProjectRoles maps project-level roles considered overly broad to their severity
+var broadProjectRoles = map[string]AuditSeverity{
+	"roles/owner":                           AuditSeverityCritical,
+	"roles/editor":                          AuditSeverityHigh,
+	"roles/resourcemanager.projectIamAdmin": AuditSeverityCritical,
+	"roles/iam.securityAdmin":               AuditSeverityCritical,
+	"roles/iam.serviceAccountAdmin":         AuditSeverityHigh,
+	"roles/iam.serviceAccountKeyAdmin":      AuditSeverityHigh,
+	"roles/iam.serviceAccountTokenCreator":  AuditSeverityHigh,
+	"roles/iam.workloadIdentityPoolAdmin":   AuditSeverityHigh,
+	"roles/storage.admin":                   AuditSeverityMedium,
+	"roles/compute.admin":                   AuditSeverityMedium,
+	"roles/container.admin":                 AuditSeverityMedium,
+	"roles/secretmanager.admin":             AuditSeverityMedium,
+}
+
+// AuditFinding represents a single issue detected by the audit
+type AuditFinding struct {
+	RuleID         string        `json:"rule_id"`
+	Severity       AuditSeverity `json:"severity"`
+	Resource       string        `json:"resource"`
+	Message        string        `json:"message"`
+	Recommendation string        `json:"recommendation"`
+}
+
+// AuditServiceAccount holds the audit inputs collected for a service account
+type AuditServiceAccount struct {
+	Email           string                    `json:"email"`
+	Bindings        []WorkloadIdentityBinding `json:"bindings,omitempty"`
+	UserManagedKeys []ServiceAccountKeyInfo   `json:"user_managed_keys,omitempty"`
+	ProjectRoles    []string                  `json:"project_roles,omitempty"`
+}
+
+// AuditSnapshot holds all workload identity resources of a project that the audit evaluates
+type AuditSnapshot struct {
+	ProjectID       string                                     `json:"project_id"`
+	ProjectNumber   string                                     `json:"project_number,omitempty"`
+	Pools           []*WorkloadIdentityPoolInfo                `json:"pools"`
+	Providers       map[string][]*WorkloadIdentityProviderInfo `json:"providers"` // keyed by pool ID
+	ServiceAccounts []AuditServiceAccount                      `json:"service_accounts"`
+	// PrincipalRoles maps federated principal members granted roles directly on the project
+	PrincipalRoles map[string][]string `json:"principal_roles,omitempty"`
+}
+
+// AuditReport holds the results of a workload identity audit
+type AuditReport struct {
+	ProjectID   string                `json:"project_id"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Findings    []AuditFinding        `json:"findings"`
+	Summary     map[AuditSeverity]int `json:"summary"`
+}
+
+// CollectAuditSnapshot gathers pools, providers, service account bindings, keys and
+// project roles for the client's project
+func (c *Client) CollectAuditSnapshot() (*AuditSnapshot, error) {
+	logger := c.logger.WithField("function", "CollectAuditSnapshot")
+	logger.Info("Collecting workload identity resources for audit", "project_id", c.ProjectID)
+
+	snapshot := c.newAuditSnapshot()
+	if err := c.collectWorkloadIdentityPools(snapshot); err != nil {
+		return nil, err
+	}
+
+	// Read the project policy once rather than per service account
+	policy, err := c.ResourceManager.Projects.GetIamPolicy(c.ProjectID, &cloudresourcemanager.GetIamPolicyRequest{}).Context(c.ctx).Do()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeGCP, "IAM_POLICY_GET_FAILED",
+			"Failed to get project IAM policy")
+	}
+	memberRoles := make(map[string][]string)
+	for _, binding := range policy.Bindings {
+		for _, member := range binding.Members {
+			memberRoles[member] = append(memberRoles[member], binding.Role)
+			if c.isWorkloadIdentityMember(member) {
+				snapshot.PrincipalRoles[member] = append(snapshot.PrincipalRoles[member], binding.Role)
+			}
+		}
+	}
+
+	if _, err := c.collectFederatedServiceAccounts(snapshot, memberRoles); err != nil {
+		return nil, err
+	}
+	c.collectServiceAccountKeys(snapshot)
+
+	logger.Info("Audit snapshot collected",
+		"pools", len(snapshot.Pools),
+		"federated_service_accounts", len(snapshot.ServiceAccounts))
+	return snapshot, nil
+}
+
+// newAuditSnapshot returns an empty snapshot of the client's project
+func (c *Client) newAuditSnapshot() *AuditSnapshot {
+	snapshot := &AuditSnapshot{
+		ProjectID:      c.ProjectID,
+		Providers:      make(map[string][]*WorkloadIdentityProviderInfo),
+		PrincipalRoles: make(map[string][]string),
+	}
+	if c.projectInfo != nil {
+		snapshot.ProjectNumber = c.projectInfo.ProjectNumber
+	}
+	return snapshot
+}
+
+// collectWorkloadIdentityPools adds the project's pools and their providers to a snapshot
+func (c *Client) collectWorkloadIdentityPools(snapshot *AuditSnapshot) error {
+	pools, err := c.ListWorkloadIdentityPools()
+	if err != nil {
+		return err
+	}
+	snapshot.Pools = pools
+
+	for _, pool := range pools {
+		poolID := path.Base(pool.Name)
+		providers, err := c.ListWorkloadIdentityProviders(poolID)
+		if err != nil {
+			return err
+		}
+		snapshot.Providers[poolID] = providers
+	}
+	return nil
+}
+
+// collectFederatedServiceAccounts adds the service accounts with workload identity bindings
+// to a snapshot, with their project roles from memberRoles but without their keys. It returns the service accounts
+// whose bindings could not be read.
+func (c *Client) collectFederatedServiceAccounts(snapshot *AuditSnapshot, memberRoles map[string][]string) ([]string, error) {
+	logger := c.logger.WithField("function", "collectFederatedServiceAccounts")
+
+	accounts, err := c.ListServiceAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var unreadable []string
+	for _, account := range accounts {
+		bindings, err := c.ListServiceAccountWorkloadIdentityBindings(account.Email)
+		if err != nil {
+			logger.Warn("Failed to read service account bindings", "service_account", account.Email, "error", err)
+			unreadable = append(unreadable, account.Email)
+			continue
+		}
+		// Only federated service accounts are in scope
+		if len(bindings) == 0 {
+			continue
+		}
+
+		snapshot.ServiceAccounts = append(snapshot.ServiceAccounts, AuditServiceAccount{
+			Email:        account.Email,
+			Bindings:     bindings,
+			ProjectRoles: memberRoles["serviceAccount:"+account.Email],
+		})
+	}
+	return unreadable, nil
+}
+
+// collectServiceAccountKeys adds the user-managed keys of the snapshot's service accounts
+func (c *Client) collectServiceAccountKeys(snapshot *AuditSnapshot) {
+	logger := c.logger.WithField("function", "collectServiceAccountKeys")
+
+	for i := range snapshot.ServiceAccounts {
+		account := &snapshot.ServiceAccounts[i]
+		keys, err := c.ListServiceAccountKeys(account.Email)
+		if err != nil {
+			logger.Warn("Failed to list service account keys", "service_account", account.Email, "error", err)
+		}
+		account.UserManagedKeys = keys
+	}
+}
+
+// EvaluateAudit evaluates all audit rules against a snapshot
+func EvaluateAudit(snapshot *AuditSnapshot) *AuditReport {
+	report := &AuditReport{
+		ProjectID:   snapshot.ProjectID,
+		GeneratedAt: time.Now().UTC(),
+		Findings:    []AuditFinding{},
+	}
+
+	disabled := make(map[string]bool) // pool ID or pool ID/provider ID
+
+	for _, pool := range snapshot.Pools {
+		poolID := path.Base(pool.Name)
+		if pool.Disabled {
+			disabled[poolID] = true
+		}
+
+		for _, provider := range snapshot.Providers[poolID] {
+			providerID := path.Base(provider.Name)
+			if provider.Disabled {
+				disabled[poolID+"/"+providerID] = true
+			}
+			report.addConditionFindings(provider.Name, provider.AttributeCondition)
+		}
+	}
+
+	for _, sa := range snapshot.ServiceAccounts {
+		resource := fmt.Sprintf("projects/%s/serviceAccounts/%s", snapshot.ProjectID, sa.Email)
+
+		for _, binding := range sa.Bindings {
+			if strings.Contains(binding.Member, "/attribute.repository_owner/") {
+				owner := binding.Member[strings.LastIndex(binding.Member, "/")+1:]
+				report.add(AuditRuleOwnerPrincipalSet, "", resource,
+					fmt.Sprintf("%s grants %s to every repository owned by '%s'", sa.Email, binding.Role, owner))
+			}
+
+			if binding.Condition != nil && binding.Condition.Expression != "" {
+				report.addMutableNameFinding(resource, binding.Condition.Expression)
+			}
+
+			if disabled[binding.PoolID] || (binding.ProviderID != "" && disabled[binding.PoolID+"/"+binding.ProviderID]) {
+				target := binding.PoolID
+				if binding.ProviderID != "" {
+					target = binding.PoolID + "/" + binding.ProviderID
+				}
+				report.add(AuditRuleDisabledProviderBound, "", resource,
+					fmt.Sprintf("%s still has %s bound to disabled %s", sa.Email, binding.Role, target))
+			}
+		}
+
+		active := 0
+		for _, key := range sa.UserManagedKeys {
+			if !key.Disabled {
+				active++
+			}
+		}
+		if len(sa.UserManagedKeys) > 0 {
+			severity := AuditSeverityHigh
+			if active == 0 {
+				severity = AuditSeverityLow
+			}
+			report.add(AuditRuleUserManagedKeys, severity, resource,
+				fmt.Sprintf("%s has %d user-managed key(s), %d active", sa.Email, len(sa.UserManagedKeys), active))
+		}
+
+		for _, role := range sa.ProjectRoles {
+			if severity, ok := broadProjectRoles[role]; ok {
+				report.add(AuditRuleBroadProjectRole, severity, resource,
+					fmt.Sprintf("%s holds %s on project %s", sa.Email, role, snapshot.ProjectID))
+			}
+		}
+	}
+
+	for member, roles := range snapshot.PrincipalRoles {
+		for _, role := range roles {
+			if severity, ok := broadProjectRoles[role]; ok {
+				report.add(AuditRuleBroadProjectRole, severity, member,
+					fmt.Sprintf("Federated principal holds %s directly on project %s", role, snapshot.ProjectID))
+			}
+		}
+	}
+
+	report.sort()
+	return report
+}
+
+// addConditionFindings checks a provider attribute condition
+func (r *AuditReport) addConditionFindings(resource, condition string) {
+	if strings.TrimSpace(condition) == "" {
+		r.add(AuditRuleProviderNoCondition, "", resource, "Provider has no attribute condition")
+		return
+	}
+
+	restricted := conditionRepositoryRegex.MatchString(condition) || conditionOwnerRegex.MatchString(condition) ||
+		repositoryIDRegex.MatchString(condition) || repositoryOwnerIDRegex.MatchString(condition)
+	if !restricted {
+		r.add(AuditRuleProviderNoRepository, "", resource,
+			fmt.Sprintf("Condition does not restrict repository or owner: %s", condition))
+		return
+	}
+
+	r.addMutableNameFinding(resource, condition)
+}
+
+// addMutableNameFinding flags conditions that match names without the immutable ID claims
+func (r *AuditReport) addMutableNameFinding(resource, condition string) {
+	usesNames := conditionRepositoryRegex.MatchString(condition) || conditionOwnerRegex.MatchString(condition)
+	usesIDs := repositoryIDRegex.MatchString(condition) || repositoryOwnerIDRegex.MatchString(condition)
+	if usesNames && !usesIDs {
+		r.add(AuditRuleMutableNameCondition, "", resource,
+			"Condition matches repository by name without repository_id or repository_owner_id")
+	}
+}
+
+// add records a finding; an empty severity uses the rule default
+func (r *AuditReport) add(ruleID string, severity AuditSeverity, resource, message string) {
+	rule := GetAuditRule(ruleID)
+	if severity == "" {
+		severity = rule.Severity
+	}
+	r.Findings = append(r.Findings, AuditFinding{
+		RuleID:         ruleID,
+		Severity:       severity,
+		Resource:       resource,
+		Message:        message,
+		Recommendation: rule.Recommendation,
+	})
+}
+
+// sort orders findings by severity, then rule and resource, and refreshes the summary
+func (r *AuditReport) sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.Severity.Rank() != b.Severity.Rank() {
+			return a.Severity.Rank() > b.Severity.Rank()
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Resource < b.Resource
+	})
+
+	r.Summary = make(map[AuditSeverity]int)
+	for _, finding := range r.Findings {
+		r.Summary[finding.Severity]++
+	}
+}
+
+// FilterBySeverity removes findings below the given severity
+func (r *AuditReport) FilterBySeverity(minimum AuditSeverity) {
+	filtered := r.Findings[:0]
+	for _, finding := range r.Findings {
+		if finding.Severity.Rank() >= minimum.Rank() {
+			filtered = append(filtered, finding)
+		}
+	}
+	r.Findings = filtered
+	r.sort()
+}
+
+// HasFindingsAtOrAbove reports whether any finding meets the given severity
+func (r *AuditReport) HasFindingsAtOrAbove(severity AuditSeverity) bool {
+	for _, finding := range r.Findings {
+		if finding.Severity.Rank() >= severity.Rank() {
+			return true
+		}
+	}
+	return false
+}
+
+// ToSARIF renders the report as a SARIF 2.1.0 log for code scanning tools
+func (r *AuditReport) ToSARIF(toolVersion string) ([]byte, error) {
+	type sarifMessage struct {
+		Text string `json:"text"`
+	}
+	type sarifRule struct {
+		ID               string                 `json:"id"`
+		Name             string                 `json:"name"`
+		ShortDescription sarifMessage           `json:"shortDescription"`
+		Help             sarifMessage           `json:"help"`
+		Properties       map[string]interface{} `json:"properties"`
+	}
+	type sarifLogicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+	type sarifLocation struct {
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+	}
+	type sarifResult struct {
+		RuleID     string                 `json:"ruleId"`
+		Level      string                 `json:"level"`
+		Message    sarifMessage           `json:"message"`
+		Locations  []sarifLocation        `json:"locations"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+
+	rules := make([]sarifRule, len(AuditRules))
+	for i, rule := range AuditRules {
+		rules[i] = sarifRule{
+			ID:               rule.ID,
+			Name:             rule.Name,
+			ShortDescription: sarifMessage{Text: rule.Description},
+			Help:             sarifMessage{Text: rule.Recommendation},
+			Properties: map[string]interface{}{
+				"security-severity": sarifSecuritySeverity(rule.Severity),
+				"tags":              []string{"security", "workload-identity"},
+			},
+		}
+	}
+
+	results := make([]sarifResult, len(r.Findings))
+	for i, finding := range r.Findings {
+		results[i] = sarifResult{
+			RuleID:  finding.RuleID,
+			Level:   sarifLevel(finding.Severity),
+			Message: sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: finding.Resource, Kind: "resource"}},
+			}},
+			Properties: map[string]interface{}{
+				"severity":          finding.Severity,
+				"security-severity": sarifSecuritySeverity(finding.Severity),
+			},
+		}
+	}
+
+	log := map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "gcp-wif-audit",
+						"version":        toolVersion,
+						"informationUri": "https://github.com/Fordjour12/gcp-wif",
+						"rules":          rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// sarifLevel maps audit severities to SARIF result levels
+func sarifLevel(severity AuditSeverity) string {
+	switch severity {
+	case AuditSeverityCritical, AuditSeverityHigh:
+		return "error"
+	case AuditSeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps audit severities to GitHub code scanning scores
+func sarifSecuritySeverity(severity AuditSeverity) string {
+	switch severity {
+	case AuditSeverityCritical:
+		return "9.5"
+	case AuditSeverityHigh:
+		return "8.0"
+	case AuditSeverityMedium:
+		return "5.5"
+	case AuditSeverityLow:
+		return "3.0"
+	default:
+		return "0.0"
+	}
+}
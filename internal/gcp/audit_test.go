@@ -0,0 +1,130 @@
+package gcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEvaluateAudit(t *testing.T) {
+	poolName := "projects/123/locations/global/workloadIdentityPools/github-pool"
+	snapshot := &AuditSnapshot{
+		ProjectID: "test-project-123",
+		Pools:     []*WorkloadIdentityPoolInfo{{Name: poolName}},
+		Providers: map[string][]*WorkloadIdentityProviderInfo{
+			"github-pool": {
+				{Name: poolName + "/providers/open", AttributeCondition: ""},
+				{Name: poolName + "/providers/named", AttributeCondition: "assertion.repository=='myorg/myrepo'"},
+				{Name: poolName + "/providers/old", AttributeCondition: "assertion.repository_id=='42'", Disabled: true},
+			},
+		},
+		ServiceAccounts: []AuditServiceAccount{
+			{
+				Email: "deployer@test-project-123.iam.gserviceaccount.com",
+				Bindings: []WorkloadIdentityBinding{
+					{Role: "roles/iam.workloadIdentityUser", Member: "principalSet://iam.googleapis.com/" + poolName + "/attribute.repository_owner/myorg", PoolID: "github-pool"},
+					{Role: "roles/iam.serviceAccountTokenCreator", Member: "principalSet://iam.googleapis.com/" + poolName + "/providers/old/*", PoolID: "github-pool", ProviderID: "old"},
+				},
+				UserManagedKeys: []ServiceAccountKeyInfo{{KeyID: "abc"}},
+				ProjectRoles:    []string{"roles/editor", "roles/run.admin"},
+			},
+		},
+	}
+
+	report := EvaluateAudit(snapshot)
+
+	found := make(map[string]bool)
+	for _, finding := range report.Findings {
+		found[finding.RuleID] = true
+	}
+
+	for _, ruleID := range []string{
+		AuditRuleProviderNoCondition,
+		AuditRuleOwnerPrincipalSet,
+		AuditRuleMutableNameCondition,
+		AuditRuleDisabledProviderBound,
+		AuditRuleUserManagedKeys,
+		AuditRuleBroadProjectRole,
+	} {
+		if !found[ruleID] {
+			t.Errorf("Expected finding for rule %s", ruleID)
+		}
+	}
+
+	if report.Findings[0].Severity != AuditSeverityCritical {
+		t.Errorf("Expected findings sorted by severity, first was %s", report.Findings[0].Severity)
+	}
+
+	report.FilterBySeverity(AuditSeverityHigh)
+	for _, finding := range report.Findings {
+		if finding.Severity.Rank() < AuditSeverityHigh.Rank() {
+			t.Errorf("Finding %s below minimum severity after filtering", finding.RuleID)
+		}
+	}
+}
+
+func TestAuditConditionFindings(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		expected  []string
+	}{
+		{"empty", "", []string{AuditRuleProviderNoCondition}},
+		{"repository in a string", "assertion.ref == 'refs/heads/repository'", []string{AuditRuleProviderNoRepository}},
+		{"repository visibility", "assertion.repository_visibility == 'private'", []string{AuditRuleProviderNoRepository}},
+		{"repository name", "assertion.repository == 'myorg/myrepo'", []string{AuditRuleMutableNameCondition}},
+		{"owner name", "assertion.repository_owner == 'myorg'", []string{AuditRuleMutableNameCondition}},
+		{"name mentioning id", "assertion.repository == 'myorg/repository_id'", []string{AuditRuleMutableNameCondition}},
+		{"repository id", "assertion.repository_id == '42'", nil},
+		{"name and id", "assertion.repository == 'myorg/myrepo' && assertion.repository_owner_id == '7'", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &AuditReport{}
+			report.addConditionFindings("provider", tt.condition)
+
+			var ruleIDs []string
+			for _, finding := range report.Findings {
+				ruleIDs = append(ruleIDs, finding.RuleID)
+			}
+			if len(ruleIDs) != len(tt.expected) {
+				t.Fatalf("Expected findings %v, got %v", tt.expected, ruleIDs)
+			}
+			for i := range ruleIDs {
+				if ruleIDs[i] != tt.expected[i] {
+					t.Errorf("Expected findings %v, got %v", tt.expected, ruleIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditReportToSARIF(t *testing.T) {
+	report := &AuditReport{ProjectID: "test-project-123"}
+	report.add(AuditRuleProviderNoCondition, "", "projects/123/providers/open", "Provider has no attribute condition")
+
+	data, err := report.ToSARIF("test")
+	if err != nil {
+		t.Fatalf("Failed to render SARIF: %v", err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+				Level  string `json:"level"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("Invalid SARIF JSON: %v", err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("Unexpected SARIF structure: %s", string(data))
+	}
+	if log.Runs[0].Results[0].RuleID != AuditRuleProviderNoCondition || log.Runs[0].Results[0].Level != "error" {
+		t.Errorf("Unexpected SARIF result: %+v", log.Runs[0].Results[0])
+	}
+}
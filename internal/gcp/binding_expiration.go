@@ -0,0 +1,146 @@
+package gcp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"google.golang.org/api/iam/v1"
+)
+
+// bindingExpirationRegex matches the expiration clause rendered into IAM conditions.
+// The token expiry clause request.time < timestamp(assertion.exp) is not matched.
+var bindingExpirationRegex = regexp.MustCompile(`request\.time\s*<\s*timestamp\(\s*['"]([^'"]+)['"]\s*\)`)
+
+// bindingDescriptionExpirationRegex matches the " until <time>" suffix of the description
+// of a time-bound binding
+var bindingDescriptionExpirationRegex = regexp.MustCompile(`\s+until\s+\S+$`)
+
+// ParseBindingExpiration resolves an expiration value into an absolute UTC time. The value
+// may be a duration relative to now ("72h", "90m"), a number of days ("7d") or an RFC3339
+// timestamp ("2025-12-31T00:00:00Z").
+func ParseBindingExpiration(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.NewValidationError("Expiration value cannot be empty")
+	}
+
+	var expiresAt time.Time
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return time.Time{}, invalidExpirationError(value)
+		}
+		expiresAt = now.Add(time.Duration(days) * 24 * time.Hour)
+	} else if duration, err := time.ParseDuration(value); err == nil {
+		expiresAt = now.Add(duration)
+	} else if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+		expiresAt = timestamp
+	} else {
+		return time.Time{}, invalidExpirationError(value)
+	}
+
+	if !expiresAt.After(now) {
+		return time.Time{}, errors.NewValidationError(
+			fmt.Sprintf("Expiration %s is in the past", expiresAt.UTC().Format(time.RFC3339)),
+			"Use a positive duration such as --expires 72h",
+			"Or a future RFC3339 timestamp")
+	}
+
+	return expiresAt.UTC().Truncate(time.Second), nil
+}
+
+// invalidExpirationError returns the validation error for an unparseable expiration value
+func invalidExpirationError(value string) error {
+	return errors.NewValidationError(
+		fmt.Sprintf("Invalid expiration: %s", value),
+		"Use a duration such as 72h or 90m",
+		"Use a number of days such as 7d",
+		"Or an RFC3339 timestamp such as 2025-12-31T00:00:00Z")
+}
+
+// buildBindingExpirationClause renders the CEL clause that stops a binding from granting
+// access after the expiration time
+func buildBindingExpirationClause(expirationTime string) string {
+	return fmt.Sprintf("request.time < timestamp('%s')", expirationTime)
+}
+
+// ExtractBindingExpiration returns the expiration time rendered into an IAM condition expression
+func ExtractBindingExpiration(expression string) (time.Time, bool) {
+	match := bindingExpirationRegex.FindStringSubmatch(expression)
+	if match == nil {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, match[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+// setBindingConditionExpiration moves the expiration of a time-bound condition to expiresAt,
+// in its expression and in the description that states it
+func setBindingConditionExpiration(condition *iam.Expr, expiresAt time.Time) {
+	expirationTime := expiresAt.UTC().Format(time.RFC3339)
+	condition.Expression = bindingExpirationRegex.ReplaceAllLiteralString(condition.Expression,
+		buildBindingExpirationClause(expirationTime))
+	condition.Description = bindingDescriptionExpirationRegex.ReplaceAllLiteralString(condition.Description,
+		" until "+expirationTime)
+}
+
+// ExtendWorkloadIdentityBindingExpiration moves the expiration of every time-bound workload
+// identity binding on a service account to expiresAt. When repository is set, only bindings
+// whose condition targets that repository are changed. It returns the number of bindings updated.
+func (c *Client) ExtendWorkloadIdentityBindingExpiration(serviceAccountEmail, repository string, expiresAt time.Time) (int, error) {
+	logger := c.logger.WithField("function", "ExtendWorkloadIdentityBindingExpiration")
+	logger.Info("Extending workload identity binding expiration",
+		"service_account", serviceAccountEmail,
+		"repository", repository,
+		"expires_at", expiresAt.Format(time.RFC3339))
+
+	resource := fmt.Sprintf("projects/%s/serviceAccounts/%s", c.ProjectID, serviceAccountEmail)
+
+	// Conditional bindings are only returned for policy version 3
+	policy, err := c.IAMService.Projects.ServiceAccounts.GetIamPolicy(resource).
+		OptionsRequestedPolicyVersion(3).Context(c.ctx).Do()
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrorTypeGCP, "IAM_POLICY_GET_FAILED",
+			fmt.Sprintf("Failed to get IAM policy for service account %s", serviceAccountEmail))
+	}
+
+	updated := 0
+	for _, binding := range policy.Bindings {
+		if !c.isWorkloadIdentityRole(binding.Role) || binding.Condition == nil {
+			continue
+		}
+		if _, ok := ExtractBindingExpiration(binding.Condition.Expression); !ok {
+			continue
+		}
+		if repository != "" && ParseAttributeCondition(binding.Condition.Expression).Repository != repository {
+			continue
+		}
+		setBindingConditionExpiration(binding.Condition, expiresAt)
+		updated++
+	}
+
+	if updated == 0 {
+		logger.Info("No time-bound bindings found", "service_account", serviceAccountEmail)
+		return 0, nil
+	}
+
+	policy.Version = 3
+	if _, err := c.IAMService.Projects.ServiceAccounts.SetIamPolicy(resource, &iam.SetIamPolicyRequest{
+		Policy: policy,
+	}).Context(c.ctx).Do(); err != nil {
+		return 0, errors.WrapError(err, errors.ErrorTypeGCP, "IAM_POLICY_SET_FAILED",
+			fmt.Sprintf("Failed to update IAM policy for service account %s", serviceAccountEmail))
+	}
+
+	logger.Info("Workload identity binding expiration extended",
+		"service_account", serviceAccountEmail,
+		"bindings", updated)
+	return updated, nil
+}
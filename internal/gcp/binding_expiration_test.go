@@ -0,0 +1,90 @@
+package gcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/iam/v1"
+)
+
+func TestParseBindingExpiration(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"72h", now.Add(72 * time.Hour), false},
+		{"7d", now.Add(7 * 24 * time.Hour), false},
+		{"2025-12-31T00:00:00Z", time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), false},
+		{"2024-01-01T00:00:00Z", time.Time{}, true},
+		{"-1h", time.Time{}, true},
+		{"soon", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		expiresAt, err := ParseBindingExpiration(test.value, now)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Expected error for %q", test.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.value, err)
+			continue
+		}
+		if !expiresAt.Equal(test.expected) {
+			t.Errorf("For %q expected %s, got %s", test.value, test.expected, expiresAt)
+		}
+	}
+}
+
+func TestBindingExpirationCondition(t *testing.T) {
+	c := &Client{}
+	expression := c.buildComprehensiveSecurityExpression(&IAMBindingConfig{
+		Repository:     "myorg/myrepo",
+		ExpirationTime: "2025-12-31T00:00:00Z",
+	})
+
+	if !strings.Contains(expression, "request.time < timestamp('2025-12-31T00:00:00Z')") {
+		t.Fatalf("Expected expiration clause in expression: %s", expression)
+	}
+
+	expiresAt, ok := ExtractBindingExpiration(expression)
+	if !ok || !expiresAt.Equal(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Failed to extract expiration from expression, got %s", expiresAt)
+	}
+
+	if _, ok := ExtractBindingExpiration("assertion.repository=='myorg/myrepo' && request.time < timestamp(assertion.exp)"); ok {
+		t.Error("Token expiry clause should not be treated as a binding expiration")
+	}
+}
+
+func TestSetBindingConditionExpiration(t *testing.T) {
+	c := &Client{}
+	config := &IAMBindingConfig{Repository: "myorg/myrepo", ExpirationTime: "2025-12-31T00:00:00Z"}
+	created := c.buildEnhancedIAMCondition(config)
+	condition := &iam.Expr{Title: created.Title, Description: created.Description, Expression: created.Expression}
+
+	setBindingConditionExpiration(condition, time.Date(2026, 3, 31, 12, 0, 0, 0, time.FixedZone("CET", 3600)))
+
+	if expiresAt, ok := ExtractBindingExpiration(condition.Expression); !ok || !expiresAt.Equal(time.Date(2026, 3, 31, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the expression to expire at 2026-03-31T11:00:00Z, got %s", condition.Expression)
+	}
+	if !strings.HasSuffix(condition.Description, "comprehensive security conditions until 2026-03-31T11:00:00Z") {
+		t.Errorf("Expected the description to state the new expiration, got %q", condition.Description)
+	}
+	if strings.Contains(condition.Description, "2025-12-31") || strings.Contains(condition.Expression, "2025-12-31") {
+		t.Errorf("Expected the previous expiration to be gone, got %q and %q", condition.Description, condition.Expression)
+	}
+
+	// A description that does not state the expiration is kept
+	condition.Description = "Custom binding"
+	setBindingConditionExpiration(condition, time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC))
+	if condition.Description != "Custom binding" {
+		t.Errorf("Expected a custom description to be kept, got %q", condition.Description)
+	}
+}
@@ -0,0 +1,158 @@
+package gcp
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParsedCondition holds the GitHub access policy inferred from a CEL attribute condition
+type ParsedCondition struct {
+	Repository        string   `json:"repository,omitempty"`
+	RepositoryOwner   string   `json:"repository_owner,omitempty"`
+	RepositoryID      int64    `json:"repository_id,omitempty"`
+	RepositoryOwnerID int64    `json:"repository_owner_id,omitempty"`
+	TrustedRepos      []string `json:"trusted_repos,omitempty"`
+	AllowedBranches   []string `json:"allowed_branches,omitempty"`
+	AllowedTags       []string `json:"allowed_tags,omitempty"`
+	AllowPullRequests bool     `json:"allow_pull_requests"`
+	RequireActor      bool     `json:"require_actor"`
+	ValidateTokenPath bool     `json:"validate_token_path"`
+	Unrecognized      []string `json:"unrecognized,omitempty"` // Clauses that could not be mapped to config fields
+}
+
+// Regular expressions for the clauses produced by buildGitHubSecurityConditions and
+// buildComprehensiveSecurityExpression. Both assertion.* and attribute.* forms are accepted.
+var (
+	conditionRepositoryRegex   = regexp.MustCompile(`(?:assertion|attribute)\.repository\s*==\s*['"]([^'"]+)['"]`)
+	conditionOwnerRegex        = regexp.MustCompile(`(?:assertion|attribute)\.repository_owner\s*==\s*['"]([^'"]+)['"]`)
+	conditionRefEqualsRegex    = regexp.MustCompile(`(?:assertion|attribute)\.ref\s*==\s*['"]refs/(heads|tags)/([^'"]+)['"]`)
+	conditionRefMatchesRegex   = regexp.MustCompile(`(?:assertion|attribute)\.ref\.matches\(\s*['"]\^?refs/(heads|tags)/([^'"]+?)\$?['"]\s*\)`)
+	conditionPullRequestRegex  = regexp.MustCompile(`(?:assertion|attribute)\.ref\.startsWith\(\s*['"]refs/pull/`)
+	conditionActorRegex        = regexp.MustCompile(`has\(\s*assertion\.actor\s*\)`)
+	conditionWorkflowPathRegex = regexp.MustCompile(`assertion\.job_workflow_ref\.startsWith\(`)
+	conditionClauseSplitRegex  = regexp.MustCompile(`\s*(?:&&|\|\|)\s*`)
+)
+
+// knownConditionClauses matches clauses that the parser understands or that are
+// emitted by this tool without carrying repository policy.
+var knownConditionClauses = []*regexp.Regexp{
+	conditionRepositoryRegex,
+	conditionOwnerRegex,
+	repositoryIDRegex,
+	repositoryOwnerIDRegex,
+	conditionRefEqualsRegex,
+	conditionRefMatchesRegex,
+	conditionPullRequestRegex,
+	conditionActorRegex,
+	conditionWorkflowPathRegex,
+	regexp.MustCompile(`assertion\.base_ref\s*==`),
+	regexp.MustCompile(`has\(\s*assertion\.pull_request\s*\)`),
+	regexp.MustCompile(`assertion\.environment`),
+	regexp.MustCompile(`request\.time\s*<\s*timestamp\(`),
+}
+
+// ParseAttributeCondition infers repository, branch, tag and pull request policy from a
+// CEL attribute condition. It is the inverse of buildGitHubSecurityConditions and is
+// tolerant of hand-written conditions; clauses it cannot map are reported in Unrecognized.
+func ParseAttributeCondition(condition string) *ParsedCondition {
+	parsed := &ParsedCondition{}
+	if strings.TrimSpace(condition) == "" {
+		return parsed
+	}
+
+	for _, match := range conditionRepositoryRegex.FindAllStringSubmatch(condition, -1) {
+		repo := match[1]
+		if parsed.Repository == "" {
+			parsed.Repository = repo
+			continue
+		}
+		if repo != parsed.Repository && !containsString(parsed.TrustedRepos, repo) {
+			parsed.TrustedRepos = append(parsed.TrustedRepos, repo)
+		}
+	}
+
+	if match := conditionOwnerRegex.FindStringSubmatch(condition); match != nil {
+		parsed.RepositoryOwner = match[1]
+	}
+	if match := repositoryIDRegex.FindStringSubmatch(condition); match != nil {
+		parsed.RepositoryID, _ = strconv.ParseInt(match[1], 10, 64)
+	}
+	if match := repositoryOwnerIDRegex.FindStringSubmatch(condition); match != nil {
+		parsed.RepositoryOwnerID, _ = strconv.ParseInt(match[1], 10, 64)
+	}
+
+	for _, match := range conditionRefEqualsRegex.FindAllStringSubmatch(condition, -1) {
+		parsed.addRef(match[1], match[2])
+	}
+
+	for _, match := range conditionRefMatchesRegex.FindAllStringSubmatch(condition, -1) {
+		// Convert the regular expression back to the wildcard form used in config
+		parsed.addRef(match[1], strings.ReplaceAll(match[2], ".*", "*"))
+	}
+
+	parsed.AllowPullRequests = conditionPullRequestRegex.MatchString(condition)
+	parsed.RequireActor = conditionActorRegex.MatchString(condition)
+	parsed.ValidateTokenPath = conditionWorkflowPathRegex.MatchString(condition)
+
+	for _, clause := range conditionClauseSplitRegex.Split(condition, -1) {
+		clause = trimClauseParens(clause)
+		if clause == "" {
+			continue
+		}
+		known := false
+		for _, pattern := range knownConditionClauses {
+			if pattern.MatchString(clause) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			parsed.Unrecognized = append(parsed.Unrecognized, clause)
+		}
+	}
+
+	return parsed
+}
+
+// addRef records a branch or tag reference, skipping duplicates
+func (p *ParsedCondition) addRef(kind, name string) {
+	switch kind {
+	case "heads":
+		if !containsString(p.AllowedBranches, name) {
+			p.AllowedBranches = append(p.AllowedBranches, name)
+		}
+	case "tags":
+		if !containsString(p.AllowedTags, name) {
+			p.AllowedTags = append(p.AllowedTags, name)
+		}
+	}
+}
+
+// trimClauseParens strips grouping parentheses left over from splitting on logical operators
+func trimClauseParens(clause string) string {
+	clause = strings.TrimLeft(strings.TrimSpace(clause), "(")
+	for strings.HasSuffix(clause, ")") && strings.Count(clause, ")") > strings.Count(clause, "(") {
+		clause = strings.TrimSuffix(clause, ")")
+	}
+	return strings.TrimSpace(clause)
+}
+
+// RepositoryParts splits the inferred repository into owner and name
+func (p *ParsedCondition) RepositoryParts() (string, string) {
+	parts := strings.SplitN(p.Repository, "/", 2)
+	if len(parts) != 2 {
+		return p.Repository, ""
+	}
+	return parts[0], parts[1]
+}
+
+// containsString checks if a slice contains a string
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package gcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAttributeCondition_GeneratedCondition(t *testing.T) {
+	c := &Client{}
+	condition := c.buildGitHubSecurityConditions(&SecurityConditions{
+		Repository:        "myorg/myrepo",
+		AllowedBranches:   []string{"main", "release"},
+		AllowedTags:       []string{"v*"},
+		AllowPullRequests: true,
+	}, GetDefaultGitHubOIDCConfig())
+
+	parsed := ParseAttributeCondition(condition)
+
+	if parsed.Repository != "myorg/myrepo" {
+		t.Errorf("Expected repository myorg/myrepo, got %s", parsed.Repository)
+	}
+	if parsed.RepositoryOwner != "myorg" {
+		t.Errorf("Expected repository owner myorg, got %s", parsed.RepositoryOwner)
+	}
+	if !reflect.DeepEqual(parsed.AllowedBranches, []string{"main", "release"}) {
+		t.Errorf("Unexpected branches: %v", parsed.AllowedBranches)
+	}
+	if !reflect.DeepEqual(parsed.AllowedTags, []string{"v*"}) {
+		t.Errorf("Unexpected tags: %v", parsed.AllowedTags)
+	}
+	if !parsed.AllowPullRequests {
+		t.Error("Expected pull requests to be allowed")
+	}
+	if !parsed.RequireActor || !parsed.ValidateTokenPath {
+		t.Error("Expected actor and token path checks to be detected")
+	}
+	if len(parsed.Unrecognized) != 0 {
+		t.Errorf("Expected no unrecognized clauses, got %v", parsed.Unrecognized)
+	}
+}
+
+func TestParseAttributeCondition_HandWritten(t *testing.T) {
+	parsed := ParseAttributeCondition(`attribute.repository == "acme/api" && assertion.workflow == "deploy"`)
+
+	if parsed.Repository != "acme/api" {
+		t.Errorf("Expected repository acme/api, got %s", parsed.Repository)
+	}
+	if parsed.AllowPullRequests {
+		t.Error("Expected pull requests to be disallowed")
+	}
+	if len(parsed.Unrecognized) != 1 || parsed.Unrecognized[0] != `assertion.workflow == "deploy"` {
+		t.Errorf("Expected workflow clause to be unrecognized, got %v", parsed.Unrecognized)
+	}
+
+	owner, name := parsed.RepositoryParts()
+	if owner != "acme" || name != "api" {
+		t.Errorf("Unexpected repository parts: %s, %s", owner, name)
+	}
+}
+
+func TestParseAttributeCondition_Empty(t *testing.T) {
+	parsed := ParseAttributeCondition("")
+	if parsed.Repository != "" || len(parsed.Unrecognized) != 0 {
+		t.Errorf("Expected empty result, got %+v", parsed)
+	}
+}
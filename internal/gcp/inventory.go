@@ -0,0 +1,419 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+)
+
+// DefaultInventoryParallelism is the number of projects scanned concurrently by default
+const DefaultInventoryParallelism = 4
+
+// InventoryEntry describes one path from a GitHub repository to a service account
+type InventoryEntry struct {
+	ProjectID         string `json:"project_id"`         // Project that owns the pool and provider
+	PoolID            string `json:"pool_id"`            // Workload identity pool ID
+	ProviderID        string `json:"provider_id"`        // Workload identity provider ID
+	Issuer            string `json:"issuer"`             // OIDC issuer URI of the provider
+	TrustedRepository string `json:"trusted_repository"` // Repository, "owner/*" or "*" when unrestricted
+	ServiceAccount    string `json:"service_account"`    // Bound service account email, empty when unbound
+	ServiceProject    string `json:"service_account_project,omitempty"`
+	Role              string `json:"role,omitempty"`
+	Disabled          bool   `json:"disabled"` // Pool or provider is disabled
+}
+
+// ProjectInventory holds the scan result for a single project
+type ProjectInventory struct {
+	ProjectID       string `json:"project_id"`
+	Pools           int    `json:"pools"`
+	Providers       int    `json:"providers"`
+	ServiceAccounts int    `json:"federated_service_accounts"`
+	Error           string `json:"error,omitempty"`
+	// BindingsError reports service account bindings that could not be scanned in a project
+	// whose pools and providers were
+	BindingsError string `json:"bindings_error,omitempty"`
+}
+
+// FleetInventory is the consolidated workload identity inventory of several projects
+type FleetInventory struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Projects    []ProjectInventory `json:"projects"`
+	Entries     []InventoryEntry   `json:"entries"`
+}
+
+// inventoryMemberProjectRegex extracts the project number hosting the pool from a principal member
+var inventoryMemberProjectRegex = regexp.MustCompile(`iam\.googleapis\.com/projects/([^/]+)/locations/`)
+
+// ListFolderProjects lists the active projects under a folder, optionally including sub-folders
+func ListFolderProjects(folderID string, recursive bool) ([]string, error) {
+	logger := logging.WithField("function", "ListFolderProjects")
+	logger.Debug("Listing folder projects", "folder_id", folderID, "recursive", recursive)
+
+	folderID = strings.TrimPrefix(folderID, "folders/")
+
+	cmd := exec.Command("gcloud", "projects", "list",
+		"--filter", fmt.Sprintf("parent.type=folder AND parent.id=%s AND lifecycleState=ACTIVE", folderID),
+		"--format", "json")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeGCP, "FOLDER_PROJECTS_LIST_FAILED",
+			fmt.Sprintf("Failed to list projects in folder %s: %s", folderID, string(output)))
+	}
+
+	var projectsData []map[string]interface{}
+	if err := json.Unmarshal(output, &projectsData); err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeGCP, "FOLDER_PROJECTS_PARSE_FAILED",
+			"Failed to parse folder projects list")
+	}
+
+	var projects []string
+	for _, projectData := range projectsData {
+		if projectID, ok := projectData["projectId"].(string); ok {
+			projects = append(projects, projectID)
+		}
+	}
+
+	if !recursive {
+		return projects, nil
+	}
+
+	cmd = exec.Command("gcloud", "resource-manager", "folders", "list",
+		"--folder", folderID,
+		"--format", "json")
+	output, err = cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeGCP, "FOLDER_LIST_FAILED",
+			fmt.Sprintf("Failed to list sub-folders of folder %s: %s", folderID, string(output)))
+	}
+
+	var foldersData []map[string]interface{}
+	if err := json.Unmarshal(output, &foldersData); err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeGCP, "FOLDER_LIST_PARSE_FAILED",
+			"Failed to parse sub-folder list")
+	}
+
+	for _, folderData := range foldersData {
+		name, ok := folderData["name"].(string)
+		if !ok {
+			continue
+		}
+		subProjects, err := ListFolderProjects(name, true)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, subProjects...)
+	}
+
+	logger.Debug("Folder projects listed", "folder_id", folderID, "count", len(projects))
+	return projects, nil
+}
+
+// CollectFleetInventory scans the given projects concurrently, at most parallelism at a
+// time, and builds a consolidated inventory. Projects that cannot be scanned are reported
+// with an error instead of failing the whole inventory, and projects whose service account
+// bindings cannot be read still report their pools and providers.
+func CollectFleetInventory(ctx context.Context, projectIDs []string, parallelism int) *FleetInventory {
+	logger := logging.WithField("function", "CollectFleetInventory")
+	if parallelism < 1 {
+		parallelism = DefaultInventoryParallelism
+	}
+	logger.Info("Collecting fleet inventory", "projects", len(projectIDs), "parallelism", parallelism)
+
+	scans := make([]fleetProjectScan, len(projectIDs))
+
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, projectID := range projectIDs {
+		wg.Add(1)
+		go func(i int, projectID string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			scan := &scans[i]
+			scan.projectID = projectID
+			client, err := NewClient(ctx, projectID)
+			if err != nil {
+				scan.err = err
+				return
+			}
+			defer client.Close()
+
+			snapshot := client.newAuditSnapshot()
+			if err := client.collectWorkloadIdentityPools(snapshot); err != nil {
+				scan.err = err
+				return
+			}
+			scan.snapshot = snapshot
+
+			unreadable, err := client.collectFederatedServiceAccounts(snapshot, nil)
+			switch {
+			case err != nil:
+				scan.bindingsError = fmt.Sprintf("service account bindings not scanned: %v", err)
+			case len(unreadable) > 0:
+				scan.bindingsError = fmt.Sprintf("bindings of %d service account(s) could not be read: %s",
+					len(unreadable), strings.Join(unreadable, ", "))
+			}
+		}(i, projectID)
+	}
+	wg.Wait()
+
+	inventory := assembleFleetInventory(scans)
+	logger.Info("Fleet inventory collected", "projects", len(inventory.Projects), "entries", len(inventory.Entries))
+	return inventory
+}
+
+// fleetProjectScan is the outcome of scanning one project of a fleet: its snapshot, unless
+// its pools could not be listed, and why its bindings are incomplete
+type fleetProjectScan struct {
+	projectID     string
+	snapshot      *AuditSnapshot
+	bindingsError string
+	err           error
+}
+
+// assembleFleetInventory builds the inventory of the scanned projects, reporting the
+// providers of projects whose bindings are incomplete and the projects that failed
+func assembleFleetInventory(scans []fleetProjectScan) *FleetInventory {
+	logger := logging.WithField("function", "assembleFleetInventory")
+
+	snapshots := make([]*AuditSnapshot, len(scans))
+	bindingErrors := make(map[string]string)
+	for i, scan := range scans {
+		snapshots[i] = scan.snapshot
+		if scan.snapshot != nil && scan.bindingsError != "" {
+			bindingErrors[scan.projectID] = scan.bindingsError
+		}
+	}
+
+	inventory := BuildFleetInventory(snapshots)
+	for i := range inventory.Projects {
+		project := &inventory.Projects[i]
+		if bindingsError, ok := bindingErrors[project.ProjectID]; ok {
+			logger.Warn("Failed to scan service account bindings", "project_id", project.ProjectID, "error", bindingsError)
+			project.BindingsError = bindingsError
+		}
+	}
+
+	// Record projects that failed in input order
+	for _, scan := range scans {
+		if scan.err == nil {
+			continue
+		}
+		logger.Warn("Failed to scan project", "project_id", scan.projectID, "error", scan.err)
+		inventory.Projects = append(inventory.Projects, ProjectInventory{
+			ProjectID: scan.projectID,
+			Error:     scan.err.Error(),
+		})
+	}
+	sort.SliceStable(inventory.Projects, func(i, j int) bool {
+		return inventory.Projects[i].ProjectID < inventory.Projects[j].ProjectID
+	})
+	return inventory
+}
+
+// BuildFleetInventory correlates pools, providers and service account bindings across
+// snapshots. Bindings may reference pools hosted in another scanned project, which are
+// resolved through the project number in the principal member.
+func BuildFleetInventory(snapshots []*AuditSnapshot) *FleetInventory {
+	inventory := &FleetInventory{
+		GeneratedAt: time.Now().UTC(),
+		Projects:    []ProjectInventory{},
+		Entries:     []InventoryEntry{},
+	}
+
+	type poolRef struct {
+		projectID string
+		pool      *WorkloadIdentityPoolInfo
+		providers []*WorkloadIdentityProviderInfo
+	}
+	pools := make(map[string]*poolRef) // keyed by "<project number or ID>/<pool ID>"
+	bound := make(map[string]bool)     // provider resource names with at least one binding
+
+	for _, snapshot := range snapshots {
+		if snapshot == nil {
+			continue
+		}
+		project := ProjectInventory{
+			ProjectID:       snapshot.ProjectID,
+			Pools:           len(snapshot.Pools),
+			ServiceAccounts: len(snapshot.ServiceAccounts),
+		}
+		for _, pool := range snapshot.Pools {
+			poolID := path.Base(pool.Name)
+			ref := &poolRef{projectID: snapshot.ProjectID, pool: pool, providers: snapshot.Providers[poolID]}
+			project.Providers += len(ref.providers)
+			pools[snapshot.ProjectID+"/"+poolID] = ref
+			if snapshot.ProjectNumber != "" {
+				pools[snapshot.ProjectNumber+"/"+poolID] = ref
+			}
+		}
+		inventory.Projects = append(inventory.Projects, project)
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot == nil {
+			continue
+		}
+		for _, sa := range snapshot.ServiceAccounts {
+			for _, binding := range sa.Bindings {
+				poolProject := snapshot.ProjectID
+				if match := inventoryMemberProjectRegex.FindStringSubmatch(binding.Member); match != nil {
+					poolProject = match[1]
+				}
+
+				base := InventoryEntry{
+					PoolID:         binding.PoolID,
+					ServiceAccount: sa.Email,
+					ServiceProject: snapshot.ProjectID,
+					Role:           binding.Role,
+				}
+
+				ref, ok := pools[poolProject+"/"+binding.PoolID]
+				if !ok {
+					// Pool lives in a project outside the scan
+					base.ProjectID = poolProject
+					base.ProviderID = binding.ProviderID
+					for _, repo := range inventoryBindingRepositories(binding, nil) {
+						entry := base
+						entry.TrustedRepository = repo
+						inventory.Entries = append(inventory.Entries, entry)
+					}
+					continue
+				}
+
+				base.ProjectID = ref.projectID
+				for _, provider := range ref.providers {
+					providerID := path.Base(provider.Name)
+					if binding.ProviderID != "" && binding.ProviderID != providerID {
+						continue
+					}
+					bound[provider.Name] = true
+
+					parsed := ParseAttributeCondition(provider.AttributeCondition)
+					for _, repo := range inventoryBindingRepositories(binding, parsed) {
+						entry := base
+						entry.ProviderID = providerID
+						entry.Issuer = provider.IssuerURI
+						entry.TrustedRepository = repo
+						entry.Disabled = ref.pool.Disabled || provider.Disabled
+						inventory.Entries = append(inventory.Entries, entry)
+					}
+				}
+			}
+		}
+	}
+
+	// Providers without any binding are still listed so the inventory covers every issuer
+	seen := make(map[*poolRef]bool)
+	for _, ref := range pools {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		for _, provider := range ref.providers {
+			if bound[provider.Name] {
+				continue
+			}
+			for _, repo := range inventoryConditionRepositories(ParseAttributeCondition(provider.AttributeCondition)) {
+				inventory.Entries = append(inventory.Entries, InventoryEntry{
+					ProjectID:         ref.projectID,
+					PoolID:            path.Base(ref.pool.Name),
+					ProviderID:        path.Base(provider.Name),
+					Issuer:            provider.IssuerURI,
+					TrustedRepository: repo,
+					Disabled:          ref.pool.Disabled || provider.Disabled,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(inventory.Entries, func(i, j int) bool {
+		a, b := inventory.Entries[i], inventory.Entries[j]
+		if a.TrustedRepository != b.TrustedRepository {
+			return a.TrustedRepository < b.TrustedRepository
+		}
+		if a.ProjectID != b.ProjectID {
+			return a.ProjectID < b.ProjectID
+		}
+		if a.ProviderID != b.ProviderID {
+			return a.ProviderID < b.ProviderID
+		}
+		return a.ServiceAccount < b.ServiceAccount
+	})
+
+	return inventory
+}
+
+// inventoryBindingRepositories returns the repositories that can use a binding. The
+// principal member narrows access first; pool-wide members fall back to the binding
+// condition and then to the provider attribute condition.
+func inventoryBindingRepositories(binding WorkloadIdentityBinding, provider *ParsedCondition) []string {
+	if idx := strings.Index(binding.Member, "/attribute.repository/"); idx != -1 {
+		return []string{binding.Member[idx+len("/attribute.repository/"):]}
+	}
+	for _, attribute := range []string{"/attribute.repository_ref/", "/attribute.repository_environment/"} {
+		if idx := strings.Index(binding.Member, attribute); idx != -1 {
+			repository, _, _ := strings.Cut(binding.Member[idx+len(attribute):], "@")
+			return []string{repository}
+		}
+	}
+	if idx := strings.Index(binding.Member, "/attribute.repository_owner/"); idx != -1 {
+		return []string{binding.Member[idx+len("/attribute.repository_owner/"):] + "/*"}
+	}
+	if binding.Condition != nil && binding.Condition.Expression != "" {
+		if repos := inventoryConditionRepositories(ParseAttributeCondition(binding.Condition.Expression)); repos[0] != "*" {
+			return repos
+		}
+	}
+	if provider != nil {
+		return inventoryConditionRepositories(provider)
+	}
+	return []string{"*"}
+}
+
+// inventoryConditionRepositories returns the repositories admitted by a parsed condition
+func inventoryConditionRepositories(parsed *ParsedCondition) []string {
+	switch {
+	case parsed.Repository != "":
+		return append([]string{parsed.Repository}, parsed.TrustedRepos...)
+	case parsed.RepositoryOwner != "":
+		return []string{parsed.RepositoryOwner + "/*"}
+	default:
+		return []string{"*"}
+	}
+}
+
+// ToCSV renders the inventory entries as CSV with a header row
+func (f *FleetInventory) ToCSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	rows := [][]string{{
+		"trusted_repository", "project_id", "pool_id", "provider_id", "issuer",
+		"service_account", "service_account_project", "role", "disabled",
+	}}
+	for _, entry := range f.Entries {
+		rows = append(rows, []string{
+			entry.TrustedRepository, entry.ProjectID, entry.PoolID, entry.ProviderID, entry.Issuer,
+			entry.ServiceAccount, entry.ServiceProject, entry.Role, fmt.Sprintf("%t", entry.Disabled),
+		})
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
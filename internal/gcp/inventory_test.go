@@ -0,0 +1,120 @@
+package gcp
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+)
+
+func TestBuildFleetInventory(t *testing.T) {
+	hostPool := "projects/111/locations/global/workloadIdentityPools/github-pool"
+	host := &AuditSnapshot{
+		ProjectID:     "wif-host",
+		ProjectNumber: "111",
+		Pools:         []*WorkloadIdentityPoolInfo{{Name: hostPool}},
+		Providers: map[string][]*WorkloadIdentityProviderInfo{
+			"github-pool": {
+				{
+					Name:               hostPool + "/providers/github",
+					IssuerURI:          "https://token.actions.githubusercontent.com",
+					AttributeCondition: "assertion.repository=='acme/api' || assertion.repository=='acme/web'",
+				},
+				{
+					Name:      hostPool + "/providers/unused",
+					IssuerURI: "https://token.actions.githubusercontent.com",
+				},
+			},
+		},
+	}
+	// Service account in another project bound to the host pool
+	app := &AuditSnapshot{
+		ProjectID: "app-prod",
+		ServiceAccounts: []AuditServiceAccount{
+			{
+				Email: "deployer@app-prod.iam.gserviceaccount.com",
+				Bindings: []WorkloadIdentityBinding{
+					{
+						Role:       "roles/iam.workloadIdentityUser",
+						Member:     "principalSet://iam.googleapis.com/" + hostPool + "/attribute.repository/acme/api",
+						PoolID:     "github-pool",
+						ProviderID: "github",
+					},
+				},
+			},
+		},
+	}
+
+	inventory := BuildFleetInventory([]*AuditSnapshot{host, app, nil})
+
+	if len(inventory.Projects) != 2 {
+		t.Fatalf("Expected 2 projects, got %d", len(inventory.Projects))
+	}
+
+	var bound, unbound []InventoryEntry
+	for _, entry := range inventory.Entries {
+		if entry.ServiceAccount != "" {
+			bound = append(bound, entry)
+		} else {
+			unbound = append(unbound, entry)
+		}
+	}
+
+	if len(bound) != 1 {
+		t.Fatalf("Expected 1 bound entry, got %+v", bound)
+	}
+	entry := bound[0]
+	if entry.TrustedRepository != "acme/api" || entry.ProjectID != "wif-host" || entry.ServiceProject != "app-prod" {
+		t.Errorf("Unexpected cross-project entry: %+v", entry)
+	}
+	if entry.Issuer != "https://token.actions.githubusercontent.com" {
+		t.Errorf("Expected issuer to be resolved from provider, got %q", entry.Issuer)
+	}
+
+	if len(unbound) != 1 || unbound[0].ProviderID != "unused" || unbound[0].TrustedRepository != "*" {
+		t.Errorf("Expected unconditioned unbound provider entry, got %+v", unbound)
+	}
+
+	data, err := inventory.ToCSV()
+	if err != nil {
+		t.Fatalf("Failed to render CSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(inventory.Entries)+1 || !strings.HasPrefix(lines[0], "trusted_repository,") {
+		t.Errorf("Unexpected CSV output:\n%s", string(data))
+	}
+}
+
+func TestAssembleFleetInventoryBindingsError(t *testing.T) {
+	pool := "projects/111/locations/global/workloadIdentityPools/github-pool"
+	scans := []fleetProjectScan{
+		{
+			projectID: "wif-host",
+			snapshot: &AuditSnapshot{
+				ProjectID: "wif-host",
+				Pools:     []*WorkloadIdentityPoolInfo{{Name: pool}},
+				Providers: map[string][]*WorkloadIdentityProviderInfo{
+					"github-pool": {{Name: pool + "/providers/github", AttributeCondition: "assertion.repository=='acme/api'"}},
+				},
+			},
+			bindingsError: "service account bindings not scanned: permission denied",
+		},
+		{projectID: "locked", err: stderrors.New("permission denied")},
+	}
+
+	inventory := assembleFleetInventory(scans)
+
+	if len(inventory.Projects) != 2 {
+		t.Fatalf("Expected 2 projects, got %+v", inventory.Projects)
+	}
+	host, locked := inventory.Projects[1], inventory.Projects[0]
+	if host.ProjectID != "wif-host" || host.Pools != 1 || host.Providers != 1 || host.Error != "" ||
+		host.BindingsError != "service account bindings not scanned: permission denied" {
+		t.Errorf("Expected the host's providers with its bindings error, got %+v", host)
+	}
+	if locked.ProjectID != "locked" || locked.Error != "permission denied" {
+		t.Errorf("Expected the failed project to be reported, got %+v", locked)
+	}
+	if len(inventory.Entries) != 1 || inventory.Entries[0].ProviderID != "github" || inventory.Entries[0].TrustedRepository != "acme/api" {
+		t.Errorf("Expected the provider of the project with a bindings error, got %+v", inventory.Entries)
+	}
+}
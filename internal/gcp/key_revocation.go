@@ -0,0 +1,49 @@
+package gcp
+
+import "time"
+
+// DefaultKeyRevocationGracePeriod is how long keys stay disabled before they are deleted
+const DefaultKeyRevocationGracePeriod = 7 * 24 * time.Hour
+
+// KeyRevocationPlan describes the actions needed to revoke a service account's keys
+type KeyRevocationPlan struct {
+	Disable []ServiceAccountKeyInfo `json:"disable"` // Active keys to disable now
+	Delete  []ServiceAccountKeyInfo `json:"delete"`  // Keys whose grace period has elapsed
+	Pending []ServiceAccountKeyInfo `json:"pending"` // Disabled keys still inside the grace period
+	// DeleteAfter maps pending key names to the time they become eligible for deletion
+	DeleteAfter map[string]time.Time `json:"delete_after"`
+}
+
+// PlanKeyRevocation decides which keys to disable and delete. Keys are disabled first and
+// only deleted once they have been disabled for the grace period, so a workload that still
+// depends on a key fails recoverably before the key is gone. disabledAt records when keys
+// were disabled by earlier runs; disabled keys without a record start their grace period now.
+// A zero grace period disables and deletes every key immediately.
+func PlanKeyRevocation(keys []ServiceAccountKeyInfo, disabledAt map[string]time.Time, now time.Time, gracePeriod time.Duration) *KeyRevocationPlan {
+	plan := &KeyRevocationPlan{DeleteAfter: make(map[string]time.Time)}
+
+	for _, key := range keys {
+		if !key.Disabled {
+			plan.Disable = append(plan.Disable, key)
+			if gracePeriod <= 0 {
+				plan.Delete = append(plan.Delete, key)
+			} else {
+				plan.DeleteAfter[key.Name] = now.Add(gracePeriod)
+			}
+			continue
+		}
+
+		start, ok := disabledAt[key.Name]
+		if !ok {
+			start = now
+		}
+		if deleteAfter := start.Add(gracePeriod); now.Before(deleteAfter) {
+			plan.Pending = append(plan.Pending, key)
+			plan.DeleteAfter[key.Name] = deleteAfter
+			continue
+		}
+		plan.Delete = append(plan.Delete, key)
+	}
+
+	return plan
+}
@@ -0,0 +1,42 @@
+package gcp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanKeyRevocation(t *testing.T) {
+	now := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	grace := 7 * 24 * time.Hour
+
+	keys := []ServiceAccountKeyInfo{
+		{Name: "keys/active", Disabled: false},
+		{Name: "keys/recent", Disabled: true},
+		{Name: "keys/old", Disabled: true},
+		{Name: "keys/unknown", Disabled: true},
+	}
+	disabledAt := map[string]time.Time{
+		"keys/recent": now.Add(-24 * time.Hour),
+		"keys/old":    now.Add(-8 * 24 * time.Hour),
+	}
+
+	plan := PlanKeyRevocation(keys, disabledAt, now, grace)
+
+	if len(plan.Disable) != 1 || plan.Disable[0].Name != "keys/active" {
+		t.Errorf("Expected active key to be disabled, got %+v", plan.Disable)
+	}
+	if len(plan.Delete) != 1 || plan.Delete[0].Name != "keys/old" {
+		t.Errorf("Expected only the old key to be deleted, got %+v", plan.Delete)
+	}
+	if len(plan.Pending) != 2 {
+		t.Errorf("Expected recent and unknown keys to be pending, got %+v", plan.Pending)
+	}
+	if !plan.DeleteAfter["keys/unknown"].Equal(now.Add(grace)) {
+		t.Errorf("Expected unrecorded key grace period to start now, got %s", plan.DeleteAfter["keys/unknown"])
+	}
+
+	immediate := PlanKeyRevocation(keys, nil, now, 0)
+	if len(immediate.Disable) != 1 || len(immediate.Delete) != len(keys) || len(immediate.Pending) != 0 {
+		t.Errorf("Expected zero grace period to delete every key, got %+v", immediate)
+	}
+}
@@ -0,0 +1,182 @@
+package gcp
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// repositoryIDRegex and repositoryOwnerIDRegex match the clauses pinning a condition to the
+// immutable IDs of the repository and its owner
+var (
+	repositoryIDRegex      = regexp.MustCompile(`(?:assertion|attribute)\.repository_id\s*==\s*['"](\d+)['"]`)
+	repositoryOwnerIDRegex = regexp.MustCompile(`(?:assertion|attribute)\.repository_owner_id\s*==\s*['"](\d+)['"]`)
+)
+
+// RepositoryCondition returns the clause matching tokens of the repository. With its ID the
+// clause also requires the immutable repository_id claim, so a repository that is renamed,
+// or deleted, and whose name is registered again by someone else does not match.
+func RepositoryCondition(repository string, repositoryID int64) string {
+	condition := fmt.Sprintf("assertion.repository=='%s'", repository)
+	if repositoryID > 0 {
+		condition += fmt.Sprintf(" && assertion.repository_id=='%d'", repositoryID)
+	}
+	return condition
+}
+
+// RepositoryOwnerCondition returns the clause matching tokens of the repository's owner,
+// pinned to the owner's immutable ID when it is known
+func RepositoryOwnerCondition(owner string, ownerID int64) string {
+	condition := fmt.Sprintf("assertion.repository_owner=='%s'", owner)
+	if ownerID > 0 {
+		condition += fmt.Sprintf(" && assertion.repository_owner_id=='%d'", ownerID)
+	}
+	return condition
+}
+
+// PinRepositoryCondition pins the repository and owner name clauses of an existing
+// condition to their IDs. Conditions that are already pinned are returned unchanged.
+func PinRepositoryCondition(condition, repository string, repositoryID, ownerID int64) string {
+	if repositoryID > 0 && !repositoryIDRegex.MatchString(condition) {
+		condition = replaceNameClause(condition, "repository", repository, RepositoryCondition(repository, repositoryID))
+	}
+	if ownerID > 0 && !repositoryOwnerIDRegex.MatchString(condition) {
+		owner := strings.Split(repository, "/")[0]
+		condition = replaceNameClause(condition, "repository_owner", owner, RepositoryOwnerCondition(owner, ownerID))
+	}
+	return condition
+}
+
+// replaceNameClause replaces each assertion.<claim>=='<value>' clause, in either quote style
+func replaceNameClause(condition, claim, value, replacement string) string {
+	pattern := regexp.MustCompile(`assertion\.` + claim + `\s*==\s*['"]` + regexp.QuoteMeta(value) + `['"]`)
+	return pattern.ReplaceAllLiteralString(condition, replacement)
+}
+
+// RepositoryMember returns the principalSet attribute path selecting the repository's
+// identities, by ID when it is known
+func RepositoryMember(repository string, repositoryID int64) string {
+	if repositoryID > 0 {
+		return "attribute.repository_id/" + strconv.FormatInt(repositoryID, 10)
+	}
+	return "attribute.repository/" + repository
+}
+
+// PinAttributeMapping adds the repository and owner ID attributes to an attribute mapping,
+// returning whether it changed
+func PinAttributeMapping(mapping map[string]string) bool {
+	changed := false
+	for attribute, claim := range map[string]string{
+		"attribute.repository_id":       "assertion.repository_id",
+		"attribute.repository_owner_id": "assertion.repository_owner_id",
+	} {
+		if _, ok := mapping[attribute]; !ok {
+			mapping[attribute] = claim
+			changed = true
+		}
+	}
+	return changed
+}
+
+// PinWorkloadIdentityProvider pins the attribute condition of an existing provider to the
+// repository and owner IDs and maps their claims. It returns the condition before and after;
+// a provider that is already pinned is left untouched.
+func (c *Client) PinWorkloadIdentityProvider(poolID, providerID, repository string, repositoryID, ownerID int64) (string, string, error) {
+	logger := c.logger.WithField("function", "PinWorkloadIdentityProvider")
+
+	provider, err := c.GetWorkloadIdentityProviderInfo(poolID, providerID)
+	if err != nil {
+		return "", "", err
+	}
+	if provider == nil || !provider.Exists {
+		return "", "", errors.NewGCPError(fmt.Sprintf("Workload identity provider %s does not exist in pool %s", providerID, poolID),
+			"Run 'gcp-wif setup' to create it")
+	}
+
+	before := provider.AttributeCondition
+	after := PinRepositoryCondition(before, repository, repositoryID, ownerID)
+	mapping := make(map[string]string, len(provider.AttributeMapping)+2)
+	for attribute, claim := range provider.AttributeMapping {
+		mapping[attribute] = claim
+	}
+	if mappingChanged := PinAttributeMapping(mapping); after == before && !mappingChanged {
+		logger.Debug("Workload identity provider already pinned", "provider_id", providerID)
+		return before, after, nil
+	}
+
+	attributes := make([]string, 0, len(mapping))
+	for attribute, claim := range mapping {
+		attributes = append(attributes, attribute+"="+claim)
+	}
+	sort.Strings(attributes)
+
+	cmd := exec.Command("gcloud", "iam", "workload-identity-pools", "providers", "update-oidc", providerID,
+		"--project", c.ProjectID,
+		"--location", "global",
+		"--workload-identity-pool", poolID,
+		"--attribute-mapping", strings.Join(attributes, ","),
+		"--attribute-condition", after,
+		"--format", "json")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", "", errors.WrapError(err, errors.ErrorTypeGCP, "WI_PROVIDER_UPDATE_FAILED",
+			fmt.Sprintf("Failed to update workload identity provider %s: %s", providerID, string(output)))
+	}
+
+	logger.Info("Workload identity provider pinned to repository IDs",
+		"provider_id", providerID, "repository_id", repositoryID, "repository_owner_id", ownerID)
+	return before, after, nil
+}
+
+// repositoryIDMigration returns the principal set a service account binding selects the
+// repository by name, and the one selecting it by ID that replaces it. Bindings to other
+// principal sets, or of repositories whose ID is unknown, have nothing to migrate.
+func (c *Client) repositoryIDMigration(config *WorkloadIdentityConfig) (from, to string, ok bool) {
+	if config.RepositoryID <= 0 || len(config.PrincipalSets) > 0 {
+		return "", "", false
+	}
+	return c.poolPrincipalSet(config.PoolID, RepositoryMember(config.Repository, 0)),
+		c.poolPrincipalSet(config.PoolID, RepositoryMember(config.Repository, config.RepositoryID)), true
+}
+
+// MigrateServiceAccountBindingToRepositoryID binds a service account to the principal set
+// of the repository's ID and removes its binding to the repository's name. It reports
+// whether there was a name binding to migrate.
+func (c *Client) MigrateServiceAccountBindingToRepositoryID(config *WorkloadIdentityConfig) (bool, error) {
+	logger := c.logger.WithField("function", "MigrateServiceAccountBindingToRepositoryID")
+
+	from, to, ok := c.repositoryIDMigration(config)
+	if !ok {
+		return false, nil
+	}
+	bindings, err := c.ListServiceAccountWorkloadIdentityBindings(config.ServiceAccountEmail)
+	if err != nil {
+		return false, err
+	}
+	bound := false
+	for _, binding := range bindings {
+		if binding.Member == from {
+			bound = true
+			break
+		}
+	}
+	if !bound {
+		logger.Debug("Service account not bound by repository name", "service_account", config.ServiceAccountEmail)
+		return false, nil
+	}
+
+	if err := c.BindServiceAccountToWorkloadIdentity(config); err != nil {
+		return false, err
+	}
+	if err := c.removePrincipalSetBindings(config.ServiceAccountEmail, []string{from}); err != nil {
+		return false, err
+	}
+
+	logger.Info("Service account binding migrated to repository ID",
+		"service_account", config.ServiceAccountEmail, "member", to)
+	return true, nil
+}
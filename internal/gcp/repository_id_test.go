@@ -0,0 +1,72 @@
+package gcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPinRepositoryCondition(t *testing.T) {
+	condition := `assertion.repository=="acme/app" && assertion.ref=='refs/heads/main'`
+	pinned := PinRepositoryCondition(condition, "acme/app", 123, 456)
+	want := "assertion.repository=='acme/app' && assertion.repository_id=='123' && assertion.ref=='refs/heads/main'"
+	if pinned != want {
+		t.Errorf("Expected %s, got %s", want, pinned)
+	}
+	if again := PinRepositoryCondition(pinned, "acme/app", 123, 456); again != pinned {
+		t.Errorf("Expected a pinned condition to be left unchanged, got %s", again)
+	}
+
+	owner := PinRepositoryCondition("assertion.repository_owner=='acme'", "acme/app", 123, 456)
+	if owner != "assertion.repository_owner=='acme' && assertion.repository_owner_id=='456'" {
+		t.Errorf("Expected the owner clause to be pinned, got %s", owner)
+	}
+
+	if RepositoryMember("acme/app", 123) != "attribute.repository_id/123" || RepositoryMember("acme/app", 0) != "attribute.repository/acme/app" {
+		t.Error("Expected principal sets to select the repository by ID when it is known")
+	}
+}
+
+func TestParseAttributeCondition_RepositoryIDs(t *testing.T) {
+	c := &Client{}
+	condition := c.buildGitHubSecurityConditions(&SecurityConditions{
+		Repository:        "myorg/myrepo",
+		RepositoryID:      123,
+		RepositoryOwnerID: 456,
+		AllowedBranches:   []string{"main"},
+	}, GetDefaultGitHubOIDCConfig())
+	if !strings.Contains(condition, "assertion.repository_id=='123'") {
+		t.Errorf("Expected the condition to be pinned to the repository ID, got %s", condition)
+	}
+
+	parsed := ParseAttributeCondition(condition)
+	if parsed.Repository != "myorg/myrepo" || parsed.RepositoryID != 123 {
+		t.Errorf("Expected repository myorg/myrepo with ID 123, got %s with ID %d", parsed.Repository, parsed.RepositoryID)
+	}
+	if len(parsed.Unrecognized) != 0 {
+		t.Errorf("Expected no unrecognized clauses, got %v", parsed.Unrecognized)
+	}
+}
+
+func TestRepositoryIDMigration(t *testing.T) {
+	c := &Client{ProjectID: "test-project"}
+	pool := "principalSet://iam.googleapis.com/projects/test-project/locations/global/workloadIdentityPools/github-pool/"
+
+	from, to, ok := c.repositoryIDMigration(&WorkloadIdentityConfig{PoolID: "github-pool", Repository: "acme/app", RepositoryID: 123})
+	if !ok || from != pool+"attribute.repository/acme/app" || to != pool+"attribute.repository_id/123" {
+		t.Errorf("Expected the name principal set to move to the ID one, got %q -> %q, %v", from, to, ok)
+	}
+
+	// The new binding is the member setup itself binds
+	config := &IAMBindingConfig{PoolID: "github-pool", Repository: "acme/app", RepositoryID: 123}
+	if members := c.buildPrincipalSetMembers(config); len(members) != 1 || members[0] != to {
+		t.Errorf("Expected setup to bind %s, got %v", to, members)
+	}
+
+	if _, _, ok := c.repositoryIDMigration(&WorkloadIdentityConfig{PoolID: "github-pool", Repository: "acme/app"}); ok {
+		t.Error("Expected nothing to migrate without the repository ID")
+	}
+	if _, _, ok := c.repositoryIDMigration(&WorkloadIdentityConfig{PoolID: "github-pool", Repository: "acme/app", RepositoryID: 123,
+		PrincipalSets: []string{RepositoryRefMember("acme/app", "refs/heads/main")}}); ok {
+		t.Error("Expected bindings to other principal sets to be left alone")
+	}
+}
@@ -582,3 +582,98 @@ func (c *Client) UpdateServiceAccount(name, displayName, description string) (*i
 	logger.Info("Service account updated successfully", "name", name)
 	return serviceAccount, nil
 }
+
+// ServiceAccountKeyInfo holds information about a service account key
+type ServiceAccountKeyInfo struct {
+	Name        string    `json:"name"`
+	KeyID       string    `json:"key_id"`
+	KeyType     string    `json:"key_type"`
+	KeyOrigin   string    `json:"key_origin,omitempty"`
+	Disabled    bool      `json:"disabled"`
+	ValidAfter  time.Time `json:"valid_after"`
+	ValidBefore time.Time `json:"valid_before"`
+}
+
+// ListServiceAccountKeys lists the user-managed keys of a service account.
+// System-managed keys are rotated by Google and are not returned.
+func (c *Client) ListServiceAccountKeys(serviceAccountEmail string) ([]ServiceAccountKeyInfo, error) {
+	logger := c.logger.WithField("function", "ListServiceAccountKeys")
+	logger.Debug("Listing user-managed service account keys", "email", serviceAccountEmail)
+
+	serviceAccountName := fmt.Sprintf("projects/%s/serviceAccounts/%s", c.ProjectID, serviceAccountEmail)
+
+	response, err := c.IAMService.Projects.ServiceAccounts.Keys.List(serviceAccountName).
+		KeyTypes("USER_MANAGED").
+		Context(c.ctx).Do()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeGCP, "SA_KEYS_LIST_FAILED",
+			fmt.Sprintf("Failed to list keys for service account %s", serviceAccountEmail))
+	}
+
+	keys := make([]ServiceAccountKeyInfo, 0, len(response.Keys))
+	for _, key := range response.Keys {
+		info := ServiceAccountKeyInfo{
+			Name:      key.Name,
+			KeyID:     key.Name[strings.LastIndex(key.Name, "/")+1:],
+			KeyType:   key.KeyType,
+			KeyOrigin: key.KeyOrigin,
+			Disabled:  key.Disabled,
+		}
+		if validAfter, err := time.Parse(time.RFC3339, key.ValidAfterTime); err == nil {
+			info.ValidAfter = validAfter
+		}
+		if validBefore, err := time.Parse(time.RFC3339, key.ValidBeforeTime); err == nil {
+			info.ValidBefore = validBefore
+		}
+		keys = append(keys, info)
+	}
+
+	logger.Debug("Service account keys listed", "email", serviceAccountEmail, "count", len(keys))
+	return keys, nil
+}
+
+// ActiveServiceAccountKeys returns the keys that are not disabled
+func ActiveServiceAccountKeys(keys []ServiceAccountKeyInfo) []ServiceAccountKeyInfo {
+	var active []ServiceAccountKeyInfo
+	for _, key := range keys {
+		if !key.Disabled {
+			active = append(active, key)
+		}
+	}
+	return active
+}
+
+// DisableServiceAccountKey disables a user-managed key so it can no longer authenticate
+func (c *Client) DisableServiceAccountKey(keyName string) error {
+	logger := c.logger.WithField("function", "DisableServiceAccountKey")
+	logger.Info("Disabling service account key", "key", keyName)
+
+	_, err := c.IAMService.Projects.ServiceAccounts.Keys.Disable(keyName, &iam.DisableServiceAccountKeyRequest{}).
+		Context(c.ctx).Do()
+	if err != nil {
+		return errors.WrapError(err, errors.ErrorTypeGCP, "SA_KEY_DISABLE_FAILED",
+			fmt.Sprintf("Failed to disable service account key %s", keyName))
+	}
+
+	logger.Info("Service account key disabled", "key", keyName)
+	return nil
+}
+
+// DeleteServiceAccountKey permanently deletes a user-managed key
+func (c *Client) DeleteServiceAccountKey(keyName string) error {
+	logger := c.logger.WithField("function", "DeleteServiceAccountKey")
+	logger.Info("Deleting service account key", "key", keyName)
+
+	_, err := c.IAMService.Projects.ServiceAccounts.Keys.Delete(keyName).Context(c.ctx).Do()
+	if err != nil {
+		if strings.Contains(err.Error(), "404") || strings.Contains(err.Error(), "not found") {
+			logger.Debug("Service account key already deleted", "key", keyName)
+			return nil
+		}
+		return errors.WrapError(err, errors.ErrorTypeGCP, "SA_KEY_DELETE_FAILED",
+			fmt.Sprintf("Failed to delete service account key %s", keyName))
+	}
+
+	logger.Info("Service account key deleted", "key", keyName)
+	return nil
+}
@@ -0,0 +1,173 @@
+package gcp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// DefaultSubjectClaimKeys are the claims of GitHub's default subject, repo:OWNER/REPO:CONTEXT
+var DefaultSubjectClaimKeys = []string{"repo", "context"}
+
+// MaxSubjectLength is the longest google.subject a workload identity provider accepts
+const MaxSubjectLength = 127
+
+// subjectClaimKeys are the claims GitHub accepts in a customized subject template
+var subjectClaimKeys = map[string]bool{
+	"actor": true, "actor_id": true, "base_ref": true, "context": true, "enterprise": true,
+	"enterprise_id": true, "environment": true, "event_name": true, "head_ref": true,
+	"job_workflow_ref": true, "job_workflow_sha": true, "ref": true, "ref_type": true,
+	"repo": true, "repository": true, "repository_id": true, "repository_owner": true,
+	"repository_owner_id": true, "repository_visibility": true, "run_attempt": true,
+	"run_id": true, "run_number": true, "runner_environment": true, "sha": true,
+	"workflow": true, "workflow_ref": true, "workflow_sha": true,
+}
+
+// longSubjectClaims are claims whose values can push the subject past MaxSubjectLength
+var longSubjectClaims = map[string]bool{
+	"job_workflow_ref": true, "job_workflow_sha": true, "workflow_ref": true, "workflow_sha": true, "sha": true,
+}
+
+// subjectComparisonRegex matches the string literals a condition or mapping compares the
+// subject with, through either the assertion or the mapped google.subject
+var subjectComparisonRegex = regexp.MustCompile(
+	`(?:assertion\.sub|google\.subject)\s*(==|!=|\.startsWith\(|\.endsWith\(|\.matches\(|\.contains\()\s*['"]([^'"]*)['"]`)
+
+// SubjectClaims returns the claims the GitHub subject is built from, in order
+func (m *GitHubClaimsMapping) SubjectClaims() []string {
+	if m == nil || len(m.SubjectClaimKeys) == 0 {
+		return DefaultSubjectClaimKeys
+	}
+	return m.SubjectClaimKeys
+}
+
+// SubjectCustomized reports whether the subject uses a template other than GitHub's default
+func (m *GitHubClaimsMapping) SubjectCustomized() bool {
+	return strings.Join(m.SubjectClaims(), ",") != strings.Join(DefaultSubjectClaimKeys, ",")
+}
+
+// SubjectMayExceedLimit reports whether the subject template includes claims long enough
+// for google.subject to exceed MaxSubjectLength, which fails the token exchange
+func (m *GitHubClaimsMapping) SubjectMayExceedLimit() bool {
+	if m.Subject != "" && m.Subject != "assertion.sub" {
+		return false
+	}
+	for _, key := range m.SubjectClaims() {
+		if longSubjectClaims[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSubjectClaimKeys checks a subject template against the claims GitHub accepts
+func ValidateSubjectClaimKeys(keys []string) error {
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if !subjectClaimKeys[key] {
+			return errors.NewValidationError(fmt.Sprintf("'%s' is not a claim GitHub can include in the OIDC subject", key),
+				"Use claims such as repo, context, repository_owner_id, repository_id, job_workflow_ref or environment")
+		}
+		if seen[key] {
+			return errors.NewValidationError(fmt.Sprintf("Claim '%s' is listed twice in the OIDC subject template", key))
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// subjectSegments returns the claims a subject literal pins, in order. Anchored literals
+// that do not start with a claim name are not subjects this tool can reason about and
+// yield nil; others, such as suffixes, are read from their first claim name on.
+func subjectSegments(literal string, anchored bool) []string {
+	tokens := strings.Split(literal, ":")
+	start := 0
+	for !anchored && start < len(tokens) && !isSubjectSegment(tokens[start]) {
+		start++
+	}
+	var segments []string
+	for i := start; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token == "pull_request":
+			segments = append(segments, token)
+		case isSubjectSegment(token):
+			segments = append(segments, token)
+			i++ // skip the claim's value
+		default:
+			return segments
+		}
+	}
+	return segments
+}
+
+// isSubjectSegment reports whether a subject token names a claim
+func isSubjectSegment(token string) bool {
+	return token == "pull_request" || (subjectClaimKeys[token] && token != "context")
+}
+
+// subjectClaimIndex returns the position in the template of the claim that produces a
+// subject segment, or -1. The default context claim produces the environment, ref and
+// pull_request segments.
+func subjectClaimIndex(template []string, segment string) int {
+	for i, key := range template {
+		if key == segment {
+			return i
+		}
+	}
+	if segment == "environment" || segment == "ref" || segment == "pull_request" {
+		for i, key := range template {
+			if key == "context" {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ValidateSubjectConditions checks that the conditions and attribute mappings comparing the
+// subject only depend on claims the subject template includes, in the template's order.
+// Comparisons of other claims, such as assertion.environment, hold for any template.
+func (m *GitHubClaimsMapping) ValidateSubjectConditions(expressions ...string) error {
+	template := m.SubjectClaims()
+	for _, expression := range expressions {
+		for _, match := range subjectComparisonRegex.FindAllStringSubmatch(expression, -1) {
+			operator, literal := match[1], match[2]
+
+			// Equalities and prefixes pin the subject from its first claim on
+			anchored := operator == "==" || operator == "!=" || operator == ".startsWith("
+			segments := subjectSegments(literal, anchored)
+			if len(segments) == 0 {
+				continue
+			}
+			previous := -1
+			for _, segment := range segments {
+				index := subjectClaimIndex(template, segment)
+				if index < 0 {
+					return errors.NewValidationError(
+						fmt.Sprintf("Condition compares the subject with '%s', but the OIDC subject template (%s) does not include the %s claim",
+							literal, strings.Join(template, ", "), segment),
+						fmt.Sprintf("Compare assertion.%s instead of the subject", segment),
+						"Or add the claim to the subject template with 'gcp-wif github oidc-subject --set'")
+				}
+				if index <= previous || (anchored && index != previous+1) {
+					return errors.NewValidationError(
+						fmt.Sprintf("Condition compares the subject with '%s', but the OIDC subject template orders its claims %s",
+							literal, strings.Join(template, ", ")))
+				}
+				previous = index
+			}
+
+			// An equality has to cover every claim of the template to ever match
+			if (operator == "==" || operator == "!=") && previous != len(template)-1 {
+				return errors.NewValidationError(
+					fmt.Sprintf("Condition compares the subject with '%s', which never equals a subject built from %s",
+						literal, strings.Join(template, ", ")),
+					"Use assertion.sub.startsWith(...) to match a prefix of the subject")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package gcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSubjectConditions(t *testing.T) {
+	defaults := GetDefaultGitHubClaimsMapping()
+	custom := GetDefaultGitHubClaimsMapping()
+	custom.SubjectClaimKeys = []string{"repository_owner_id", "repository_id", "job_workflow_ref"}
+
+	tests := []struct {
+		name      string
+		mapping   *GitHubClaimsMapping
+		condition string
+		wantErr   string
+	}{
+		{"default subject with environment", defaults, "assertion.sub=='repo:acme/app:environment:production'", ""},
+		{"default subject prefix", defaults, "assertion.sub.startsWith('repo:acme/app:ref:')", ""},
+		{"claims other than the subject", custom, "assertion.repository=='acme/app' && assertion.environment=='production'", ""},
+		{"custom subject prefix", custom, "google.subject.startsWith('repository_owner_id:1:repository_id:2:')", ""},
+		{"dropped claim", custom, "assertion.sub.startsWith('repo:acme/app')", "does not include the repo claim"},
+		{"dropped context", custom, "assertion.sub.endsWith(':environment:production')", "does not include the environment claim"},
+		{"out of order", custom, "assertion.sub.startsWith('repository_id:2:repository_owner_id:1')", "orders its claims"},
+		{"partial equality", custom, "assertion.sub=='repository_owner_id:1:repository_id:2'", "never equals"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.mapping.ValidateSubjectConditions(tt.condition)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Expected the condition to hold, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSubjectClaimsAttributeMapping(t *testing.T) {
+	c := &Client{}
+	mapping := GetDefaultGitHubClaimsMapping()
+	defaultMapping := c.buildGitHubAttributeMapping(mapping)
+
+	mapping.SubjectClaimKeys = []string{"repository_id", "event_name", "job_workflow_ref"}
+	attributes := c.buildGitHubAttributeMapping(mapping)
+	if attributes != defaultMapping+",attribute.event_name=assertion.event_name" {
+		t.Errorf("Expected the subject's unmapped claims to be appended once, got %s", attributes)
+	}
+	if !mapping.SubjectCustomized() || !mapping.SubjectMayExceedLimit() {
+		t.Error("Expected a job_workflow_ref subject to be customized and possibly too long")
+	}
+
+	if err := ValidateSubjectClaimKeys([]string{"repo", "context"}); err != nil {
+		t.Errorf("Expected the default template to be valid, got %v", err)
+	}
+	if err := ValidateSubjectClaimKeys([]string{"repo", "branch"}); err == nil {
+		t.Error("Expected an unknown claim to be rejected")
+	}
+}
@@ -24,19 +24,29 @@ type GitHubOIDCConfig struct {
 
 // GitHubClaimsMapping holds mapping configuration for GitHub OIDC claims
 type GitHubClaimsMapping struct {
-	Subject           string `json:"subject"`            // assertion.sub
-	Actor             string `json:"actor"`              // assertion.actor
-	Repository        string `json:"repository"`         // assertion.repository
-	RepositoryOwner   string `json:"repository_owner"`   // assertion.repository_owner
-	Ref               string `json:"ref"`                // assertion.ref
-	RefType           string `json:"ref_type"`           // assertion.ref_type
-	BaseRef           string `json:"base_ref"`           // assertion.base_ref
-	HeadRef           string `json:"head_ref"`           // assertion.head_ref
-	PullRequest       string `json:"pull_request"`       // assertion.pull_request
-	WorkflowRef       string `json:"workflow_ref"`       // assertion.workflow_ref
-	JobWorkflowRef    string `json:"job_workflow_ref"`   // assertion.job_workflow_ref
-	RunnerEnvironment string `json:"runner_environment"` // assertion.runner_environment
-	Environment       string `json:"environment"`        // assertion.environment
+	Subject           string `json:"subject"`             // assertion.sub
+	Actor             string `json:"actor"`               // assertion.actor
+	Repository        string `json:"repository"`          // assertion.repository
+	RepositoryOwner   string `json:"repository_owner"`    // assertion.repository_owner
+	RepositoryID      string `json:"repository_id"`       // assertion.repository_id
+	RepositoryOwnerID string `json:"repository_owner_id"` // assertion.repository_owner_id
+	Ref               string `json:"ref"`                 // assertion.ref
+	RefType           string `json:"ref_type"`            // assertion.ref_type
+	BaseRef           string `json:"base_ref"`            // assertion.base_ref
+	HeadRef           string `json:"head_ref"`            // assertion.head_ref
+	PullRequest       string `json:"pull_request"`        // assertion.pull_request
+	WorkflowRef       string `json:"workflow_ref"`        // assertion.workflow_ref
+	JobWorkflowRef    string `json:"job_workflow_ref"`    // assertion.job_workflow_ref
+	RunnerEnvironment string `json:"runner_environment"`  // assertion.runner_environment
+	Environment       string `json:"environment"`         // assertion.environment
+	// RepositoryRef and RepositoryEnvironment qualify the ref and environment with the
+	// repository, so principal sets selecting them do not match other repositories' tokens
+	// in a shared pool
+	RepositoryRef         string `json:"repository_ref,omitempty"`
+	RepositoryEnvironment string `json:"repository_environment,omitempty"`
+	// SubjectClaimKeys is the repository's customized OIDC subject template, empty for
+	// GitHub's default repo:OWNER/REPO:CONTEXT subject
+	SubjectClaimKeys []string `json:"subject_claim_keys,omitempty"`
 }
 
 // WorkloadIdentityConfig holds configuration for workload identity setup
@@ -55,6 +65,19 @@ type WorkloadIdentityConfig struct {
 	CreateNew           bool                 `json:"create_new"`                 // Create new or use existing
 	GitHubOIDC          *GitHubOIDCConfig    `json:"github_oidc,omitempty"`      // GitHub-specific OIDC configuration
 	ClaimsMapping       *GitHubClaimsMapping `json:"claims_mapping,omitempty"`   // Custom claims mapping
+	ExpirationTime      string               `json:"expiration_time,omitempty"`  // Optional: RFC3339 time after which bindings stop granting access
+	// PrincipalSets selects the identities bound to the service account, as attribute paths
+	// within the pool such as attribute.repository_ref/owner/name@refs/heads/main. Principal
+	// sets span every provider in the pool, so when a pool holds providers with different
+	// conditions, such as separate terraform plan and apply providers, each service account
+	// is bound to an attribute only its own tokens carry. Empty binds the whole repository.
+	PrincipalSets []string `json:"principal_sets,omitempty"`
+	// AllowedEnvironments restricts the provider to jobs running in these GitHub environments
+	AllowedEnvironments []string `json:"allowed_environments,omitempty"`
+	// RepositoryID and RepositoryOwnerID pin conditions and principal sets to the immutable
+	// IDs of the repository and its owner, which survive renames
+	RepositoryID      int64 `json:"repository_id,omitempty"`
+	RepositoryOwnerID int64 `json:"repository_owner_id,omitempty"`
 }
 
 // WorkloadIdentityPoolInfo holds detailed information about a workload identity pool
@@ -88,9 +111,14 @@ type WorkloadIdentityProviderInfo struct {
 // SecurityConditions holds various security conditions for workload identity
 type SecurityConditions struct {
 	Repository        string   `json:"repository"`
+	RepositoryID      int64    `json:"repository_id,omitempty"`
+	RepositoryOwnerID int64    `json:"repository_owner_id,omitempty"`
 	AllowedBranches   []string `json:"allowed_branches,omitempty"`
 	AllowedTags       []string `json:"allowed_tags,omitempty"`
 	AllowPullRequests bool     `json:"allow_pull_requests"`
+	// AllowedEnvironments restricts tokens to jobs that run in one of these GitHub
+	// environments, so the environment's protection rules gate the identity
+	AllowedEnvironments []string `json:"allowed_environments,omitempty"`
 }
 
 // IAMBindingConfig holds configuration for IAM policy bindings
@@ -99,10 +127,13 @@ type IAMBindingConfig struct {
 	PoolID              string            `json:"pool_id"`
 	ProviderID          string            `json:"provider_id"`
 	Repository          string            `json:"repository"`
+	RepositoryID        int64             `json:"repository_id,omitempty"`
+	RepositoryOwnerID   int64             `json:"repository_owner_id,omitempty"`
 	AllowedBranches     []string          `json:"allowed_branches,omitempty"`
 	AllowedTags         []string          `json:"allowed_tags,omitempty"`
 	AllowPullRequests   bool              `json:"allow_pull_requests"`
 	GitHubOIDC          *GitHubOIDCConfig `json:"github_oidc,omitempty"`
+	PrincipalSets       []string          `json:"principal_sets,omitempty"`
 	BindingTitle        string            `json:"binding_title,omitempty"`
 	BindingDescription  string            `json:"binding_description,omitempty"`
 	ExpirationTime      string            `json:"expiration_time,omitempty"` // Optional binding expiration
@@ -161,6 +192,8 @@ func GetDefaultGitHubClaimsMapping() *GitHubClaimsMapping {
 		Actor:             "assertion.actor",
 		Repository:        "assertion.repository",
 		RepositoryOwner:   "assertion.repository_owner",
+		RepositoryID:      "assertion.repository_id",
+		RepositoryOwnerID: "assertion.repository_owner_id",
 		Ref:               "assertion.ref",
 		RefType:           "assertion.ref_type",
 		BaseRef:           "assertion.base_ref",
@@ -170,6 +203,9 @@ func GetDefaultGitHubClaimsMapping() *GitHubClaimsMapping {
 		JobWorkflowRef:    "assertion.job_workflow_ref",
 		RunnerEnvironment: "assertion.runner_environment",
 		Environment:       "assertion.environment",
+		RepositoryRef:     "assertion.repository + '@' + assertion.ref",
+		// Jobs without an environment map to the bare repository, which no binding selects
+		RepositoryEnvironment: "assertion.repository + '@' + (has(assertion.environment) ? assertion.environment : '')",
 	}
 }
 
@@ -512,12 +548,24 @@ func (c *Client) CreateWorkloadIdentityProvider(config *WorkloadIdentityConfig)
 
 	// Create enhanced attribute condition with security constraints
 	attributeCondition := c.buildGitHubSecurityConditions(&SecurityConditions{
-		Repository:        config.Repository,
-		AllowedBranches:   config.AllowedBranches,
-		AllowedTags:       config.AllowedTags,
-		AllowPullRequests: config.AllowPullRequests,
+		Repository:          config.Repository,
+		RepositoryID:        config.RepositoryID,
+		RepositoryOwnerID:   config.RepositoryOwnerID,
+		AllowedBranches:     config.AllowedBranches,
+		AllowedTags:         config.AllowedTags,
+		AllowPullRequests:   config.AllowPullRequests,
+		AllowedEnvironments: config.AllowedEnvironments,
 	}, oidcConfig)
 
+	// The condition must not depend on claims the repository's subject template drops
+	if err := claimsMapping.ValidateSubjectConditions(attributeCondition, attributeMapping); err != nil {
+		return nil, err
+	}
+	if claimsMapping.SubjectMayExceedLimit() {
+		logger.Warn("The customized OIDC subject may exceed the google.subject limit",
+			"subject_claims", strings.Join(claimsMapping.SubjectClaims(), ","), "limit", MaxSubjectLength)
+	}
+
 	// Get audience configuration
 	audiences := strings.Join(oidcConfig.AllowedAudiences, ",")
 
@@ -566,6 +614,12 @@ func (c *Client) buildGitHubAttributeMapping(claimsMapping *GitHubClaimsMapping)
 	mappings = append(mappings, fmt.Sprintf("attribute.actor=%s", claimsMapping.Actor))
 	mappings = append(mappings, fmt.Sprintf("attribute.repository=%s", claimsMapping.Repository))
 	mappings = append(mappings, fmt.Sprintf("attribute.repository_owner=%s", claimsMapping.RepositoryOwner))
+	if claimsMapping.RepositoryID != "" {
+		mappings = append(mappings, fmt.Sprintf("attribute.repository_id=%s", claimsMapping.RepositoryID))
+	}
+	if claimsMapping.RepositoryOwnerID != "" {
+		mappings = append(mappings, fmt.Sprintf("attribute.repository_owner_id=%s", claimsMapping.RepositoryOwnerID))
+	}
 	mappings = append(mappings, fmt.Sprintf("attribute.ref=%s", claimsMapping.Ref))
 
 	// Enhanced GitHub-specific mappings
@@ -587,20 +641,41 @@ func (c *Client) buildGitHubAttributeMapping(claimsMapping *GitHubClaimsMapping)
 	if claimsMapping.Environment != "" {
 		mappings = append(mappings, fmt.Sprintf("attribute.environment=%s", claimsMapping.Environment))
 	}
+	if claimsMapping.RepositoryRef != "" {
+		mappings = append(mappings, fmt.Sprintf("attribute.repository_ref=%s", claimsMapping.RepositoryRef))
+	}
+	if claimsMapping.RepositoryEnvironment != "" {
+		mappings = append(mappings, fmt.Sprintf("attribute.repository_environment=%s", claimsMapping.RepositoryEnvironment))
+	}
+
+	// Claims a customized subject includes are mapped too, so bindings can select on them
+	for _, key := range claimsMapping.SubjectClaims() {
+		if key == "repo" || key == "context" {
+			continue
+		}
+		attribute := fmt.Sprintf("attribute.%s=", key)
+		mapped := false
+		for _, mapping := range mappings {
+			mapped = mapped || strings.HasPrefix(mapping, attribute)
+		}
+		if !mapped {
+			mappings = append(mappings, attribute+"assertion."+key)
+		}
+	}
 
 	return strings.Join(mappings, ",")
 }
 
 // buildGitHubSecurityConditions builds enhanced security conditions for GitHub OIDC
 func (c *Client) buildGitHubSecurityConditions(conditions *SecurityConditions, oidcConfig *GitHubOIDCConfig) string {
-	baseCondition := fmt.Sprintf("assertion.repository=='%s'", conditions.Repository)
+	baseCondition := RepositoryCondition(conditions.Repository, conditions.RepositoryID)
 
 	var additionalConditions []string
 
 	// Add repository verification (prevent forked repo attacks if enabled)
 	if oidcConfig.BlockForkedRepos {
 		additionalConditions = append(additionalConditions,
-			fmt.Sprintf("assertion.repository_owner=='%s'", strings.Split(conditions.Repository, "/")[0]))
+			RepositoryOwnerCondition(strings.Split(conditions.Repository, "/")[0], conditions.RepositoryOwnerID))
 	}
 
 	// Add actor verification if required
@@ -653,6 +728,15 @@ func (c *Client) buildGitHubSecurityConditions(conditions *SecurityConditions, o
 		additionalConditions = append(additionalConditions, fmt.Sprintf("(%s)", strings.Join(prConditions, " && ")))
 	}
 
+	// Add GitHub environment restrictions
+	if len(conditions.AllowedEnvironments) > 0 {
+		environmentConditions := make([]string, len(conditions.AllowedEnvironments))
+		for i, environment := range conditions.AllowedEnvironments {
+			environmentConditions[i] = fmt.Sprintf("assertion.environment=='%s'", environment)
+		}
+		additionalConditions = append(additionalConditions, fmt.Sprintf("(%s)", strings.Join(environmentConditions, " || ")))
+	}
+
 	// Add trusted repositories check if specified
 	if len(oidcConfig.TrustedRepos) > 0 {
 		trustedConditions := make([]string, len(oidcConfig.TrustedRepos))
@@ -737,10 +821,14 @@ func (c *Client) BindServiceAccountToWorkloadIdentity(config *WorkloadIdentityCo
 		PoolID:              config.PoolID,
 		ProviderID:          config.ProviderID,
 		Repository:          config.Repository,
+		RepositoryID:        config.RepositoryID,
+		RepositoryOwnerID:   config.RepositoryOwnerID,
 		AllowedBranches:     config.AllowedBranches,
 		AllowedTags:         config.AllowedTags,
 		AllowPullRequests:   config.AllowPullRequests,
 		GitHubOIDC:          config.GitHubOIDC,
+		PrincipalSets:       config.PrincipalSets,
+		ExpirationTime:      config.ExpirationTime,
 	}
 
 	// Create multiple role bindings with different security levels
@@ -760,7 +848,9 @@ func (c *Client) BindServiceAccountToWorkloadIdentity(config *WorkloadIdentityCo
 		"repository", config.Repository,
 		"branches", config.AllowedBranches,
 		"tags", config.AllowedTags,
-		"pull_requests", config.AllowPullRequests)
+		"pull_requests", config.AllowPullRequests,
+		"expires_at", config.ExpirationTime,
+		"principal_sets", config.PrincipalSets)
 
 	return nil
 }
@@ -772,15 +862,14 @@ func (c *Client) createServiceAccountTokenCreatorBinding(config *IAMBindingConfi
 		"service_account", config.ServiceAccountEmail,
 		"repository", config.Repository)
 
-	// Build enhanced principal set member
-	member := c.buildPrincipalSetMember(config)
-
 	// Build enhanced security condition
 	condition := c.buildEnhancedIAMCondition(config)
 
-	// Create the binding
-	if err := c.executeIAMBinding(config.ServiceAccountEmail, member, "roles/iam.serviceAccountTokenCreator", condition); err != nil {
-		return err
+	// Create the binding for each principal set
+	for _, member := range c.buildPrincipalSetMembers(config) {
+		if err := c.executeIAMBinding(config.ServiceAccountEmail, member, "roles/iam.serviceAccountTokenCreator", condition); err != nil {
+			return err
+		}
 	}
 
 	logger.Info("Service account token creator binding created successfully",
@@ -797,20 +886,22 @@ func (c *Client) createWorkloadIdentityUserBinding(config *IAMBindingConfig) err
 		"service_account", config.ServiceAccountEmail,
 		"repository", config.Repository)
 
-	// Build legacy principal set member (for backward compatibility)
-	member := fmt.Sprintf("principalSet://iam.googleapis.com/projects/%s/locations/global/workloadIdentityPools/%s/attribute.repository/%s",
-		c.ProjectID, config.PoolID, config.Repository)
-
 	// Build basic condition for legacy binding
 	condition := &IAMCondition{
 		Title:       fmt.Sprintf("Legacy WIF for %s", config.Repository),
 		Description: fmt.Sprintf("Legacy workload identity user access for repository %s", config.Repository),
-		Expression:  fmt.Sprintf("assertion.repository=='%s'", config.Repository),
+		Expression:  RepositoryCondition(config.Repository, config.RepositoryID),
+	}
+	if config.ExpirationTime != "" {
+		condition.Description += fmt.Sprintf(" until %s", config.ExpirationTime)
+		condition.Expression += " && " + buildBindingExpirationClause(config.ExpirationTime)
 	}
 
-	// Create the binding
-	if err := c.executeIAMBinding(config.ServiceAccountEmail, member, "roles/iam.workloadIdentityUser", condition); err != nil {
-		return err
+	// Create the binding for each principal set
+	for _, member := range c.buildPrincipalSetMembers(config) {
+		if err := c.executeIAMBinding(config.ServiceAccountEmail, member, "roles/iam.workloadIdentityUser", condition); err != nil {
+			return err
+		}
 	}
 
 	logger.Debug("Workload identity user binding created successfully",
@@ -820,11 +911,36 @@ func (c *Client) createWorkloadIdentityUserBinding(config *IAMBindingConfig) err
 	return nil
 }
 
-// buildPrincipalSetMember builds an enhanced principal set member string with multiple attribute options
-func (c *Client) buildPrincipalSetMember(config *IAMBindingConfig) string {
-	// Use provider-based principal set for more granular control
-	return fmt.Sprintf("principalSet://iam.googleapis.com/projects/%s/locations/global/workloadIdentityPools/%s/providers/%s/*",
-		c.ProjectID, config.PoolID, config.ProviderID)
+// buildPrincipalSetMembers builds the principal set members bound to the service account:
+// its principal sets, or the repository's identities in the pool
+func (c *Client) buildPrincipalSetMembers(config *IAMBindingConfig) []string {
+	attributes := config.PrincipalSets
+	if len(attributes) == 0 {
+		attributes = []string{RepositoryMember(config.Repository, config.RepositoryID)}
+	}
+	members := make([]string, len(attributes))
+	for i, attribute := range attributes {
+		members[i] = c.poolPrincipalSet(config.PoolID, attribute)
+	}
+	return members
+}
+
+// poolPrincipalSet returns the principal set of the identities in a pool with an attribute
+func (c *Client) poolPrincipalSet(poolID, attribute string) string {
+	return fmt.Sprintf("principalSet://iam.googleapis.com/projects/%s/locations/global/workloadIdentityPools/%s/%s",
+		c.ProjectID, poolID, attribute)
+}
+
+// RepositoryRefMember returns the principalSet attribute path selecting the repository's
+// identities for a ref, such as refs/heads/main
+func RepositoryRefMember(repository, ref string) string {
+	return "attribute.repository_ref/" + repository + "@" + ref
+}
+
+// RepositoryEnvironmentMember returns the principalSet attribute path selecting the
+// repository's identities for jobs running in a GitHub environment
+func RepositoryEnvironmentMember(repository, environment string) string {
+	return "attribute.repository_environment/" + repository + "@" + environment
 }
 
 // IAMCondition represents an IAM condition for policy bindings
@@ -844,6 +960,9 @@ func (c *Client) buildEnhancedIAMCondition(config *IAMBindingConfig) *IAMConditi
 	description := config.BindingDescription
 	if description == "" {
 		description = fmt.Sprintf("Enhanced workload identity access for GitHub repository %s with comprehensive security conditions", config.Repository)
+		if config.ExpirationTime != "" {
+			description += fmt.Sprintf(" until %s", config.ExpirationTime)
+		}
 	}
 
 	// Build comprehensive CEL expression
@@ -861,14 +980,14 @@ func (c *Client) buildComprehensiveSecurityExpression(config *IAMBindingConfig)
 	var conditions []string
 
 	// Base repository condition (required)
-	conditions = append(conditions, fmt.Sprintf("assertion.repository=='%s'", config.Repository))
+	conditions = append(conditions, RepositoryCondition(config.Repository, config.RepositoryID))
 
 	// Add GitHub OIDC security conditions if configured
 	if config.GitHubOIDC != nil {
 		// Repository owner verification (prevent forked repo attacks)
 		if config.GitHubOIDC.BlockForkedRepos {
 			repositoryOwner := strings.Split(config.Repository, "/")[0]
-			conditions = append(conditions, fmt.Sprintf("assertion.repository_owner=='%s'", repositoryOwner))
+			conditions = append(conditions, RepositoryOwnerCondition(repositoryOwner, config.RepositoryOwnerID))
 		}
 
 		// Actor verification (ensure actor claim is present)
@@ -949,6 +1068,11 @@ func (c *Client) buildComprehensiveSecurityExpression(config *IAMBindingConfig)
 	// Add time-based conditions (optional - prevent very old tokens)
 	conditions = append(conditions, "request.time < timestamp(assertion.exp)")
 
+	// Add binding expiration for time-bound access
+	if config.ExpirationTime != "" {
+		conditions = append(conditions, buildBindingExpirationClause(config.ExpirationTime))
+	}
+
 	// Combine all conditions with AND logic
 	return strings.Join(conditions, " && ")
 }
@@ -1012,24 +1136,26 @@ func (c *Client) RemoveServiceAccountWorkloadIdentityBinding(config *WorkloadIde
 		return errors.NewValidationError("Service account email is required")
 	}
 
-	bindingConfig := &IAMBindingConfig{
-		ServiceAccountEmail: config.ServiceAccountEmail,
-		PoolID:              config.PoolID,
-		ProviderID:          config.ProviderID,
-		Repository:          config.Repository,
+	// Remove the bindings of the principal sets, by repository name and by ID as bindings
+	// made before the repository was pinned to its ID select it by name
+	members := c.buildPrincipalSetMembers(&IAMBindingConfig{
+		PoolID:        config.PoolID,
+		Repository:    config.Repository,
+		RepositoryID:  config.RepositoryID,
+		PrincipalSets: config.PrincipalSets,
+	})
+	if len(config.PrincipalSets) == 0 && config.RepositoryID > 0 {
+		members = append(members, c.poolPrincipalSet(config.PoolID, RepositoryMember(config.Repository, 0)))
 	}
-
-	// Remove service account token creator binding
-	member := c.buildPrincipalSetMember(bindingConfig)
-	if err := c.removeIAMBinding(config.ServiceAccountEmail, member, "roles/iam.serviceAccountTokenCreator"); err != nil {
-		logger.Warn("Failed to remove service account token creator binding", "error", err)
+	if err := c.removePrincipalSetBindings(config.ServiceAccountEmail, members); err != nil {
+		logger.Warn("Failed to remove workload identity binding", "error", err)
 	}
 
-	// Remove legacy workload identity user binding
-	legacyMember := fmt.Sprintf("principalSet://iam.googleapis.com/projects/%s/locations/global/workloadIdentityPools/%s/attribute.repository/%s",
-		c.ProjectID, config.PoolID, config.Repository)
-	if err := c.removeIAMBinding(config.ServiceAccountEmail, legacyMember, "roles/iam.workloadIdentityUser"); err != nil {
-		logger.Warn("Failed to remove legacy workload identity user binding", "error", err)
+	// Earlier versions bound the token creator role to a provider-level member
+	providerMember := fmt.Sprintf("principalSet://iam.googleapis.com/projects/%s/locations/global/workloadIdentityPools/%s/providers/%s/*",
+		c.ProjectID, config.PoolID, config.ProviderID)
+	if err := c.removeIAMBinding(config.ServiceAccountEmail, providerMember, "roles/iam.serviceAccountTokenCreator"); err != nil {
+		logger.Debug("No provider-level token creator binding to remove", "error", err)
 	}
 
 	logger.Info("Service account workload identity bindings removed successfully",
@@ -1039,6 +1165,20 @@ func (c *Client) RemoveServiceAccountWorkloadIdentityBinding(config *WorkloadIde
 	return nil
 }
 
+// removePrincipalSetBindings removes the workload identity roles of principal sets on a
+// service account, returning the first failure after trying every binding
+func (c *Client) removePrincipalSetBindings(serviceAccountEmail string, members []string) error {
+	var firstErr error
+	for _, member := range members {
+		for _, role := range []string{"roles/iam.serviceAccountTokenCreator", "roles/iam.workloadIdentityUser"} {
+			if err := c.removeIAMBinding(serviceAccountEmail, member, role); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
 // removeIAMBinding removes an IAM policy binding
 func (c *Client) removeIAMBinding(serviceAccountEmail, member, role string) error {
 	logger := c.logger.WithField("function", "removeIAMBinding")
@@ -1104,6 +1244,11 @@ func (c *Client) ListServiceAccountWorkloadIdentityBindings(serviceAccountEmail
 						PoolID:     c.extractPoolIDFromMember(member),
 						ProviderID: c.extractProviderIDFromMember(member),
 					}
+					if binding.Condition != nil {
+						if expiresAt, ok := ExtractBindingExpiration(binding.Condition.Expression); ok {
+							wfBinding.ExpiresAt = &expiresAt
+						}
+					}
 					bindings = append(bindings, wfBinding)
 				}
 			}
@@ -1122,6 +1267,7 @@ type WorkloadIdentityBinding struct {
 	Repository string        `json:"repository"`
 	PoolID     string        `json:"pool_id"`
 	ProviderID string        `json:"provider_id,omitempty"`
+	ExpiresAt  *time.Time    `json:"expires_at,omitempty"` // Set for time-bound bindings
 }
 
 // IAMPolicy represents an IAM policy
@@ -1333,44 +1479,7 @@ func (c *Client) GetWorkloadIdentityProviderInfo(poolID, providerID string) (*Wo
 			"Failed to parse workload identity provider information")
 	}
 
-	info := &WorkloadIdentityProviderInfo{
-		Name:               fmt.Sprintf("%v", providerData["name"]),
-		DisplayName:        fmt.Sprintf("%v", providerData["displayName"]),
-		Description:        fmt.Sprintf("%v", providerData["description"]),
-		State:              fmt.Sprintf("%v", providerData["state"]),
-		Disabled:           providerData["disabled"] == true,
-		AttributeCondition: fmt.Sprintf("%v", providerData["attributeCondition"]),
-		Exists:             true,
-		FullResourceName:   fmt.Sprintf("%v", providerData["name"]),
-	}
-
-	// Parse issuer URI and allowed audiences from OIDC section
-	if oidcData, ok := providerData["oidc"].(map[string]interface{}); ok {
-		if issuerURI, ok := oidcData["issuerUri"].(string); ok {
-			info.IssuerURI = issuerURI
-		}
-		if audiences, ok := oidcData["allowedAudiences"].([]interface{}); ok {
-			info.AllowedAudiences = make([]string, len(audiences))
-			for i, audience := range audiences {
-				info.AllowedAudiences[i] = fmt.Sprintf("%v", audience)
-			}
-		}
-	}
-
-	// Parse attribute mapping
-	if attributeMapping, ok := providerData["attributeMapping"].(map[string]interface{}); ok {
-		info.AttributeMapping = make(map[string]string)
-		for k, v := range attributeMapping {
-			info.AttributeMapping[k] = fmt.Sprintf("%v", v)
-		}
-	}
-
-	// Parse creation time
-	if createTimeStr, ok := providerData["createTime"].(string); ok {
-		if createTime, err := time.Parse(time.RFC3339, createTimeStr); err == nil {
-			info.CreateTime = createTime
-		}
-	}
+	info := parseWorkloadIdentityProviderData(providerData)
 
 	logger.Debug("Workload identity provider info retrieved",
 		"pool_id", poolID,
@@ -1425,6 +1534,86 @@ func (c *Client) ListWorkloadIdentityPools() ([]*WorkloadIdentityPoolInfo, error
 	return pools, nil
 }
 
+// ListWorkloadIdentityProviders lists all workload identity providers in a pool
+func (c *Client) ListWorkloadIdentityProviders(poolID string) ([]*WorkloadIdentityProviderInfo, error) {
+	logger := c.logger.WithField("function", "ListWorkloadIdentityProviders")
+	logger.Debug("Listing workload identity providers", "project_id", c.ProjectID, "pool_id", poolID)
+
+	cmd := exec.Command("gcloud", "iam", "workload-identity-pools", "providers", "list",
+		"--project", c.ProjectID,
+		"--location", "global",
+		"--workload-identity-pool", poolID,
+		"--format", "json")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeGCP, "WI_PROVIDERS_LIST_FAILED",
+			fmt.Sprintf("Failed to list workload identity providers in pool %s: %s", poolID, string(output)))
+	}
+
+	var providersData []map[string]interface{}
+	if err := json.Unmarshal(output, &providersData); err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeGCP, "WI_PROVIDERS_PARSE_FAILED",
+			"Failed to parse workload identity providers list")
+	}
+
+	providers := make([]*WorkloadIdentityProviderInfo, len(providersData))
+	for i, providerData := range providersData {
+		providers[i] = parseWorkloadIdentityProviderData(providerData)
+	}
+
+	logger.Debug("Workload identity providers listed", "pool_id", poolID, "count", len(providers))
+	return providers, nil
+}
+
+// parseWorkloadIdentityProviderData converts gcloud provider JSON into WorkloadIdentityProviderInfo
+func parseWorkloadIdentityProviderData(providerData map[string]interface{}) *WorkloadIdentityProviderInfo {
+	info := &WorkloadIdentityProviderInfo{
+		Name:             fmt.Sprintf("%v", providerData["name"]),
+		DisplayName:      fmt.Sprintf("%v", providerData["displayName"]),
+		Description:      fmt.Sprintf("%v", providerData["description"]),
+		State:            fmt.Sprintf("%v", providerData["state"]),
+		Disabled:         providerData["disabled"] == true,
+		Exists:           true,
+		FullResourceName: fmt.Sprintf("%v", providerData["name"]),
+	}
+
+	// Providers without a condition omit the field entirely
+	if attributeCondition, ok := providerData["attributeCondition"].(string); ok {
+		info.AttributeCondition = attributeCondition
+	}
+
+	// Parse issuer URI and allowed audiences from OIDC section
+	if oidcData, ok := providerData["oidc"].(map[string]interface{}); ok {
+		if issuerURI, ok := oidcData["issuerUri"].(string); ok {
+			info.IssuerURI = issuerURI
+		}
+		if audiences, ok := oidcData["allowedAudiences"].([]interface{}); ok {
+			info.AllowedAudiences = make([]string, len(audiences))
+			for i, audience := range audiences {
+				info.AllowedAudiences[i] = fmt.Sprintf("%v", audience)
+			}
+		}
+	}
+
+	// Parse attribute mapping
+	if attributeMapping, ok := providerData["attributeMapping"].(map[string]interface{}); ok {
+		info.AttributeMapping = make(map[string]string)
+		for k, v := range attributeMapping {
+			info.AttributeMapping[k] = fmt.Sprintf("%v", v)
+		}
+	}
+
+	// Parse creation time
+	if createTimeStr, ok := providerData["createTime"].(string); ok {
+		if createTime, err := time.Parse(time.RFC3339, createTimeStr); err == nil {
+			info.CreateTime = createTime
+		}
+	}
+
+	return info
+}
+
 // DeleteWorkloadIdentityPool deletes a workload identity pool with enhanced error handling
 func (c *Client) DeleteWorkloadIdentityPool(poolID string) error {
 	logger := c.logger.WithField("function", "DeleteWorkloadIdentityPool")
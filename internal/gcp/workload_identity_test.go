@@ -0,0 +1,32 @@
+package gcp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildPrincipalSetMembers(t *testing.T) {
+	c := &Client{ProjectID: "test-project"}
+	pool := "principalSet://iam.googleapis.com/projects/test-project/locations/global/workloadIdentityPools/github-pool/"
+
+	members := c.buildPrincipalSetMembers(&IAMBindingConfig{PoolID: "github-pool", ProviderID: "github-provider", Repository: "acme/app", RepositoryID: 123})
+	if !reflect.DeepEqual(members, []string{pool + "attribute.repository_id/123"}) {
+		t.Errorf("Expected the repository's identities by default, got %v", members)
+	}
+
+	members = c.buildPrincipalSetMembers(&IAMBindingConfig{PoolID: "github-pool", ProviderID: "github-provider", Repository: "acme/app",
+		PrincipalSets: []string{RepositoryRefMember("acme/app", "refs/heads/main")}})
+	if !reflect.DeepEqual(members, []string{pool + "attribute.repository_ref/acme/app@refs/heads/main"}) {
+		t.Errorf("Expected only the principal sets, got %v", members)
+	}
+	for _, member := range members {
+		if strings.Contains(member, "/providers/") {
+			t.Errorf("Principal sets span the pool and cannot select a provider: %s", member)
+		}
+	}
+
+	if mapping := c.buildGitHubAttributeMapping(GetDefaultGitHubClaimsMapping()); !strings.Contains(mapping, "attribute.repository_ref=assertion.repository + '@' + assertion.ref") {
+		t.Errorf("Expected the repository ref attribute to be mapped, got %s", mapping)
+	}
+}
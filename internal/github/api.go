@@ -0,0 +1,476 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// Names of the Actions variables holding the WIF outputs a workflow authenticates with
+const (
+	VariableProjectID                = "PROJECT_ID"
+	VariableWorkloadIdentityProvider = "WORKLOAD_IDENTITY_PROVIDER"
+	VariableServiceAccount           = "SERVICE_ACCOUNT"
+)
+
+// APIClientConfig selects the GitHub API endpoint and how requests are authenticated,
+// either with a token or as a GitHub App installation
+type APIClientConfig struct {
+	// BaseURL is the REST API root, e.g. https://github.example.com/api/v3 for GHES
+	BaseURL string
+	Token   string
+
+	AppID             string
+	AppInstallationID string
+	// AppPrivateKey is the PEM encoded private key of the GitHub App
+	AppPrivateKey []byte
+}
+
+// APIClientConfigFromEnv reads the API configuration from GITHUB_API_URL, GITHUB_TOKEN or
+// GH_TOKEN, and GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID and GITHUB_APP_PRIVATE_KEY (or
+// GITHUB_APP_PRIVATE_KEY_FILE) for GitHub App authentication
+func APIClientConfigFromEnv() (APIClientConfig, error) {
+	cfg := APIClientConfig{
+		BaseURL:           os.Getenv("GITHUB_API_URL"),
+		Token:             os.Getenv("GITHUB_TOKEN"),
+		AppID:             os.Getenv("GITHUB_APP_ID"),
+		AppInstallationID: os.Getenv("GITHUB_APP_INSTALLATION_ID"),
+		AppPrivateKey:     []byte(os.Getenv("GITHUB_APP_PRIVATE_KEY")),
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("GH_TOKEN")
+	}
+	if path := os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE"); path != "" && len(cfg.AppPrivateKey) == 0 {
+		key, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, errors.WrapError(err, errors.ErrorTypeFileSystem, "GITHUB_APP_KEY_READ_FAILED",
+				fmt.Sprintf("Failed to read GitHub App private key %s", path))
+		}
+		cfg.AppPrivateKey = key
+	}
+	return cfg, nil
+}
+
+// APIClient is a minimal GitHub REST API client for managing Actions variables and secrets
+type APIClient struct {
+	BaseURL string
+	Client  *http.Client
+	Now     func() time.Time
+
+	token  string
+	appID  string
+	appKey *rsa.PrivateKey
+
+	// installationID is the configured GitHub App installation, looked up per repository
+	// when empty; installations caches the installation access token per owner
+	installationID string
+	mu             sync.Mutex
+	installations  map[string]installationToken
+}
+
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewAPIClient creates a client from the configuration. GitHub App credentials take
+// precedence over a token when both are set.
+func NewAPIClient(cfg APIClientConfig) (*APIClient, error) {
+	client := &APIClient{
+		BaseURL:        strings.TrimSuffix(cfg.BaseURL, "/"),
+		Client:         &http.Client{Timeout: 30 * time.Second},
+		Now:            time.Now,
+		token:          cfg.Token,
+		installationID: cfg.AppInstallationID,
+		installations:  make(map[string]installationToken),
+	}
+	if client.BaseURL == "" {
+		client.BaseURL = DefaultGitHubAPIURL
+	}
+
+	if cfg.AppID != "" || len(cfg.AppPrivateKey) > 0 {
+		if cfg.AppID == "" || len(cfg.AppPrivateKey) == 0 {
+			return nil, errors.NewConfigurationError("GitHub App authentication requires both an app ID and a private key",
+				"Set GITHUB_APP_ID and GITHUB_APP_PRIVATE_KEY_FILE")
+		}
+		key, err := parseAppPrivateKey(cfg.AppPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		client.appID, client.appKey = cfg.AppID, key
+		return client, nil
+	}
+
+	if client.token == "" {
+		return nil, errors.NewConfigurationError("No GitHub credentials configured",
+			"Set GITHUB_TOKEN to a token allowed to manage Actions variables and secrets",
+			"Or configure a GitHub App with GITHUB_APP_ID and GITHUB_APP_PRIVATE_KEY_FILE")
+	}
+	return client, nil
+}
+
+// parseAppPrivateKey parses a PKCS#1 or PKCS#8 PEM encoded RSA key
+func parseAppPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.NewConfigurationError("GitHub App private key is not PEM encoded",
+			"Use the .pem file downloaded from the GitHub App settings")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeConfiguration, "GITHUB_APP_KEY_INVALID",
+			"Failed to parse GitHub App private key")
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.NewConfigurationError("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// VariableScope is where Actions variables and secrets are stored: a repository, or one
+// of its deployment environments
+type VariableScope struct {
+	Owner       string
+	Repo        string
+	Environment string
+}
+
+// String returns owner/repo, followed by the environment when set
+func (s VariableScope) String() string {
+	if s.Environment != "" {
+		return fmt.Sprintf("%s/%s (environment %s)", s.Owner, s.Repo, s.Environment)
+	}
+	return s.Owner + "/" + s.Repo
+}
+
+// path returns the API path of the scope
+func (s VariableScope) path() string {
+	path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(s.Owner), url.PathEscape(s.Repo))
+	if s.Environment != "" {
+		path += "/environments/" + url.PathEscape(s.Environment)
+	}
+	return path
+}
+
+// VariableChange records what writing a variable or secret did
+type VariableChange struct {
+	Scope  VariableScope `json:"-"`
+	Name   string        `json:"name"`
+	Action string        `json:"action"` // created, updated, unchanged or written (secrets)
+}
+
+// GetVariable returns the value of an Actions variable and whether it exists
+func (c *APIClient) GetVariable(ctx context.Context, scope VariableScope, name string) (string, bool, error) {
+	var variable struct {
+		Value string `json:"value"`
+	}
+	status, err := c.do(ctx, scope, http.MethodGet, scope.path()+"/actions/variables/"+url.PathEscape(name), nil, &variable)
+	if status == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return variable.Value, true, nil
+}
+
+// SetVariable creates or updates an Actions variable. A variable that already has the
+// value is left untouched.
+func (c *APIClient) SetVariable(ctx context.Context, scope VariableScope, name, value string) (VariableChange, error) {
+	change := VariableChange{Scope: scope, Name: name}
+	current, exists, err := c.GetVariable(ctx, scope, name)
+	if err != nil {
+		return change, err
+	}
+	switch {
+	case exists && current == value:
+		change.Action = "unchanged"
+		return change, nil
+	case exists:
+		change.Action = "updated"
+		_, err = c.do(ctx, scope, http.MethodPatch, scope.path()+"/actions/variables/"+url.PathEscape(name),
+			map[string]string{"name": name, "value": value}, nil)
+	default:
+		change.Action = "created"
+		_, err = c.do(ctx, scope, http.MethodPost, scope.path()+"/actions/variables",
+			map[string]string{"name": name, "value": value}, nil)
+	}
+	return change, err
+}
+
+// SetVariables writes the variables in name order
+func (c *APIClient) SetVariables(ctx context.Context, scope VariableScope, variables map[string]string) ([]VariableChange, error) {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []VariableChange
+	for _, name := range names {
+		change, err := c.SetVariable(ctx, scope, name, variables[name])
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// SetSecret encrypts the value with the scope's public key and creates or updates the
+// Actions secret. Secret values cannot be read back, so the secret is always written.
+func (c *APIClient) SetSecret(ctx context.Context, scope VariableScope, name, value string) (VariableChange, error) {
+	change := VariableChange{Scope: scope, Name: name, Action: "written"}
+
+	var publicKey struct {
+		KeyID string `json:"key_id"`
+		Key   string `json:"key"`
+	}
+	if _, err := c.do(ctx, scope, http.MethodGet, scope.path()+"/actions/secrets/public-key", nil, &publicKey); err != nil {
+		return change, err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(publicKey.Key)
+	if err != nil || len(decoded) != 32 {
+		return change, errors.NewGitHubError(fmt.Sprintf("GitHub API returned an invalid public key for %s", scope))
+	}
+	var key [32]byte
+	copy(key[:], decoded)
+
+	sealed, err := box.SealAnonymous(nil, []byte(value), &key, rand.Reader)
+	if err != nil {
+		return change, errors.NewInternalError(fmt.Sprintf("Failed to encrypt secret %s", name), err)
+	}
+	_, err = c.do(ctx, scope, http.MethodPut, scope.path()+"/actions/secrets/"+url.PathEscape(name), map[string]string{
+		"encrypted_value": base64.StdEncoding.EncodeToString(sealed),
+		"key_id":          publicKey.KeyID,
+	}, nil)
+	return change, err
+}
+
+// do sends an authenticated request for the scope, decoding a JSON response into out.
+// The status code is returned alongside errors so callers can handle a missing resource.
+func (c *APIClient) do(ctx context.Context, scope VariableScope, method, path string, in, out interface{}) (int, error) {
+	token, err := c.authToken(ctx, scope)
+	if err != nil {
+		return 0, err
+	}
+	return c.request(ctx, method, path, "Bearer "+token, in, out)
+}
+
+// request sends a request with the given Authorization header
+func (c *APIClient) request(ctx context.Context, method, path, authorization string, in, out interface{}) (int, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return 0, errors.NewInternalError("Failed to encode GitHub API request", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrorTypeInternal, "GITHUB_API_REQUEST_FAILED",
+			"Failed to build GitHub API request")
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", authorization)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrorTypeNetwork, "GITHUB_API_FAILED",
+			fmt.Sprintf("GitHub API request %s %s failed", method, path))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, errors.WrapError(err, errors.ErrorTypeNetwork, "GITHUB_API_FAILED",
+			fmt.Sprintf("Failed to read GitHub API response for %s %s", method, path))
+	}
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, apiStatusError(method, path, resp, data)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, errors.WrapError(err, errors.ErrorTypeGitHub, "GITHUB_API_INVALID_RESPONSE",
+				fmt.Sprintf("GitHub API returned an unexpected response for %s %s", method, path))
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// apiStatusError maps an unsuccessful response to an error with suggestions
+func apiStatusError(method, path string, resp *http.Response, data []byte) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(data, &body)
+	message := fmt.Sprintf("GitHub API returned %s for %s %s", resp.Status, method, path)
+	if body.Message != "" {
+		message += ": " + body.Message
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return errors.NewGitHubError(message,
+			"Check that the token or GitHub App credentials are valid and not expired")
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return errors.NewGitHubError(message,
+			"The token needs the Variables and Secrets repository permissions (read and write), or the repo scope for classic tokens",
+			"Environment variables also require the Environments permission")
+	case http.StatusNotFound:
+		return errors.NewGitHubError(message,
+			"Check the repository owner and name, and that the environment exists",
+			"GitHub also answers 404 when the credentials cannot see the repository")
+	default:
+		return errors.NewGitHubError(message)
+	}
+}
+
+// authToken returns the token to authenticate a request for the scope with, exchanging
+// a GitHub App JWT for an installation token when needed
+func (c *APIClient) authToken(ctx context.Context, scope VariableScope) (string, error) {
+	if c.appKey == nil {
+		return c.token, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.installations[scope.Owner]; ok && c.Now().Add(time.Minute).Before(cached.ExpiresAt) {
+		return cached.Token, nil
+	}
+
+	jwt, err := c.appJWT()
+	if err != nil {
+		return "", err
+	}
+	installationID := c.installationID
+	if installationID == "" {
+		var installation struct {
+			ID int64 `json:"id"`
+		}
+		path := fmt.Sprintf("/repos/%s/%s/installation", url.PathEscape(scope.Owner), url.PathEscape(scope.Repo))
+		if _, err := c.request(ctx, http.MethodGet, path, "Bearer "+jwt, nil, &installation); err != nil {
+			return "", err
+		}
+		installationID = fmt.Sprintf("%d", installation.ID)
+	}
+
+	var token installationToken
+	path := fmt.Sprintf("/app/installations/%s/access_tokens", url.PathEscape(installationID))
+	if _, err := c.request(ctx, http.MethodPost, path, "Bearer "+jwt, nil, &token); err != nil {
+		return "", err
+	}
+	c.installations[scope.Owner] = token
+	return token.Token, nil
+}
+
+// appJWT returns a short-lived RS256 JWT identifying the GitHub App
+func (c *APIClient) appJWT() (string, error) {
+	now := c.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		// Backdated to allow for clock drift; GitHub rejects tokens valid for over 10 minutes
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": c.appID,
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.appKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", errors.NewInternalError("Failed to sign GitHub App token", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ActionsVariables returns the repository-level Actions variables holding the WIF outputs
+func (w *WorkflowConfig) ActionsVariables() map[string]string {
+	return map[string]string{
+		VariableProjectID:                w.ProjectID,
+		VariableWorkloadIdentityProvider: w.WorkloadIdentityProvider,
+		VariableServiceAccount:           w.ServiceAccountEmail,
+	}
+}
+
+// EnvironmentActionsVariables returns the environment-level Actions variables of the
+// pipeline environments with their own identity, keyed by environment name
+func (w *WorkflowConfig) EnvironmentActionsVariables() map[string]map[string]string {
+	variables := make(map[string]map[string]string)
+	for _, name := range w.Advanced.Pipeline {
+		env, _ := w.GetEnvironment(name)
+		if env.ServiceAccountEmail == "" && env.WorkloadIdentityProvider == "" {
+			continue
+		}
+		serviceAccount, provider := w.GetEnvironmentIdentity(name)
+		variables[name] = map[string]string{
+			VariableWorkloadIdentityProvider: provider,
+			VariableServiceAccount:           serviceAccount,
+		}
+	}
+	return variables
+}
+
+// Repository is what the tool uses of a GitHub repository
+type Repository struct {
+	ID            int64
+	OwnerID       int64
+	FullName      string
+	DefaultBranch string
+}
+
+// repositoryPath returns the API path of a repository
+func repositoryPath(owner, repo string) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+// GetRepository returns a repository with its immutable ID and the ID of its owner
+func (c *APIClient) GetRepository(ctx context.Context, owner, repo string) (*Repository, error) {
+	var response struct {
+		ID            int64  `json:"id"`
+		FullName      string `json:"full_name"`
+		DefaultBranch string `json:"default_branch"`
+		Owner         struct {
+			ID int64 `json:"id"`
+		} `json:"owner"`
+	}
+	scope := VariableScope{Owner: owner, Repo: repo}
+	if _, err := c.do(ctx, scope, http.MethodGet, repositoryPath(owner, repo), nil, &response); err != nil {
+		return nil, err
+	}
+	return &Repository{
+		ID:            response.ID,
+		OwnerID:       response.Owner.ID,
+		FullName:      response.FullName,
+		DefaultBranch: response.DefaultBranch,
+	}, nil
+}
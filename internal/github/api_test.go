@@ -0,0 +1,281 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// fakeGitHub is a stand-in for the variables, secrets and GitHub App endpoints of the API
+type fakeGitHub struct {
+	t      *testing.T
+	mu     sync.Mutex
+	token  string
+	appKey *rsa.PublicKey
+
+	publicKey, privateKey *[32]byte
+	variables             map[string]string // keyed by scope path + "/" + name
+	secrets               map[string]string
+	requests              []string
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *httptest.Server) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeGitHub{
+		t:          t,
+		token:      "test-token",
+		publicKey:  publicKey,
+		privateKey: privateKey,
+		variables:  make(map[string]string),
+		secrets:    make(map[string]string),
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+	authorization := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	// GitHub App endpoints authenticate with the app's JWT
+	if r.URL.Path == "/repos/acme/app/installation" || strings.HasPrefix(r.URL.Path, "/app/") {
+		if !f.validJWT(authorization) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(map[string]int64{"id": 42})
+			return
+		}
+		if r.URL.Path != "/app/installations/42/access_tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token": "test-token", "expires_at": "2099-01-01T00:00:00Z"}`))
+		return
+	}
+	if authorization != f.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message": "Bad credentials"}`))
+		return
+	}
+
+	var body map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	path := r.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/actions/secrets/public-key"):
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"key_id": "key-1",
+			"key":    base64.StdEncoding.EncodeToString(f.publicKey[:]),
+		})
+	case strings.Contains(path, "/actions/secrets/") && r.Method == http.MethodPut:
+		sealed, _ := base64.StdEncoding.DecodeString(body["encrypted_value"])
+		value, ok := box.OpenAnonymous(nil, sealed, f.publicKey, f.privateKey)
+		if !ok || body["key_id"] != "key-1" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		f.secrets[path] = string(value)
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasSuffix(path, "/actions/variables") && r.Method == http.MethodPost:
+		f.variables[path+"/"+body["name"]] = body["value"]
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/actions/variables/"):
+		value, exists := f.variables[path]
+		switch {
+		case !exists:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		case r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]string{"value": value})
+		case r.Method == http.MethodPatch:
+			f.variables[path] = body["value"]
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// validJWT verifies the RS256 signature of a GitHub App JWT
+func (f *fakeGitHub) validJWT(token string) bool {
+	parts := strings.Split(token, ".")
+	if f.appKey == nil || len(parts) != 3 {
+		return false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(f.appKey, crypto.SHA256, digest[:], signature) != nil {
+		return false
+	}
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	return strings.Contains(string(claims), `"iss":"1234"`)
+}
+
+func TestAPIClientVariablesAndSecrets(t *testing.T) {
+	fake, server := newFakeGitHub(t)
+	client, err := NewAPIClient(APIClientConfig{BaseURL: server.URL + "/", Token: "test-token"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	repo := VariableScope{Owner: "acme", Repo: "app"}
+	staging := VariableScope{Owner: "acme", Repo: "app", Environment: "staging"}
+
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	changes, err := client.SetVariables(ctx, repo, cfg.ActionsVariables())
+	if err != nil {
+		t.Fatalf("Failed to set variables: %v", err)
+	}
+	if len(changes) != 3 || changes[0].Name != VariableProjectID || changes[0].Action != "created" {
+		t.Errorf("Expected three created variables, got %+v", changes)
+	}
+	if got := fake.variables["/repos/acme/app/actions/variables/"+VariableServiceAccount]; got != cfg.ServiceAccountEmail {
+		t.Errorf("Expected the service account variable to be %q, got %q", cfg.ServiceAccountEmail, got)
+	}
+
+	// Writing again only updates what changed
+	cfg.ServiceAccountEmail = "deployer@other-project.iam.gserviceaccount.com"
+	changes, err = client.SetVariables(ctx, repo, cfg.ActionsVariables())
+	if err != nil {
+		t.Fatalf("Failed to update variables: %v", err)
+	}
+	for _, change := range changes {
+		expected := "unchanged"
+		if change.Name == VariableServiceAccount {
+			expected = "updated"
+		}
+		if change.Action != expected {
+			t.Errorf("Expected %s to be %s, got %s", change.Name, expected, change.Action)
+		}
+	}
+
+	if _, err := client.SetVariable(ctx, staging, VariableServiceAccount, "staging@my-project.iam.gserviceaccount.com"); err != nil {
+		t.Fatalf("Failed to set environment variable: %v", err)
+	}
+	if _, exists := fake.variables["/repos/acme/app/environments/staging/actions/variables/"+VariableServiceAccount]; !exists {
+		t.Errorf("Expected an environment variable, got %v", fake.variables)
+	}
+
+	if _, err := client.SetSecret(ctx, staging, "DB_PASSWORD", "hunter2"); err != nil {
+		t.Fatalf("Failed to set secret: %v", err)
+	}
+	if got := fake.secrets["/repos/acme/app/environments/staging/actions/secrets/DB_PASSWORD"]; got != "hunter2" {
+		t.Errorf("Expected the sealed secret to decrypt to its value, got %q", got)
+	}
+
+	fake.token = "rotated"
+	if _, err := client.SetVariable(ctx, repo, VariableProjectID, "x"); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("Expected a credentials error, got %v", err)
+	}
+}
+
+func TestAPIClientGitHubApp(t *testing.T) {
+	fake, server := newFakeGitHub(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.appKey = &key.PublicKey
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	client, err := NewAPIClient(APIClientConfig{BaseURL: server.URL, AppID: "1234", AppPrivateKey: pemKey})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	scope := VariableScope{Owner: "acme", Repo: "app"}
+	for i := 0; i < 2; i++ {
+		if _, err := client.SetVariable(context.Background(), scope, VariableProjectID, "my-project"); err != nil {
+			t.Fatalf("Failed to set variable as a GitHub App: %v", err)
+		}
+	}
+
+	// The installation token is requested once and reused
+	requests := strings.Join(fake.requests, "\n")
+	if strings.Count(requests, "POST /app/installations/42/access_tokens") != 1 || !strings.Contains(requests, "GET /repos/acme/app/installation") {
+		t.Errorf("Expected one installation token exchange, got:\n%s", requests)
+	}
+
+	if _, err := NewAPIClient(APIClientConfig{AppID: "1234"}); err == nil {
+		t.Error("Expected an app ID without a private key to be rejected")
+	}
+	if _, err := NewAPIClient(APIClientConfig{}); err == nil {
+		t.Error("Expected missing credentials to be rejected")
+	}
+}
+
+func TestActionsVariablesWorkflow(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.UseActionsVariables = true
+	cfg.Advanced.Environments = map[string]Environment{
+		"staging":    {Name: "staging"},
+		"production": {Name: "production", ServiceAccountEmail: "prod@my-project.iam.gserviceaccount.com"},
+	}
+	cfg.Advanced.Pipeline = []string{"staging", "production"}
+
+	if err := cfg.ValidateConfig(); err != nil {
+		t.Fatalf("Expected config to be valid: %v", err)
+	}
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+	if strings.Contains(content, cfg.ServiceAccountEmail) || strings.Contains(content, "prod@my-project") {
+		t.Error("Expected no service account to be written out")
+	}
+
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	if got := workflow.Env["WORKLOAD_IDENTITY_PROVIDER"]; got != "${{ vars.WORKLOAD_IDENTITY_PROVIDER }}" {
+		t.Errorf("Expected the provider to come from a repository variable, got %q", got)
+	}
+	if job := workflow.Job(DeployJobID("production")); job == nil || job.Env["SERVICE_ACCOUNT"] != "${{ vars.SERVICE_ACCOUNT }}" {
+		t.Errorf("Expected production to read its environment's service account variable, got %+v", job)
+	}
+	if job := workflow.Job(DeployJobID("staging")); job == nil || job.Env["SERVICE_ACCOUNT"] != "" {
+		t.Errorf("Expected staging to use the repository variables, got %+v", job)
+	}
+
+	environments := cfg.EnvironmentActionsVariables()
+	if len(environments) != 1 || environments["production"][VariableServiceAccount] != "prod@my-project.iam.gserviceaccount.com" ||
+		environments["production"][VariableWorkloadIdentityProvider] != cfg.WorkloadIdentityProvider {
+		t.Errorf("Expected production's identity variables, got %v", environments)
+	}
+
+	imported, err := ImportWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to import workflow: %v", err)
+	}
+	if got := imported.Config; !got.UseActionsVariables || got.ProjectID != cfg.ProjectID || got.ServiceAccountEmail != "" {
+		t.Errorf("Expected the import to read the variables setting and header project, got %q, %q", got.ProjectID, got.ServiceAccountEmail)
+	}
+}
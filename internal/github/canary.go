@@ -0,0 +1,117 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// Canary defaults
+const (
+	DefaultCanaryInterval = "60s"
+	DefaultCanaryTag      = "canary"
+)
+
+// DefaultCanarySteps are the traffic percentages a canary revision is promoted through
+var DefaultCanarySteps = []int{10, 50, 100}
+
+var (
+	canaryTagRegex      = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,44}[a-z0-9])?$`)
+	canaryIntervalRegex = regexp.MustCompile(`^[0-9]+[smh]$`)
+)
+
+// CanaryConfig defines progressive delivery to Cloud Run. The new revision is deployed
+// without traffic under a revision tag, then receives traffic in steps with the configured
+// health checks run between steps. Traffic is routed back to the previously serving
+// revision when the rollout fails.
+type CanaryConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Steps    []int  `json:"steps,omitempty"`    // Traffic percentages, ending at 100
+	Interval string `json:"interval,omitempty"` // Wait before checking each step, e.g. "60s", "5m"
+	Tag      string `json:"tag,omitempty"`      // Revision tag of the new revision
+}
+
+// GetSteps returns the traffic percentages, the defaults when none are configured
+func (c CanaryConfig) GetSteps() []int {
+	if len(c.Steps) == 0 {
+		return DefaultCanarySteps
+	}
+	return c.Steps
+}
+
+// GetInterval returns the wait between traffic steps
+func (c CanaryConfig) GetInterval() string {
+	if c.Interval == "" {
+		return DefaultCanaryInterval
+	}
+	return c.Interval
+}
+
+// GetTag returns the revision tag of the canary revision
+func (c CanaryConfig) GetTag() string {
+	if c.Tag == "" {
+		return DefaultCanaryTag
+	}
+	return c.Tag
+}
+
+// canarySettings returns the template data of the canary rollout, nil when it is disabled
+func (w *WorkflowConfig) canarySettings() map[string]interface{} {
+	canary := w.Advanced.Canary
+	if !canary.Enabled {
+		return nil
+	}
+	steps := make([]string, 0, len(canary.GetSteps()))
+	for _, step := range canary.GetSteps() {
+		steps = append(steps, strconv.Itoa(step))
+	}
+	// The health checks run once against the tagged revision, then again inside the traffic
+	// loop, one level deeper in the step's script
+	checks := w.GenerateHealthCheckCommands("$SERVICE_URL")
+	return map[string]interface{}{
+		"Tag":                     canary.GetTag(),
+		"Steps":                   strings.Join(steps, " "),
+		"Interval":                canary.GetInterval(),
+		"HealthCheckCommands":     checks,
+		"StepHealthCheckCommands": strings.ReplaceAll(checks, "\n        ", "\n          "),
+	}
+}
+
+// validateCanary checks the canary rollout settings
+func (w *WorkflowConfig) validateCanary() error {
+	canary := w.Advanced.Canary
+	if !canary.Enabled {
+		return nil
+	}
+	if w.IsTerraform() {
+		return errors.NewValidationError("Workflow: Canary deployments are not supported for terraform workflows", "workflow.advanced.canary", "INVALID")
+	}
+	if w.Target != "" && w.Target != TargetCloudRun {
+		return errors.NewValidationError(fmt.Sprintf("Workflow: Canary deployments require the %s target, not %s", TargetCloudRun, w.Target),
+			"workflow.advanced.canary", "INVALID")
+	}
+
+	previous := 0
+	for _, step := range canary.GetSteps() {
+		if step <= previous || step > 100 {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Canary traffic steps must increase between 1 and 100, got %v", canary.Steps),
+				"workflow.advanced.canary.steps", "INVALID")
+		}
+		previous = step
+	}
+	if previous != 100 {
+		return errors.NewValidationError("Workflow: The last canary traffic step must route 100% of traffic", "workflow.advanced.canary.steps", "INVALID")
+	}
+	if !canaryIntervalRegex.MatchString(canary.GetInterval()) {
+		return errors.NewValidationError(fmt.Sprintf("Workflow: Invalid canary interval '%s' (use format like '30s', '5m', '1h')", canary.Interval),
+			"workflow.advanced.canary.interval", "INVALID")
+	}
+	if !canaryTagRegex.MatchString(canary.GetTag()) {
+		return errors.NewValidationError(fmt.Sprintf("Workflow: Invalid canary tag '%s' (lowercase letters, digits and hyphens, starting with a letter)", canary.Tag),
+			"workflow.advanced.canary.tag", "INVALID")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanaryRollout(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.Advanced.Canary = CanaryConfig{Enabled: true, Steps: []int{25, 100}, Interval: "2m"}
+	cfg.AddHealthCheck(HealthCheck{Name: "readiness", URL: "/ready"})
+
+	if err := cfg.ValidateConfig(); err != nil {
+		t.Fatalf("Expected canary config to be valid: %v", err)
+	}
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	var names []string
+	for _, step := range workflow.Job("deploy").Steps {
+		names = append(names, step.Name)
+	}
+	order := strings.Join(names, "\n")
+	for _, expected := range []string{
+		"Record serving revision\nDeploy to Cloud Run\nShift traffic to canary revision\nVerify deployment health",
+		"Roll back canary traffic\nHandle deployment failure",
+	} {
+		if !strings.Contains(order, expected) {
+			t.Errorf("Expected steps in order %q, got:\n%s", expected, order)
+		}
+	}
+
+	for _, expected := range []string{
+		"        tag: canary\n        no_traffic: ${{ steps.previous.outputs.revision != '' }}\n",
+		"        CANARY_STEPS: 25 100\n        CANARY_INTERVAL: 2m\n",
+		"--to-revisions=$CANARY_REVISION=$PERCENT",
+		"--to-revisions=$PREVIOUS_REVISION=100",
+		`"$SERVICE_URL/ready"`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected workflow to contain %q", expected)
+		}
+	}
+
+	if findings, _ := LintWorkflow("deploy.yml", content, LintExpectations{}); len(findings) > 0 {
+		t.Errorf("Expected no lint findings, got %+v", findings)
+	}
+}
+
+func TestCanaryValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		canary  CanaryConfig
+		target  string
+		message string
+	}{
+		{"decreasing steps", CanaryConfig{Steps: []int{50, 10, 100}}, "", "must increase"},
+		{"over 100", CanaryConfig{Steps: []int{50, 150}}, "", "must increase"},
+		{"partial rollout", CanaryConfig{Steps: []int{10, 50}}, "", "must route 100%"},
+		{"interval", CanaryConfig{Interval: "soon"}, "", "Invalid canary interval"},
+		{"tag", CanaryConfig{Tag: "Canary_1"}, "", "Invalid canary tag"},
+		{"target", CanaryConfig{}, TargetGKE, "require the cloud-run target"},
+	}
+
+	for _, test := range tests {
+		cfg := testWorkflowConfig(DefaultWorkflowConfig())
+		cfg.Target = test.target
+		cfg.GKE = GKEConfig{Cluster: "cluster"}
+		cfg.Advanced.Canary = test.canary
+		cfg.Advanced.Canary.Enabled = true
+		if err := cfg.ValidateConfig(); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+}
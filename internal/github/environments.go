@@ -0,0 +1,304 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// MaxEnvironmentReviewers is the number of required reviewers GitHub allows per environment
+const MaxEnvironmentReviewers = 6
+
+// RemoteEnvironment is the state of a deployment environment in the GitHub repository
+type RemoteEnvironment struct {
+	Name               string   `json:"name"`
+	WaitTimer          int      `json:"wait_timer"`
+	PreventSelfReview  bool     `json:"prevent_self_review"`
+	Reviewers          []string `json:"reviewers,omitempty"` // @login for users, org/slug for teams
+	ProtectedBranches  bool     `json:"protected_branches"`
+	DeploymentBranches []string `json:"deployment_branches,omitempty"`
+}
+
+// EnvironmentDrift is a setting of a GitHub environment that differs from the configuration
+type EnvironmentDrift struct {
+	Environment string `json:"environment"`
+	Field       string `json:"field"`
+	Expected    string `json:"expected"`
+	Actual      string `json:"actual"`
+}
+
+// environmentReviewer is a reviewer as the environments API references it
+type environmentReviewer struct {
+	Type string `json:"type"` // User or Team
+	ID   int64  `json:"id"`
+}
+
+// environmentPath returns the API path of a repository's environment
+func environmentPath(owner, repo, name string) string {
+	return fmt.Sprintf("/repos/%s/%s/environments/%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(name))
+}
+
+// GetEnvironment returns the state of a GitHub environment, nil when it does not exist
+func (c *APIClient) GetEnvironment(ctx context.Context, owner, repo, name string) (*RemoteEnvironment, error) {
+	scope := VariableScope{Owner: owner, Repo: repo, Environment: name}
+	var response struct {
+		Name            string `json:"name"`
+		ProtectionRules []struct {
+			Type              string `json:"type"`
+			WaitTimer         int    `json:"wait_timer"`
+			PreventSelfReview bool   `json:"prevent_self_review"`
+			Reviewers         []struct {
+				Type     string `json:"type"`
+				Reviewer struct {
+					Login string `json:"login"`
+					Slug  string `json:"slug"`
+				} `json:"reviewer"`
+			} `json:"reviewers"`
+		} `json:"protection_rules"`
+		DeploymentBranchPolicy *struct {
+			ProtectedBranches    bool `json:"protected_branches"`
+			CustomBranchPolicies bool `json:"custom_branch_policies"`
+		} `json:"deployment_branch_policy"`
+	}
+	status, err := c.do(ctx, scope, http.MethodGet, environmentPath(owner, repo, name), nil, &response)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remote := &RemoteEnvironment{Name: response.Name}
+	for _, rule := range response.ProtectionRules {
+		switch rule.Type {
+		case "wait_timer":
+			remote.WaitTimer = rule.WaitTimer
+		case "required_reviewers":
+			remote.PreventSelfReview = rule.PreventSelfReview
+			for _, reviewer := range rule.Reviewers {
+				if reviewer.Type == "Team" {
+					remote.Reviewers = append(remote.Reviewers, owner+"/"+reviewer.Reviewer.Slug)
+				} else {
+					remote.Reviewers = append(remote.Reviewers, "@"+reviewer.Reviewer.Login)
+				}
+			}
+		}
+	}
+
+	if policy := response.DeploymentBranchPolicy; policy != nil {
+		remote.ProtectedBranches = policy.ProtectedBranches
+		if policy.CustomBranchPolicies {
+			policies, err := c.listBranchPolicies(ctx, scope)
+			if err != nil {
+				return nil, err
+			}
+			for _, policy := range policies {
+				remote.DeploymentBranches = append(remote.DeploymentBranches, policy.Name)
+			}
+			sort.Strings(remote.DeploymentBranches)
+		}
+	}
+	return remote, nil
+}
+
+// ApplyEnvironment creates or updates a GitHub environment with the configured reviewers,
+// wait timer and deployment branch policy
+func (c *APIClient) ApplyEnvironment(ctx context.Context, owner, repo string, env Environment) error {
+	scope := VariableScope{Owner: owner, Repo: repo, Environment: env.Name}
+	protection := env.Protection
+
+	reviewers := make([]environmentReviewer, 0, len(protection.RequiredReviewers))
+	for _, name := range protection.RequiredReviewers {
+		reviewer, err := c.resolveReviewer(ctx, scope, name)
+		if err != nil {
+			return err
+		}
+		reviewers = append(reviewers, reviewer)
+	}
+
+	type branchPolicy struct {
+		ProtectedBranches    bool `json:"protected_branches"`
+		CustomBranchPolicies bool `json:"custom_branch_policies"`
+	}
+	body := struct {
+		WaitTimer              int                   `json:"wait_timer"`
+		PreventSelfReview      bool                  `json:"prevent_self_review"`
+		Reviewers              []environmentReviewer `json:"reviewers"`
+		DeploymentBranchPolicy *branchPolicy         `json:"deployment_branch_policy"`
+	}{
+		WaitTimer:         protection.WaitTimer,
+		PreventSelfReview: protection.PreventSelfReview,
+		Reviewers:         reviewers,
+	}
+	if protection.ProtectedBranches || len(protection.DeploymentBranches) > 0 {
+		body.DeploymentBranchPolicy = &branchPolicy{
+			ProtectedBranches:    protection.ProtectedBranches,
+			CustomBranchPolicies: len(protection.DeploymentBranches) > 0,
+		}
+	}
+	if _, err := c.do(ctx, scope, http.MethodPut, environmentPath(owner, repo, env.Name), body, nil); err != nil {
+		return err
+	}
+	if len(protection.DeploymentBranches) == 0 {
+		return nil
+	}
+	return c.syncBranchPolicies(ctx, scope, protection.DeploymentBranches)
+}
+
+type branchPolicyEntry struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// listBranchPolicies returns the custom deployment branch policies of an environment
+func (c *APIClient) listBranchPolicies(ctx context.Context, scope VariableScope) ([]branchPolicyEntry, error) {
+	var response struct {
+		BranchPolicies []branchPolicyEntry `json:"branch_policies"`
+	}
+	path := environmentPath(scope.Owner, scope.Repo, scope.Environment) + "/deployment-branch-policies?per_page=100"
+	if _, err := c.do(ctx, scope, http.MethodGet, path, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.BranchPolicies, nil
+}
+
+// syncBranchPolicies adds the missing deployment branch patterns and removes the others
+func (c *APIClient) syncBranchPolicies(ctx context.Context, scope VariableScope, branches []string) error {
+	existing, err := c.listBranchPolicies(ctx, scope)
+	if err != nil {
+		return err
+	}
+	base := environmentPath(scope.Owner, scope.Repo, scope.Environment) + "/deployment-branch-policies"
+
+	wanted := make(map[string]bool)
+	for _, branch := range branches {
+		wanted[branch] = true
+	}
+	for _, policy := range existing {
+		if wanted[policy.Name] {
+			delete(wanted, policy.Name)
+			continue
+		}
+		if _, err := c.do(ctx, scope, http.MethodDelete, base+"/"+strconv.FormatInt(policy.ID, 10), nil, nil); err != nil {
+			return err
+		}
+	}
+	for _, branch := range branches {
+		if !wanted[branch] {
+			continue
+		}
+		if _, err := c.do(ctx, scope, http.MethodPost, base, map[string]string{"name": branch, "type": "branch"}, nil); err != nil {
+			return err
+		}
+		delete(wanted, branch)
+	}
+	return nil
+}
+
+// resolveReviewer resolves a configured reviewer to its GitHub ID. org/team and @org/team
+// name teams; @name is a user, or a team of the repository owner when no such user exists.
+func (c *APIClient) resolveReviewer(ctx context.Context, scope VariableScope, reviewer string) (environmentReviewer, error) {
+	name := strings.TrimPrefix(strings.TrimSpace(reviewer), "@")
+	var account struct {
+		ID int64 `json:"id"`
+	}
+
+	org, team, isTeam := strings.Cut(name, "/")
+	if !isTeam {
+		status, err := c.do(ctx, scope, http.MethodGet, "/users/"+url.PathEscape(name), nil, &account)
+		if err == nil {
+			return environmentReviewer{Type: "User", ID: account.ID}, nil
+		}
+		if status != http.StatusNotFound {
+			return environmentReviewer{}, err
+		}
+		org, team = scope.Owner, name
+	}
+
+	status, err := c.do(ctx, scope, http.MethodGet, fmt.Sprintf("/orgs/%s/teams/%s", url.PathEscape(org), url.PathEscape(team)), nil, &account)
+	if status == http.StatusNotFound {
+		return environmentReviewer{}, errors.NewGitHubError(fmt.Sprintf("Reviewer '%s' is neither a GitHub user nor a team of %s", reviewer, org),
+			"Use @username for users and org/team for teams",
+			"Teams are only visible to credentials with the organization Members (read) permission")
+	}
+	if err != nil {
+		return environmentReviewer{}, err
+	}
+	return environmentReviewer{Type: "Team", ID: account.ID}, nil
+}
+
+// CompareEnvironment reports how a GitHub environment differs from its configuration. A
+// missing environment is reported as a single drift.
+func CompareEnvironment(owner string, env Environment, remote *RemoteEnvironment) []EnvironmentDrift {
+	if remote == nil {
+		return []EnvironmentDrift{{Environment: env.Name, Field: "environment", Expected: "exists", Actual: "missing"}}
+	}
+
+	var drifts []EnvironmentDrift
+	add := func(field, expected, actual string) {
+		if expected != actual {
+			drifts = append(drifts, EnvironmentDrift{Environment: env.Name, Field: field, Expected: expected, Actual: actual})
+		}
+	}
+	protection := env.Protection
+	add("wait_timer", strconv.Itoa(protection.WaitTimer), strconv.Itoa(remote.WaitTimer))
+
+	// A bare @name matches a user or a team of the owner, whichever it resolved to
+	var expected, actual []string
+	for _, reviewer := range protection.RequiredReviewers {
+		expected = append(expected, normalizeReviewer(reviewer))
+	}
+	for _, reviewer := range remote.Reviewers {
+		normalized := normalizeReviewer(reviewer)
+		for _, want := range expected {
+			if strings.HasPrefix(want, "@") && normalized == strings.ToLower(owner)+"/"+strings.TrimPrefix(want, "@") {
+				normalized = want
+			}
+		}
+		actual = append(actual, normalized)
+	}
+	sort.Strings(expected)
+	sort.Strings(actual)
+	add("required_reviewers", strings.Join(expected, ", "), strings.Join(actual, ", "))
+	if len(protection.RequiredReviewers) > 0 || len(remote.Reviewers) > 0 {
+		add("prevent_self_review", strconv.FormatBool(protection.PreventSelfReview), strconv.FormatBool(remote.PreventSelfReview))
+	}
+
+	add("deployment_branches", protection.DeploymentBranchPolicy(),
+		describeBranchPolicy(remote.ProtectedBranches, remote.DeploymentBranches))
+	return drifts
+}
+
+// normalizeReviewer returns the lower-case @login or org/team form of a reviewer
+func normalizeReviewer(reviewer string) string {
+	reviewer = strings.ToLower(strings.TrimSpace(reviewer))
+	if strings.Contains(reviewer, "/") {
+		return strings.TrimPrefix(reviewer, "@")
+	}
+	return "@" + strings.TrimPrefix(reviewer, "@")
+}
+
+// DeploymentBranchPolicy describes the branches allowed to deploy to the environment
+func (p EnvironmentProtection) DeploymentBranchPolicy() string {
+	return describeBranchPolicy(p.ProtectedBranches, p.DeploymentBranches)
+}
+
+// describeBranchPolicy returns a readable form of a deployment branch policy
+func describeBranchPolicy(protected bool, branches []string) string {
+	switch {
+	case protected:
+		return "protected branches"
+	case len(branches) > 0:
+		sorted := append([]string(nil), branches...)
+		sort.Strings(sorted)
+		return strings.Join(sorted, ", ")
+	default:
+		return "all branches"
+	}
+}
@@ -0,0 +1,175 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeEnvironments is a stand-in for the environments and deployment branch policy
+// endpoints, storing what was written in the shape GitHub returns it
+type fakeEnvironments struct {
+	mu           sync.Mutex
+	environments map[string]map[string]interface{}
+	policies     map[string][]branchPolicyEntry
+	nextID       int64
+}
+
+func (f *fakeEnvironments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := r.URL.Path
+	users := map[string]int64{"alice": 1, "bob": 2}
+	teams := map[string]int64{"acme/platform": 10, "acme/production-team": 11}
+	logins := map[int64]string{1: "alice", 2: "bob"}
+	slugs := map[int64]string{10: "platform", 11: "production-team"}
+
+	switch {
+	case strings.HasPrefix(path, "/users/"):
+		if id, ok := users[strings.TrimPrefix(path, "/users/")]; ok {
+			_ = json.NewEncoder(w).Encode(map[string]int64{"id": id})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case strings.HasPrefix(path, "/orgs/"):
+		parts := strings.Split(path, "/") // "", orgs, org, teams, slug
+		if id, ok := teams[parts[2]+"/"+parts[4]]; ok {
+			_ = json.NewEncoder(w).Encode(map[string]int64{"id": id})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case strings.Contains(path, "/deployment-branch-policies"):
+		env := strings.Split(path, "/")[5]
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"branch_policies": f.policies[env]})
+		case http.MethodPost:
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			f.nextID++
+			f.policies[env] = append(f.policies[env], branchPolicyEntry{ID: f.nextID, Name: body["name"]})
+		case http.MethodDelete:
+			id := path[strings.LastIndex(path, "/")+1:]
+			var kept []branchPolicyEntry
+			for _, policy := range f.policies[env] {
+				if id != strconv.FormatInt(policy.ID, 10) {
+					kept = append(kept, policy)
+				}
+			}
+			f.policies[env] = kept
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case strings.HasPrefix(path, "/repos/acme/app/environments/"):
+		env := strings.TrimPrefix(path, "/repos/acme/app/environments/")
+		if r.Method == http.MethodGet {
+			if stored, ok := f.environments[env]; ok {
+				_ = json.NewEncoder(w).Encode(stored)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body struct {
+			WaitTimer              int                    `json:"wait_timer"`
+			PreventSelfReview      bool                   `json:"prevent_self_review"`
+			Reviewers              []environmentReviewer  `json:"reviewers"`
+			DeploymentBranchPolicy map[string]interface{} `json:"deployment_branch_policy"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		var reviewers []map[string]interface{}
+		for _, reviewer := range body.Reviewers {
+			entry := map[string]interface{}{"type": reviewer.Type}
+			if reviewer.Type == "Team" {
+				entry["reviewer"] = map[string]string{"slug": slugs[reviewer.ID]}
+			} else {
+				entry["reviewer"] = map[string]string{"login": logins[reviewer.ID]}
+			}
+			reviewers = append(reviewers, entry)
+		}
+		f.environments[env] = map[string]interface{}{
+			"name": env,
+			"protection_rules": []map[string]interface{}{
+				{"type": "wait_timer", "wait_timer": body.WaitTimer},
+				{"type": "required_reviewers", "prevent_self_review": body.PreventSelfReview, "reviewers": reviewers},
+			},
+			"deployment_branch_policy": body.DeploymentBranchPolicy,
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestApplyEnvironment(t *testing.T) {
+	fake := &fakeEnvironments{environments: make(map[string]map[string]interface{}), policies: make(map[string][]branchPolicyEntry)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := NewAPIClient(APIClientConfig{BaseURL: server.URL, Token: "test-token"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	env := Environment{Name: "production", Protection: EnvironmentProtection{
+		RequiredReviewers:  []string{"@alice", "acme/platform", "@production-team"},
+		WaitTimer:          5,
+		PreventSelfReview:  true,
+		DeploymentBranches: []string{"main", "release/*"},
+	}}
+
+	remote, err := client.GetEnvironment(ctx, "acme", "app", env.Name)
+	if err != nil || remote != nil {
+		t.Fatalf("Expected a missing environment, got %+v, %v", remote, err)
+	}
+	if drifts := CompareEnvironment("acme", env, remote); len(drifts) != 1 || drifts[0].Actual != "missing" {
+		t.Errorf("Expected the missing environment to be reported, got %+v", drifts)
+	}
+
+	fake.policies["production"] = []branchPolicyEntry{{ID: 100, Name: "develop"}}
+	if err := client.ApplyEnvironment(ctx, "acme", "app", env); err != nil {
+		t.Fatalf("Failed to apply environment: %v", err)
+	}
+	remote, err = client.GetEnvironment(ctx, "acme", "app", env.Name)
+	if err != nil || remote == nil {
+		t.Fatalf("Expected the environment to exist, got %v", err)
+	}
+	if drifts := CompareEnvironment("acme", env, remote); len(drifts) > 0 {
+		t.Errorf("Expected no drift after applying, got %+v (remote %+v)", drifts, remote)
+	}
+	if strings.Join(remote.DeploymentBranches, ",") != "main,release/*" {
+		t.Errorf("Expected stale branch policies to be replaced, got %v", remote.DeploymentBranches)
+	}
+
+	// Settings changed in GitHub are reported field by field
+	env.Protection.WaitTimer = 10
+	env.Protection.RequiredReviewers = []string{"@bob"}
+	drifts := CompareEnvironment("acme", env, remote)
+	fields := make([]string, 0, len(drifts))
+	for _, drift := range drifts {
+		fields = append(fields, drift.Field)
+	}
+	if strings.Join(fields, ",") != "wait_timer,required_reviewers" {
+		t.Errorf("Expected wait timer and reviewer drift, got %+v", drifts)
+	}
+
+	env.Protection.RequiredReviewers = []string{"@nobody"}
+	if err := client.ApplyEnvironment(ctx, "acme", "app", env); err == nil || !strings.Contains(err.Error(), "neither a GitHub user nor a team") {
+		t.Errorf("Expected an unknown reviewer to be rejected, got %v", err)
+	}
+}
+
+func TestValidateEnvironmentBranchPolicy(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.AddEnvironment("production", Environment{Name: "production", Protection: EnvironmentProtection{
+		ProtectedBranches:  true,
+		DeploymentBranches: []string{"main"},
+	}})
+	if err := cfg.ValidateEnvironments(); err == nil || !strings.Contains(err.Error(), "choose one") {
+		t.Errorf("Expected both branch policies to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// LocalCheckout is a git working tree whose origin is a GitHub repository
+type LocalCheckout struct {
+	Dir string // root of the working tree
+}
+
+// FindLocalCheckout returns the git working tree containing dir when its origin remote is
+// the repository, or nil when there is none and changes have to go through the API
+func FindLocalCheckout(dir, owner, repo string) *LocalCheckout {
+	root, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	remote, err := runGit(dir, "remote", "get-url", "origin")
+	if err != nil {
+		return nil
+	}
+	remoteOwner, remoteRepo, ok := ParseRemoteRepository(remote)
+	if !ok || !strings.EqualFold(remoteOwner, owner) || !strings.EqualFold(remoteRepo, repo) {
+		return nil
+	}
+	return &LocalCheckout{Dir: root}
+}
+
+// ParseRemoteRepository returns the owner and name of the repository a GitHub remote URL
+// points to, in its https, ssh or scp-like form
+func ParseRemoteRepository(remote string) (owner, repo string, ok bool) {
+	remote = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(remote), "/"), ".git")
+	if i := strings.Index(remote, "://"); i >= 0 {
+		remote = remote[i+3:]
+	} else if _, after, found := strings.Cut(remote, ":"); found {
+		remote = "host/" + after // git@github.com:owner/repo
+	}
+	parts := strings.Split(remote, "/")
+	if len(parts) < 3 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", "", false
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], true
+}
+
+// RelativePath returns a path, absolute or relative to the current directory, relative to
+// the root of the working tree
+func (l *LocalCheckout) RelativePath(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.NewInternalError(fmt.Sprintf("Failed to resolve %s", path), err)
+	}
+	root, err := filepath.EvalSymlinks(l.Dir)
+	if err != nil {
+		root = l.Dir
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(absolute)); err == nil {
+		absolute = filepath.Join(dir, filepath.Base(absolute))
+	}
+	relative, err := filepath.Rel(root, absolute)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", errors.NewValidationError(fmt.Sprintf("%s is outside the git working tree %s", path, l.Dir))
+	}
+	return filepath.ToSlash(relative), nil
+}
+
+// CommitAndPush commits the pull request's files to the pull request's branch, created
+// from the base branch as just fetched from origin, and pushes it to origin. The commit is
+// made in a temporary worktree, so the working tree, its local changes and its current
+// branch are left as they are.
+func (l *LocalCheckout) CommitAndPush(pr *PullRequest) error {
+	base := pr.Base
+	if base == "" {
+		head, err := runGit(l.Dir, "rev-parse", "--abbrev-ref", "origin/HEAD")
+		if err != nil {
+			return errors.NewGitHubError("Failed to find the default branch of origin",
+				"Run 'git remote set-head origin --auto', or set the pull request's base branch")
+		}
+		base = strings.TrimPrefix(head, "origin/")
+	}
+	if _, err := runGit(l.Dir, "fetch", "origin", base); err != nil {
+		return err
+	}
+
+	worktree, err := os.MkdirTemp("", "gcp-wif-pr-")
+	if err != nil {
+		return errors.WrapError(err, errors.ErrorTypeFileSystem, "DIRECTORY_CREATE_FAILED",
+			"Failed to create a temporary worktree directory")
+	}
+	defer os.RemoveAll(worktree)
+	if _, err := runGit(l.Dir, "worktree", "add", "--no-track", "-b", pr.Branch, worktree, "FETCH_HEAD"); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = runGit(l.Dir, "worktree", "remove", "--force", worktree)
+	}()
+
+	paths := make([]string, 0, len(pr.Files))
+	for _, file := range pr.Files {
+		path := filepath.Join(worktree, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.WrapError(err, errors.ErrorTypeFileSystem, "DIRECTORY_CREATE_FAILED",
+				fmt.Sprintf("Failed to create directory for %s", file.Path))
+		}
+		if err := os.WriteFile(path, file.Content, 0644); err != nil {
+			return errors.WrapError(err, errors.ErrorTypeFileSystem, "FILE_WRITE_FAILED",
+				fmt.Sprintf("Failed to write %s", file.Path))
+		}
+		paths = append(paths, file.Path)
+	}
+
+	if _, err := runGit(worktree, append([]string{"add", "--"}, paths...)...); err != nil {
+		return err
+	}
+	if _, err := runGit(worktree, "commit", "-m", pr.CommitMessage); err != nil {
+		return err
+	}
+	_, err = runGit(worktree, "push", "--set-upstream", "origin", pr.Branch)
+	return err
+}
+
+// runGit runs a git command in dir and returns its trimmed output
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.WrapError(err, errors.ErrorTypeGitHub, "GIT_COMMAND_FAILED",
+			fmt.Sprintf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(string(output))))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,821 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkflowImport is a workflow file read back into a WorkflowConfig
+type WorkflowImport struct {
+	Config *WorkflowConfig `json:"config"`
+	// Unrecognized lists the constructs without a WorkflowConfig equivalent, which are not
+	// kept when the workflow is regenerated from the configuration
+	Unrecognized []WorkflowIssue `json:"unrecognized,omitempty"`
+}
+
+var (
+	secretAssignRegex  = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=\$\{\{\s*secrets\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+	pinnedUsesRegex    = regexp.MustCompile(`@[0-9a-f]{40}$`)
+	refBranchRegex     = regexp.MustCompile(`refs/heads/([^'"\s]+)`)
+	allowedActorsRegex = regexp.MustCompile(`ALLOWED_ACTORS="([^"]*)"`)
+
+	headerDescriptionRegex = regexp.MustCompile(`^# (.+)$`)
+	headerVersionRegex     = regexp.MustCompile(`^# Generated (?:on .* )?by GCP WIF CLI Tool v(\S*)$`)
+	headerRepositoryRegex  = regexp.MustCompile(`^# Repository: (\S+)$`)
+	headerProjectRegex     = regexp.MustCompile(`^# Project: (\S+)$`)
+
+	healthCheckScriptRegex = regexp.MustCompile(`(?s)echo "Running (\S+) health check\.\.\."\s+for i in \{1\.\.(\d+)\}; do\s+` +
+		`RESPONSE_CODE=\$\(curl -s -o /dev/null -w "%\{http_code\}" -X (\w+) --max-time (\S+) "\$SERVICE_URL([^"]*)".*?` +
+		`if \[ "\$RESPONSE_CODE" = "(\d+)" \].*?sleep (\S+)`)
+
+	helmReleaseRegex   = regexp.MustCompile(`helm upgrade --install (\S+) (\S+)`)
+	kubectlImageRegex  = regexp.MustCompile(`kubectl set image deployment/(\S+) ([^=\s]+)=`)
+	kubectlApplyRegex  = regexp.MustCompile(`kubectl apply (?:--namespace \S+ )?-f (\S+)`)
+	namespaceRegex     = regexp.MustCompile(`--namespace (\S+)`)
+	outputURLRegex     = regexp.MustCompile(`echo "url=(https?://[^"$]+)"`)
+	firebaseSiteRegex  = regexp.MustCompile(`https://([a-z0-9-]+)\.web\.app`)
+	firebaseChanRegex  = regexp.MustCompile(`hosting:channel:deploy (\S+)`)
+	deployFlagRegex    = regexp.MustCompile(`^--([a-z-]+)(?:=(.*))?$`)
+	notifyStepRegex    = regexp.MustCompile(`^Notify (\S+) \((\w+)\)$`)
+	secretRefNameRegex = regexp.MustCompile(`^\$\{\{\s*secrets\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+	varsReferenceRegex = regexp.MustCompile(`^\$\{\{\s*vars\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+	attestorFlagRegex  = regexp.MustCompile(`--attestor="?([^"\s]+)`)
+	keyVersionRegex    = regexp.MustCompile(`--keyversion="?([^"\s]+)`)
+)
+
+// workflowEnvFields are the workflow-level env variables the generator renders from
+// WorkflowConfig fields; any other variable is imported into EnvVars
+var workflowEnvFields = keySet("PROJECT_ID", "PROJECT_NUMBER", "REGION", "SERVICE_NAME", "REGISTRY", "IMAGE_NAME",
+	"IMAGE_TAG", "DOCKERFILE_PATH", "BUILD_CONTEXT", "WORKLOAD_IDENTITY_PROVIDER", "SERVICE_ACCOUNT",
+	"MAX_TOKEN_LIFETIME", "PORT", "TF_IN_AUTOMATION", "TF_INPUT", "TF_WORKING_DIR", "TF_STATE_BUCKET",
+	"TF_STATE_PREFIX", "PLAN_WORKLOAD_IDENTITY_PROVIDER", "PLAN_SERVICE_ACCOUNT",
+	"APPLY_WORKLOAD_IDENTITY_PROVIDER", "APPLY_SERVICE_ACCOUNT")
+
+// generatedDeployFlags are the Cloud Run deploy flags the generator always renders
+var generatedDeployFlags = keySet("max-instances", "min-instances", "concurrency", "timeout", "allow-unauthenticated", "ingress")
+
+// importedActions are the actions whose steps are rendered from the configuration
+var importedActions = []string{
+	"actions/checkout", "actions/github-script", "actions/setup-node", "google-github-actions/auth",
+	"google-github-actions/setup-gcloud", "google-github-actions/deploy-cloudrun", "google-github-actions/get-gke-credentials",
+	"google-github-actions/deploy-cloud-functions", "google-github-actions/deploy-appengine", "docker/setup-buildx-action",
+	"docker/login-action", "docker/build-push-action", "hashicorp/setup-terraform", "sigstore/cosign-installer",
+}
+
+// importedSteps are the names of generated run steps that carry no settings of their own
+// or whose settings are read by the importer
+var importedSteps = keySet("Checkout code", "Determine environment", "Block forked repositories", "Verify signed commits",
+	"Verify allowed actors", "Security gate", "Set up build environment", "Verify authentication", "Verify deployment health",
+	"Update deployment status", "Handle deployment failure", "Record serving revision", "Shift traffic to canary revision",
+	"Roll back canary traffic", "Deploy to GKE with Helm", "Deploy to GKE with kubectl", "Record App Engine URL",
+	"Build site", "Deploy to Firebase Hosting", "Cleanup failed deployment")
+
+// ImportWorkflowFile reads a workflow file back into a WorkflowConfig
+func ImportWorkflowFile(path string) (*WorkflowImport, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow file: %w", err)
+	}
+	imported, err := ImportWorkflow(string(content))
+	if err != nil {
+		return nil, err
+	}
+	imported.Config.Path = filepath.Dir(path)
+	imported.Config.Filename = filepath.Base(path)
+	return imported, nil
+}
+
+// ImportWorkflow reads a generated or hand-written workflow into a WorkflowConfig: triggers,
+// env, the authentication identity, the deployment target settings, build options, health
+// checks and security gates. Constructs without a configuration equivalent are reported.
+func ImportWorkflow(content string) (*WorkflowImport, error) {
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		return nil, err
+	}
+
+	importer := &workflowImporter{
+		workflow: workflow,
+		cfg:      DefaultWorkflowConfig(),
+		result:   &WorkflowImport{},
+	}
+	importer.importHeader(content)
+	importer.importWorkflow()
+
+	importer.result.Config = importer.cfg
+	sort.SliceStable(importer.result.Unrecognized, func(i, j int) bool {
+		return importer.result.Unrecognized[i].Line < importer.result.Unrecognized[j].Line
+	})
+	return importer.result, nil
+}
+
+// workflowImporter fills a WorkflowConfig from a parsed workflow
+type workflowImporter struct {
+	workflow *ParsedWorkflow
+	cfg      *WorkflowConfig
+	result   *WorkflowImport
+	job      *WorkflowJob // Job whose settings are resolved
+
+	headerProject string // Project named in the generated header
+}
+
+func (im *workflowImporter) unrecognized(line int, path, format string, args ...interface{}) {
+	im.result.Unrecognized = append(im.result.Unrecognized, WorkflowIssue{
+		Line:    line,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve returns the value of a ${{ env.NAME }} reference from the job or workflow env,
+// or the value itself. Values read from Actions variables are unknown and resolve to "".
+func (im *workflowImporter) resolve(value string) string {
+	if match := envReferenceRegex.FindStringSubmatch(strings.TrimSpace(value)); match != nil {
+		if resolved, ok := im.workflow.Env[match[1]]; ok {
+			value = resolved
+		}
+		if im.job != nil {
+			if resolved, ok := im.job.Env[match[1]]; ok {
+				value = resolved
+			}
+		}
+	}
+	if varsReferenceRegex.MatchString(strings.TrimSpace(value)) {
+		return ""
+	}
+	return value
+}
+
+// importHeader reads the description, version and repository from a generated header
+func (im *workflowImporter) importHeader(content string) {
+	lines := strings.Split(content, "\n")
+	if len(lines) < 2 || !headerVersionRegex.MatchString(lines[1]) {
+		return
+	}
+	im.cfg.Version = headerVersionRegex.FindStringSubmatch(lines[1])[1]
+	if match := headerDescriptionRegex.FindStringSubmatch(lines[0]); match != nil {
+		im.cfg.Description = match[1]
+	}
+	for _, line := range lines[2:] {
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if match := headerRepositoryRegex.FindStringSubmatch(line); match != nil {
+			im.cfg.Repository = match[1]
+		}
+		if match := headerProjectRegex.FindStringSubmatch(line); match != nil {
+			im.headerProject = match[1]
+		}
+	}
+}
+
+func (im *workflowImporter) importWorkflow() {
+	cfg := im.cfg
+	cfg.Name = im.workflow.Name
+
+	// Settings the generator renders only when enabled start out disabled
+	cfg.Triggers = WorkflowTriggers{}
+	cfg.Security.BlockForkedRepos = false
+	cfg.Security.RequireApproval = false
+	cfg.Advanced.Timeout = ""
+	cfg.Advanced.Concurrency = ConcurrencyConfig{}
+	cfg.Port = 0
+
+	root := im.workflow.Root.Content[0]
+	if on := mappingValue(root, "on"); on != nil {
+		im.importTriggers(on)
+	}
+	if concurrency := mappingValue(root, "concurrency"); concurrency != nil {
+		if concurrency.Kind == yaml.ScalarNode {
+			cfg.Advanced.Concurrency.Group = concurrency.Value
+		} else {
+			cfg.Advanced.Concurrency.Group = scalarValue(mappingValue(concurrency, "group"))
+			// Terraform workflows only cancel runs in progress for pull requests
+			cancel := scalarValue(mappingValue(concurrency, "cancel-in-progress"))
+			cfg.Advanced.Concurrency.CancelInProgress = cancel == "true" || strings.Contains(cancel, "github.event_name == 'pull_request'")
+		}
+	}
+	if permissions := mappingValue(root, "permissions"); permissions != nil {
+		im.unrecognized(permissions.Line, "permissions", "workflow-level permissions are not configurable; jobs declare their own")
+	}
+	im.importEnv()
+
+	for _, job := range im.workflow.Jobs {
+		for _, step := range job.Steps {
+			if pinnedUsesRegex.MatchString(step.Uses) {
+				cfg.Security.PinActions = true
+			}
+		}
+		switch {
+		case job.ID == "security-checks":
+			im.importSecurityJob(job)
+		case job.ID == "plan" && hasStep(job, "hashicorp/setup-terraform"):
+			im.importTerraformJob(job, true)
+		case job.ID == "apply" && hasStep(job, "hashicorp/setup-terraform"):
+			im.importTerraformJob(job, false)
+		case job.ID == "cleanup":
+		case strings.HasPrefix(job.Uses, "slsa-framework/slsa-github-generator/"):
+			cfg.Security.SLSAProvenance = true
+		case job.ID == BuildJobID && hasStep(job, "docker/build-push-action"):
+			im.importBuildJob(job)
+		case im.isDeployJob(job) && im.job == nil:
+			im.importDeployJob(job)
+		case im.isDeployJob(job):
+			im.unrecognized(job.Line, "jobs."+job.ID, "only the first deploy job is imported; configure environments to deploy '%s'", job.ID)
+		default:
+			im.unrecognized(job.Line, "jobs."+job.ID, "job '%s' is not generated from the configuration", job.ID)
+		}
+	}
+}
+
+// isDeployJob reports whether a job authenticates to Google Cloud and deploys
+func (im *workflowImporter) isDeployJob(job *WorkflowJob) bool {
+	return hasStep(job, "google-github-actions/auth")
+}
+
+func (im *workflowImporter) importTriggers(on *yaml.Node) {
+	triggers := &im.cfg.Triggers
+	var events [][2]*yaml.Node
+	switch on.Kind {
+	case yaml.ScalarNode:
+		events = append(events, [2]*yaml.Node{on, nil})
+	case yaml.SequenceNode:
+		for _, event := range on.Content {
+			events = append(events, [2]*yaml.Node{event, nil})
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(on.Content); i += 2 {
+			events = append(events, [2]*yaml.Node{on.Content[i], on.Content[i+1]})
+		}
+	}
+
+	for _, event := range events {
+		name, config := event[0].Value, event[1]
+		path := "on." + name
+		switch name {
+		case "push":
+			triggers.Push.Enabled = true
+			triggers.Push.Branches = nodeStrings(mappingValue(config, "branches"))
+			triggers.Push.Tags = nodeStrings(mappingValue(config, "tags"))
+			triggers.Push.Paths = nodeStrings(mappingValue(config, "paths"))
+			triggers.Push.Ignore = nodeStrings(mappingValue(config, "paths-ignore"))
+			im.unrecognizedKeys(config, path, "branches", "tags", "paths", "paths-ignore")
+		case "pull_request":
+			triggers.PullRequest.Enabled = true
+			triggers.PullRequest.Branches = nodeStrings(mappingValue(config, "branches"))
+			triggers.PullRequest.Types = nodeStrings(mappingValue(config, "types"))
+			triggers.PullRequest.Paths = nodeStrings(mappingValue(config, "paths"))
+			triggers.PullRequest.Ignore = nodeStrings(mappingValue(config, "paths-ignore"))
+			im.unrecognizedKeys(config, path, "branches", "types", "paths", "paths-ignore")
+		case "workflow_dispatch":
+			triggers.Manual = true
+			if inputs := mappingValue(config, "inputs"); inputs != nil {
+				im.unrecognizedKeys(inputs, path+".inputs", "environment", "force_deploy", "debug_mode")
+			}
+		case "release":
+			triggers.Release = true
+		case "schedule":
+			if config == nil {
+				continue
+			}
+			for _, entry := range config.Content {
+				cron := mappingValue(entry, "cron")
+				if cron == nil {
+					continue
+				}
+				triggers.Schedule = append(triggers.Schedule, ScheduleTrigger{
+					Cron:        cron.Value,
+					Description: strings.TrimSpace(strings.TrimPrefix(cron.LineComment, "#")),
+				})
+			}
+		default:
+			im.unrecognized(event[0].Line, path, "trigger '%s' is not supported", name)
+		}
+	}
+}
+
+// unrecognizedKeys reports the keys of a mapping the configuration cannot represent
+func (im *workflowImporter) unrecognizedKeys(node *yaml.Node, path string, known ...string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	allowed := keySet(known...)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; !allowed[key.Value] {
+			im.unrecognized(key.Line, joinWorkflowPath(path, key.Value), "'%s' is not supported", key.Value)
+		}
+	}
+}
+
+func (im *workflowImporter) importEnv() {
+	cfg := im.cfg
+	env := im.workflow.Env
+	cfg.ProjectID = env["PROJECT_ID"]
+	cfg.ProjectNumber = env["PROJECT_NUMBER"]
+	cfg.Region = env["REGION"]
+	cfg.ServiceName = env["SERVICE_NAME"]
+	cfg.WorkloadIdentityProvider = env["WORKLOAD_IDENTITY_PROVIDER"]
+	cfg.ServiceAccountEmail = env["SERVICE_ACCOUNT"]
+
+	// The values of Actions variables are not in the workflow; the project ID is still
+	// named in the generated header
+	for _, field := range []*string{&cfg.ProjectID, &cfg.WorkloadIdentityProvider, &cfg.ServiceAccountEmail} {
+		if varsReferenceRegex.MatchString(*field) {
+			cfg.UseActionsVariables = true
+			*field = ""
+		}
+	}
+	if cfg.ProjectID == "" {
+		cfg.ProjectID = im.headerProject
+	}
+	if lifetime := env["MAX_TOKEN_LIFETIME"]; lifetime != "" {
+		cfg.Security.MaxTokenLifetime = lifetime
+	}
+	if registry := env["REGISTRY"]; registry != "" && registry != fmt.Sprintf("%s-docker.pkg.dev", cfg.Region) {
+		cfg.Registry = registry
+	}
+	if dockerfile := env["DOCKERFILE_PATH"]; dockerfile != "" {
+		cfg.DockerfilePath = dockerfile
+	}
+	if context := env["BUILD_CONTEXT"]; context != "" {
+		cfg.BuildContext = context
+	}
+	if port := env["PORT"]; port != "" {
+		if value, err := strconv.Atoi(port); err == nil {
+			cfg.Port = value
+		} else {
+			im.unrecognized(0, "env.PORT", "port '%s' is not a number", port)
+		}
+	}
+	if image := env["IMAGE_NAME"]; image != "" && image != cfg.ServiceName {
+		im.unrecognized(0, "env.IMAGE_NAME", "image name '%s' differs from the service name and is not configurable", image)
+	}
+
+	for name, value := range env {
+		if workflowEnvFields[name] {
+			continue
+		}
+		if cfg.EnvVars == nil {
+			cfg.EnvVars = make(map[string]string)
+		}
+		cfg.EnvVars[name] = value
+	}
+	if tfDir, ok := env["TF_WORKING_DIR"]; ok {
+		cfg.Kind = WorkflowKindTerraform
+		cfg.Terraform.WorkingDirectory = tfDir
+		cfg.Terraform.StateBucket = env["TF_STATE_BUCKET"]
+		cfg.Terraform.StatePrefix = env["TF_STATE_PREFIX"]
+	}
+}
+
+func (im *workflowImporter) importSecurityJob(job *WorkflowJob) {
+	security := &im.cfg.Security
+	if job.Environment != "" {
+		security.RequireApproval = true
+	}
+	for _, step := range job.Steps {
+		switch step.Name {
+		case "Block forked repositories":
+			security.BlockForkedRepos = true
+		case "Verify signed commits":
+			security.RequireSignedCommits = true
+		case "Verify allowed actors":
+			if match := allowedActorsRegex.FindStringSubmatch(step.Run); match != nil {
+				security.AllowedActors = strings.Fields(match[1])
+			}
+		default:
+			im.checkStep(job, step)
+		}
+	}
+}
+
+func (im *workflowImporter) importTerraformJob(job *WorkflowJob, plan bool) {
+	im.job = job
+	defer func() { im.job = nil }()
+
+	terraform := &im.cfg.Terraform
+	im.cfg.Kind = WorkflowKindTerraform
+	if job.TimeoutMinutes != "" {
+		im.cfg.Advanced.Timeout = job.TimeoutMinutes + "m"
+	}
+	for _, step := range job.Steps {
+		switch {
+		case usesAction(step, "google-github-actions/auth"):
+			provider, account := im.resolve(step.With["workload_identity_provider"]), im.resolve(step.With["service_account"])
+			if plan {
+				terraform.PlanWorkloadIdentityProvider, terraform.PlanServiceAccountEmail = provider, account
+			} else {
+				im.cfg.WorkloadIdentityProvider, im.cfg.ServiceAccountEmail = provider, account
+			}
+		case usesAction(step, "hashicorp/setup-terraform"):
+			terraform.Version = step.With["terraform_version"]
+		}
+	}
+	if !plan {
+		terraform.ApplyEnvironment = job.Environment
+		if match := refBranchRegex.FindStringSubmatch(job.If); match != nil {
+			terraform.ApplyBranch = match[1]
+		}
+	}
+}
+
+func (im *workflowImporter) importDeployJob(job *WorkflowJob) {
+	im.job = job
+	cfg := im.cfg
+
+	if job.TimeoutMinutes != "" {
+		cfg.Advanced.Timeout = job.TimeoutMinutes + "m"
+	}
+	if strings.Contains(job.Environment, "needs.security-checks.outputs.environment") {
+		cfg.Security.RequireApproval = true
+	}
+	if len(job.Matrix) > 0 {
+		im.unrecognized(job.Line, "jobs."+job.ID+".strategy", "the matrix strategy is not imported; configure it with --matrix")
+	}
+
+	hooks := make(map[string]*NotificationHook)
+	var hookOrder []string
+	for _, step := range job.Steps {
+		switch {
+		case usesAction(step, "google-github-actions/auth"):
+			if provider := im.resolve(step.With["workload_identity_provider"]); provider != "" {
+				cfg.WorkloadIdentityProvider = provider
+			}
+			if account := im.resolve(step.With["service_account"]); account != "" {
+				cfg.ServiceAccountEmail = account
+			}
+			if lifetime := im.resolve(step.With["access_token_lifetime"]); lifetime != "" {
+				cfg.Security.MaxTokenLifetime = lifetime
+			}
+		case im.importBuildStep(step):
+		case usesAction(step, "google-github-actions/deploy-cloudrun"):
+			im.importCloudRun(step)
+		case usesAction(step, "google-github-actions/get-gke-credentials"):
+			cfg.Target = TargetGKE
+			cfg.GKE.Cluster = im.resolve(step.With["cluster_name"])
+			if location := im.resolve(step.With["location"]); location != cfg.Region {
+				cfg.GKE.Location = location
+			}
+		case usesAction(step, "google-github-actions/deploy-cloud-functions"):
+			im.importCloudFunctions(step)
+		case usesAction(step, "google-github-actions/deploy-appengine"):
+			cfg.Target = TargetAppEngine
+			cfg.AppEngine.WorkingDirectory = step.With["working_directory"]
+			cfg.AppEngine.Deliverables = step.With["deliverables"]
+			cfg.AppEngine.Version = step.With["version"]
+			cfg.AppEngine.NoPromote = step.With["promote"] == "false"
+			im.importAssignments(step.With["env_vars"], &cfg.EnvVars)
+		case usesAction(step, "actions/setup-node"):
+			cfg.Firebase.NodeVersion = step.With["node-version"]
+		case step.Name == "Deploy to GKE with Helm":
+			cfg.GKE.Deployer = "helm"
+			if match := helmReleaseRegex.FindStringSubmatch(step.Run); match != nil {
+				cfg.GKE.Release, cfg.GKE.Chart = match[1], match[2]
+			}
+			im.importGKEScript(step.Run)
+		case step.Name == "Deploy to GKE with kubectl":
+			if match := kubectlImageRegex.FindStringSubmatch(step.Run); match != nil {
+				cfg.GKE.Deployment, cfg.GKE.Container = match[1], match[2]
+			}
+			if match := kubectlApplyRegex.FindStringSubmatch(step.Run); match != nil {
+				cfg.GKE.Manifests = match[1]
+			}
+			im.importGKEScript(step.Run)
+		case step.Name == "Build site":
+			var commands []string
+			for _, line := range strings.Split(strings.TrimSpace(step.Run), "\n") {
+				if line = strings.TrimSpace(line); line != "" && line != "npm ci" {
+					commands = append(commands, line)
+				}
+			}
+			cfg.Firebase.BuildCommand = strings.Join(commands, " && ")
+		case step.Name == "Deploy to Firebase Hosting":
+			cfg.Target = TargetFirebaseHosting
+			if match := firebaseChanRegex.FindStringSubmatch(step.Run); match != nil {
+				cfg.Firebase.Channel = match[1]
+			}
+			if match := firebaseSiteRegex.FindStringSubmatch(step.Run); match != nil && match[1] != cfg.ProjectID {
+				cfg.Firebase.Site = match[1]
+			}
+		case step.Name == "Shift traffic to canary revision":
+			im.importCanary(step)
+		case step.Name == "Verify deployment health":
+			im.importHealthChecks(step.Run)
+		case notifyStepRegex.MatchString(step.Name):
+			match := notifyStepRegex.FindStringSubmatch(step.Name)
+			secret := secretRefNameRegex.FindStringSubmatch(step.Env["NOTIFY_URL"])
+			if secret == nil {
+				im.unrecognized(step.Line, "jobs."+job.ID, "notification step '%s' does not read its URL from a secret", step.Name)
+				continue
+			}
+			key := match[1] + "/" + secret[1]
+			hook, exists := hooks[key]
+			if !exists {
+				hook = &NotificationHook{Type: match[1]}
+				if secret[1] != notificationTypes[match[1]].Secret {
+					hook.URL = secret[1]
+				}
+				hooks[key] = hook
+				hookOrder = append(hookOrder, key)
+			}
+			hook.Events = append(hook.Events, match[2])
+		default:
+			im.checkStep(job, step)
+		}
+	}
+
+	for _, key := range hookOrder {
+		cfg.Advanced.NotificationHooks = append(cfg.Advanced.NotificationHooks, *hooks[key])
+	}
+}
+
+// importBuildJob imports the job that builds the image for every entry of a matrix deploy job
+func (im *workflowImporter) importBuildJob(job *WorkflowJob) {
+	for _, step := range job.Steps {
+		if !im.importBuildStep(step) {
+			im.checkStep(job, step)
+		}
+	}
+}
+
+// importBuildStep imports a step that builds, signs or attests the container image,
+// reporting whether it was one
+func (im *workflowImporter) importBuildStep(step *WorkflowStep) bool {
+	cfg := im.cfg
+	switch {
+	case usesAction(step, "docker/build-push-action"):
+		im.importBuild(step)
+	case usesAction(step, "anchore/sbom-action"):
+		cfg.Security.GenerateSBOM = true
+	case step.Name == "Sign container image":
+		cfg.Security.SignImages = true
+	case step.Name == "Create Binary Authorization attestation":
+		binauthz := &cfg.Security.BinaryAuthorization
+		binauthz.Enabled = true
+		if match := attestorFlagRegex.FindStringSubmatch(step.Run); match != nil {
+			binauthz.Attestor = strings.TrimPrefix(match[1], "projects/"+cfg.ProjectID+"/attestors/")
+		}
+		if match := keyVersionRegex.FindStringSubmatch(step.Run); match != nil {
+			binauthz.KeyVersion = match[1]
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// checkStep reports a step the configuration does not render
+func (im *workflowImporter) checkStep(job *WorkflowJob, step *WorkflowStep) {
+	if importedSteps[step.Name] {
+		return
+	}
+	for _, action := range importedActions {
+		if usesAction(step, action) {
+			return
+		}
+	}
+	name := step.Name
+	if name == "" {
+		name = step.Uses
+	}
+	if name == "" {
+		name = firstLine(step.Run)
+	}
+	im.unrecognized(step.Line, "jobs."+job.ID+".steps", "step '%s' is not generated from the configuration", name)
+}
+
+func (im *workflowImporter) importBuild(step *WorkflowStep) {
+	cfg := im.cfg
+	if context := im.resolve(step.With["context"]); context != "" {
+		cfg.BuildContext = context
+	}
+	if file := im.resolve(step.With["file"]); file != "" {
+		cfg.DockerfilePath = file
+	}
+	if platforms := step.With["platforms"]; platforms != "" {
+		cfg.Platforms = strings.Split(platforms, ",")
+		cfg.MultiPlatform = len(cfg.Platforms) > 1
+	}
+	im.importAssignments(step.With["build-args"], &cfg.BuildArgs)
+	for _, line := range nonEmptyLines(step.With["secrets"]) {
+		match := secretAssignRegex.FindStringSubmatch(line)
+		if match == nil {
+			im.unrecognized(step.Line, "build-push-action.secrets", "build secret '%s' does not reference a repository secret", line)
+			continue
+		}
+		if cfg.BuildSecrets == nil {
+			cfg.BuildSecrets = make(map[string]string)
+		}
+		cfg.BuildSecrets[match[1]] = match[2]
+	}
+	for _, line := range nonEmptyLines(step.With["cache-from"]) {
+		cfg.CacheFromImages = append(cfg.CacheFromImages, strings.TrimPrefix(strings.TrimPrefix(line, "- "), "type=registry,ref="))
+	}
+}
+
+func (im *workflowImporter) importCloudRun(step *WorkflowStep) {
+	cfg := im.cfg
+	cfg.Target = ""
+	if service := im.resolve(step.With["service"]); service != "" {
+		cfg.ServiceName = service
+	}
+	if region := im.resolve(step.With["region"]); region != "" {
+		cfg.Region = region
+	}
+	if port := im.resolve(step.With["port"]); port != "" {
+		cfg.Port, _ = strconv.Atoi(port)
+	}
+	im.importAssignments(step.With["env_vars"], &cfg.EnvVars)
+	for _, line := range nonEmptyLines(step.With["secrets"]) {
+		match := secretAssignRegex.FindStringSubmatch(line)
+		if match == nil {
+			im.unrecognized(step.Line, "deploy-cloudrun.secrets", "secret '%s' does not reference a repository secret", line)
+			continue
+		}
+		if cfg.Secrets == nil {
+			cfg.Secrets = make(map[string]string)
+		}
+		cfg.Secrets[match[1]] = match[2]
+	}
+	cfg.CPULimit = step.With["cpu"]
+	cfg.MemoryLimit = step.With["memory"]
+	cfg.MaxInstances, _ = strconv.Atoi(step.With["max_instances"])
+	cfg.MinInstances, _ = strconv.Atoi(step.With["min_instances"])
+	if tag := step.With["tag"]; tag != "" {
+		cfg.Advanced.Canary.Enabled = true
+		cfg.Advanced.Canary.Tag = tag
+	}
+
+	for _, flag := range strings.Fields(step.With["flags"]) {
+		match := deployFlagRegex.FindStringSubmatch(flag)
+		if match == nil || !generatedDeployFlags[match[1]] {
+			im.unrecognized(step.Line, "deploy-cloudrun.flags", "deploy flag '%s' is not configurable", flag)
+			continue
+		}
+		// The generator renders the instance flags with defaults of 100 and 0
+		switch match[1] {
+		case "max-instances":
+			if cfg.MaxInstances == 0 && match[2] != "100" {
+				cfg.MaxInstances, _ = strconv.Atoi(match[2])
+			}
+		case "min-instances":
+			if cfg.MinInstances == 0 {
+				cfg.MinInstances, _ = strconv.Atoi(match[2])
+			}
+		}
+	}
+}
+
+func (im *workflowImporter) importCloudFunctions(step *WorkflowStep) {
+	cfg := im.cfg
+	functions := &cfg.CloudFunctions
+	cfg.Target = TargetCloudFunctions
+	if name := im.resolve(step.With["name"]); name != "" {
+		cfg.ServiceName = name
+	}
+	if region := im.resolve(step.With["region"]); region != "" {
+		cfg.Region = region
+	}
+	functions.Runtime = step.With["runtime"]
+	functions.EntryPoint = step.With["entry_point"]
+	functions.SourceDir = step.With["source_dir"]
+	functions.RuntimeServiceAccount = step.With["service_account"]
+	if topic := step.With["event_trigger_pubsub_topic"]; topic != "" {
+		functions.TriggerTopic = topic[strings.LastIndex(topic, "/")+1:]
+	}
+	cfg.MemoryLimit = step.With["memory"]
+	cfg.MaxInstances, _ = strconv.Atoi(step.With["max_instance_count"])
+	cfg.MinInstances, _ = strconv.Atoi(step.With["min_instance_count"])
+	im.importAssignments(step.With["environment_variables"], &cfg.EnvVars)
+}
+
+func (im *workflowImporter) importGKEScript(script string) {
+	gke := &im.cfg.GKE
+	if match := namespaceRegex.FindStringSubmatch(script); match != nil && match[1] != "default" {
+		gke.Namespace = match[1]
+	}
+	if match := outputURLRegex.FindStringSubmatch(script); match != nil {
+		gke.ServiceURL = match[1]
+	}
+}
+
+func (im *workflowImporter) importCanary(step *WorkflowStep) {
+	canary := &im.cfg.Advanced.Canary
+	canary.Enabled = true
+	canary.Tag = step.Env["CANARY_TAG"]
+	canary.Interval = step.Env["CANARY_INTERVAL"]
+	canary.Steps = nil
+	for _, value := range strings.Fields(step.Env["CANARY_STEPS"]) {
+		if percent, err := strconv.Atoi(value); err == nil {
+			canary.Steps = append(canary.Steps, percent)
+		}
+	}
+}
+
+// importHealthChecks reads the health checks rendered by generateHealthCheckCommand
+func (im *workflowImporter) importHealthChecks(script string) {
+	for _, match := range healthCheckScriptRegex.FindAllStringSubmatch(script, -1) {
+		retries, _ := strconv.Atoi(match[2])
+		code, _ := strconv.Atoi(match[6])
+		im.cfg.AddHealthCheck(HealthCheck{
+			Name:        match[1],
+			URL:         match[5],
+			Method:      match[3],
+			Timeout:     match[4],
+			Retries:     retries,
+			WaitTime:    match[7],
+			HealthyCode: code,
+		})
+	}
+}
+
+// importAssignments adds KEY=VALUE lines to a map
+func (im *workflowImporter) importAssignments(block string, values *map[string]string) {
+	for _, line := range nonEmptyLines(block) {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if *values == nil {
+			*values = make(map[string]string)
+		}
+		(*values)[strings.TrimSpace(key)] = value
+	}
+}
+
+// hasStep reports whether a job has a step using an action
+func hasStep(job *WorkflowJob, action string) bool {
+	for _, step := range job.Steps {
+		if usesAction(step, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesAction reports whether a step uses an action, at any version
+func usesAction(step *WorkflowStep, action string) bool {
+	return strings.HasPrefix(step.Uses, action+"@")
+}
+
+// mappingValue returns the value of a key of a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the value of a scalar node, or an empty string
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// nodeStrings returns the values of a scalar or a list of scalars
+func nodeStrings(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.ScalarNode {
+		return []string{node.Value}
+	}
+	var values []string
+	for _, item := range node.Content {
+		if item.Kind == yaml.ScalarNode {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
+
+// nonEmptyLines returns the trimmed, non-empty lines of a block
+func nonEmptyLines(block string) []string {
+	var lines []string
+	for _, line := range strings.Split(block, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// firstLine returns the first line of a script
+func firstLine(script string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(script), "\n")
+	return line
+}
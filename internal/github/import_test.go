@@ -0,0 +1,146 @@
+package github
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportWorkflow(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.Port = 8080
+	cfg.EnvVars = map[string]string{"LOG_LEVEL": "debug"}
+	cfg.Secrets = map[string]string{"DB_PASSWORD": "DATABASE_PASSWORD"}
+	cfg.BuildArgs = map[string]string{"NODE_ENV": "production"}
+	cfg.Triggers.Schedule = []ScheduleTrigger{{Cron: "0 3 * * 1", Description: "Weekly redeploy"}}
+	cfg.Advanced.Canary = CanaryConfig{Enabled: true, Steps: []int{25, 100}, Interval: "2m", Tag: "canary"}
+	cfg.AddHealthCheck(HealthCheck{Name: "readiness", URL: "/ready", Method: "GET", Timeout: "10s", Retries: 5, WaitTime: "3s", HealthyCode: 204})
+	cfg.Security.RequireSignedCommits = true
+	cfg.Security.AllowedActors = []string{"alice", "bob"}
+
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	imported, err := ImportWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to import workflow: %v", err)
+	}
+	if len(imported.Unrecognized) > 0 {
+		t.Errorf("Expected a generated workflow to be fully recognized, got %+v", imported.Unrecognized)
+	}
+
+	got := imported.Config
+	for _, check := range []struct {
+		field         string
+		got, expected interface{}
+	}{
+		{"name", got.Name, cfg.Name},
+		{"project", got.ProjectID, cfg.ProjectID},
+		{"region", got.Region, cfg.Region},
+		{"service", got.ServiceName, cfg.ServiceName},
+		{"provider", got.WorkloadIdentityProvider, cfg.WorkloadIdentityProvider},
+		{"service account", got.ServiceAccountEmail, cfg.ServiceAccountEmail},
+		{"port", got.Port, cfg.Port},
+		{"push", got.Triggers.Push, cfg.Triggers.Push},
+		{"pull request", got.Triggers.PullRequest, cfg.Triggers.PullRequest},
+		{"schedule", got.Triggers.Schedule, cfg.Triggers.Schedule},
+		{"manual", got.Triggers.Manual, cfg.Triggers.Manual},
+		{"env", got.EnvVars, cfg.EnvVars},
+		{"secrets", got.Secrets, cfg.Secrets},
+		{"build args", got.BuildArgs, cfg.BuildArgs},
+		{"canary", got.Advanced.Canary, cfg.Advanced.Canary},
+		{"health checks", got.Advanced.HealthChecks, cfg.Advanced.HealthChecks},
+		{"security", got.Security, cfg.Security},
+		{"timeout", got.Advanced.Timeout, cfg.Advanced.Timeout},
+	} {
+		if !reflect.DeepEqual(check.got, check.expected) {
+			t.Errorf("Imported %s %+v, expected %+v", check.field, check.got, check.expected)
+		}
+	}
+
+	// The imported configuration regenerates the same workflow
+	regenerated, err := got.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to regenerate workflow: %v", err)
+	}
+	if regenerated != content {
+		t.Errorf("Expected the imported configuration to regenerate the workflow, got:\n%s", regenerated)
+	}
+}
+
+func TestImportHandWrittenWorkflow(t *testing.T) {
+	content := `name: Deploy
+on:
+  push:
+    branches: [main]
+  issues:
+    types: [opened]
+env:
+  REGION: europe-west1
+  FEATURE_FLAG: "on"
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  deploy:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+      id-token: write
+    steps:
+      - uses: actions/checkout@v4
+      - uses: google-github-actions/auth@v2
+        with:
+          workload_identity_provider: projects/123456/locations/global/workloadIdentityPools/github/providers/github
+          service_account: deployer@my-project.iam.gserviceaccount.com
+      - name: Run migrations
+        run: ./migrate.sh
+      - uses: google-github-actions/deploy-cloudrun@v2
+        with:
+          service: api
+          region: ${{ env.REGION }}
+          flags: --cpu-boost --max-instances=4
+`
+	imported, err := ImportWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to import workflow: %v", err)
+	}
+
+	cfg := imported.Config
+	if cfg.ServiceName != "api" || cfg.Region != "europe-west1" || cfg.MaxInstances != 4 {
+		t.Errorf("Unexpected deploy settings: service %q, region %q, max instances %d", cfg.ServiceName, cfg.Region, cfg.MaxInstances)
+	}
+	if cfg.ServiceAccountEmail != "deployer@my-project.iam.gserviceaccount.com" || !strings.HasSuffix(cfg.WorkloadIdentityProvider, "/providers/github") {
+		t.Errorf("Unexpected identity: %q, %q", cfg.ServiceAccountEmail, cfg.WorkloadIdentityProvider)
+	}
+	if !reflect.DeepEqual(cfg.Triggers.Push.Branches, []string{"main"}) || cfg.Triggers.PullRequest.Enabled || cfg.Triggers.Manual {
+		t.Errorf("Unexpected triggers: %+v", cfg.Triggers)
+	}
+	if cfg.EnvVars["FEATURE_FLAG"] != "on" {
+		t.Errorf("Expected unknown env to be imported as env vars, got %v", cfg.EnvVars)
+	}
+
+	var messages []string
+	for _, issue := range imported.Unrecognized {
+		messages = append(messages, issue.Path+": "+issue.Message)
+	}
+	report := strings.Join(messages, "\n")
+	for _, expected := range []string{"on.issues", "jobs.lint", "Run migrations", "--cpu-boost"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected %q to be reported as unrecognized, got:\n%s", expected, report)
+		}
+	}
+}
+
+func TestImportHeaderVersion(t *testing.T) {
+	for _, header := range []string{
+		"# Generated by GCP WIF CLI Tool v1.0",
+		"# Generated on 2025-01-01T00:00:00Z by GCP WIF CLI Tool v1.0", // before the timestamp was dropped
+	} {
+		if match := headerVersionRegex.FindStringSubmatch(header); match == nil || match[1] != "1.0" {
+			t.Errorf("Expected version 1.0 to be read from %q, got %v", header, match)
+		}
+	}
+}
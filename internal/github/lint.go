@@ -0,0 +1,419 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+)
+
+// Lint finding severities
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+	LintSeverityInfo    = "info"
+)
+
+// Lint rule identifiers
+const (
+	LintRuleSchema             = "schema"
+	LintRuleScriptInjection    = "script-injection"
+	LintRulePullRequestTarget  = "pull-request-target-checkout"
+	LintRuleIDTokenPermission  = "id-token-permission"
+	LintRuleLongLivedKey       = "long-lived-credentials"
+	LintRuleProviderMismatch   = "provider-mismatch"
+	LintRuleServiceAccountDiff = "service-account-mismatch"
+)
+
+// LintFinding is a security problem found in a workflow file
+type LintFinding struct {
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column,omitempty"`
+	Job         string `json:"job,omitempty"`
+	Step        string `json:"step,omitempty"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// LintExpectations describe the WIF resources workflows are expected to authenticate with.
+// Empty fields are not checked.
+type LintExpectations struct {
+	// Projects holds the project ID and number the provider may be addressed by
+	Projects        []string `json:"projects,omitempty"`
+	PoolID          string   `json:"pool_id,omitempty"`
+	ProviderIDs     []string `json:"provider_ids,omitempty"`
+	ServiceAccounts []string `json:"service_accounts,omitempty"`
+}
+
+// LintReport is the result of linting a set of workflow files
+type LintReport struct {
+	Files    []string      `json:"files"`
+	AuthUses int           `json:"auth_uses"` // Number of google-github-actions/auth steps found
+	Findings []LintFinding `json:"findings"`
+}
+
+// HasErrors reports whether any error-severity finding was found
+func (r *LintReport) HasErrors() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == LintSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// Event fields an outside contributor controls; interpolating them into a shell
+	// script lets them run arbitrary commands
+	untrustedContextRegex = regexp.MustCompile(`github\.head_ref|github\.event\.(` + strings.Join([]string{
+		`issue\.(title|body)`,
+		`pull_request\.(title|body|head\.ref|head\.label|head\.repo\.default_branch)`,
+		`(comment|review|review_comment)\.body`,
+		`discussion\.(title|body)`,
+		`pages\.[^.\s]+\.page_name`,
+		`(commits\.[^.\s]+|head_commit)\.(message|author\.(email|name))`,
+		`workflow_run\.(head_branch|head_commit\.(message|author\.(email|name)))`,
+	}, "|") + `)`)
+	dispatchInputRegex = regexp.MustCompile(`(?:github\.event\.inputs|inputs)\.([A-Za-z0-9_-]+)`)
+	expressionRegex    = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+	envReferenceRegex  = regexp.MustCompile(`^\$\{\{\s*env\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+	providerNameRegex  = regexp.MustCompile(`^projects/([^/]+)/locations/global/workloadIdentityPools/([^/]+)/providers/([^/]+)$`)
+)
+
+// LintWorkflowDir lints every .yml and .yaml file in a workflow directory
+func LintWorkflowDir(dir string, expectations LintExpectations) (*LintReport, error) {
+	logger := logging.WithField("function", "LintWorkflowDir")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "WORKFLOW_DIR_READ_FAILED",
+			fmt.Sprintf("Failed to read workflow directory: %s", dir))
+	}
+
+	report := &LintReport{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "WORKFLOW_READ_FAILED",
+				fmt.Sprintf("Failed to read workflow file: %s", path))
+		}
+
+		findings, authUses := LintWorkflow(path, string(content), expectations)
+		report.Files = append(report.Files, path)
+		report.AuthUses += authUses
+		report.Findings = append(report.Findings, findings...)
+	}
+
+	logger.Info("Workflows linted", "dir", dir, "files", len(report.Files), "findings", len(report.Findings))
+	return report, nil
+}
+
+// LintWorkflow checks a single workflow for insecure patterns and returns the findings
+// together with the number of google-github-actions/auth steps it contains
+func LintWorkflow(file, content string, expectations LintExpectations) ([]LintFinding, int) {
+	workflow, err := ParseWorkflow(content)
+	if workflow == nil {
+		var findings []LintFinding
+		if schemaErr, ok := err.(*WorkflowSchemaError); ok {
+			for _, issue := range schemaErr.Issues {
+				findings = append(findings, LintFinding{
+					Rule: LintRuleSchema, Severity: LintSeverityError, File: file,
+					Line: issue.Line, Column: issue.Column, Message: issue.Message,
+				})
+			}
+		}
+		return findings, 0
+	}
+
+	linter := &workflowLinter{file: file, workflow: workflow, expectations: expectations}
+	// Schema issues in existing workflows are reported but do not stop the security checks
+	if schemaErr, ok := err.(*WorkflowSchemaError); ok {
+		for _, issue := range schemaErr.Issues {
+			linter.findings = append(linter.findings, LintFinding{
+				Rule: LintRuleSchema, Severity: LintSeverityWarning, File: file,
+				Line: issue.Line, Column: issue.Column, Message: fmt.Sprintf("%s: %s", issue.Path, issue.Message),
+			})
+		}
+	}
+
+	linter.lint()
+	sort.SliceStable(linter.findings, func(i, j int) bool {
+		return linter.findings[i].Line < linter.findings[j].Line
+	})
+	return linter.findings, linter.authUses
+}
+
+type workflowLinter struct {
+	file         string
+	workflow     *ParsedWorkflow
+	expectations LintExpectations
+	findings     []LintFinding
+	authUses     int
+}
+
+func (l *workflowLinter) add(job *WorkflowJob, step *WorkflowStep, rule, severity, message, remediation string) {
+	finding := LintFinding{
+		Rule: rule, Severity: severity, File: l.file,
+		Message: message, Remediation: remediation,
+	}
+	if job != nil {
+		finding.Job = job.ID
+		finding.Line, finding.Column = job.Line, job.Column
+	}
+	if step != nil {
+		finding.Step = stepLabel(step)
+		finding.Line, finding.Column = step.Line, step.Column
+	}
+	l.findings = append(l.findings, finding)
+}
+
+func (l *workflowLinter) lint() {
+	pullRequestTarget := false
+	for _, trigger := range l.workflow.Triggers {
+		if trigger == "pull_request_target" {
+			pullRequestTarget = true
+		}
+	}
+
+	workflowIDToken := l.workflow.Permissions["id-token"] == "write" || l.workflow.Permissions["*"] == "write-all"
+
+	for _, job := range l.workflow.Jobs {
+		jobAuthenticates := false
+		for _, step := range job.Steps {
+			l.checkScriptInjection(job, step)
+			if pullRequestTarget {
+				l.checkPullRequestTargetCheckout(job, step)
+			}
+			if l.checkCredentials(job, step) {
+				jobAuthenticates = true
+			}
+		}
+
+		if !jobAuthenticates {
+			continue
+		}
+		switch {
+		case job.Permissions != nil && job.Permissions["id-token"] != "write" && job.Permissions["*"] != "write-all":
+			l.add(job, nil, LintRuleIDTokenPermission, LintSeverityError,
+				"job authenticates with Workload Identity Federation but its permissions do not grant 'id-token: write'",
+				"Add 'id-token: write' to the job's permissions")
+		case job.Permissions == nil && !workflowIDToken:
+			l.add(job, nil, LintRuleIDTokenPermission, LintSeverityError,
+				"job authenticates with Workload Identity Federation but 'id-token: write' is not granted",
+				"Add a permissions block with 'id-token: write' and 'contents: read' to the job")
+		case job.Permissions == nil:
+			l.add(job, nil, LintRuleIDTokenPermission, LintSeverityWarning,
+				"'id-token: write' is inherited from the workflow level",
+				"Grant 'id-token: write' only on the jobs that authenticate to Google Cloud")
+		}
+	}
+
+	// A workflow-level grant gives every job the ability to mint OIDC tokens
+	if workflowIDToken {
+		for _, job := range l.workflow.Jobs {
+			if job.Permissions == nil && !jobUsesAuth(job) {
+				l.add(job, nil, LintRuleIDTokenPermission, LintSeverityWarning,
+					"job does not authenticate to Google Cloud but inherits 'id-token: write' from the workflow",
+					"Move 'id-token: write' from the workflow permissions to the jobs that need it")
+			}
+		}
+	}
+}
+
+// checkScriptInjection flags untrusted context interpolated directly into shell scripts
+// and github-script code
+func (l *workflowLinter) checkScriptInjection(job *WorkflowJob, step *WorkflowStep) {
+	script, kind := step.Run, "run script"
+	if strings.HasPrefix(step.Uses, "actions/github-script@") {
+		script, kind = step.With["script"], "github-script code"
+	}
+	if script == "" {
+		return
+	}
+
+	reported := make(map[string]bool)
+	for _, match := range expressionRegex.FindAllStringSubmatch(script, -1) {
+		expression := strings.TrimSpace(match[1])
+		if reported[expression] {
+			continue
+		}
+		switch {
+		case untrustedContextRegex.MatchString(expression):
+			reported[expression] = true
+			l.add(job, step, LintRuleScriptInjection, LintSeverityError,
+				fmt.Sprintf("untrusted input '${{ %s }}' is interpolated into a %s", expression, kind),
+				"Pass the value through an environment variable (env: VALUE: ${{ ... }}) and reference \"$VALUE\" instead")
+		case l.freeFormInput(expression):
+			reported[expression] = true
+			l.add(job, step, LintRuleScriptInjection, LintSeverityWarning,
+				fmt.Sprintf("workflow input '${{ %s }}' is interpolated into a %s", expression, kind),
+				"Pass the input through an environment variable or declare it with type: choice")
+		}
+	}
+}
+
+// freeFormInput reports whether an expression references a workflow input that accepts
+// arbitrary text. Choice, boolean, number and environment inputs are validated by GitHub.
+func (l *workflowLinter) freeFormInput(expression string) bool {
+	for _, match := range dispatchInputRegex.FindAllStringSubmatch(expression, -1) {
+		switch l.workflow.Inputs[match[1]] {
+		case "choice", "boolean", "number", "environment":
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// checkPullRequestTargetCheckout flags checking out pull request code in a
+// pull_request_target workflow, which runs with secrets and a write token
+func (l *workflowLinter) checkPullRequestTargetCheckout(job *WorkflowJob, step *WorkflowStep) {
+	if !strings.HasPrefix(step.Uses, "actions/checkout@") {
+		return
+	}
+	ref := step.With["ref"] + " " + step.With["repository"]
+	if strings.Contains(ref, "github.event.pull_request.head") || strings.Contains(ref, "github.head_ref") ||
+		strings.Contains(ref, "refs/pull/") {
+		l.add(job, step, LintRulePullRequestTarget, LintSeverityError,
+			"pull_request_target workflow checks out the pull request head, running untrusted code with repository secrets",
+			"Use the pull_request event for building untrusted code, or split privileged steps into a workflow_run workflow")
+	}
+}
+
+// checkCredentials inspects authentication steps and reports whether the step authenticates
+// with Workload Identity Federation
+func (l *workflowLinter) checkCredentials(job *WorkflowJob, step *WorkflowStep) bool {
+	if strings.HasPrefix(step.Uses, "google-github-actions/setup-gcloud@") && step.With["service_account_key"] != "" {
+		l.add(job, step, LintRuleLongLivedKey, LintSeverityError,
+			"setup-gcloud is configured with a long-lived service account key",
+			"Authenticate with google-github-actions/auth and Workload Identity Federation instead")
+	}
+	if !strings.HasPrefix(step.Uses, "google-github-actions/auth@") {
+		return false
+	}
+	l.authUses++
+
+	if step.With["credentials_json"] != "" {
+		l.add(job, step, LintRuleLongLivedKey, LintSeverityError,
+			"authentication uses a long-lived service account key (credentials_json)",
+			"Replace credentials_json with workload_identity_provider and service_account, then revoke the key with 'gcp-wif keys revoke'")
+	}
+
+	provider := step.With["workload_identity_provider"]
+	if provider == "" {
+		return false
+	}
+
+	if resolved, ok := l.resolve(job, step, provider); ok {
+		l.checkProvider(job, step, resolved)
+	}
+	if account := step.With["service_account"]; account != "" && len(l.expectations.ServiceAccounts) > 0 {
+		if resolved, ok := l.resolve(job, step, account); ok && !containsString(l.expectations.ServiceAccounts, resolved) {
+			l.add(job, step, LintRuleServiceAccountDiff, LintSeverityError,
+				fmt.Sprintf("service account %s does not match the configured service account %s",
+					resolved, strings.Join(l.expectations.ServiceAccounts, " or ")),
+				"Update the workflow or the configuration so they reference the same service account")
+		}
+	}
+	return true
+}
+
+func (l *workflowLinter) checkProvider(job *WorkflowJob, step *WorkflowStep, provider string) {
+	e := l.expectations
+	if len(e.Projects) == 0 && e.PoolID == "" && len(e.ProviderIDs) == 0 {
+		return
+	}
+
+	match := providerNameRegex.FindStringSubmatch(provider)
+	if match == nil {
+		l.add(job, step, LintRuleProviderMismatch, LintSeverityError,
+			fmt.Sprintf("workload_identity_provider '%s' is not a full provider resource name", provider),
+			"Use projects/PROJECT_NUMBER/locations/global/workloadIdentityPools/POOL/providers/PROVIDER")
+		return
+	}
+
+	var mismatches []string
+	if len(e.Projects) > 0 && !containsString(e.Projects, match[1]) {
+		mismatches = append(mismatches, fmt.Sprintf("project %s (expected %s)", match[1], strings.Join(e.Projects, " or ")))
+	}
+	if e.PoolID != "" && match[2] != e.PoolID {
+		mismatches = append(mismatches, fmt.Sprintf("pool %s (expected %s)", match[2], e.PoolID))
+	}
+	if len(e.ProviderIDs) > 0 && !containsString(e.ProviderIDs, match[3]) {
+		mismatches = append(mismatches, fmt.Sprintf("provider %s (expected %s)", match[3], strings.Join(e.ProviderIDs, " or ")))
+	}
+	if len(mismatches) > 0 {
+		l.add(job, step, LintRuleProviderMismatch, LintSeverityError,
+			"workload_identity_provider does not match the configuration: "+strings.Join(mismatches, ", "),
+			"Regenerate the workflow with 'gcp-wif workflow generate' or update the configuration")
+	}
+}
+
+// resolve returns the literal value of an input, following ${{ env.NAME }} references
+// through step, job and workflow env. Other expressions cannot be resolved statically.
+func (l *workflowLinter) resolve(job *WorkflowJob, step *WorkflowStep, value string) (string, bool) {
+	for depth := 0; depth < 5; depth++ {
+		if !strings.Contains(value, "${{") {
+			return strings.TrimSpace(value), true
+		}
+		match := envReferenceRegex.FindStringSubmatch(strings.TrimSpace(value))
+		if match == nil {
+			return "", false
+		}
+		resolved, found := "", false
+		for _, env := range []map[string]string{step.Env, job.Env, l.workflow.Env} {
+			if v, ok := env[match[1]]; ok {
+				resolved, found = v, true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+		value = resolved
+	}
+	return "", false
+}
+
+func jobUsesAuth(job *WorkflowJob) bool {
+	for _, step := range job.Steps {
+		if strings.HasPrefix(step.Uses, "google-github-actions/auth@") {
+			return true
+		}
+	}
+	return false
+}
+
+func stepLabel(step *WorkflowStep) string {
+	switch {
+	case step.Name != "":
+		return step.Name
+	case step.ID != "":
+		return step.ID
+	case step.Uses != "":
+		return step.Uses
+	}
+	return fmt.Sprintf("step at line %d", step.Line)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestLintWorkflow(t *testing.T) {
+	content := `name: risky
+on:
+  pull_request_target:
+  workflow_dispatch:
+    inputs:
+      environment:
+        type: choice
+        options: [staging, production]
+      note:
+        type: string
+permissions:
+  id-token: write
+jobs:
+  greet:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - run: |
+          echo "${{ github.event.pull_request.title }}"
+          echo "${{ inputs.environment }} ${{ inputs.note }}"
+  deploy:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    env:
+      PROVIDER: projects/123/locations/global/workloadIdentityPools/github/providers/other
+    steps:
+      - uses: google-github-actions/auth@v2
+        with:
+          workload_identity_provider: ${{ env.PROVIDER }}
+          service_account: deployer@my-project.iam.gserviceaccount.com
+      - uses: google-github-actions/auth@v2
+        with:
+          credentials_json: ${{ secrets.GCP_KEY }}
+`
+
+	findings, authUses := LintWorkflow("risky.yml", content, LintExpectations{
+		Projects:        []string{"my-project", "123"},
+		PoolID:          "github",
+		ProviderIDs:     []string{"github"},
+		ServiceAccounts: []string{"deployer@my-project.iam.gserviceaccount.com"},
+	})
+	if authUses != 2 {
+		t.Errorf("Expected 2 auth steps, got %d", authUses)
+	}
+
+	expected := map[string]int{
+		LintRulePullRequestTarget + "/" + LintSeverityError:   1,
+		LintRuleScriptInjection + "/" + LintSeverityError:     1, // pull_request.title
+		LintRuleScriptInjection + "/" + LintSeverityWarning:   1, // free-form input, not the choice input
+		LintRuleIDTokenPermission + "/" + LintSeverityError:   1, // deploy overrides permissions without id-token
+		LintRuleIDTokenPermission + "/" + LintSeverityWarning: 1, // greet inherits id-token
+		LintRuleProviderMismatch + "/" + LintSeverityError:    1,
+		LintRuleLongLivedKey + "/" + LintSeverityError:        1,
+	}
+	actual := make(map[string]int)
+	for _, finding := range findings {
+		actual[finding.Rule+"/"+finding.Severity]++
+	}
+	for key, count := range expected {
+		if actual[key] != count {
+			t.Errorf("Expected %d %s finding(s), got %d: %+v", count, key, actual[key], findings)
+		}
+	}
+	if len(findings) != 7 {
+		t.Errorf("Expected 7 findings, got %d: %+v", len(findings), findings)
+	}
+
+	for _, finding := range findings {
+		if finding.Rule == LintRuleProviderMismatch && (finding.Line != 30 || finding.Job != "deploy") {
+			t.Errorf("Unexpected provider mismatch location: %+v", finding)
+		}
+	}
+}
+
+func TestLintGeneratedWorkflow(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultProductionWorkflowConfig())
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+
+	findings, _ := LintWorkflow("deploy.yml", content, LintExpectations{
+		Projects:        []string{"123"},
+		PoolID:          "github",
+		ProviderIDs:     []string{"github"},
+		ServiceAccounts: []string{cfg.ServiceAccountEmail},
+	})
+	for _, finding := range findings {
+		if finding.Severity == LintSeverityError {
+			t.Errorf("Generated workflow has lint error: %+v", finding)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// Matrix variables that fan the deploy job out: each matrix entry deploys with its own
+// region or service name instead of the workflow-level REGION and SERVICE_NAME
+const (
+	MatrixKeyRegion  = "region"
+	MatrixKeyService = "service"
+)
+
+// matrixFanOutEnv maps the fan-out matrix variables to the env variables they override
+var matrixFanOutEnv = map[string]string{
+	MatrixKeyRegion:  "REGION",
+	MatrixKeyService: "SERVICE_NAME",
+}
+
+var matrixReferenceRegex = regexp.MustCompile(`\bmatrix\.([A-Za-z_][A-Za-z0-9_-]*)`)
+
+// SetMatrixVariable enables the matrix strategy and sets the values of a matrix variable
+func (w *WorkflowConfig) SetMatrixVariable(name string, values []string) {
+	m := &w.Advanced.MatrixStrategy
+	if m.Variables == nil {
+		m.Variables = make(map[string][]interface{})
+	}
+	items := make([]interface{}, len(values))
+	for i, value := range values {
+		items[i] = value
+	}
+	m.Variables[name] = items
+	m.Enabled = true
+}
+
+// MatrixKeys returns the sorted names of the matrix variables, including keys that only
+// appear in include entries
+func (m MatrixStrategy) MatrixKeys() []string {
+	var keys []string
+	for name := range m.Variables {
+		keys = append(keys, name)
+	}
+	for _, entry := range m.Include {
+		for name := range entry {
+			if !containsString(keys, name) {
+				keys = append(keys, name)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateMatrixStrategy checks an enabled matrix strategy
+func (w *WorkflowConfig) validateMatrixStrategy() error {
+	m := w.Advanced.MatrixStrategy
+	if !m.Enabled {
+		return nil
+	}
+	if w.IsTerraform() {
+		return errors.NewValidationError("Workflow: Matrix strategy is not supported for terraform workflows", "workflow.advanced.matrix_strategy", "INVALID")
+	}
+	if len(m.Variables) == 0 && len(m.Include) == 0 {
+		return errors.NewValidationError("Workflow: Matrix strategy needs at least one variable or include entry", "workflow.advanced.matrix_strategy.variables", "REQUIRED")
+	}
+	for _, name := range m.MatrixKeys() {
+		if name == "include" || name == "exclude" || !workflowIdentifierRegex.MatchString(name) {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Invalid matrix variable name '%s'", name), "workflow.advanced.matrix_strategy.variables", "INVALID")
+		}
+	}
+	for name, values := range m.Variables {
+		if len(values) == 0 {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Matrix variable '%s' has no values", name), "workflow.advanced.matrix_strategy.variables", "INVALID")
+		}
+	}
+	for _, entry := range m.Exclude {
+		for name := range entry {
+			if _, ok := m.Variables[name]; !ok {
+				return errors.NewValidationError(fmt.Sprintf("Workflow: Matrix exclude entry uses '%s', which is not a matrix variable", name),
+					"workflow.advanced.matrix_strategy.exclude", "INVALID")
+			}
+		}
+	}
+	if m.MaxParallel < 0 {
+		return errors.NewValidationError("Workflow: Matrix max parallel must not be negative", "workflow.advanced.matrix_strategy.max_parallel", "INVALID")
+	}
+	return nil
+}
+
+// matrixStrategyYAML renders the strategy block of the deploy job, or an empty string when
+// the matrix strategy is disabled
+func (w *WorkflowConfig) matrixStrategyYAML() (string, error) {
+	m := w.Advanced.MatrixStrategy
+	if !m.Enabled {
+		return "", nil
+	}
+
+	add := func(parent *yaml.Node, key string, value interface{}, style yaml.Style) error {
+		var node yaml.Node
+		if err := node.Encode(value); err != nil {
+			return errors.NewInternalError(fmt.Sprintf("Failed to encode matrix value '%s'", key), err)
+		}
+		node.Style = style
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &node)
+		return nil
+	}
+
+	matrix := &yaml.Node{Kind: yaml.MappingNode}
+	var names []string
+	for name := range m.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := add(matrix, name, m.Variables[name], yaml.FlowStyle); err != nil {
+			return "", err
+		}
+	}
+	if len(m.Include) > 0 {
+		if err := add(matrix, "include", m.Include, 0); err != nil {
+			return "", err
+		}
+	}
+	if len(m.Exclude) > 0 {
+		if err := add(matrix, "exclude", m.Exclude, 0); err != nil {
+			return "", err
+		}
+	}
+
+	strategy := &yaml.Node{Kind: yaml.MappingNode}
+	if err := add(strategy, "fail-fast", m.FailFast, 0); err != nil {
+		return "", err
+	}
+	if m.MaxParallel > 0 {
+		if err := add(strategy, "max-parallel", m.MaxParallel, 0); err != nil {
+			return "", err
+		}
+	}
+	strategy.Content = append(strategy.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "matrix"}, matrix)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(strategy); err != nil {
+		return "", errors.NewInternalError("Failed to render matrix strategy", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", errors.NewInternalError("Failed to render matrix strategy", err)
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// matrixEnv returns the env variables the deploy job overrides from fan-out matrix
+// variables, keyed by env variable name
+func (w *WorkflowConfig) matrixEnv() map[string]string {
+	m := w.Advanced.MatrixStrategy
+	env := make(map[string]string)
+	if !m.Enabled {
+		return env
+	}
+	for _, key := range m.MatrixKeys() {
+		if name, ok := matrixFanOutEnv[key]; ok {
+			env[name] = key
+		}
+	}
+	return env
+}
+
+// matrixReferences returns the matrix keys referenced by the expressions in a scalar. The
+// whole value of an if condition is an expression, with or without ${{ }}.
+func matrixReferences(value string, condition bool) []string {
+	var expressions []string
+	if condition && !strings.Contains(value, "${{") {
+		expressions = append(expressions, value)
+	}
+	rest := value
+	for {
+		start := strings.Index(rest, "${{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			break
+		}
+		expressions = append(expressions, rest[start+3:start+end])
+		rest = rest[start+end+2:]
+	}
+
+	var keys []string
+	for _, expression := range expressions {
+		for _, match := range matrixReferenceRegex.FindAllStringSubmatch(expression, -1) {
+			keys = append(keys, match[1])
+		}
+	}
+	return keys
+}
@@ -0,0 +1,202 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatrixStrategyRendering(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.SetMatrixVariable(MatrixKeyRegion, []string{"us-central1", "europe-west1"})
+	cfg.Advanced.MatrixStrategy.MaxParallel = 1
+	cfg.Advanced.MatrixStrategy.Exclude = []map[string]interface{}{{"region": "europe-west1"}}
+
+	if err := cfg.ValidateConfig(); err != nil {
+		t.Fatalf("Expected matrix config to be valid: %v", err)
+	}
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+
+	for _, expected := range []string{
+		"    strategy:\n      fail-fast: true\n      max-parallel: 1\n      matrix:\n        region: [us-central1, europe-west1]\n",
+		"        exclude:\n          - region: europe-west1\n",
+		"    env:\n      REGION: ${{ matrix.region }}\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected workflow to contain %q", expected)
+		}
+	}
+
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	if deploy := workflow.Job("deploy"); deploy == nil || len(deploy.Matrix) != 1 || deploy.Matrix[0] != "region" {
+		t.Errorf("Expected the deploy job to define the region matrix variable, got %+v", deploy)
+	}
+
+	// The image is built once, by a job every matrix entry waits for
+	build, deploy := workflow.Job(BuildJobID), workflow.Job("deploy")
+	if build == nil || len(build.Matrix) > 0 || !hasStep(build, "docker/build-push-action") {
+		t.Fatalf("Expected an unmatrixed build job that pushes the image, got %+v", build)
+	}
+	if hasStep(deploy, "docker/build-push-action") {
+		t.Error("Expected the matrix deploy job not to build the image")
+	}
+	if strings.Join(deploy.Needs, ",") != "security-checks,build" {
+		t.Errorf("Expected the deploy job to need the build job, got %v", deploy.Needs)
+	}
+	if !strings.Contains(content, "image-digest: ${{ needs.build.outputs.image-digest }}") {
+		t.Error("Expected the deploy job to report the digest the build job pushed")
+	}
+	if findings, _ := LintWorkflow("deploy.yml", content, LintExpectations{}); len(findings) > 0 {
+		t.Errorf("Expected no lint findings, got %+v", findings)
+	}
+
+	// Without a matrix the deploy job is rendered unchanged
+	plain, err := testWorkflowConfig(DefaultWorkflowConfig()).GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if strings.Contains(plain, "strategy:") || strings.Contains(plain, "matrix.") {
+		t.Error("Expected no matrix strategy when the matrix is disabled")
+	}
+	if strings.Contains(plain, "\n  build:\n") {
+		t.Error("Expected the deploy job to build the image when the matrix is disabled")
+	}
+}
+
+func TestMatrixPipelineBuildJob(t *testing.T) {
+	cfg := testPipelineConfig()
+	cfg.SetMatrixVariable(MatrixKeyRegion, []string{"us-central1", "europe-west1"})
+	cfg.Security.SignImages = true
+	cfg.Security.SLSAProvenance = true
+
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+
+	// The build job pushes with the first environment's identity
+	build := workflow.Job(BuildJobID)
+	if build == nil || build.Env["SERVICE_ACCOUNT"] != cfg.ServiceAccountEmail {
+		t.Fatalf("Expected a build job with the staging identity, got %+v", build)
+	}
+	staging, production := workflow.Job("deploy-staging"), workflow.Job("deploy-production")
+	if strings.Join(staging.Needs, ",") != "security-checks,build" ||
+		strings.Join(production.Needs, ",") != "security-checks,build,deploy-staging" {
+		t.Errorf("Expected every deploy job to need the build job, got %v and %v", staging.Needs, production.Needs)
+	}
+	for _, job := range []*WorkflowJob{staging, production} {
+		if hasStep(job, "docker/build-push-action") || hasStep(job, "sigstore/cosign-installer") {
+			t.Errorf("Expected %s not to build or sign the image", job.ID)
+		}
+	}
+	if !hasStep(build, "sigstore/cosign-installer") {
+		t.Error("Expected the build job to sign the image it pushed")
+	}
+	if provenance := workflow.Job(ProvenanceJobID); provenance == nil || strings.Join(provenance.Needs, ",") != BuildJobID {
+		t.Errorf("Expected provenance for the image the build job pushed, got %+v", provenance)
+	}
+
+	imported, err := ImportWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to import workflow: %v", err)
+	}
+	for _, finding := range imported.Unrecognized {
+		if strings.HasPrefix(finding.Path, "jobs.build") {
+			t.Errorf("Expected the build job to be imported, got %+v", finding)
+		}
+	}
+	if !imported.Config.Security.SignImages {
+		t.Error("Expected image signing to be imported from the build job")
+	}
+}
+
+func TestMatrixStrategyValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(cfg *WorkflowConfig)
+		message string
+	}{
+		{"empty", func(cfg *WorkflowConfig) { cfg.Advanced.MatrixStrategy.Enabled = true }, "at least one variable"},
+		{"no values", func(cfg *WorkflowConfig) { cfg.SetMatrixVariable("region", nil) }, "has no values"},
+		{"invalid name", func(cfg *WorkflowConfig) { cfg.SetMatrixVariable("my region", []string{"a"}) }, "Invalid matrix variable name"},
+		{"reserved name", func(cfg *WorkflowConfig) { cfg.SetMatrixVariable("include", []string{"a"}) }, "Invalid matrix variable name"},
+		{"unknown exclude", func(cfg *WorkflowConfig) {
+			cfg.SetMatrixVariable("region", []string{"a"})
+			cfg.Advanced.MatrixStrategy.Exclude = []map[string]interface{}{{"service": "b"}}
+		}, "not a matrix variable"},
+		{"terraform", func(cfg *WorkflowConfig) {
+			cfg.Kind = WorkflowKindTerraform
+			cfg.SetMatrixVariable("region", []string{"a"})
+		}, "not supported for terraform"},
+	}
+
+	for _, test := range tests {
+		cfg := testWorkflowConfig(DefaultWorkflowConfig())
+		test.setup(cfg)
+		if err := cfg.ValidateConfig(); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+}
+
+func TestMatrixReferences(t *testing.T) {
+	content := `name: Matrix
+on: push
+env:
+  TARGET: ${{ matrix.region }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        go: ["1.23", "1.24"]
+        include:
+          - os: ubuntu-latest
+    steps:
+      - run: echo ${{ matrix.go }} ${{ matrix.os }} ${{ matrix.arch }}
+        if: matrix.go != '1.23'
+  dynamic:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix: ${{ fromJSON(needs.build.outputs.matrix) }}
+    steps:
+      - run: echo ${{ matrix.anything }}
+  plain:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ matrix.go }}
+`
+	issues := ValidateWorkflowSchema(content)
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, issue.Message)
+	}
+	expected := []string{
+		"matrix.region is only available in jobs with a matrix strategy",
+		"matrix.arch is not defined by the job's matrix (defines: go, os)",
+		"matrix.go is only available in jobs with a matrix strategy",
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("Expected %d issues, got %q", len(expected), messages)
+	}
+	for i, message := range expected {
+		if messages[i] != message {
+			t.Errorf("Issue %d: expected %q, got %q", i, message, messages[i])
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// DefaultWorkflowBaseDir is where the last generated content of each workflow is recorded,
+// relative to the repository root, so edits made to the workflow file can be merged when
+// it is regenerated
+const DefaultWorkflowBaseDir = ".gcp-wif/generated"
+
+// Conflict markers written around hunks that were changed both in the file and by the generator
+const (
+	conflictMarkerEdited    = "<<<<<<< edited"
+	conflictMarkerSeparator = "======="
+	conflictMarkerGenerated = ">>>>>>> generated"
+)
+
+// ConflictResolution decides how a merge conflict is written
+type ConflictResolution int
+
+const (
+	// ResolveWithMarkers writes both sides between conflict markers
+	ResolveWithMarkers ConflictResolution = iota
+	// ResolveKeepEdited keeps the lines as edited in the workflow file
+	ResolveKeepEdited
+	// ResolveUseGenerated replaces the edited lines with the newly generated ones
+	ResolveUseGenerated
+)
+
+// ConflictResolver chooses the resolution of a merge conflict
+type ConflictResolver func(conflict MergeConflict) ConflictResolution
+
+// MergeConflict is a hunk changed differently in the workflow file and by the generator
+type MergeConflict struct {
+	Line      int // Line of the hunk in the merged workflow
+	Base      []string
+	Edited    []string
+	Generated []string
+}
+
+// MergeResult is the outcome of merging edits into a regenerated workflow
+type MergeResult struct {
+	Content   string
+	Merged    int             // Hunks taken from the edited file
+	Resolved  int             // Conflicts settled by the resolver
+	Conflicts []MergeConflict // Conflicts written with markers
+}
+
+// WorkflowMergeConflictError reports conflicts left in a merged workflow file
+type WorkflowMergeConflictError struct {
+	Path      string
+	Conflicts []MergeConflict
+}
+
+// Error implements the error interface
+func (e *WorkflowMergeConflictError) Error() string {
+	lines := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		lines = append(lines, fmt.Sprintf("line %d", conflict.Line))
+	}
+	return fmt.Sprintf("workflow %s has %d merge conflict(s) marked with %q at %s",
+		e.Path, len(e.Conflicts), conflictMarkerEdited, strings.Join(lines, ", "))
+}
+
+// GetWorkflowBasePath returns where the last generated content of the workflow is recorded:
+// under the repository root, keyed by the path of the workflow file within it, so it does
+// not depend on the working directory and workflows with the same name do not share a base
+func (w *WorkflowConfig) GetWorkflowBasePath() string {
+	root := w.workflowRoot()
+	relative, err := filepath.Rel(root, w.GetWorkflowFilePath())
+	if err != nil {
+		relative = w.Filename
+	}
+	return filepath.Join(root, DefaultWorkflowBaseDir, relative)
+}
+
+// workflowRoot returns the repository root of the workflow file: the directory holding its
+// .github directory, or the workflow directory when it is outside one
+func (w *WorkflowConfig) workflowRoot() string {
+	dir := filepath.Clean(w.Path)
+	for current := dir; ; {
+		parent := filepath.Dir(current)
+		if filepath.Base(current) == ".github" {
+			return parent
+		}
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// recordWorkflowBase records generated content as the base of the next merge
+func (w *WorkflowConfig) recordWorkflowBase(content string) error {
+	path := w.GetWorkflowBasePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to record generated workflow: %w", err)
+	}
+	return nil
+}
+
+// mergeWithWorkflowFile merges the edits made to the workflow file since it was last
+// generated into the newly generated content
+func (w *WorkflowConfig) mergeWithWorkflowFile(filePath, generated string, resolve ConflictResolver) (MergeResult, error) {
+	edited, err := os.ReadFile(filePath)
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("failed to read workflow file: %w", err)
+	}
+	basePath := w.GetWorkflowBasePath()
+	base, err := os.ReadFile(basePath)
+	if os.IsNotExist(err) {
+		return MergeResult{}, errors.NewFileSystemError(
+			fmt.Sprintf("No generated copy of %s is recorded at %s, so edits cannot be merged", filePath, basePath),
+			"Regenerate once with --overwrite to record it, then use --merge for later regenerations")
+	}
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("failed to read generated workflow base: %w", err)
+	}
+	return MergeWorkflow(string(base), string(edited), generated, resolve), nil
+}
+
+// MergeWorkflow performs a line-based three-way merge: changes the generator made to base
+// are applied to edited, keeping the edits. Hunks changed on both sides are passed to
+// resolve; without a resolver, or when it asks for markers, they are written between
+// conflict markers.
+func MergeWorkflow(base, edited, generated string, resolve ConflictResolver) MergeResult {
+	baseLines, editedLines, generatedLines := splitLines(base), splitLines(edited), splitLines(generated)
+	toEdited := matchLines(baseLines, editedLines)
+	toGenerated := matchLines(baseLines, generatedLines)
+
+	var result MergeResult
+	var out strings.Builder
+	lines := 0
+	write := func(hunk []string) {
+		for _, line := range hunk {
+			out.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				out.WriteString("\n")
+			}
+			lines++
+		}
+	}
+
+	// Lines present in all three versions split the files into hunks that are merged
+	// independently
+	b, e, g := 0, 0, 0
+	for i := 0; i <= len(baseLines); i++ {
+		ei, gi := len(editedLines), len(generatedLines)
+		if i < len(baseLines) {
+			if toEdited[i] < 0 || toGenerated[i] < 0 {
+				continue
+			}
+			ei, gi = toEdited[i], toGenerated[i]
+		}
+
+		baseHunk, editedHunk, generatedHunk := baseLines[b:i], editedLines[e:ei], generatedLines[g:gi]
+		switch {
+		case equalLines(editedHunk, baseHunk), equalLines(editedHunk, generatedHunk):
+			write(generatedHunk)
+		case equalLines(generatedHunk, baseHunk):
+			result.Merged++
+			write(editedHunk)
+		default:
+			conflict := MergeConflict{Line: lines + 1, Base: baseHunk, Edited: editedHunk, Generated: generatedHunk}
+			resolution := ResolveWithMarkers
+			if resolve != nil {
+				resolution = resolve(conflict)
+			}
+			switch resolution {
+			case ResolveKeepEdited:
+				result.Resolved++
+				write(editedHunk)
+			case ResolveUseGenerated:
+				result.Resolved++
+				write(generatedHunk)
+			default:
+				result.Conflicts = append(result.Conflicts, conflict)
+				write([]string{conflictMarkerEdited})
+				write(editedHunk)
+				write([]string{conflictMarkerSeparator})
+				write(generatedHunk)
+				write([]string{conflictMarkerGenerated})
+			}
+		}
+
+		if i < len(baseLines) {
+			write(generatedLines[gi : gi+1])
+		}
+		b, e, g = i+1, ei+1, gi+1
+	}
+
+	result.Content = out.String()
+	if !strings.HasSuffix(generated, "\n") {
+		result.Content = strings.TrimSuffix(result.Content, "\n")
+	}
+	return result
+}
+
+// splitLines splits content into lines, each keeping its line ending
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.SplitAfter(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// equalLines reports whether two hunks have the same lines, ignoring a missing final newline
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.TrimSuffix(a[i], "\n") != strings.TrimSuffix(b[i], "\n") {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLines returns, for each line of a, the index of the line of b it is matched with in a
+// longest common subsequence, or -1 when it has no match
+func matchLines(a, b []string) []int {
+	matches := make([]int, len(a))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// Common leading and trailing lines are matched directly, which keeps the table small
+	// for the usual handful of edits
+	start := 0
+	for start < len(a) && start < len(b) && equalLines(a[start:start+1], b[start:start+1]) {
+		matches[start] = start
+		start++
+	}
+	endA, endB := len(a), len(b)
+	for endA > start && endB > start && equalLines(a[endA-1:endA], b[endB-1:endB]) {
+		endA--
+		endB--
+		matches[endA] = endB
+	}
+
+	n, m := endA-start, endB-start
+	lengths := make([][]int32, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equalLines(a[start+i:start+i+1], b[start+j:start+j+1]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equalLines(a[start+i:start+i+1], b[start+j:start+j+1]):
+			matches[start+i] = start + j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
@@ -0,0 +1,170 @@
+package github
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeWorkflow(t *testing.T) {
+	base := "name: Deploy\non: push\nenv:\n  REGION: us-central1\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n"
+	edited := "name: Deploy\non: push\nenv:\n  REGION: us-central1\n  EXTRA: yes\njobs:\n  deploy:\n    runs-on: self-hosted\n"
+	generated := "name: Deploy to Cloud Run\non: push\nenv:\n  REGION: europe-west1\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n"
+
+	result := MergeWorkflow(base, edited, generated, nil)
+	expected := "name: Deploy to Cloud Run\non: push\nenv:\n<<<<<<< edited\n  REGION: us-central1\n  EXTRA: yes\n=======\n  REGION: europe-west1\n>>>>>>> generated\njobs:\n  deploy:\n    runs-on: self-hosted\n"
+	if result.Content != expected {
+		t.Errorf("Unexpected merge:\n%s", result.Content)
+	}
+	if result.Merged != 1 || len(result.Conflicts) != 1 || result.Conflicts[0].Line != 4 {
+		t.Errorf("Expected one kept edit and one conflict at line 4, got %d and %+v", result.Merged, result.Conflicts)
+	}
+
+	// A resolver settles the conflict instead of writing markers
+	result = MergeWorkflow(base, edited, generated, func(conflict MergeConflict) ConflictResolution {
+		return ResolveUseGenerated
+	})
+	if len(result.Conflicts) != 0 || result.Resolved != 1 || !strings.Contains(result.Content, "  REGION: europe-west1\njobs:") {
+		t.Errorf("Expected the generated lines to be used, got:\n%s", result.Content)
+	}
+
+	// An unedited file takes the generated content as is
+	if result := MergeWorkflow(base, base, generated, nil); result.Content != generated {
+		t.Errorf("Expected the generated content, got:\n%s", result.Content)
+	}
+}
+
+func TestGetWorkflowBasePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		filename string
+		expected string
+	}{
+		{".github/workflows", "deploy.yml", filepath.Join(".gcp-wif", "generated", ".github", "workflows", "deploy.yml")},
+		{"/src/app/.github/workflows", "deploy.yml", filepath.Join("/src/app", ".gcp-wif", "generated", ".github", "workflows", "deploy.yml")},
+		{"/src/other/.github/workflows", "deploy.yml", filepath.Join("/src/other", ".gcp-wif", "generated", ".github", "workflows", "deploy.yml")},
+		{"/src/app/.github/workflows/staging", "deploy.yml", filepath.Join("/src/app", ".gcp-wif", "generated", ".github", "workflows", "staging", "deploy.yml")},
+		{"/src/ci", "deploy.yml", filepath.Join("/src/ci", ".gcp-wif", "generated", "deploy.yml")},
+	}
+
+	for _, tt := range tests {
+		cfg := &WorkflowConfig{Path: tt.path, Filename: tt.filename}
+		if got := cfg.GetWorkflowBasePath(); got != tt.expected {
+			t.Errorf("GetWorkflowBasePath() for %s = %s, expected %s", filepath.Join(tt.path, tt.filename), got, tt.expected)
+		}
+	}
+}
+
+func TestWriteWorkflowFileMerge(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	options := WriteWorkflowFileOptions{Validate: true}
+
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.WriteWorkflowFileWithOptions(content, options); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+	if recorded, err := os.ReadFile(cfg.GetWorkflowBasePath()); err != nil || string(recorded) != content {
+		t.Fatalf("Expected the generated workflow to be recorded: %v", err)
+	}
+
+	// Hand edit the file, then regenerate with a different region
+	path := cfg.GetWorkflowFilePath()
+	edited := strings.Replace(content, "    runs-on: ubuntu-latest\n", "    runs-on: self-hosted\n", 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Region = "europe-west1"
+	regenerated, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+
+	if err := cfg.WriteWorkflowFileWithOptions(regenerated, options); err == nil {
+		t.Error("Expected an existing file not to be replaced without overwrite or merge")
+	}
+	options.Merge = true
+	if err := cfg.WriteWorkflowFileWithOptions(regenerated, options); err != nil {
+		t.Fatalf("Failed to merge workflow: %v", err)
+	}
+	merged, _ := os.ReadFile(path)
+	if !strings.Contains(string(merged), "runs-on: self-hosted") || !strings.Contains(string(merged), "REGION: europe-west1") {
+		t.Errorf("Expected the edit and the regenerated region, got:\n%s", merged)
+	}
+	if recorded, _ := os.ReadFile(cfg.GetWorkflowBasePath()); string(recorded) != regenerated {
+		t.Error("Expected the regenerated workflow to become the merge base")
+	}
+
+	// Editing a line the generator also changes leaves conflict markers
+	if err := os.WriteFile(path, []byte(strings.Replace(string(merged), "REGION: europe-west1", "REGION: asia-east1", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Region = "us-east1"
+	regenerated, _ = cfg.GenerateWorkflow()
+	err = cfg.WriteWorkflowFileWithOptions(regenerated, options)
+	var conflicts *WorkflowMergeConflictError
+	if !stderrors.As(err, &conflicts) || len(conflicts.Conflicts) != 1 {
+		t.Fatalf("Expected a merge conflict, got %v", err)
+	}
+	if merged, _ := os.ReadFile(path); !strings.Contains(string(merged), "<<<<<<< edited\n  REGION: asia-east1\n=======\n  REGION: us-east1\n>>>>>>> generated\n") {
+		t.Errorf("Expected conflict markers, got:\n%s", merged)
+	}
+
+	// Without a recorded base there is nothing to merge against
+	if err := os.RemoveAll(filepath.Dir(cfg.GetWorkflowBasePath())); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.WriteWorkflowFileWithOptions(regenerated, options); err == nil || !strings.Contains(err.Error(), "cannot be merged") {
+		t.Errorf("Expected a missing base to be reported, got %v", err)
+	}
+}
+
+func TestWriteWorkflowFileMergeHeaderEdits(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	options := WriteWorkflowFileOptions{Validate: true}
+
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if strings.Contains(content, "Generated on") {
+		t.Fatalf("Expected the header not to change between renders, got:\n%s", content[:strings.Index(content, "\nname:")])
+	}
+	if err := cfg.WriteWorkflowFileWithOptions(content, options); err != nil {
+		t.Fatalf("Failed to write workflow: %v", err)
+	}
+
+	// Edit the lines around the generated header, then regenerate twice
+	lines := strings.SplitAfter(content, "\n")
+	lines[0] = "# Deploys the API to Cloud Run\n"
+	lines[1] += "# Owned by the platform team\n"
+	edited := strings.Join(lines, "")
+	path := cfg.GetWorkflowFilePath()
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	options.Merge = true
+	for _, region := range []string{"europe-west1", "asia-east1"} {
+		cfg.Region = region
+		regenerated, err := cfg.GenerateWorkflow()
+		if err != nil {
+			t.Fatalf("Failed to generate workflow: %v", err)
+		}
+		if err := cfg.WriteWorkflowFileWithOptions(regenerated, options); err != nil {
+			t.Fatalf("Expected the header edits to merge when regenerating for %s, got %v", region, err)
+		}
+		merged, _ := os.ReadFile(path)
+		if !strings.HasPrefix(string(merged), "# Deploys the API to Cloud Run\n# Generated by ") ||
+			!strings.Contains(string(merged), "# Owned by the platform team\n") ||
+			!strings.Contains(string(merged), "REGION: "+region) {
+			t.Errorf("Expected the header edits and region %s, got:\n%s", region, merged)
+		}
+	}
+}
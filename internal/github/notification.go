@@ -0,0 +1,374 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// Notification hook types
+const (
+	NotificationSlack   = "slack"
+	NotificationTeams   = "teams"
+	NotificationDiscord = "discord"
+	NotificationWebhook = "webhook"
+)
+
+// Notification events, each rendered as a step of the deploy job
+const (
+	NotificationEventStart   = "start"
+	NotificationEventSuccess = "success"
+	NotificationEventFailure = "failure"
+)
+
+var (
+	secretNameRegex       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	secretExpressionRegex = regexp.MustCompile(`^\$\{\{\s*secrets\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+
+	// notificationEventConditions are the step conditions of each event
+	notificationEvents          = []string{NotificationEventStart, NotificationEventSuccess, NotificationEventFailure}
+	notificationEventConditions = map[string]string{
+		NotificationEventStart:   "",
+		NotificationEventSuccess: "success()",
+		NotificationEventFailure: "failure()",
+	}
+	notificationEventTitles = map[string]string{
+		NotificationEventStart:   "🚀 Deployment of %s started",
+		NotificationEventSuccess: "✅ Deployment of %s succeeded",
+		NotificationEventFailure: "❌ Deployment of %s failed",
+	}
+)
+
+// notificationField is a payload field: either a message attribute or a literal value
+type notificationField struct {
+	Key       string
+	Attribute string // text, title, event, repository, ref, sha, actor or run_url
+	Literal   interface{}
+}
+
+// notificationTypes describes each hook type: its default secret, the config keys copied
+// into the payload, and the payload fields
+var notificationTypes = map[string]struct {
+	Secret string
+	Config []string
+	Fields []notificationField
+}{
+	NotificationSlack: {
+		Secret: "SLACK_WEBHOOK_URL",
+		Config: []string{"channel", "username", "icon_emoji"},
+		Fields: []notificationField{{Key: "text", Attribute: "text"}},
+	},
+	NotificationTeams: {
+		Secret: "TEAMS_WEBHOOK_URL",
+		Config: []string{"themeColor"},
+		Fields: []notificationField{
+			{Key: "@type", Literal: "MessageCard"},
+			{Key: "@context", Literal: "https://schema.org/extensions"},
+			{Key: "summary", Attribute: "title"},
+			{Key: "text", Attribute: "text"},
+		},
+	},
+	NotificationDiscord: {
+		Secret: "DISCORD_WEBHOOK_URL",
+		Config: []string{"username", "avatar_url"},
+		Fields: []notificationField{{Key: "content", Attribute: "text"}},
+	},
+	NotificationWebhook: {
+		Secret: "NOTIFICATION_WEBHOOK_URL",
+		Fields: []notificationField{
+			{Key: "event", Attribute: "event"},
+			{Key: "title", Attribute: "title"},
+			{Key: "text", Attribute: "text"},
+			{Key: "repository", Attribute: "repository"},
+			{Key: "ref", Attribute: "ref"},
+			{Key: "sha", Attribute: "sha"},
+			{Key: "actor", Attribute: "actor"},
+			{Key: "run_url", Attribute: "run_url"},
+		},
+	},
+}
+
+// notificationJQ maps message attributes to the jq expressions that read them on the runner
+var notificationJQ = map[string]string{
+	"text":       "$text",
+	"title":      "$title",
+	"event":      "$event",
+	"repository": "env.GITHUB_REPOSITORY",
+	"ref":        "env.GITHUB_REF_NAME",
+	"sha":        "env.GITHUB_SHA",
+	"actor":      "env.GITHUB_ACTOR",
+	"run_url":    "$run_url",
+}
+
+// NotificationMessage is the content of a notification
+type NotificationMessage struct {
+	Event      string
+	Subject    string // Service or component being deployed
+	Repository string
+	Ref        string
+	SHA        string
+	Actor      string
+	RunURL     string
+}
+
+// Title returns the one-line summary of the message
+func (m NotificationMessage) Title() string {
+	format, ok := notificationEventTitles[m.Event]
+	if !ok {
+		format = "Deployment of %s: " + m.Event
+	}
+	return fmt.Sprintf(format, m.Subject)
+}
+
+// Text returns the full message text
+func (m NotificationMessage) Text() string {
+	return fmt.Sprintf("%s\n%s@%s by %s\n%s", m.Title(), m.Repository, m.Ref, m.Actor, m.RunURL)
+}
+
+func (m NotificationMessage) attribute(name string) string {
+	switch name {
+	case "text":
+		return m.Text()
+	case "title":
+		return m.Title()
+	case "event":
+		return m.Event
+	case "repository":
+		return m.Repository
+	case "ref":
+		return m.Ref
+	case "sha":
+		return m.SHA
+	case "actor":
+		return m.Actor
+	case "run_url":
+		return m.RunURL
+	}
+	return ""
+}
+
+// SampleNotificationMessage returns a message with placeholder run details, for testing hooks
+func SampleNotificationMessage(event, subject string) NotificationMessage {
+	return NotificationMessage{
+		Event:      event,
+		Subject:    subject,
+		Repository: "example/repository",
+		Ref:        "main",
+		SHA:        "0000000000000000000000000000000000000000",
+		Actor:      "gcp-wif",
+		RunURL:     "https://github.com/example/repository/actions/runs/1",
+	}
+}
+
+// SecretName returns the repository secret that holds the hook URL. The URL may name the
+// secret directly or as a ${{ secrets.NAME }} expression; an empty URL uses the type's
+// default secret. An empty result means the URL is not a secret reference.
+func (h NotificationHook) SecretName() string {
+	if strings.TrimSpace(h.URL) == "" {
+		return notificationTypes[h.Type].Secret
+	}
+	return secretName(h.URL)
+}
+
+// secretName returns the secret named by a reference of the form NAME or
+// ${{ secrets.NAME }}, or an empty string when the reference is neither
+func secretName(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if match := secretExpressionRegex.FindStringSubmatch(ref); match != nil {
+		return match[1]
+	}
+	if secretNameRegex.MatchString(ref) {
+		return ref
+	}
+	return ""
+}
+
+// GetEvents returns the hook's events, defaulting to success and failure
+func (h NotificationHook) GetEvents() []string {
+	if len(h.Events) == 0 {
+		return []string{NotificationEventSuccess, NotificationEventFailure}
+	}
+	return h.Events
+}
+
+// BuildNotificationPayload returns the JSON body a hook type expects for a message
+func BuildNotificationPayload(hook NotificationHook, message NotificationMessage) ([]byte, error) {
+	spec, ok := notificationTypes[hook.Type]
+	if !ok {
+		return nil, errors.NewValidationError(fmt.Sprintf("Unknown notification type '%s'", hook.Type),
+			"Use slack, teams, discord or webhook")
+	}
+	payload := make(map[string]interface{})
+	for _, field := range spec.Fields {
+		if field.Attribute != "" {
+			payload[field.Key] = message.attribute(field.Attribute)
+		} else {
+			payload[field.Key] = field.Literal
+		}
+	}
+	for _, key := range spec.Config {
+		if value, ok := hook.Config[key]; ok {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to encode notification payload", err)
+	}
+	return body, nil
+}
+
+// notificationFilter returns the jq program that builds a hook's payload on the runner
+func notificationFilter(hook NotificationHook) string {
+	spec := notificationTypes[hook.Type]
+	var fields []string
+	add := func(key, expression string) {
+		quoted, _ := json.Marshal(key)
+		fields = append(fields, fmt.Sprintf("%s: %s", quoted, expression))
+	}
+	for _, field := range spec.Fields {
+		if field.Attribute != "" {
+			add(field.Key, notificationJQ[field.Attribute])
+		} else {
+			literal, _ := json.Marshal(field.Literal)
+			add(field.Key, string(literal))
+		}
+	}
+	for _, key := range spec.Config {
+		if value, ok := hook.Config[key]; ok {
+			literal, _ := json.Marshal(value)
+			add(key, string(literal))
+		}
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+// notificationSteps renders the deploy job steps that notify the configured hooks of an
+// event. subject is the expression naming what is deployed. Hook URLs are only read from
+// secrets, through the step env, so they never appear in the workflow or the logs.
+func (w *WorkflowConfig) notificationSteps(event, subject string) string {
+	var steps strings.Builder
+	for _, hook := range w.Advanced.NotificationHooks {
+		if !containsString(hook.GetEvents(), event) {
+			continue
+		}
+		message := NotificationMessage{
+			Event:      event,
+			Subject:    subject,
+			Repository: "${{ github.repository }}",
+			Ref:        "${{ github.ref_name }}",
+			Actor:      "${{ github.actor }}",
+			RunURL:     "${{ github.server_url }}/${{ github.repository }}/actions/runs/${{ github.run_id }}",
+		}
+
+		fmt.Fprintf(&steps, "\n\n    - name: Notify %s (%s)", hook.Type, event)
+		if condition := notificationEventConditions[event]; condition != "" {
+			fmt.Fprintf(&steps, "\n      if: %s", condition)
+		}
+		fmt.Fprintf(&steps, "\n      env:")
+		fmt.Fprintf(&steps, "\n        NOTIFY_URL: ${{ secrets.%s }}", hook.SecretName())
+		fmt.Fprintf(&steps, "\n        NOTIFY_EVENT: %s", event)
+		fmt.Fprintf(&steps, "\n        NOTIFY_TITLE: %s", yamlDoubleQuote(message.Title()))
+		fmt.Fprintf(&steps, "\n        NOTIFY_TEXT: %s", yamlDoubleQuote(message.Text()))
+		fmt.Fprintf(&steps, "\n        NOTIFY_RUN_URL: %s", yamlDoubleQuote(message.RunURL))
+		fmt.Fprintf(&steps, "\n      run: |")
+		fmt.Fprintf(&steps, "\n        if [ -z \"$NOTIFY_URL\" ]; then")
+		fmt.Fprintf(&steps, "\n          echo \"::warning::Secret %s is not set, skipping %s notification\"", hook.SecretName(), hook.Type)
+		fmt.Fprintf(&steps, "\n          exit 0")
+		fmt.Fprintf(&steps, "\n        fi")
+		fmt.Fprintf(&steps, "\n        jq -n --arg event \"$NOTIFY_EVENT\" --arg title \"$NOTIFY_TITLE\" --arg text \"$NOTIFY_TEXT\" --arg run_url \"$NOTIFY_RUN_URL\" \\")
+		fmt.Fprintf(&steps, "\n          '%s' > notification.json", strings.ReplaceAll(notificationFilter(hook), "'", `'\''`))
+		fmt.Fprintf(&steps, "\n        curl -fsS -X POST -H \"Content-Type: application/json\" --data @notification.json \"$NOTIFY_URL\" \\")
+		fmt.Fprintf(&steps, "\n          || echo \"::warning::Failed to send %s notification\"", hook.Type)
+	}
+	return steps.String()
+}
+
+// yamlDoubleQuote quotes a string as a YAML double-quoted scalar
+func yamlDoubleQuote(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+// ValidateNotificationHooks checks the configured notification hooks
+func (w *WorkflowConfig) ValidateNotificationHooks() error {
+	for i, hook := range w.Advanced.NotificationHooks {
+		field := fmt.Sprintf("workflow.advanced.notification_hooks[%d]", i)
+		spec, ok := notificationTypes[hook.Type]
+		if !ok {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Unknown notification type '%s' (expected slack, teams, discord or webhook)", hook.Type),
+				field+".type", "INVALID")
+		}
+		if strings.Contains(hook.URL, "://") {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Notification URL for %s must reference a repository secret, not a literal URL", hook.Type),
+				field+".url", "INSECURE")
+		}
+		secret := hook.SecretName()
+		if secret == "" || strings.HasPrefix(strings.ToUpper(secret), "GITHUB_") {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Invalid notification secret name '%s'", hook.URL),
+				field+".url", "INVALID")
+		}
+		for _, event := range hook.Events {
+			if _, ok := notificationEventConditions[event]; !ok {
+				return errors.NewValidationError(fmt.Sprintf("Workflow: Unknown notification event '%s' (expected %s)", event, strings.Join(notificationEvents, ", ")),
+					field+".events", "INVALID")
+			}
+		}
+		var keys []string
+		for key := range hook.Config {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if !containsString(spec.Config, key) {
+				return errors.NewValidationError(fmt.Sprintf("Workflow: Unsupported %s notification setting '%s'", hook.Type, key),
+					field+".config", "INVALID")
+			}
+			if strings.Contains(hook.Config[key], "${{") {
+				return errors.NewValidationError(fmt.Sprintf("Workflow: Notification setting '%s' must not contain expressions", key),
+					field+".config", "INVALID")
+			}
+		}
+	}
+	return nil
+}
+
+// SendNotification posts a notification payload to a webhook URL and returns the response
+// status code
+func SendNotification(client *http.Client, url string, payload []byte) (int, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrorTypeValidation, "NOTIFICATION_REQUEST_FAILED",
+			"Failed to build notification request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, errors.WrapError(err, errors.ErrorTypeNetwork, "NOTIFICATION_FAILED",
+			"Failed to send notification")
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message := fmt.Sprintf("Notification endpoint returned %s", resp.Status)
+		if text := strings.TrimSpace(string(body)); text != "" {
+			message += ": " + text
+		}
+		return resp.StatusCode, errors.NewError(errors.ErrorTypeNetwork, "NOTIFICATION_REJECTED", message).
+			WithSuggestions("Check the webhook URL and that the endpoint accepts JSON")
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,118 @@
+package github
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotificationSteps(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.Advanced.NotificationHooks = []NotificationHook{
+		{Type: NotificationSlack, Config: map[string]string{"channel": "#deploys"}},
+		{Type: NotificationWebhook, URL: "${{ secrets.DEPLOY_HOOK }}", Events: []string{NotificationEventStart, NotificationEventFailure}},
+	}
+
+	if err := cfg.ValidateConfig(); err != nil {
+		t.Fatalf("Expected notification hooks to be valid: %v", err)
+	}
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	var names []string
+	for _, step := range workflow.Job("deploy").Steps {
+		if strings.HasPrefix(step.Name, "Notify") {
+			names = append(names, step.Name+" if "+step.If+" via "+step.Env["NOTIFY_URL"])
+		}
+	}
+	expected := []string{
+		"Notify webhook (start) if  via ${{ secrets.DEPLOY_HOOK }}",
+		"Notify slack (success) if success() via ${{ secrets.SLACK_WEBHOOK_URL }}",
+		"Notify slack (failure) if failure() via ${{ secrets.SLACK_WEBHOOK_URL }}",
+		"Notify webhook (failure) if failure() via ${{ secrets.DEPLOY_HOOK }}",
+	}
+	if strings.Join(names, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected notification steps:\n%s", strings.Join(names, "\n"))
+	}
+	if !strings.Contains(content, `'{"text": $text, "channel": "#deploys"}'`) {
+		t.Error("Expected the slack payload to include the configured channel")
+	}
+
+	if findings, _ := LintWorkflow("deploy.yml", content, LintExpectations{}); len(findings) > 0 {
+		t.Errorf("Expected no lint findings, got %+v", findings)
+	}
+}
+
+func TestNotificationValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    NotificationHook
+		message string
+	}{
+		{"unknown type", NotificationHook{Type: "pager"}, "Unknown notification type"},
+		{"literal url", NotificationHook{Type: NotificationSlack, URL: "https://hooks.slack.com/services/T/B/X"}, "must reference a repository secret"},
+		{"reserved secret", NotificationHook{Type: NotificationSlack, URL: "GITHUB_TOKEN"}, "Invalid notification secret name"},
+		{"unknown event", NotificationHook{Type: NotificationDiscord, Events: []string{"cancelled"}}, "Unknown notification event"},
+		{"unknown setting", NotificationHook{Type: NotificationWebhook, Config: map[string]string{"channel": "x"}}, "Unsupported webhook notification setting"},
+		{"expression setting", NotificationHook{Type: NotificationSlack, Config: map[string]string{"channel": "${{ github.actor }}"}}, "must not contain expressions"},
+	}
+
+	for _, test := range tests {
+		cfg := testWorkflowConfig(DefaultWorkflowConfig())
+		cfg.Advanced.NotificationHooks = []NotificationHook{test.hook}
+		if err := cfg.ValidateConfig(); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook := NotificationHook{Type: NotificationTeams, Config: map[string]string{"themeColor": "FF0000"}}
+	payload, err := BuildNotificationPayload(hook, SampleNotificationMessage(NotificationEventFailure, "my-service"))
+	if err != nil {
+		t.Fatalf("Failed to build payload: %v", err)
+	}
+	status, err := SendNotification(server.Client(), server.URL, payload)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Expected delivery, got status %d: %v", status, err)
+	}
+	if received["@type"] != "MessageCard" || received["themeColor"] != "FF0000" ||
+		received["summary"] != "❌ Deployment of my-service failed" {
+		t.Errorf("Unexpected payload: %v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	if _, err := SendNotification(failing.Client(), failing.URL, payload); err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("Expected the endpoint's rejection to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// OIDCSubjectTemplate is the OIDC subject claim template that applies to a repository
+type OIDCSubjectTemplate struct {
+	// ClaimKeys are the claims the subject is built from, empty for GitHub's default
+	// repo:OWNER/REPO:CONTEXT subject
+	ClaimKeys []string `json:"include_claim_keys,omitempty"`
+	// Source is where the template is set: repository, organization or default
+	Source string `json:"source"`
+}
+
+// oidcSubjectCustomization is the body of the OIDC subject customization endpoints
+type oidcSubjectCustomization struct {
+	UseDefault       bool     `json:"use_default"`
+	IncludeClaimKeys []string `json:"include_claim_keys,omitempty"`
+}
+
+// oidcSubjectPath returns the API path of a repository's OIDC subject customization
+func oidcSubjectPath(owner, repo string) string {
+	return fmt.Sprintf("/repos/%s/%s/actions/oidc/customization/sub", url.PathEscape(owner), url.PathEscape(repo))
+}
+
+// GetOIDCSubjectTemplate returns the subject template tokens of a repository are issued
+// with. A repository that uses the default inherits its organization's template.
+func (c *APIClient) GetOIDCSubjectTemplate(ctx context.Context, owner, repo string) (*OIDCSubjectTemplate, error) {
+	scope := VariableScope{Owner: owner, Repo: repo}
+	var customization oidcSubjectCustomization
+	if _, err := c.do(ctx, scope, http.MethodGet, oidcSubjectPath(owner, repo), nil, &customization); err != nil {
+		return nil, err
+	}
+	if !customization.UseDefault {
+		return &OIDCSubjectTemplate{ClaimKeys: customization.IncludeClaimKeys, Source: "repository"}, nil
+	}
+
+	// Personal accounts have no organization template
+	customization = oidcSubjectCustomization{}
+	path := fmt.Sprintf("/orgs/%s/actions/oidc/customization/sub", url.PathEscape(owner))
+	status, err := c.do(ctx, scope, http.MethodGet, path, nil, &customization)
+	if status == http.StatusNotFound || (err == nil && len(customization.IncludeClaimKeys) == 0) {
+		return &OIDCSubjectTemplate{Source: "default"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCSubjectTemplate{ClaimKeys: customization.IncludeClaimKeys, Source: "organization"}, nil
+}
+
+// SetOIDCSubjectTemplate customizes the subject of a repository's tokens. No claim keys
+// reverts the repository to the default, or its organization's template.
+func (c *APIClient) SetOIDCSubjectTemplate(ctx context.Context, owner, repo string, claimKeys []string) error {
+	scope := VariableScope{Owner: owner, Repo: repo}
+	body := oidcSubjectCustomization{UseDefault: len(claimKeys) == 0, IncludeClaimKeys: claimKeys}
+	_, err := c.do(ctx, scope, http.MethodPut, oidcSubjectPath(owner, repo), body, nil)
+	return err
+}
@@ -0,0 +1,58 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOIDCSubjectTemplate(t *testing.T) {
+	repository := oidcSubjectCustomization{UseDefault: true}
+	organization := http.StatusNotFound
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/acme/app/actions/oidc/customization/sub" && r.Method == http.MethodPut:
+			repository = oidcSubjectCustomization{}
+			_ = json.NewDecoder(r.Body).Decode(&repository)
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/repos/acme/app/actions/oidc/customization/sub":
+			_ = json.NewEncoder(w).Encode(repository)
+		case r.URL.Path == "/orgs/acme/actions/oidc/customization/sub" && organization == http.StatusOK:
+			_ = json.NewEncoder(w).Encode(map[string][]string{"include_claim_keys": {"repo", "context", "job_workflow_ref"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewAPIClient(APIClientConfig{BaseURL: server.URL, Token: "test-token"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	template, err := client.GetOIDCSubjectTemplate(ctx, "acme", "app")
+	if err != nil || template.Source != "default" || len(template.ClaimKeys) != 0 {
+		t.Errorf("Expected GitHub's default subject for a personal account, got %+v, %v", template, err)
+	}
+
+	organization = http.StatusOK
+	template, err = client.GetOIDCSubjectTemplate(ctx, "acme", "app")
+	if err != nil || template.Source != "organization" || strings.Join(template.ClaimKeys, ",") != "repo,context,job_workflow_ref" {
+		t.Errorf("Expected the organization's template to be inherited, got %+v, %v", template, err)
+	}
+
+	if err := client.SetOIDCSubjectTemplate(ctx, "acme", "app", []string{"repository_owner_id", "repository_id"}); err != nil {
+		t.Fatalf("Failed to set subject template: %v", err)
+	}
+	template, err = client.GetOIDCSubjectTemplate(ctx, "acme", "app")
+	if err != nil || template.Source != "repository" || strings.Join(template.ClaimKeys, ",") != "repository_owner_id,repository_id" {
+		t.Errorf("Expected the repository's template, got %+v, %v", template, err)
+	}
+
+	if err := client.SetOIDCSubjectTemplate(ctx, "acme", "app", nil); err != nil || !repository.UseDefault {
+		t.Errorf("Expected no claims to revert to the default, got %+v, %v", repository, err)
+	}
+}
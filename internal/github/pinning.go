@@ -0,0 +1,301 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+)
+
+// DefaultActionLockFile is where resolved action commit SHAs are recorded
+const DefaultActionLockFile = ".gcp-wif/actions.lock.json"
+
+// DefaultGitHubAPIURL is the base URL of the GitHub REST API
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+var (
+	// usesLineRegex matches a "uses: owner/repo[/path]@ref" line, keeping its indentation,
+	// list marker and optional quotes so the line can be rewritten in place
+	usesLineRegex  = regexp.MustCompile(`^(\s*(?:-\s+)?uses:\s*)(["']?)([^@\s"'#]+)@([^\s"'#]+)(["']?)(\s+#.*)?$`)
+	commitSHARegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// ActionReference is a "uses:" reference to a third-party action
+type ActionReference struct {
+	Action string `json:"action"` // owner/repo or owner/repo/path
+	Ref    string `json:"ref"`    // Tag, branch or commit SHA
+	Line   int    `json:"line"`
+}
+
+// Key returns the lock file key of the reference
+func (r ActionReference) Key() string {
+	return r.Action + "@" + r.Ref
+}
+
+// Repository returns the owner/repo part of the action
+func (r ActionReference) Repository() string {
+	parts := strings.SplitN(r.Action, "/", 3)
+	if len(parts) < 2 {
+		return r.Action
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// Pinned reports whether the reference already points at a full commit SHA
+func (r ActionReference) Pinned() bool {
+	return commitSHARegex.MatchString(r.Ref)
+}
+
+// ActionResolver resolves an action reference to the commit SHA it currently points at
+type ActionResolver interface {
+	Resolve(ref ActionReference) (string, error)
+}
+
+// LockedAction is a resolved action reference recorded in the lock file
+type LockedAction struct {
+	SHA        string    `json:"sha"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// ActionLock records the commit SHA of every action reference, keyed by "action@ref"
+type ActionLock struct {
+	Version int                     `json:"version"`
+	Actions map[string]LockedAction `json:"actions"`
+}
+
+// NewActionLock returns an empty lock
+func NewActionLock() *ActionLock {
+	return &ActionLock{Version: 1, Actions: make(map[string]LockedAction)}
+}
+
+// LoadActionLock reads a lock file. A missing file yields an empty lock.
+func LoadActionLock(path string) (*ActionLock, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewActionLock(), nil
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "ACTION_LOCK_READ_FAILED",
+			fmt.Sprintf("Failed to read action lock file: %s", path))
+	}
+
+	lock := NewActionLock()
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeConfiguration, "ACTION_LOCK_PARSE_FAILED",
+			fmt.Sprintf("Failed to parse action lock file: %s", path))
+	}
+	if lock.Actions == nil {
+		lock.Actions = make(map[string]LockedAction)
+	}
+	return lock, nil
+}
+
+// Save writes the lock file, creating its directory when needed
+func (l *ActionLock) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.WrapError(err, errors.ErrorTypeFileSystem, "ACTION_LOCK_DIR_FAILED",
+			fmt.Sprintf("Failed to create directory for action lock file: %s", path))
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return errors.WrapError(err, errors.ErrorTypeInternal, "ACTION_LOCK_MARSHAL_FAILED",
+			"Failed to encode action lock file")
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return errors.WrapError(err, errors.ErrorTypeFileSystem, "ACTION_LOCK_WRITE_FAILED",
+			fmt.Sprintf("Failed to write action lock file: %s", path))
+	}
+	return nil
+}
+
+// Resolve implements ActionResolver using only the recorded SHAs, so generation works offline
+func (l *ActionLock) Resolve(ref ActionReference) (string, error) {
+	locked, ok := l.Actions[ref.Key()]
+	if !ok {
+		return "", errors.NewConfigurationError(
+			fmt.Sprintf("Action %s is not in the action lock file", ref.Key()),
+			"Run 'gcp-wif workflow pin' to resolve and record missing actions")
+	}
+	return locked.SHA, nil
+}
+
+// GitHubActionResolver resolves action references through the GitHub REST API
+type GitHubActionResolver struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+// NewGitHubActionResolver creates a resolver for api.github.com, authenticating with the
+// GITHUB_TOKEN or GH_TOKEN environment variable when set to avoid anonymous rate limits
+func NewGitHubActionResolver() *GitHubActionResolver {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return &GitHubActionResolver{
+		BaseURL: DefaultGitHubAPIURL,
+		Token:   token,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Resolve returns the commit SHA the reference's tag or branch points at
+func (r *GitHubActionResolver) Resolve(ref ActionReference) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/commits/%s", strings.TrimSuffix(r.BaseURL, "/"), ref.Repository(), ref.Ref)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", errors.WrapError(err, errors.ErrorTypeInternal, "ACTION_RESOLVE_REQUEST_FAILED",
+			"Failed to build GitHub API request")
+	}
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.Token)
+	}
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return "", errors.WrapError(err, errors.ErrorTypeNetwork, "ACTION_RESOLVE_FAILED",
+			fmt.Sprintf("Failed to resolve %s via the GitHub API", ref.Key()))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", errors.WrapError(err, errors.ErrorTypeNetwork, "ACTION_RESOLVE_FAILED",
+			fmt.Sprintf("Failed to read GitHub API response for %s", ref.Key()))
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return "", errors.NewGitHubError(
+			fmt.Sprintf("Action reference %s does not exist", ref.Key()),
+			"Check the action name and version")
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return "", errors.NewGitHubError(
+			fmt.Sprintf("GitHub API rate limit reached while resolving %s", ref.Key()),
+			"Set GITHUB_TOKEN to authenticate API requests")
+	default:
+		return "", errors.NewGitHubError(
+			fmt.Sprintf("GitHub API returned %s while resolving %s", resp.Status, ref.Key()))
+	}
+
+	sha := strings.TrimSpace(string(body))
+	if !commitSHARegex.MatchString(sha) {
+		return "", errors.NewGitHubError(
+			fmt.Sprintf("GitHub API returned an unexpected commit SHA for %s", ref.Key()))
+	}
+	return sha, nil
+}
+
+// RecordingResolver resolves references from the lock when possible, falling back to another
+// resolver and recording the result in the lock. With Refresh set every reference is
+// resolved again through the fallback.
+type RecordingResolver struct {
+	Lock     *ActionLock
+	Fallback ActionResolver
+	Refresh  bool
+	Now      func() time.Time
+
+	// resolved tracks references resolved by this resolver so each is refreshed once
+	resolved map[string]bool
+}
+
+// Resolve implements ActionResolver
+func (r *RecordingResolver) Resolve(ref ActionReference) (string, error) {
+	if locked, ok := r.Lock.Actions[ref.Key()]; ok && (!r.Refresh || r.resolved[ref.Key()]) {
+		return locked.SHA, nil
+	}
+
+	sha, err := r.Fallback.Resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	r.Lock.Actions[ref.Key()] = LockedAction{SHA: sha, ResolvedAt: now().UTC()}
+	if r.resolved == nil {
+		r.resolved = make(map[string]bool)
+	}
+	r.resolved[ref.Key()] = true
+	return sha, nil
+}
+
+// pinnableAction reports whether a "uses:" reference can be pinned. Local actions and
+// docker:// references have no commit, and the SLSA generator must be called by its tag.
+func pinnableAction(action string) bool {
+	return !strings.HasPrefix(action, "./") && !strings.HasPrefix(action, "docker:") &&
+		!strings.HasPrefix(action, "slsa-framework/slsa-github-generator/")
+}
+
+// ExtractActionReferences returns the third-party action references in a workflow. Local
+// actions, docker:// references and the SLSA generator are skipped.
+func ExtractActionReferences(content string) []ActionReference {
+	var refs []ActionReference
+	for i, line := range strings.Split(content, "\n") {
+		match := usesLineRegex.FindStringSubmatch(line)
+		if match == nil || !pinnableAction(match[3]) {
+			continue
+		}
+		refs = append(refs, ActionReference{Action: match[3], Ref: match[4], Line: i + 1})
+	}
+	return refs
+}
+
+// PinWorkflowActions rewrites every unpinned action reference to its commit SHA, keeping
+// the original ref as a trailing comment: "uses: actions/checkout@<sha> # v4". References
+// that are already pinned are left unchanged. It returns the rewritten content and the
+// references that were pinned.
+func PinWorkflowActions(content string, resolver ActionResolver) (string, []ActionReference, error) {
+	logger := logging.WithField("function", "PinWorkflowActions")
+
+	lines := strings.Split(content, "\n")
+	var pinned []ActionReference
+	for i, line := range lines {
+		match := usesLineRegex.FindStringSubmatch(line)
+		if match == nil || !pinnableAction(match[3]) {
+			continue
+		}
+		ref := ActionReference{Action: match[3], Ref: match[4], Line: i + 1}
+		if ref.Pinned() {
+			continue
+		}
+
+		sha, err := resolver.Resolve(ref)
+		if err != nil {
+			return "", nil, err
+		}
+		lines[i] = fmt.Sprintf("%s%s%s@%s%s # %s", match[1], match[2], ref.Action, sha, match[5], ref.Ref)
+		pinned = append(pinned, ref)
+	}
+
+	logger.Debug("Pinned workflow actions", "count", len(pinned))
+	return strings.Join(lines, "\n"), pinned, nil
+}
+
+// UniqueActionReferences returns the distinct unpinned references, sorted by key
+func UniqueActionReferences(refs []ActionReference) []ActionReference {
+	seen := make(map[string]bool)
+	var unique []ActionReference
+	for _, ref := range refs {
+		if ref.Pinned() || seen[ref.Key()] {
+			continue
+		}
+		seen[ref.Key()] = true
+		unique = append(unique, ref)
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i].Key() < unique[j].Key() })
+	return unique
+}
@@ -0,0 +1,129 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	checkoutSHA = "11bd71901bbe5b1630ceea73d27597364c9af683"
+	authSHA     = "6fc4af4b145ae7821d527454aa9bd537d1f2dc5f"
+)
+
+func TestPinWorkflowActions(t *testing.T) {
+	content := `jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+      - name: Auth
+        uses: "google-github-actions/auth@v2"
+      - uses: ./local-action
+      - uses: actions/checkout@` + checkoutSHA + ` # v4
+`
+	lock := NewActionLock()
+	lock.Actions["actions/checkout@v4"] = LockedAction{SHA: checkoutSHA}
+	lock.Actions["google-github-actions/auth@v2"] = LockedAction{SHA: authSHA}
+
+	pinned, refs, err := PinWorkflowActions(content, lock)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(refs) != 2 {
+		t.Errorf("Expected 2 pinned references, got %+v", refs)
+	}
+	for _, expected := range []string{
+		"      - uses: actions/checkout@" + checkoutSHA + " # v4\n",
+		`        uses: "google-github-actions/auth@` + authSHA + `" # v2`,
+		"      - uses: ./local-action\n",
+	} {
+		if !strings.Contains(pinned, expected) {
+			t.Errorf("Expected pinned content to contain %q:\n%s", expected, pinned)
+		}
+	}
+
+	// References missing from the lock fail instead of silently staying unpinned
+	if _, _, err := PinWorkflowActions("- uses: docker/login-action@v3", lock); err == nil {
+		t.Error("Expected error for action missing from the lock")
+	}
+}
+
+func TestGitHubActionResolver(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Accept") != "application/vnd.github.sha" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Unexpected headers: %v", r.Header)
+		}
+		switch r.URL.Path {
+		case "/repos/github/codeql-action/commits/v3":
+			w.Write([]byte(checkoutSHA))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &GitHubActionResolver{BaseURL: server.URL, Token: "token", Client: server.Client()}
+	lock := NewActionLock()
+	resolver := &RecordingResolver{Lock: lock, Fallback: api, Refresh: true}
+
+	// Subpath actions resolve against their repository; repeats hit the API once per run
+	ref := ActionReference{Action: "github/codeql-action/upload-sarif", Ref: "v3"}
+	for i := 0; i < 2; i++ {
+		sha, err := resolver.Resolve(ref)
+		if err != nil || sha != checkoutSHA {
+			t.Fatalf("Expected %s, got %s (%v)", checkoutSHA, sha, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 API request, got %d", requests)
+	}
+	if lock.Actions[ref.Key()].SHA != checkoutSHA {
+		t.Errorf("Expected resolution to be recorded in the lock")
+	}
+
+	if _, err := resolver.Resolve(ActionReference{Action: "nobody/missing", Ref: "v1"}); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+}
+
+func TestGenerateWorkflowWithPinnedActions(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+
+	lock := NewActionLock()
+	for _, ref := range UniqueActionReferences(ExtractActionReferences(content)) {
+		lock.Actions[ref.Key()] = LockedAction{SHA: authSHA}
+	}
+	lockPath := filepath.Join(t.TempDir(), "actions.lock.json")
+	if err := lock.Save(lockPath); err != nil {
+		t.Fatalf("Failed to save lock: %v", err)
+	}
+
+	cfg.Security.PinActions = true
+	cfg.Security.ActionLockFile = lockPath
+	pinned, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate pinned workflow: %v", err)
+	}
+	for _, ref := range ExtractActionReferences(pinned) {
+		if !ref.Pinned() {
+			t.Errorf("Line %d: %s is not pinned", ref.Line, ref.Key())
+		}
+	}
+	if err := cfg.ValidateWorkflowContent(pinned); err != nil {
+		t.Errorf("Pinned workflow failed validation: %v", err)
+	}
+
+	// A lock that lacks an action makes generation fail
+	cfg.Security.ActionLockFile = filepath.Join(t.TempDir(), "missing.lock.json")
+	if _, err := cfg.GenerateWorkflow(); err == nil {
+		t.Error("Expected error when the lock file does not cover the workflow's actions")
+	}
+}
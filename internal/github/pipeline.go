@@ -0,0 +1,178 @@
+package github
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// DefaultDeployJobID is the ID of the deploy job when no environment pipeline is configured
+const DefaultDeployJobID = "deploy"
+
+// BuildJobID is the ID of the job that builds the image once for every entry of a matrix
+// deploy job
+const BuildJobID = "build"
+
+// DeployJobID returns the ID of the job that deploys to an environment of the pipeline
+func DeployJobID(environment string) string {
+	return DefaultDeployJobID + "-" + environment
+}
+
+// DeployJobIDs returns the IDs of the deploy jobs, in pipeline order
+func (w *WorkflowConfig) DeployJobIDs() []string {
+	if len(w.Advanced.Pipeline) == 0 {
+		return []string{DefaultDeployJobID}
+	}
+	ids := make([]string, 0, len(w.Advanced.Pipeline))
+	for _, name := range w.Advanced.Pipeline {
+		ids = append(ids, DeployJobID(name))
+	}
+	return ids
+}
+
+// buildJobID returns the ID of the job that builds the container image, or "" when the
+// deploy jobs build it themselves. A matrix would otherwise rebuild it for every entry.
+func (w *WorkflowConfig) buildJobID() string {
+	target, err := w.GetDeploymentTarget()
+	if err != nil || !w.Advanced.MatrixStrategy.Enabled || !target.BuildsContainer() {
+		return ""
+	}
+	return BuildJobID
+}
+
+// buildJobEnv returns the job-level env of the build job: the identity of the pipeline's
+// first environment, which pushes the image every later environment deploys
+func (w *WorkflowConfig) buildJobEnv() map[string]string {
+	jobEnv := make(map[string]string)
+	if len(w.Advanced.Pipeline) == 0 || w.UseActionsVariables {
+		return jobEnv
+	}
+	serviceAccount, provider := w.GetEnvironmentIdentity(w.Advanced.Pipeline[0])
+	jobEnv["SERVICE_ACCOUNT"] = serviceAccount
+	jobEnv["WORKLOAD_IDENTITY_PROVIDER"] = provider
+	return jobEnv
+}
+
+// GetEnvironmentIdentity returns the service account and workload identity provider an
+// environment deploys with, falling back to the workflow's identity
+func (w *WorkflowConfig) GetEnvironmentIdentity(name string) (serviceAccount, provider string) {
+	serviceAccount, provider = w.ServiceAccountEmail, w.WorkloadIdentityProvider
+	if env, exists := w.GetEnvironment(name); exists {
+		if env.ServiceAccountEmail != "" {
+			serviceAccount = env.ServiceAccountEmail
+		}
+		if env.WorkloadIdentityProvider != "" {
+			provider = env.WorkloadIdentityProvider
+		}
+	}
+	return serviceAccount, provider
+}
+
+// validatePipeline checks the environment pipeline
+func (w *WorkflowConfig) validatePipeline() error {
+	if len(w.Advanced.Pipeline) == 0 {
+		return nil
+	}
+	if w.IsTerraform() {
+		return errors.NewValidationError("Workflow: Environment pipelines are not supported for terraform workflows", "workflow.advanced.pipeline", "INVALID")
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range w.Advanced.Pipeline {
+		if !workflowIdentifierRegex.MatchString(name) {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Invalid pipeline environment name '%s'", name), "workflow.advanced.pipeline", "INVALID")
+		}
+		if seen[name] {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Environment '%s' appears twice in the pipeline", name), "workflow.advanced.pipeline", "DUPLICATE")
+		}
+		seen[name] = true
+
+		env, exists := w.GetEnvironment(name)
+		if !exists {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Pipeline environment '%s' is not configured", name), "workflow.advanced.pipeline", "NOT_FOUND")
+		}
+		field := "workflow.advanced.environments." + name
+		if env.ServiceAccountEmail != "" && !strings.HasSuffix(env.ServiceAccountEmail, ".iam.gserviceaccount.com") {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Environment '%s' has an invalid service account email", name), field+".service_account_email", "INVALID")
+		}
+		if env.WorkloadIdentityProvider != "" && !strings.HasPrefix(env.WorkloadIdentityProvider, "projects/") {
+			return errors.NewValidationError(fmt.Sprintf("Workflow: Environment '%s' has an invalid workload identity provider", name), field+".workload_identity_provider", "INVALID")
+		}
+		for key, ref := range w.GetEffectiveSecrets(name) {
+			if !workflowIdentifierRegex.MatchString(key) || secretName(ref) == "" {
+				return errors.NewValidationError(fmt.Sprintf("Workflow: Secret '%s' of environment '%s' must reference a repository or environment secret", key, name),
+					field+".secrets", "INVALID")
+			}
+		}
+	}
+	return nil
+}
+
+// environmentJobEnv returns the job-level env of an environment's deploy job: its region,
+// identity, effective variables and effective secrets
+func (w *WorkflowConfig) environmentJobEnv(name string) map[string]string {
+	env, _ := w.GetEnvironment(name)
+	jobEnv := make(map[string]string)
+	for key, value := range w.GetEffectiveVariables(name) {
+		jobEnv[key] = strconv.Quote(value)
+	}
+	for key, ref := range w.GetEffectiveSecrets(name) {
+		jobEnv[key] = fmt.Sprintf("${{ secrets.%s }}", secretName(ref))
+	}
+	if env.Region != "" {
+		jobEnv["REGION"] = env.Region
+	}
+	if env.ServiceAccountEmail != "" {
+		jobEnv["SERVICE_ACCOUNT"] = env.ServiceAccountEmail
+	}
+	if env.WorkloadIdentityProvider != "" {
+		jobEnv["WORKLOAD_IDENTITY_PROVIDER"] = env.WorkloadIdentityProvider
+	}
+	// The job's environment variables take precedence over the repository's in vars
+	if w.UseActionsVariables && (env.ServiceAccountEmail != "" || env.WorkloadIdentityProvider != "") {
+		jobEnv["SERVICE_ACCOUNT"] = fmt.Sprintf("${{ vars.%s }}", VariableServiceAccount)
+		jobEnv["WORKLOAD_IDENTITY_PROVIDER"] = fmt.Sprintf("${{ vars.%s }}", VariableWorkloadIdentityProvider)
+	}
+	return jobEnv
+}
+
+// renderPipelineJobs renders the deploy-job block once per pipeline environment, each job
+// waiting for the previous environment's deployment
+func (w *WorkflowConfig) renderPipelineJobs(tmpl *template.Template, data map[string]interface{}) (string, error) {
+	jobs := make([]string, 0, len(w.Advanced.Pipeline))
+	needs := data["DeployNeeds"].(string)
+	for _, name := range w.Advanced.Pipeline {
+		env, _ := w.GetEnvironment(name)
+		jobData := make(map[string]interface{}, len(data))
+		for key, value := range data {
+			jobData[key] = value
+		}
+
+		jobEnv := w.environmentJobEnv(name)
+		for key, value := range data["JobEnv"].(map[string]string) {
+			jobEnv[key] = value // matrix fan-out wins over the environment's settings
+		}
+		jobData["JobEnv"] = jobEnv
+		jobData["DeployJobID"] = DeployJobID(name)
+		jobData["DeployNeeds"] = needs
+		jobData["DeployEnvironment"] = name
+		jobData["EnvironmentRef"] = name
+		jobData["EnvironmentURL"] = env.URL
+
+		var job strings.Builder
+		if err := tmpl.ExecuteTemplate(&job, "deploy-job", jobData); err != nil {
+			return "", errors.WrapError(err, errors.ErrorTypeValidation, "WORKFLOW_TEMPLATE_EXECUTE_FAILED",
+				fmt.Sprintf("Failed to render the deploy job for environment '%s'", name))
+		}
+		jobs = append(jobs, job.String())
+		if build := w.buildJobID(); build != "" {
+			needs = fmt.Sprintf("[security-checks, %s, %s]", build, DeployJobID(name))
+		} else {
+			needs = fmt.Sprintf("[security-checks, %s]", DeployJobID(name))
+		}
+	}
+	return strings.Join(jobs, "\n"), nil
+}
@@ -0,0 +1,100 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func testPipelineConfig() *WorkflowConfig {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.EnvVars = map[string]string{"LOG_LEVEL": "info"}
+	cfg.AddEnvironment("staging", Environment{
+		Region:    "europe-west1",
+		Variables: map[string]string{"LOG_LEVEL": "debug"},
+	})
+	cfg.AddEnvironment("production", Environment{
+		URL:                      "https://example.com",
+		ServiceAccountEmail:      "deployer-production@my-project.iam.gserviceaccount.com",
+		WorkloadIdentityProvider: "projects/123/locations/global/workloadIdentityPools/github/providers/github-production",
+		Secrets:                  map[string]string{"API_KEY": "${{ secrets.PRODUCTION_API_KEY }}"},
+	})
+	cfg.Advanced.Pipeline = []string{"staging", "production"}
+	return cfg
+}
+
+func TestPipelineJobs(t *testing.T) {
+	cfg := testPipelineConfig()
+
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	if workflow.Job(DefaultDeployJobID) != nil {
+		t.Error("Expected no single deploy job with a pipeline")
+	}
+	staging, production := workflow.Job("deploy-staging"), workflow.Job("deploy-production")
+	if staging == nil || production == nil {
+		t.Fatalf("Expected a deploy job per environment, got %+v", workflow.Jobs)
+	}
+	if strings.Join(staging.Needs, ",") != "security-checks" || strings.Join(production.Needs, ",") != "security-checks,deploy-staging" {
+		t.Errorf("Expected production to wait for staging, got %v and %v", staging.Needs, production.Needs)
+	}
+	if staging.Environment != "staging" || production.Environment != "production" {
+		t.Errorf("Expected each job to run in its GitHub environment, got %q and %q", staging.Environment, production.Environment)
+	}
+
+	// Staging keeps the workflow identity, production has its own
+	if staging.Env["REGION"] != "europe-west1" || staging.Env["LOG_LEVEL"] != "debug" || staging.Env["SERVICE_ACCOUNT"] != "" {
+		t.Errorf("Unexpected staging env: %v", staging.Env)
+	}
+	if production.Env["SERVICE_ACCOUNT"] != "deployer-production@my-project.iam.gserviceaccount.com" ||
+		!strings.HasSuffix(production.Env["WORKLOAD_IDENTITY_PROVIDER"], "/github-production") ||
+		production.Env["API_KEY"] != "${{ secrets.PRODUCTION_API_KEY }}" || production.Env["LOG_LEVEL"] != "info" {
+		t.Errorf("Unexpected production env: %v", production.Env)
+	}
+
+	if cleanup := workflow.Job("cleanup"); cleanup == nil || strings.Join(cleanup.Needs, ",") != "security-checks,deploy-staging,deploy-production" {
+		t.Errorf("Expected cleanup to wait for every deploy job, got %+v", cleanup)
+	}
+	if !strings.Contains(content, `echo "Environment: production"`) {
+		t.Error("Expected the production job to report its own environment")
+	}
+
+	if findings, _ := LintWorkflow("deploy.yml", content, LintExpectations{}); len(findings) > 0 {
+		t.Errorf("Expected no lint findings, got %+v", findings)
+	}
+}
+
+func TestPipelineValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(cfg *WorkflowConfig)
+		message string
+	}{
+		{"unknown environment", func(cfg *WorkflowConfig) { cfg.Advanced.Pipeline = append(cfg.Advanced.Pipeline, "qa") }, "is not configured"},
+		{"duplicate", func(cfg *WorkflowConfig) { cfg.Advanced.Pipeline = []string{"staging", "staging"} }, "appears twice"},
+		{"invalid account", func(cfg *WorkflowConfig) {
+			cfg.AddEnvironment("staging", Environment{ServiceAccountEmail: "deployer"})
+		}, "invalid service account email"},
+		{"literal secret", func(cfg *WorkflowConfig) {
+			cfg.AddEnvironmentSecret("production", "API_KEY", "hunter2!")
+		}, "must reference a repository or environment secret"},
+		{"terraform", func(cfg *WorkflowConfig) { cfg.Kind = WorkflowKindTerraform }, "not supported for terraform"},
+	}
+
+	for _, test := range tests {
+		cfg := testPipelineConfig()
+		test.setup(cfg)
+		if err := cfg.ValidateConfig(); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+}
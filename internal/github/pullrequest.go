@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// PullRequestFile is a file committed to the branch of a pull request
+type PullRequestFile struct {
+	Path    string // relative to the repository root
+	Content []byte
+}
+
+// PullRequest describes a pull request that proposes files on a new branch
+type PullRequest struct {
+	Owner         string
+	Repo          string
+	Branch        string // created for the pull request
+	Base          string // the repository's default branch when empty
+	Title         string
+	Body          string
+	CommitMessage string
+	Files         []PullRequestFile
+}
+
+// PullRequestInfo identifies an opened pull request
+type PullRequestInfo struct {
+	Number int    `json:"number"`
+	URL    string `json:"html_url"`
+	Branch string `json:"branch"`
+	Method string `json:"method"` // git for a local checkout, api for the contents API
+}
+
+// escapePath escapes each segment of a slash-separated path
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// DefaultBranch returns the default branch of a repository
+func (c *APIClient) DefaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	repository, err := c.GetRepository(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+	return repository.DefaultBranch, nil
+}
+
+// CommitFiles creates the pull request's branch from its base and commits the files to it
+// through the contents API, for repositories without a local checkout
+func (c *APIClient) CommitFiles(ctx context.Context, pr *PullRequest) error {
+	scope := VariableScope{Owner: pr.Owner, Repo: pr.Repo}
+	base := repositoryPath(pr.Owner, pr.Repo)
+
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if _, err := c.do(ctx, scope, http.MethodGet, base+"/git/ref/heads/"+escapePath(pr.Base), nil, &ref); err != nil {
+		return err
+	}
+	branch := map[string]string{"ref": "refs/heads/" + pr.Branch, "sha": ref.Object.SHA}
+	if status, err := c.do(ctx, scope, http.MethodPost, base+"/git/refs", branch, nil); err != nil {
+		if status == http.StatusUnprocessableEntity {
+			return errors.NewGitHubError(fmt.Sprintf("Branch '%s' already exists in %s/%s", pr.Branch, pr.Owner, pr.Repo),
+				"Delete the branch or choose another name")
+		}
+		return err
+	}
+
+	for _, file := range pr.Files {
+		contentsPath := base + "/contents/" + escapePath(path.Clean(file.Path))
+
+		// Updating a file requires the SHA of the version it replaces
+		var existing struct {
+			SHA string `json:"sha"`
+		}
+		status, err := c.do(ctx, scope, http.MethodGet, contentsPath+"?ref="+url.QueryEscape(pr.Branch), nil, &existing)
+		if err != nil && status != http.StatusNotFound {
+			return err
+		}
+		body := map[string]string{
+			"message": pr.CommitMessage,
+			"content": base64.StdEncoding.EncodeToString(file.Content),
+			"branch":  pr.Branch,
+		}
+		if existing.SHA != "" {
+			body["sha"] = existing.SHA
+		}
+		if _, err := c.do(ctx, scope, http.MethodPut, contentsPath, body, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreatePullRequest opens a pull request from the branch into its base
+func (c *APIClient) CreatePullRequest(ctx context.Context, pr *PullRequest) (*PullRequestInfo, error) {
+	scope := VariableScope{Owner: pr.Owner, Repo: pr.Repo}
+	body := map[string]string{"title": pr.Title, "body": pr.Body, "head": pr.Branch, "base": pr.Base}
+	info := &PullRequestInfo{Branch: pr.Branch}
+	if _, err := c.do(ctx, scope, http.MethodPost, repositoryPath(pr.Owner, pr.Repo)+"/pulls", body, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,204 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitFilesAndCreatePullRequest(t *testing.T) {
+	files := map[string]string{"/repos/acme/app/contents/wif-config.json": "old-sha"}
+	var written []string
+	var pull map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		switch {
+		case path == "/repos/acme/app":
+			_, _ = w.Write([]byte(`{"id": 123, "default_branch": "main", "owner": {"id": 456}}`))
+		case path == "/repos/acme/app/git/ref/heads/main":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"object": map[string]string{"sha": "base-sha"}})
+		case path == "/repos/acme/app/git/refs":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["ref"] != "refs/heads/gcp-wif/setup" || body["sha"] != "base-sha" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasPrefix(path, "/repos/acme/app/contents/") && r.Method == http.MethodGet:
+			if sha, ok := files[path]; ok {
+				_ = json.NewEncoder(w).Encode(map[string]string{"sha": sha})
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		case strings.HasPrefix(path, "/repos/acme/app/contents/"):
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			content, _ := base64.StdEncoding.DecodeString(body["content"])
+			if body["sha"] != files[path] || body["branch"] != "gcp-wif/setup" {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			written = append(written, strings.TrimPrefix(path, "/repos/acme/app/contents/")+"="+string(content))
+		case path == "/repos/acme/app/pulls":
+			_ = json.NewDecoder(r.Body).Decode(&pull)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number": 7, "html_url": "https://github.com/acme/app/pull/7"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewAPIClient(APIClientConfig{BaseURL: server.URL, Token: "test-token"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	base, err := client.DefaultBranch(ctx, "acme", "app")
+	if err != nil || base != "main" {
+		t.Fatalf("Expected the default branch main, got %q, %v", base, err)
+	}
+	repository, err := client.GetRepository(ctx, "acme", "app")
+	if err != nil || repository.ID != 123 || repository.OwnerID != 456 {
+		t.Fatalf("Expected repository 123 owned by 456, got %+v, %v", repository, err)
+	}
+	pr := &PullRequest{Owner: "acme", Repo: "app", Branch: "gcp-wif/setup", Base: base, Title: "Add WIF", Body: "Pool and provider",
+		CommitMessage: "Add WIF", Files: []PullRequestFile{
+			{Path: ".github/workflows/deploy.yml", Content: []byte("name: Deploy")},
+			{Path: "wif-config.json", Content: []byte("{}")},
+		}}
+	if err := client.CommitFiles(ctx, pr); err != nil {
+		t.Fatalf("Failed to commit files: %v", err)
+	}
+	if strings.Join(written, ",") != ".github/workflows/deploy.yml=name: Deploy,wif-config.json={}" {
+		t.Errorf("Expected both files to be committed, updating the existing one, got %v", written)
+	}
+
+	info, err := client.CreatePullRequest(ctx, pr)
+	if err != nil || info.Number != 7 || info.URL != "https://github.com/acme/app/pull/7" {
+		t.Fatalf("Expected pull request #7, got %+v, %v", info, err)
+	}
+	if pull["head"] != "gcp-wif/setup" || pull["base"] != "main" || pull["body"] != "Pool and provider" {
+		t.Errorf("Unexpected pull request request: %v", pull)
+	}
+}
+
+func TestLocalCheckoutCommitAndPush(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	for remote, want := range map[string]string{
+		"https://github.com/acme/app.git":       "acme/app",
+		"git@github.com:acme/app.git":           "acme/app",
+		"ssh://git@github.example.com/acme/app": "acme/app",
+		"not-a-remote":                          "",
+	} {
+		owner, repo, ok := ParseRemoteRepository(remote)
+		if got := owner + "/" + repo; (ok && got != want) || (!ok && want != "") {
+			t.Errorf("ParseRemoteRepository(%q) = %q, %v; want %q", remote, got, ok, want)
+		}
+	}
+
+	dir := t.TempDir()
+	origin, work := filepath.Join(dir, "origin.git"), filepath.Join(dir, "work")
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		output, err := runGit(dir, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return output
+	}
+	git(dir, "init", "--bare", "-q", origin)
+	git(dir, "init", "-q", work)
+	git(work, "config", "user.email", "test@example.com")
+	git(work, "config", "user.name", "Test")
+	git(work, "commit", "-q", "--allow-empty", "-m", "initial")
+	git(work, "remote", "add", "origin", origin)
+
+	if FindLocalCheckout(work, "acme", "app") != nil {
+		t.Error("Expected a checkout of another repository to be ignored")
+	}
+	git(work, "remote", "set-url", "origin", "https://github.com/acme/app.git")
+	checkout := FindLocalCheckout(work, "ACME", "app")
+	if checkout == nil {
+		t.Fatal("Expected the working tree to be found")
+	}
+
+	// Fetch from and push to the local bare repository instead of GitHub. The base on origin
+	// already has another version of the workflow, which is also written, untracked, in the
+	// working tree of a feature branch with its own commits and staged changes.
+	git(work, "remote", "set-url", "origin", origin)
+	git(work, "branch", "-M", "main")
+	workflow := filepath.Join(work, ".github", "workflows", "deploy.yml")
+	if err := os.MkdirAll(filepath.Dir(workflow), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(workflow, []byte("name: Old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(work, "add", ".github")
+	git(work, "commit", "-q", "-m", "old workflow")
+	git(work, "push", "-q", "origin", "main")
+	git(work, "checkout", "-q", "-b", "feature", "HEAD~1")
+	for name, content := range map[string]string{"local.txt": "local", "notes.txt": "unrelated"} {
+		if err := os.WriteFile(filepath.Join(work, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git(work, "add", "local.txt")
+	git(work, "commit", "-q", "-m", "local")
+	git(work, "add", "notes.txt")
+	if err := os.MkdirAll(filepath.Dir(workflow), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(workflow, []byte("name: Deploy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := checkout.RelativePath(workflow)
+	if err != nil || path != ".github/workflows/deploy.yml" {
+		t.Fatalf("RelativePath() = %q, %v", path, err)
+	}
+	if path, err := checkout.RelativePath(filepath.Join(work, "..github-ci", "deploy.yml")); err != nil || path != "..github-ci/deploy.yml" {
+		t.Errorf("Expected a directory starting with .. to be inside the working tree, got %q, %v", path, err)
+	}
+	if _, err := checkout.RelativePath(filepath.Join(dir, "elsewhere.yml")); err == nil {
+		t.Error("Expected a file outside the working tree to be rejected")
+	}
+
+	pr := &PullRequest{Branch: "gcp-wif/setup", Base: "main", CommitMessage: "Add WIF", Files: []PullRequestFile{
+		{Path: path, Content: []byte("name: Deploy\n")},
+	}}
+	if err := checkout.CommitAndPush(pr); err != nil {
+		t.Fatalf("Failed to commit and push: %v", err)
+	}
+	if files := git(origin, "ls-tree", "-r", "--name-only", "gcp-wif/setup"); files != ".github/workflows/deploy.yml" {
+		t.Errorf("Expected only the workflow on the pushed branch, got %q", files)
+	}
+	if content := git(origin, "show", "gcp-wif/setup:.github/workflows/deploy.yml"); content != "name: Deploy" {
+		t.Errorf("Expected the new workflow on the pushed branch, got %q", content)
+	}
+	if parent := git(origin, "rev-parse", "gcp-wif/setup~1"); parent != git(origin, "rev-parse", "main") {
+		t.Error("Expected the pushed branch to start from the base")
+	}
+
+	// The checkout is left on its branch with its changes, and the temporary worktree is gone
+	if branch := git(work, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature" {
+		t.Errorf("Expected the checkout to stay on feature, got %s", branch)
+	}
+	if status := git(work, "status", "--porcelain"); status != "A  notes.txt\n?? .github/" {
+		t.Errorf("Expected local changes to be kept, got %q", status)
+	}
+	if worktrees := git(work, "worktree", "list", "--porcelain"); strings.Count(worktrees, "worktree ") != 1 {
+		t.Errorf("Expected the temporary worktree to be removed, got:\n%s", worktrees)
+	}
+}
@@ -0,0 +1,143 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// SLSAContainerGenerator is the reusable workflow of the official SLSA generator that
+// attests the provenance of container images. The generator verifies that it is referenced
+// by a release tag, so it is never pinned to a commit SHA.
+const SLSAContainerGenerator = "slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@v2.0.0"
+
+// ProvenanceJobID is the ID of the job that generates SLSA provenance for the pushed image
+const ProvenanceJobID = "provenance"
+
+var (
+	attestorNameRegex  = regexp.MustCompile(`^(projects/[^/]+/attestors/)?[a-zA-Z0-9_-]+$`)
+	kmsKeyVersionRegex = regexp.MustCompile(`^projects/[^/]+/locations/[^/]+/keyRings/[^/]+/cryptoKeys/[^/]+/cryptoKeyVersions/[0-9]+$`)
+)
+
+// BinaryAuthorizationConfig defines the Binary Authorization attestation created for the
+// pushed image before it is deployed, so clusters and services enforcing a policy that
+// requires the attestor admit it
+type BinaryAuthorizationConfig struct {
+	Enabled bool `json:"enabled"`
+	// Attestor is the attestor name, or its full resource name when it lives in another project
+	Attestor string `json:"attestor,omitempty"`
+	// KeyVersion is the full resource name of the Cloud KMS key version that signs attestations
+	KeyVersion string `json:"key_version,omitempty"`
+}
+
+// HasSupplyChainSteps reports whether any supply-chain attestation of the pushed image
+// is enabled
+func (s SecurityConfig) HasSupplyChainSteps() bool {
+	return s.GenerateSBOM || s.SignImages || s.SLSAProvenance || s.BinaryAuthorization.Enabled
+}
+
+// SupplyChainRoles returns the roles the deploying service account needs for the enabled
+// attestations, in addition to the deployment target's roles
+func (s SecurityConfig) SupplyChainRoles() []string {
+	var roles []string
+	if s.SignImages || s.SLSAProvenance {
+		// Signatures, SBOM attestations and provenance are pushed next to the image
+		roles = append(roles, "roles/artifactregistry.writer")
+	}
+	if s.BinaryAuthorization.Enabled {
+		roles = append(roles,
+			"roles/binaryauthorization.attestorsViewer",
+			"roles/containeranalysis.notes.attacher",
+			"roles/containeranalysis.occurrences.editor",
+			"roles/cloudkms.signerVerifier",
+		)
+	}
+	return roles
+}
+
+// getAttestorName returns the full resource name of the Binary Authorization attestor
+func (w *WorkflowConfig) getAttestorName() string {
+	attestor := w.Security.BinaryAuthorization.Attestor
+	if strings.HasPrefix(attestor, "projects/") {
+		return attestor
+	}
+	return fmt.Sprintf("projects/%s/attestors/%s", w.ProjectID, attestor)
+}
+
+// supplyChainSettings returns the template data of the supply-chain steps, nil when none
+// are enabled
+func (w *WorkflowConfig) supplyChainSettings() map[string]interface{} {
+	security := w.Security
+	if !security.HasSupplyChainSteps() {
+		return nil
+	}
+	settings := map[string]interface{}{
+		"SBOM":       security.GenerateSBOM,
+		"Sign":       security.SignImages,
+		"Provenance": security.SLSAProvenance,
+		"Attestor":   "",
+		"KeyVersion": "",
+	}
+	if security.BinaryAuthorization.Enabled {
+		settings["Attestor"] = w.getAttestorName()
+		settings["KeyVersion"] = security.BinaryAuthorization.KeyVersion
+	}
+
+	// Every deploy job builds and signs its image, unless a matrix moves the build into a
+	// job of its own; provenance is generated for the image the first job of the pipeline
+	// (or the build job) pushed, with that job's identity. The generator's inputs
+	// cannot read the env context, so the identity is written out.
+	if security.SLSAProvenance {
+		serviceAccount, provider := w.ServiceAccountEmail, w.WorkloadIdentityProvider
+		if len(w.Advanced.Pipeline) > 0 {
+			serviceAccount, provider = w.GetEnvironmentIdentity(w.Advanced.Pipeline[0])
+		}
+		settings["Generator"] = SLSAContainerGenerator
+		settings["ProvenanceJobID"] = ProvenanceJobID
+		settings["ProvenanceNeeds"] = w.DeployJobIDs()[0]
+		if build := w.buildJobID(); build != "" {
+			settings["ProvenanceNeeds"] = build
+		}
+		settings["ProvenanceServiceAccount"] = serviceAccount
+		settings["ProvenanceProvider"] = provider
+	}
+	return settings
+}
+
+// validateSupplyChain checks the supply-chain attestation settings
+func (w *WorkflowConfig) validateSupplyChain() error {
+	security := w.Security
+	if !security.HasSupplyChainSteps() {
+		return nil
+	}
+	if w.IsTerraform() {
+		return errors.NewValidationError("Workflow: Supply-chain attestations are not supported for terraform workflows", "workflow.security", "INVALID")
+	}
+	target, err := w.GetDeploymentTarget()
+	if err != nil {
+		return err
+	}
+	if !target.BuildsContainer() {
+		return errors.NewValidationError(fmt.Sprintf("Workflow: Supply-chain attestations require a target that builds a container image, not %s", target.Name()),
+			"workflow.security", "INVALID")
+	}
+
+	binauthz := security.BinaryAuthorization
+	if !binauthz.Enabled {
+		return nil
+	}
+	if binauthz.Attestor == "" {
+		return errors.NewValidationError("Workflow: Binary Authorization attestor is required", "workflow.security.binary_authorization.attestor", "REQUIRED")
+	}
+	if !attestorNameRegex.MatchString(binauthz.Attestor) {
+		return errors.NewValidationError(fmt.Sprintf("Workflow: Invalid Binary Authorization attestor '%s'", binauthz.Attestor),
+			"workflow.security.binary_authorization.attestor", "INVALID")
+	}
+	if !kmsKeyVersionRegex.MatchString(binauthz.KeyVersion) {
+		return errors.NewValidationError(fmt.Sprintf("Workflow: Invalid Binary Authorization key version '%s' (expected projects/P/locations/L/keyRings/R/cryptoKeys/K/cryptoKeyVersions/N)", binauthz.KeyVersion),
+			"workflow.security.binary_authorization.key_version", "INVALID")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSupplyChainSteps(t *testing.T) {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.Security.GenerateSBOM = true
+	cfg.Security.SignImages = true
+	cfg.Security.SLSAProvenance = true
+	cfg.Security.BinaryAuthorization = BinaryAuthorizationConfig{
+		Enabled:    true,
+		Attestor:   "built-by-ci",
+		KeyVersion: "projects/my-project/locations/global/keyRings/binauthz/cryptoKeys/attestor/cryptoKeyVersions/1",
+	}
+
+	if err := cfg.ValidateConfig(); err != nil {
+		t.Fatalf("Expected supply-chain config to be valid: %v", err)
+	}
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+	if findings, _ := LintWorkflow("deploy.yml", content, LintExpectations{}); len(findings) > 0 {
+		t.Errorf("Expected no lint findings, got %+v", findings)
+	}
+
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	var names []string
+	for _, step := range workflow.Job("deploy").Steps {
+		names = append(names, step.Name)
+	}
+	// Everything is attested before the deployment, which Binary Authorization enforces
+	expected := "Build and push container image\nGenerate SBOM\nInstall cosign\nSign container image\nCreate Binary Authorization attestation\nDeploy to Cloud Run"
+	if order := strings.Join(names, "\n"); !strings.Contains(order, expected) {
+		t.Errorf("Expected steps in order %q, got:\n%s", expected, order)
+	}
+
+	provenance := workflow.Job(ProvenanceJobID)
+	if provenance == nil || provenance.Uses != SLSAContainerGenerator || strings.Join(provenance.Needs, ",") != "deploy" {
+		t.Fatalf("Expected a provenance job calling the SLSA generator after deploy, got %+v", provenance)
+	}
+	for _, expected := range []string{
+		`cosign attest --yes --type spdxjson --predicate sbom.spdx.json "$IMAGE"`,
+		`--attestor="projects/my-project/attestors/built-by-ci"`,
+		"      gcp-service-account: " + cfg.ServiceAccountEmail + "\n",
+		"      image: ${{ env.REGISTRY }}/${{ env.IMAGE_NAME }}\n",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected workflow to contain %q", expected)
+		}
+	}
+
+	// The SLSA generator must be called by its tag
+	if refs := ExtractActionReferences(content); len(refs) == 0 {
+		t.Fatal("Expected action references")
+	} else {
+		for _, ref := range refs {
+			if strings.HasPrefix(ref.Action, "slsa-framework/") {
+				t.Errorf("Expected the SLSA generator not to be pinnable, got %+v", ref)
+			}
+		}
+	}
+
+	imported, err := ImportWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to import workflow: %v", err)
+	}
+	if imported.Config.Security.BinaryAuthorization != cfg.Security.BinaryAuthorization || !imported.Config.Security.SLSAProvenance ||
+		!imported.Config.Security.SignImages || !imported.Config.Security.GenerateSBOM || len(imported.Unrecognized) > 0 {
+		t.Errorf("Expected the supply-chain settings to be imported, got %+v and %+v", imported.Config.Security, imported.Unrecognized)
+	}
+
+	roles := strings.Join(cfg.Security.SupplyChainRoles(), ",")
+	for _, role := range []string{"roles/artifactregistry.writer", "roles/containeranalysis.occurrences.editor", "roles/cloudkms.signerVerifier"} {
+		if !strings.Contains(roles, role) {
+			t.Errorf("Expected supply-chain roles to include %s, got %s", role, roles)
+		}
+	}
+}
+
+func TestSupplyChainValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(cfg *WorkflowConfig)
+		message string
+	}{
+		{"no container", func(cfg *WorkflowConfig) {
+			cfg.Target = TargetAppEngine
+			cfg.Security.SignImages = true
+		}, "builds a container image"},
+		{"attestor", func(cfg *WorkflowConfig) {
+			cfg.Security.BinaryAuthorization = BinaryAuthorizationConfig{Enabled: true}
+		}, "attestor is required"},
+		{"key version", func(cfg *WorkflowConfig) {
+			cfg.Security.BinaryAuthorization = BinaryAuthorizationConfig{Enabled: true, Attestor: "ci", KeyVersion: "my-key"}
+		}, "Invalid Binary Authorization key version"},
+	}
+
+	for _, test := range tests {
+		cfg := testWorkflowConfig(DefaultWorkflowConfig())
+		test.setup(cfg)
+		if err := cfg.ValidateConfig(); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+}
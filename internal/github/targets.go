@@ -0,0 +1,487 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// Deployment target names
+const (
+	TargetCloudRun        = "cloud-run"
+	TargetGKE             = "gke"
+	TargetCloudFunctions  = "cloud-functions"
+	TargetAppEngine       = "app-engine"
+	TargetFirebaseHosting = "firebase-hosting"
+)
+
+// DeploymentTarget is a platform the generated workflow deploys to. Each target contributes
+// its validation, the IAM roles the deploying service account needs and the workflow steps
+// that perform the deployment.
+type DeploymentTarget interface {
+	// Name is the identifier used in configuration and on the command line
+	Name() string
+	// DisplayName is the human-readable platform name
+	DisplayName() string
+	// Description summarizes how the target deploys
+	Description() string
+	// BuildsContainer reports whether a container image is built and pushed before deploying
+	BuildsContainer() bool
+	// Roles returns the IAM roles the deploying service account needs
+	Roles() []string
+	// Validate checks the target-specific workflow settings
+	Validate(w *WorkflowConfig) error
+	// DeploySteps returns the template of the deploy steps. The last step must have the id
+	// "deploy" and set a "url" output, which may be empty when the target has no URL.
+	DeploySteps() string
+	// FailureLogFilter returns the Cloud Logging filter used to show logs when the
+	// deployment fails, or an empty string when there is none
+	FailureLogFilter(w *WorkflowConfig) string
+}
+
+// GKEConfig defines settings for deploying to Google Kubernetes Engine
+type GKEConfig struct {
+	Cluster    string `json:"cluster,omitempty"`
+	Location   string `json:"location,omitempty"`   // Defaults to the workflow region
+	Namespace  string `json:"namespace,omitempty"`  // Defaults to "default"
+	Deployer   string `json:"deployer,omitempty"`   // kubectl (default) or helm
+	Manifests  string `json:"manifests,omitempty"`  // kubectl: file or directory applied before the rollout
+	Deployment string `json:"deployment,omitempty"` // kubectl: defaults to the service name
+	Container  string `json:"container,omitempty"`  // kubectl: defaults to the service name
+	Chart      string `json:"chart,omitempty"`      // helm: chart path or reference
+	Release    string `json:"release,omitempty"`    // helm: defaults to the service name
+	ServiceURL string `json:"service_url,omitempty"`
+}
+
+// CloudFunctionsConfig defines settings for deploying to Cloud Functions (2nd gen)
+type CloudFunctionsConfig struct {
+	Runtime               string `json:"runtime,omitempty"` // e.g. nodejs20, python312, go122
+	EntryPoint            string `json:"entry_point,omitempty"`
+	SourceDir             string `json:"source_dir,omitempty"`    // Defaults to "."
+	TriggerTopic          string `json:"trigger_topic,omitempty"` // Pub/Sub topic; HTTP trigger when empty
+	RuntimeServiceAccount string `json:"runtime_service_account,omitempty"`
+}
+
+// AppEngineConfig defines settings for deploying to App Engine
+type AppEngineConfig struct {
+	Deliverables     string `json:"deliverables,omitempty"`      // Defaults to "app.yaml"
+	WorkingDirectory string `json:"working_directory,omitempty"` // Defaults to "."
+	Version          string `json:"version,omitempty"`
+	NoPromote        bool   `json:"no_promote,omitempty"`
+}
+
+// FirebaseConfig defines settings for deploying to Firebase Hosting
+type FirebaseConfig struct {
+	Site         string `json:"site,omitempty"`    // Defaults to the project ID
+	Channel      string `json:"channel,omitempty"` // Preview channel; deploys live when empty
+	BuildCommand string `json:"build_command,omitempty"`
+	NodeVersion  string `json:"node_version,omitempty"` // Defaults to "20"
+}
+
+var deploymentTargets = map[string]DeploymentTarget{
+	TargetCloudRun:        cloudRunTarget{},
+	TargetGKE:             gkeTarget{},
+	TargetCloudFunctions:  cloudFunctionsTarget{},
+	TargetAppEngine:       appEngineTarget{},
+	TargetFirebaseHosting: firebaseHostingTarget{},
+}
+
+// GetDeploymentTarget returns the deployment target with the given name
+func GetDeploymentTarget(name string) (DeploymentTarget, error) {
+	target, ok := deploymentTargets[name]
+	if !ok {
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Unknown deployment target: %s", name),
+			fmt.Sprintf("Supported targets: %s", strings.Join(DeploymentTargetNames(), ", ")))
+	}
+	return target, nil
+}
+
+// DeploymentTargetNames returns the names of the supported deployment targets, sorted
+func DeploymentTargetNames() []string {
+	names := make([]string, 0, len(deploymentTargets))
+	for name := range deploymentTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetDeploymentTarget returns the configured deployment target, Cloud Run by default
+func (w *WorkflowConfig) GetDeploymentTarget() (DeploymentTarget, error) {
+	if w.Target == "" {
+		return deploymentTargets[TargetCloudRun], nil
+	}
+	return GetDeploymentTarget(w.Target)
+}
+
+// gkeSettings returns the GKE settings with defaults applied
+func (w *WorkflowConfig) gkeSettings() GKEConfig {
+	settings := w.GKE
+	if settings.Location == "" {
+		settings.Location = w.Region
+	}
+	if settings.Namespace == "" {
+		settings.Namespace = "default"
+	}
+	if settings.Deployer == "" {
+		settings.Deployer = "kubectl"
+	}
+	if settings.Deployment == "" {
+		settings.Deployment = w.ServiceName
+	}
+	if settings.Container == "" {
+		settings.Container = w.ServiceName
+	}
+	if settings.Release == "" {
+		settings.Release = w.ServiceName
+	}
+	return settings
+}
+
+// cloudFunctionsSettings returns the Cloud Functions settings with defaults applied
+func (w *WorkflowConfig) cloudFunctionsSettings() CloudFunctionsConfig {
+	settings := w.CloudFunctions
+	if settings.SourceDir == "" {
+		settings.SourceDir = "."
+	}
+	return settings
+}
+
+// appEngineSettings returns the App Engine settings with defaults applied
+func (w *WorkflowConfig) appEngineSettings() AppEngineConfig {
+	settings := w.AppEngine
+	if settings.Deliverables == "" {
+		settings.Deliverables = "app.yaml"
+	}
+	if settings.WorkingDirectory == "" {
+		settings.WorkingDirectory = "."
+	}
+	return settings
+}
+
+// firebaseSettings returns the Firebase Hosting settings with defaults applied
+func (w *WorkflowConfig) firebaseSettings() FirebaseConfig {
+	settings := w.Firebase
+	if settings.Site == "" {
+		settings.Site = w.ProjectID
+	}
+	if settings.NodeVersion == "" {
+		settings.NodeVersion = "20"
+	}
+	return settings
+}
+
+// cloudRunTarget deploys the built image to a Cloud Run service
+type cloudRunTarget struct{}
+
+func (cloudRunTarget) Name() string          { return TargetCloudRun }
+func (cloudRunTarget) DisplayName() string   { return "Cloud Run" }
+func (cloudRunTarget) BuildsContainer() bool { return true }
+func (cloudRunTarget) Description() string {
+	return "Build a container image and deploy it to a Cloud Run service"
+}
+
+func (cloudRunTarget) Roles() []string {
+	return []string{
+		"roles/run.admin",
+		"roles/storage.admin",
+		"roles/artifactregistry.admin",
+	}
+}
+
+func (cloudRunTarget) Validate(w *WorkflowConfig) error {
+	return nil
+}
+
+func (cloudRunTarget) FailureLogFilter(w *WorkflowConfig) string {
+	return "resource.type=cloud_run_revision AND resource.labels.service_name=$SERVICE_NAME"
+}
+
+func (cloudRunTarget) DeploySteps() string {
+	return `{{ if .Canary }}    # Record the revision serving traffic so a failed rollout can be routed back to it
+    - name: Record serving revision
+      id: previous
+      run: |
+        REVISION=$(gcloud run services describe $SERVICE_NAME --region=$REGION --format=json 2>/dev/null \
+          | jq -r '[.status.traffic[]? | select(.percent > 0)] | max_by(.percent) | .revisionName // empty' || true)
+        echo "revision=$REVISION" >> $GITHUB_OUTPUT
+        echo "Serving revision: ${REVISION:-none (first deployment)}"
+
+{{ end }}    # Deploy to Cloud Run with comprehensive configuration
+    - name: Deploy to Cloud Run
+      id: deploy
+      uses: google-github-actions/deploy-cloudrun@v2
+      with:
+        service: ${{ "{{" }} env.SERVICE_NAME {{ "}}" }}
+        region: ${{ "{{" }} env.REGION {{ "}}" }}
+        image: ${{ "{{" }} env.REGISTRY {{ "}}" }}/${{ "{{" }} env.IMAGE_NAME {{ "}}" }}:${{ "{{" }} env.IMAGE_TAG {{ "}}" }}{{ if .Canary }}
+        tag: {{ .Canary.Tag }}
+        no_traffic: ${{ "{{" }} steps.previous.outputs.revision != '' {{ "}}" }}{{ end }}{{ if .Port }}
+        port: ${{ "{{" }} env.PORT {{ "}}" }}{{ end }}{{ if .EnvVars }}
+        env_vars: |{{ range $key, $value := .EnvVars }}
+          {{ $key }}={{ $value }}{{ end }}{{ end }}{{ if .Secrets }}
+        secrets: |{{ range $key, $value := .Secrets }}
+          {{ $key }}=${{ "{{" }} secrets.{{ $value }} {{ "}}" }}{{ end }}{{ end }}{{ if .CPULimit }}
+        cpu: {{ .CPULimit }}{{ end }}{{ if .MemoryLimit }}
+        memory: {{ .MemoryLimit }}{{ end }}{{ if .MaxInstances }}
+        max_instances: {{ .MaxInstances }}{{ end }}{{ if .MinInstances }}
+        min_instances: {{ .MinInstances }}{{ end }}
+        flags: |
+          --max-instances={{ .MaxInstances | default 100 }}
+          --min-instances={{ .MinInstances | default 0 }}
+          --concurrency=1000
+          --timeout=300
+          --allow-unauthenticated{{ if .Security.RequireApproval }}
+          --ingress=internal{{ else }}
+          --ingress=all{{ end }}{{ if .Canary }}
+
+    # Shift traffic to the new revision in steps, checking its health before each step
+    - name: Shift traffic to canary revision
+      id: canary
+      if: steps.previous.outputs.revision != ''
+      env:
+        CANARY_TAG: {{ .Canary.Tag }}
+        CANARY_STEPS: {{ .Canary.Steps }}
+        CANARY_INTERVAL: {{ .Canary.Interval }}
+      run: |
+        gcloud run services describe $SERVICE_NAME --region=$REGION --format=json > service.json
+        CANARY_REVISION=$(jq -r --arg tag "$CANARY_TAG" '.status.traffic[] | select(.tag == $tag) | .revisionName' service.json)
+        CANARY_URL=$(jq -r --arg tag "$CANARY_TAG" '.status.traffic[] | select(.tag == $tag) | .url' service.json)
+        STABLE_URL=$(jq -r '.status.url' service.json)
+        echo "revision=$CANARY_REVISION" >> $GITHUB_OUTPUT
+        
+        echo "Checking canary revision $CANARY_REVISION at $CANARY_URL..."
+        SERVICE_URL="$CANARY_URL"
+        {{ .Canary.HealthCheckCommands }}
+        
+        for PERCENT in $CANARY_STEPS; do
+          echo "Routing $PERCENT% of traffic to $CANARY_REVISION..."
+          gcloud run services update-traffic $SERVICE_NAME \
+            --region=$REGION \
+            --to-revisions=$CANARY_REVISION=$PERCENT
+          sleep $CANARY_INTERVAL
+          
+          SERVICE_URL="$STABLE_URL"
+          {{ .Canary.StepHealthCheckCommands }}
+        done
+        echo "Canary revision $CANARY_REVISION is serving all traffic"{{ end }}`
+}
+
+// gkeTarget deploys the built image to a GKE cluster with kubectl or Helm
+type gkeTarget struct{}
+
+func (gkeTarget) Name() string          { return TargetGKE }
+func (gkeTarget) DisplayName() string   { return "GKE" }
+func (gkeTarget) BuildsContainer() bool { return true }
+func (gkeTarget) Description() string {
+	return "Build a container image and roll it out to a GKE cluster with kubectl or Helm"
+}
+
+func (gkeTarget) Roles() []string {
+	return []string{
+		"roles/container.developer",
+		"roles/artifactregistry.writer",
+	}
+}
+
+func (gkeTarget) Validate(w *WorkflowConfig) error {
+	settings := w.gkeSettings()
+	if settings.Cluster == "" {
+		return errors.NewValidationError("Workflow: GKE cluster is required for the gke target", "workflow.gke.cluster", "REQUIRED")
+	}
+	switch settings.Deployer {
+	case "kubectl":
+	case "helm":
+		if settings.Chart == "" {
+			return errors.NewValidationError("Workflow: Helm chart is required when deploying to GKE with helm", "workflow.gke.chart", "REQUIRED")
+		}
+	default:
+		return errors.NewValidationError(
+			fmt.Sprintf("Workflow: Unknown GKE deployer '%s' (use kubectl or helm)", settings.Deployer), "workflow.gke.deployer", "INVALID")
+	}
+	return nil
+}
+
+func (gkeTarget) FailureLogFilter(w *WorkflowConfig) string {
+	settings := w.gkeSettings()
+	return fmt.Sprintf("resource.type=k8s_container AND resource.labels.cluster_name=%s AND resource.labels.namespace_name=%s",
+		settings.Cluster, settings.Namespace)
+}
+
+func (gkeTarget) DeploySteps() string {
+	return `    # Deploy to GKE
+    - name: Get GKE credentials
+      uses: google-github-actions/get-gke-credentials@v2
+      with:
+        cluster_name: {{ .GKE.Cluster }}
+        location: {{ .GKE.Location }}
+        project_id: ${{ "{{" }} env.PROJECT_ID {{ "}}" }}
+{{ if eq .GKE.Deployer "helm" }}
+    - name: Deploy to GKE with Helm
+      id: deploy
+      run: |
+        helm upgrade --install {{ .GKE.Release }} {{ .GKE.Chart }} \
+          --namespace {{ .GKE.Namespace }} --create-namespace \
+          --set image.repository=$REGISTRY/$IMAGE_NAME \
+          --set image.tag=$IMAGE_TAG \
+          --wait --timeout 10m
+        echo "url={{ .GKE.ServiceURL }}" >> $GITHUB_OUTPUT{{ else }}
+    - name: Deploy to GKE with kubectl
+      id: deploy
+      run: |{{ if .GKE.Manifests }}
+        kubectl apply --namespace {{ .GKE.Namespace }} -f {{ .GKE.Manifests }}{{ end }}
+        kubectl set image deployment/{{ .GKE.Deployment }} {{ .GKE.Container }}=$REGISTRY/$IMAGE_NAME:$IMAGE_TAG --namespace {{ .GKE.Namespace }}
+        kubectl rollout status deployment/{{ .GKE.Deployment }} --namespace {{ .GKE.Namespace }} --timeout=10m
+        echo "url={{ .GKE.ServiceURL }}" >> $GITHUB_OUTPUT{{ end }}`
+}
+
+// cloudFunctionsTarget deploys source code to a 2nd gen Cloud Function
+type cloudFunctionsTarget struct{}
+
+func (cloudFunctionsTarget) Name() string          { return TargetCloudFunctions }
+func (cloudFunctionsTarget) DisplayName() string   { return "Cloud Functions" }
+func (cloudFunctionsTarget) BuildsContainer() bool { return false }
+func (cloudFunctionsTarget) Description() string {
+	return "Deploy source code to a 2nd gen Cloud Function with an HTTP or Pub/Sub trigger"
+}
+
+func (cloudFunctionsTarget) Roles() []string {
+	return []string{
+		"roles/cloudfunctions.developer",
+		"roles/iam.serviceAccountUser",
+	}
+}
+
+func (cloudFunctionsTarget) Validate(w *WorkflowConfig) error {
+	if w.CloudFunctions.Runtime == "" {
+		return errors.NewValidationError("Workflow: Runtime is required for the cloud-functions target", "workflow.cloud_functions.runtime", "REQUIRED")
+	}
+	if w.CloudFunctions.EntryPoint == "" {
+		return errors.NewValidationError("Workflow: Entry point is required for the cloud-functions target", "workflow.cloud_functions.entry_point", "REQUIRED")
+	}
+	return nil
+}
+
+func (cloudFunctionsTarget) FailureLogFilter(w *WorkflowConfig) string {
+	// 2nd gen functions run as Cloud Run services named after the function
+	return "resource.type=cloud_run_revision AND resource.labels.service_name=$SERVICE_NAME"
+}
+
+func (cloudFunctionsTarget) DeploySteps() string {
+	return `    # Deploy to Cloud Functions (2nd gen)
+    - name: Deploy to Cloud Functions
+      id: deploy
+      uses: google-github-actions/deploy-cloud-functions@v3
+      with:
+        name: ${{ "{{" }} env.SERVICE_NAME {{ "}}" }}
+        runtime: {{ .CloudFunctions.Runtime }}
+        entry_point: {{ .CloudFunctions.EntryPoint }}
+        source_dir: {{ .CloudFunctions.SourceDir }}
+        region: ${{ "{{" }} env.REGION {{ "}}" }}
+        project_id: ${{ "{{" }} env.PROJECT_ID {{ "}}" }}{{ if .CloudFunctions.RuntimeServiceAccount }}
+        service_account: {{ .CloudFunctions.RuntimeServiceAccount }}{{ end }}{{ if .MemoryLimit }}
+        memory: {{ .MemoryLimit }}{{ end }}{{ if .MaxInstances }}
+        max_instance_count: {{ .MaxInstances }}{{ end }}{{ if .MinInstances }}
+        min_instance_count: {{ .MinInstances }}{{ end }}{{ if .EnvVars }}
+        environment_variables: |{{ range $key, $value := .EnvVars }}
+          {{ $key }}={{ $value }}{{ end }}{{ end }}{{ if .CloudFunctions.TriggerTopic }}
+        event_trigger_type: google.cloud.pubsub.topic.v1.messagePublished
+        event_trigger_pubsub_topic: projects/${{ "{{" }} env.PROJECT_ID {{ "}}" }}/topics/{{ .CloudFunctions.TriggerTopic }}{{ end }}`
+}
+
+// appEngineTarget deploys the application described by app.yaml to App Engine
+type appEngineTarget struct{}
+
+func (appEngineTarget) Name() string          { return TargetAppEngine }
+func (appEngineTarget) DisplayName() string   { return "App Engine" }
+func (appEngineTarget) BuildsContainer() bool { return false }
+func (appEngineTarget) Description() string {
+	return "Deploy the application described by app.yaml to App Engine"
+}
+
+func (appEngineTarget) Roles() []string {
+	return []string{
+		"roles/appengine.appAdmin",
+		"roles/storage.admin",
+		"roles/cloudbuild.builds.editor",
+		"roles/iam.serviceAccountUser",
+	}
+}
+
+func (appEngineTarget) Validate(w *WorkflowConfig) error {
+	return nil
+}
+
+func (appEngineTarget) FailureLogFilter(w *WorkflowConfig) string {
+	return "resource.type=gae_app"
+}
+
+func (appEngineTarget) DeploySteps() string {
+	return `    # Deploy to App Engine
+    - name: Deploy to App Engine
+      id: appengine
+      uses: google-github-actions/deploy-appengine@v2
+      with:
+        project_id: ${{ "{{" }} env.PROJECT_ID {{ "}}" }}
+        working_directory: {{ .AppEngine.WorkingDirectory }}
+        deliverables: {{ .AppEngine.Deliverables }}{{ if .AppEngine.Version }}
+        version: {{ .AppEngine.Version }}{{ end }}
+        promote: {{ not .AppEngine.NoPromote }}{{ if .EnvVars }}
+        env_vars: |{{ range $key, $value := .EnvVars }}
+          {{ $key }}={{ $value }}{{ end }}{{ end }}
+
+    - name: Record App Engine URL
+      id: deploy
+      run: echo "url=${{ "{{" }} steps.appengine.outputs.version_url {{ "}}" }}" >> $GITHUB_OUTPUT`
+}
+
+// firebaseHostingTarget deploys static content to Firebase Hosting with the Firebase CLI,
+// authenticating through the credentials file written by the auth step
+type firebaseHostingTarget struct{}
+
+func (firebaseHostingTarget) Name() string          { return TargetFirebaseHosting }
+func (firebaseHostingTarget) DisplayName() string   { return "Firebase Hosting" }
+func (firebaseHostingTarget) BuildsContainer() bool { return false }
+func (firebaseHostingTarget) Description() string {
+	return "Build and deploy a static site to Firebase Hosting, live or to a preview channel"
+}
+
+func (firebaseHostingTarget) Roles() []string {
+	return []string{
+		"roles/firebasehosting.admin",
+		"roles/serviceusage.apiKeysViewer",
+		"roles/run.viewer",
+	}
+}
+
+func (firebaseHostingTarget) Validate(w *WorkflowConfig) error {
+	return nil
+}
+
+func (firebaseHostingTarget) FailureLogFilter(w *WorkflowConfig) string {
+	return ""
+}
+
+func (firebaseHostingTarget) DeploySteps() string {
+	return `    # Deploy to Firebase Hosting
+    - name: Set up Node.js
+      uses: actions/setup-node@v4
+      with:
+        node-version: {{ quote .Firebase.NodeVersion }}
+{{ if .Firebase.BuildCommand }}
+    - name: Build site
+      run: |
+        npm ci
+        {{ .Firebase.BuildCommand }}
+{{ end }}
+    - name: Deploy to Firebase Hosting
+      id: deploy
+      run: |{{ if .Firebase.Channel }}
+        npx --yes firebase-tools@13 hosting:channel:deploy {{ .Firebase.Channel }} --project $PROJECT_ID --json > firebase-deploy.json
+        echo "url=$(jq -r '.result[].url' firebase-deploy.json | head -n 1)" >> $GITHUB_OUTPUT{{ else }}
+        npx --yes firebase-tools@13 deploy --only hosting --project $PROJECT_ID --non-interactive
+        echo "url=https://{{ .Firebase.Site }}.web.app" >> $GITHUB_OUTPUT{{ end }}`
+}
@@ -0,0 +1,128 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeploymentTargetsGenerateValidWorkflows(t *testing.T) {
+	tests := []struct {
+		target   string
+		setup    func(cfg *WorkflowConfig)
+		expected []string
+		absent   []string
+	}{
+		{
+			target:   TargetCloudRun,
+			expected: []string{"deploy-cloudrun@v2", "docker/build-push-action@v5", "resource.type=cloud_run_revision"},
+		},
+		{
+			target: TargetGKE,
+			setup: func(cfg *WorkflowConfig) {
+				cfg.GKE = GKEConfig{Cluster: "prod", Manifests: "k8s/"}
+			},
+			expected: []string{
+				"get-gke-credentials@v2",
+				"cluster_name: prod",
+				"location: us-central1",
+				"kubectl apply --namespace default -f k8s/",
+				"kubectl set image deployment/my-service my-service=$REGISTRY/$IMAGE_NAME:$IMAGE_TAG",
+				"if: steps.deploy.outputs.url != ''",
+				"docker/build-push-action@v5",
+			},
+			absent: []string{"deploy-cloudrun", "gcloud run services list"},
+		},
+		{
+			target: TargetGKE,
+			setup: func(cfg *WorkflowConfig) {
+				cfg.GKE = GKEConfig{Cluster: "prod", Deployer: "helm", Chart: "./chart", Namespace: "apps"}
+			},
+			expected: []string{"helm upgrade --install my-service ./chart", "--namespace apps --create-namespace"},
+			absent:   []string{"kubectl set image"},
+		},
+		{
+			target: TargetCloudFunctions,
+			setup: func(cfg *WorkflowConfig) {
+				cfg.CloudFunctions = CloudFunctionsConfig{Runtime: "go122", EntryPoint: "Handle", TriggerTopic: "events"}
+			},
+			expected: []string{
+				"deploy-cloud-functions@v3",
+				"runtime: go122",
+				"entry_point: Handle",
+				"event_trigger_pubsub_topic: projects/${{ env.PROJECT_ID }}/topics/events",
+			},
+			absent: []string{"docker/build-push-action", "gcloud artifacts repositories list"},
+		},
+		{
+			target:   TargetAppEngine,
+			expected: []string{"deploy-appengine@v2", "deliverables: app.yaml", "promote: true", "steps.appengine.outputs.version_url", "resource.type=gae_app"},
+			absent:   []string{"docker/build-push-action"},
+		},
+		{
+			target: TargetFirebaseHosting,
+			setup: func(cfg *WorkflowConfig) {
+				cfg.Firebase = FirebaseConfig{BuildCommand: "npm run build"}
+			},
+			expected: []string{"actions/setup-node@v4", "npm run build", "firebase-tools@13 deploy --only hosting", "url=https://my-project.web.app"},
+			absent:   []string{"docker/build-push-action", "gcloud logging read"},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := testWorkflowConfig(DefaultWorkflowConfig())
+		cfg.Target = test.target
+		if test.setup != nil {
+			test.setup(cfg)
+		}
+
+		content, err := cfg.GenerateWorkflow()
+		if err != nil {
+			t.Fatalf("%s: failed to generate workflow: %v", test.target, err)
+		}
+		if err := cfg.ValidateWorkflowContent(content); err != nil {
+			t.Errorf("%s: generated workflow failed validation: %v", test.target, err)
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(content, expected) {
+				t.Errorf("%s: expected workflow to contain %q", test.target, expected)
+			}
+		}
+		for _, absent := range test.absent {
+			if strings.Contains(content, absent) {
+				t.Errorf("%s: expected workflow not to contain %q", test.target, absent)
+			}
+		}
+	}
+}
+
+func TestDeploymentTargetValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(cfg *WorkflowConfig)
+		message string
+	}{
+		{"unknown target", func(cfg *WorkflowConfig) { cfg.Target = "lambda" }, "Unknown deployment target"},
+		{"gke without cluster", func(cfg *WorkflowConfig) { cfg.Target = TargetGKE }, "GKE cluster is required"},
+		{"helm without chart", func(cfg *WorkflowConfig) {
+			cfg.Target = TargetGKE
+			cfg.GKE = GKEConfig{Cluster: "prod", Deployer: "helm"}
+		}, "Helm chart is required"},
+		{"functions without runtime", func(cfg *WorkflowConfig) { cfg.Target = TargetCloudFunctions }, "Runtime is required"},
+	}
+
+	for _, test := range tests {
+		cfg := testWorkflowConfig(DefaultWorkflowConfig())
+		test.setup(cfg)
+		if err := cfg.ValidateConfig(); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+
+	// Targets that deploy source do not need a Dockerfile
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.Target = TargetAppEngine
+	cfg.DockerfilePath = ""
+	if err := cfg.ValidateConfig(); err != nil {
+		t.Errorf("Expected app-engine config without Dockerfile to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+)
+
+const (
+	// DefaultTemplateName is the built-in template used when no template is configured
+	DefaultTemplateName = "standard"
+
+	// DefaultTemplateDir is the repository-local template directory
+	DefaultTemplateDir = ".gcp-wif/templates"
+
+	// TemplateSourceBuiltin is the source reported for templates compiled into the binary
+	TemplateSourceBuiltin = "built-in"
+
+	// templateFileSuffix marks workflow template files inside template directories
+	templateFileSuffix = ".tmpl"
+)
+
+// TemplateBlocks lists the blocks of the built-in template that templates extending it can override
+var TemplateBlocks = []string{"header", "triggers", "concurrency", "env", "security-job", "build-job", "deploy-job", "container-build-steps", "deploy-steps", "cleanup-job", "extra-jobs"}
+
+var (
+	templateNameRegex  = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+	templateBlockRegex = regexp.MustCompile(`{{-?\s*(?:define|block)\s+"([^"]+)"`)
+)
+
+// TemplateInput is an input declared in a template's metadata. Values are supplied through
+// WorkflowConfig.TemplateInputs and are available to the template as {{ .Inputs.<name> }}.
+type TemplateInput struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// WorkflowTemplate is a workflow template together with its metadata. Template files are
+// text/template documents ending in .tmpl with an optional YAML front matter block:
+//
+//	---
+//	name: gke
+//	description: Deploy to GKE instead of Cloud Run
+//	extends: standard
+//	inputs:
+//	  - name: cluster
+//	    required: true
+//	---
+//	{{ define "deploy-job" }}...{{ end }}
+//
+// A template that extends another only needs to define the blocks it overrides.
+type WorkflowTemplate struct {
+	Name        string          `json:"name" yaml:"name"`
+	Description string          `json:"description,omitempty" yaml:"description,omitempty"`
+	Extends     string          `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Inputs      []TemplateInput `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Source      string          `json:"source" yaml:"-"`
+	Path        string          `json:"path,omitempty" yaml:"-"`
+	Body        string          `json:"-" yaml:"-"`
+}
+
+// Builtin reports whether the template is compiled into the binary
+func (t *WorkflowTemplate) Builtin() bool {
+	return t.Source == TemplateSourceBuiltin
+}
+
+// Blocks returns the names of the blocks the template defines, in order of appearance
+func (t *WorkflowTemplate) Blocks() []string {
+	var blocks []string
+	for _, match := range templateBlockRegex.FindAllStringSubmatch(t.Body, -1) {
+		if !containsString(blocks, match[1]) {
+			blocks = append(blocks, match[1])
+		}
+	}
+	return blocks
+}
+
+// builtinTemplates returns the templates compiled into the binary
+func builtinTemplates() []*WorkflowTemplate {
+	return []*WorkflowTemplate{
+		{
+			Name:        DefaultTemplateName,
+			Description: "Security checks, then build and deploy to the configured target with Workload Identity Federation",
+			Source:      TemplateSourceBuiltin,
+			Body:        standardTemplate,
+		},
+		{
+			Name:        TerraformTemplateName,
+			Description: "Terraform plan on pull requests with a plan comment, apply on the main branch behind an environment",
+			Source:      TemplateSourceBuiltin,
+			Body:        terraformTemplate,
+		},
+	}
+}
+
+// DefaultTemplateSearchPath returns the template directories searched when none are
+// configured: the repository-local directory followed by the user's config directory.
+// Built-in templates are always searched last.
+func DefaultTemplateSearchPath() []string {
+	dirs := []string{DefaultTemplateDir}
+	if userDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(userDir, "gcp-wif", "templates"))
+	}
+	return dirs
+}
+
+// ParseWorkflowTemplate parses a template file's front matter and body. The template name
+// defaults to the file name without its extensions.
+func ParseWorkflowTemplate(path string, content []byte) (*WorkflowTemplate, error) {
+	tmpl := &WorkflowTemplate{Path: path}
+
+	body := string(content)
+	normalized := strings.ReplaceAll(body, "\r\n", "\n")
+	if strings.HasPrefix(normalized, "---\n") {
+		end := strings.Index(normalized[4:], "\n---")
+		if end < 0 {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("Template %s has an unterminated front matter block", path),
+				"Close the metadata block with a line containing only '---'")
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader([]byte(normalized[4 : 4+end])))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(tmpl); err != nil && err != io.EOF {
+			return nil, errors.WrapError(err, errors.ErrorTypeValidation, "TEMPLATE_METADATA_INVALID",
+				fmt.Sprintf("Failed to parse metadata of template %s", path))
+		}
+		body = normalized[4+end+len("\n---"):]
+		body = strings.TrimPrefix(body, "\n")
+	}
+	tmpl.Body = body
+
+	if tmpl.Name == "" {
+		name := strings.TrimSuffix(filepath.Base(path), templateFileSuffix)
+		tmpl.Name = strings.TrimSuffix(strings.TrimSuffix(name, ".yml"), ".yaml")
+	}
+	if !templateNameRegex.MatchString(tmpl.Name) {
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Template %s has an invalid name '%s'", path, tmpl.Name),
+			"Use lowercase letters, digits, '.', '_' and '-'")
+	}
+
+	seen := make(map[string]bool)
+	for _, input := range tmpl.Inputs {
+		if input.Name == "" {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("Template %s declares an input without a name", path))
+		}
+		if seen[input.Name] {
+			return nil, errors.NewValidationError(
+				fmt.Sprintf("Template %s declares input '%s' more than once", path, input.Name))
+		}
+		seen[input.Name] = true
+	}
+	return tmpl, nil
+}
+
+// TemplateCatalog holds the templates found on a search path. Directories earlier in the
+// path take precedence over later ones, and over the built-in templates.
+type TemplateCatalog struct {
+	SearchPath []string
+
+	// entries holds every template by name in precedence order, so a template can extend
+	// the one it shadows
+	entries map[string][]*WorkflowTemplate
+}
+
+// LoadTemplateCatalog loads the templates in the given directories followed by the
+// built-in templates. Directories that do not exist are skipped.
+func LoadTemplateCatalog(searchPath []string) (*TemplateCatalog, error) {
+	logger := logging.WithField("function", "LoadTemplateCatalog")
+
+	catalog := &TemplateCatalog{SearchPath: searchPath, entries: make(map[string][]*WorkflowTemplate)}
+	for _, dir := range searchPath {
+		files, err := filepath.Glob(filepath.Join(dir, "*"+templateFileSuffix))
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "TEMPLATE_DIR_READ_FAILED",
+				fmt.Sprintf("Failed to list templates in %s", dir))
+		}
+		sort.Strings(files)
+
+		names := make(map[string]string)
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "TEMPLATE_READ_FAILED",
+					fmt.Sprintf("Failed to read template: %s", file))
+			}
+			tmpl, err := ParseWorkflowTemplate(file, content)
+			if err != nil {
+				return nil, err
+			}
+			if other, ok := names[tmpl.Name]; ok {
+				return nil, errors.NewValidationError(
+					fmt.Sprintf("Template '%s' is defined by both %s and %s", tmpl.Name, other, file))
+			}
+			names[tmpl.Name] = file
+			tmpl.Source = dir
+			catalog.add(tmpl)
+		}
+		logger.Debug("Loaded template directory", "dir", dir, "templates", len(files))
+	}
+
+	for _, tmpl := range builtinTemplates() {
+		catalog.add(tmpl)
+	}
+	return catalog, nil
+}
+
+func (c *TemplateCatalog) add(tmpl *WorkflowTemplate) {
+	c.entries[tmpl.Name] = append(c.entries[tmpl.Name], tmpl)
+}
+
+// Get returns the highest-precedence template with the given name
+func (c *TemplateCatalog) Get(name string) (*WorkflowTemplate, error) {
+	entries := c.entries[name]
+	if len(entries) == 0 {
+		return nil, errors.NewConfigurationError(
+			fmt.Sprintf("Workflow template '%s' not found", name),
+			fmt.Sprintf("Available templates: %s", strings.Join(c.Names(), ", ")),
+			"Run 'gcp-wif workflow templates list' to see the template search path")
+	}
+	return entries[0], nil
+}
+
+// Names returns the names of all templates, sorted
+func (c *TemplateCatalog) Names() []string {
+	names := make([]string, 0, len(c.entries))
+	for name := range c.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// List returns the highest-precedence template for every name, sorted by name
+func (c *TemplateCatalog) List() []*WorkflowTemplate {
+	var templates []*WorkflowTemplate
+	for _, name := range c.Names() {
+		templates = append(templates, c.entries[name][0])
+	}
+	return templates
+}
+
+// Shadowed returns the templates hidden by a higher-precedence template of the same name
+func (c *TemplateCatalog) Shadowed(name string) []*WorkflowTemplate {
+	entries := c.entries[name]
+	if len(entries) < 2 {
+		return nil
+	}
+	return entries[1:]
+}
+
+// Resolve returns the inheritance chain of a template, starting with the root template.
+// A template that extends its own name extends the template it shadows.
+func (c *TemplateCatalog) Resolve(name string) ([]*WorkflowTemplate, error) {
+	tmpl, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []*WorkflowTemplate{tmpl}
+	for tmpl.Extends != "" {
+		parent, err := c.parentOf(tmpl)
+		if err != nil {
+			return nil, err
+		}
+		for _, seen := range chain {
+			if seen == parent {
+				return nil, errors.NewValidationError(
+					fmt.Sprintf("Template '%s' has an inheritance cycle through '%s'", name, parent.Name))
+			}
+		}
+		chain = append(chain, parent)
+		tmpl = parent
+	}
+
+	// Reverse so the root template comes first
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// parentOf returns the template a template extends
+func (c *TemplateCatalog) parentOf(tmpl *WorkflowTemplate) (*WorkflowTemplate, error) {
+	entries := c.entries[tmpl.Extends]
+	if tmpl.Extends == tmpl.Name {
+		for i, entry := range entries {
+			if entry == tmpl && i+1 < len(entries) {
+				return entries[i+1], nil
+			}
+		}
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Template '%s' extends itself but does not shadow another template", tmpl.Name))
+	}
+	if len(entries) == 0 {
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Template '%s' extends unknown template '%s'", tmpl.Name, tmpl.Extends),
+			fmt.Sprintf("Available templates: %s", strings.Join(c.Names(), ", ")))
+	}
+	return entries[0], nil
+}
+
+// ResolveTemplateInputs merges the inputs declared along a template chain with the given
+// values, applying defaults. It fails when a required input is missing or when a value is
+// given for an input no template in the chain declares.
+func ResolveTemplateInputs(chain []*WorkflowTemplate, values map[string]string) (map[string]string, error) {
+	declared := make(map[string]TemplateInput)
+	for _, tmpl := range chain {
+		for _, input := range tmpl.Inputs {
+			declared[input.Name] = input
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if _, ok := declared[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Template '%s' does not declare inputs: %s", chain[len(chain)-1].Name, strings.Join(unknown, ", ")),
+			"Run 'gcp-wif workflow templates show' to list the template's inputs")
+	}
+
+	resolved := make(map[string]string)
+	var missing []string
+	for name, input := range declared {
+		value, ok := values[name]
+		if !ok || value == "" {
+			value = input.Default
+		}
+		if value == "" && input.Required {
+			missing = append(missing, name)
+			continue
+		}
+		resolved[name] = value
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, errors.NewValidationError(
+			fmt.Sprintf("Template '%s' is missing required inputs: %s", chain[len(chain)-1].Name, strings.Join(missing, ", ")),
+			"Set them under workflow.template_inputs in the configuration")
+	}
+	return resolved, nil
+}
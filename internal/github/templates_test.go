@@ -0,0 +1,150 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTemplate(t *testing.T, dir, file, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const gkeTemplate = `---
+description: Deploy to GKE
+extends: standard
+inputs:
+  - name: cluster
+    required: true
+  - name: namespace
+    default: default
+---
+{{ define "deploy-job" }}  deploy:
+    needs: security-checks
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+      id-token: write
+    steps:
+    - uses: actions/checkout@v4
+    - uses: google-github-actions/auth@v2
+      with:
+        workload_identity_provider: ${{ "{{" }} env.WORKLOAD_IDENTITY_PROVIDER {{ "}}" }}
+        service_account: ${{ "{{" }} env.SERVICE_ACCOUNT {{ "}}" }}
+    - uses: google-github-actions/get-gke-credentials@v2
+      with:
+        cluster_name: {{ .Inputs.cluster }}
+        location: {{ .Region }}
+    - run: kubectl -n {{ .Inputs.namespace }} rollout restart deployment/{{ .ServiceName }}
+{{ end }}
+{{ define "cleanup-job" }}{{ end }}
+`
+
+func TestTemplateCatalogInheritance(t *testing.T) {
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	userDir := filepath.Join(t.TempDir(), "user")
+	writeTemplate(t, repoDir, "gke.yml.tmpl", gkeTemplate)
+	writeTemplate(t, userDir, "gke.tmpl", "---\ndescription: shadowed\n---\nname: shadowed\n")
+
+	catalog, err := LoadTemplateCatalog([]string{repoDir, userDir})
+	if err != nil {
+		t.Fatalf("Failed to load catalog: %v", err)
+	}
+	if names := catalog.Names(); strings.Join(names, ",") != "gke,standard,terraform" {
+		t.Errorf("Unexpected templates: %v", names)
+	}
+	if shadowed := catalog.Shadowed("gke"); len(shadowed) != 1 || shadowed[0].Source != userDir {
+		t.Errorf("Expected the user template to be shadowed, got %+v", shadowed)
+	}
+
+	chain, err := catalog.Resolve("gke")
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if len(chain) != 2 || !chain[0].Builtin() || chain[1].Source != repoDir {
+		t.Fatalf("Unexpected chain: %+v", chain)
+	}
+	if blocks := strings.Join(chain[1].Blocks(), ","); blocks != "deploy-job,cleanup-job" {
+		t.Errorf("Unexpected blocks: %s", blocks)
+	}
+
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.Template = "gke"
+	cfg.TemplateSearchPath = []string{repoDir, userDir}
+
+	// Required inputs must be supplied
+	if _, err := cfg.GenerateWorkflow(); err == nil || !strings.Contains(err.Error(), "missing required inputs: cluster") {
+		t.Fatalf("Expected missing input error, got %v", err)
+	}
+	cfg.TemplateInputs = map[string]string{"cluster": "prod-cluster", "zone": "a"}
+	if _, err := cfg.GenerateWorkflow(); err == nil || !strings.Contains(err.Error(), "does not declare inputs: zone") {
+		t.Fatalf("Expected unknown input error, got %v", err)
+	}
+
+	cfg.TemplateInputs = map[string]string{"cluster": "prod-cluster"}
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	for _, expected := range []string{"cluster_name: prod-cluster", "kubectl -n default rollout", "security-checks:"} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected generated workflow to contain %q", expected)
+		}
+	}
+	if strings.Contains(content, "deploy-cloudrun") || strings.Contains(content, "cleanup:") {
+		t.Error("Expected the overridden blocks to replace the built-in deploy and cleanup jobs")
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+}
+
+func TestTemplateCatalogSelfExtension(t *testing.T) {
+	dir := t.TempDir()
+	// A repository template named like the built-in overrides it while inheriting its blocks
+	writeTemplate(t, dir, "standard.tmpl", "---\nextends: standard\n---\n{{ define \"header\" }}name: Custom {{ .Name }}{{ end }}")
+	writeTemplate(t, dir, "a.tmpl", "---\nextends: b\n---\n")
+	writeTemplate(t, dir, "b.tmpl", "---\nextends: a\n---\n")
+
+	catalog, err := LoadTemplateCatalog([]string{dir})
+	if err != nil {
+		t.Fatalf("Failed to load catalog: %v", err)
+	}
+	if _, err := catalog.Resolve("a"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected inheritance cycle error, got %v", err)
+	}
+
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.TemplateSearchPath = []string{dir}
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if !strings.HasPrefix(content, "name: Custom ") || !strings.Contains(content, "deploy-cloudrun") {
+		t.Errorf("Unexpected workflow:\n%s", content)
+	}
+}
+
+func TestParseWorkflowTemplate(t *testing.T) {
+	if _, err := ParseWorkflowTemplate("x.tmpl", []byte("---\nname: x\n")); err == nil {
+		t.Error("Expected error for unterminated front matter")
+	}
+	if _, err := ParseWorkflowTemplate("x.tmpl", []byte("---\nnme: x\n---\n")); err == nil {
+		t.Error("Expected error for unknown metadata key")
+	}
+	if _, err := ParseWorkflowTemplate("Bad Name.tmpl", []byte("on: push\n")); err == nil {
+		t.Error("Expected error for invalid template name")
+	}
+
+	tmpl, err := ParseWorkflowTemplate("dir/deploy.yaml.tmpl", []byte("on: push\n"))
+	if err != nil || tmpl.Name != "deploy" || tmpl.Body != "on: push\n" {
+		t.Errorf("Unexpected template %+v (%v)", tmpl, err)
+	}
+}
@@ -0,0 +1,354 @@
+package github
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+)
+
+// Workflow kinds
+const (
+	WorkflowKindDeploy    = "deploy"
+	WorkflowKindTerraform = "terraform"
+)
+
+// TerraformTemplateName is the built-in template rendered for terraform workflows
+const TerraformTemplateName = "terraform"
+
+// TerraformConfig defines settings for terraform workflows, which plan on pull requests
+// and apply on the apply branch. The plan runs as a separate read-only service account
+// through its own provider; the apply uses the workflow's service account and provider.
+type TerraformConfig struct {
+	WorkingDirectory string `json:"working_directory,omitempty"` // Defaults to "."
+	Version          string `json:"version,omitempty"`           // Defaults to "latest"
+	StateBucket      string `json:"state_bucket,omitempty"`
+	StatePrefix      string `json:"state_prefix,omitempty"`      // Defaults to "terraform/state"
+	ApplyBranch      string `json:"apply_branch,omitempty"`      // Defaults to "main"
+	ApplyEnvironment string `json:"apply_environment,omitempty"` // Defaults to "production"
+
+	PlanServiceAccountEmail      string `json:"plan_service_account_email,omitempty"`
+	PlanWorkloadIdentityProvider string `json:"plan_workload_identity_provider,omitempty"`
+}
+
+// TerraformPlanRoles returns the IAM roles of the read-only plan service account
+func TerraformPlanRoles() []string {
+	return []string{
+		"roles/viewer",
+		"roles/iam.securityReviewer",
+		"roles/storage.objectViewer",
+	}
+}
+
+// TerraformApplyRoles returns the IAM roles of the apply service account. Infrastructure
+// repositories usually need to narrow these to the resources they manage.
+func TerraformApplyRoles() []string {
+	return []string{
+		"roles/editor",
+		"roles/resourcemanager.projectIamAdmin",
+		"roles/storage.objectAdmin",
+	}
+}
+
+// GetKind returns the workflow kind, defaulting to deploy
+func (w *WorkflowConfig) GetKind() string {
+	if w.Kind != "" {
+		return w.Kind
+	}
+	return WorkflowKindDeploy
+}
+
+// IsTerraform reports whether the workflow plans and applies terraform
+func (w *WorkflowConfig) IsTerraform() bool {
+	return w.GetKind() == WorkflowKindTerraform
+}
+
+// TerraformSettings returns the terraform settings with defaults applied
+func (w *WorkflowConfig) TerraformSettings() TerraformConfig {
+	settings := w.Terraform
+	if settings.WorkingDirectory == "" {
+		settings.WorkingDirectory = "."
+	}
+	settings.WorkingDirectory = path.Clean(settings.WorkingDirectory)
+	if settings.Version == "" {
+		settings.Version = "latest"
+	}
+	if settings.StatePrefix == "" {
+		settings.StatePrefix = "terraform/state"
+	}
+	if settings.ApplyBranch == "" {
+		settings.ApplyBranch = "main"
+	}
+	if settings.ApplyEnvironment == "" {
+		settings.ApplyEnvironment = "production"
+	}
+	return settings
+}
+
+// validateTerraformConfig checks the settings of a terraform workflow
+func (w *WorkflowConfig) validateTerraformConfig() error {
+	settings := w.TerraformSettings()
+	if settings.StateBucket == "" {
+		return errors.NewValidationError("Workflow: Terraform state bucket is required", "workflow.terraform.state_bucket", "REQUIRED")
+	}
+	if strings.HasPrefix(settings.StateBucket, "gs://") {
+		return errors.NewValidationError("Workflow: Terraform state bucket must be a bucket name, not a gs:// URL", "workflow.terraform.state_bucket", "INVALID")
+	}
+	if path.IsAbs(settings.WorkingDirectory) || strings.HasPrefix(settings.WorkingDirectory, "..") {
+		return errors.NewValidationError("Workflow: Terraform working directory must be relative to the repository root", "workflow.terraform.working_directory", "INVALID")
+	}
+	if settings.PlanServiceAccountEmail == "" {
+		return errors.NewValidationError("Workflow: Terraform plan service account email is required", "workflow.terraform.plan_service_account_email", "REQUIRED")
+	}
+	if settings.PlanWorkloadIdentityProvider == "" {
+		return errors.NewValidationError("Workflow: Terraform plan workload identity provider is required", "workflow.terraform.plan_workload_identity_provider", "REQUIRED")
+	}
+	if settings.PlanServiceAccountEmail == w.ServiceAccountEmail {
+		return errors.NewValidationError("Workflow: Terraform plan and apply must use separate service accounts", "workflow.terraform.plan_service_account_email", "INVALID")
+	}
+	if settings.PlanWorkloadIdentityProvider == w.WorkloadIdentityProvider {
+		return errors.NewValidationError("Workflow: Terraform plan and apply must use separate workload identity providers", "workflow.terraform.plan_workload_identity_provider", "INVALID")
+	}
+	return nil
+}
+
+// terraformPathFilter returns the trigger path filter for the working directory, or an
+// empty string when the configuration lives at the repository root
+func (w *WorkflowConfig) terraformPathFilter() string {
+	dir := w.TerraformSettings().WorkingDirectory
+	if dir == "." {
+		return ""
+	}
+	return fmt.Sprintf("%s/**", dir)
+}
+
+// terraformTemplate is the built-in template for terraform workflows: plan on pull requests
+// with the plan posted as a comment, and apply on the apply branch behind an environment.
+const terraformTemplate = `{{ block "header" . }}# {{ .Description }}
+# Generated by GCP WIF CLI Tool v{{ .Version }}
+# Repository: {{ .Repository }}
+# Project: {{ .ProjectID }}
+
+name: {{ .Name }}{{ end }}
+
+{{ block "triggers" . }}on:
+  pull_request:
+    branches:
+      - {{ quote .Terraform.ApplyBranch }}{{ if .TerraformPaths }}
+    paths:
+      - {{ quote .TerraformPaths }}{{ end }}
+  push:
+    branches:
+      - {{ quote .Terraform.ApplyBranch }}{{ if .TerraformPaths }}
+    paths:
+      - {{ quote .TerraformPaths }}{{ end }}{{ if .Triggers.Manual }}
+  workflow_dispatch:{{ end }}{{ end }}
+
+{{ block "concurrency" . }}{{ if .Advanced.Concurrency.Group }}
+# Superseded plans are cancelled; an apply is never cancelled part-way
+concurrency:
+  group: {{ .Advanced.Concurrency.Group }}
+  cancel-in-progress: {{ if .Advanced.Concurrency.CancelInProgress }}${{ "{{" }} github.event_name == 'pull_request' {{ "}}" }}{{ else }}false{{ end }}
+{{ end }}{{ end }}
+
+{{ block "env" . }}env:
+  # GCP Configuration
+  PROJECT_ID: {{ .ProjectID }}{{ if .ProjectNumber }}
+  PROJECT_NUMBER: {{ .ProjectNumber }}{{ end }}
+
+  # Terraform Configuration
+  TF_IN_AUTOMATION: "true"
+  TF_INPUT: "false"
+  TF_WORKING_DIR: {{ .Terraform.WorkingDirectory }}
+  TF_STATE_BUCKET: {{ .Terraform.StateBucket }}
+  TF_STATE_PREFIX: {{ .Terraform.StatePrefix }}
+
+  # Read-only identity used by terraform plan on pull requests
+  PLAN_WORKLOAD_IDENTITY_PROVIDER: {{ .Terraform.PlanWorkloadIdentityProvider }}
+  PLAN_SERVICE_ACCOUNT: {{ .Terraform.PlanServiceAccountEmail }}
+
+  # Identity used by terraform apply on {{ .Terraform.ApplyBranch }}
+  APPLY_WORKLOAD_IDENTITY_PROVIDER: {{ .WorkloadIdentityProvider }}
+  APPLY_SERVICE_ACCOUNT: {{ .ServiceAccountEmail }}{{ end }}
+
+jobs:
+{{ block "plan-job" . }}  # Plan job: runs on pull requests with the read-only service account
+  plan:
+    name: Terraform plan
+    if: github.event_name == 'pull_request'
+    runs-on: ubuntu-latest{{ if .Advanced.Timeout }}
+    timeout-minutes: {{ .TimeoutMinutes }}{{ end }}
+
+    permissions:
+      contents: read
+      id-token: write
+      pull-requests: write  # For commenting the plan
+
+    defaults:
+      run:
+        working-directory: {{ .Terraform.WorkingDirectory }}
+
+    steps:
+    - name: Checkout code
+      uses: actions/checkout@v4
+
+    - name: Authenticate to Google Cloud (plan)
+      uses: google-github-actions/auth@v2
+      with:
+        workload_identity_provider: ${{ "{{" }} env.PLAN_WORKLOAD_IDENTITY_PROVIDER {{ "}}" }}
+        service_account: ${{ "{{" }} env.PLAN_SERVICE_ACCOUNT {{ "}}" }}
+
+    - name: Set up Terraform
+      uses: hashicorp/setup-terraform@v3
+      with:
+        terraform_version: {{ .Terraform.Version }}
+        terraform_wrapper: false
+
+    - name: Terraform format
+      id: fmt
+      run: terraform fmt -check -recursive
+      continue-on-error: true
+
+    - name: Terraform init
+      id: init
+      run: terraform init -backend-config="bucket=$TF_STATE_BUCKET" -backend-config="prefix=$TF_STATE_PREFIX"
+
+    - name: Terraform validate
+      id: validate
+      run: terraform validate -no-color
+      continue-on-error: true
+
+    # The plan service account cannot write the state lock, so the plan runs without locking
+    - name: Terraform plan
+      id: plan
+      if: steps.validate.outcome == 'success'
+      run: |
+        set +e
+        terraform plan -lock=false -no-color -detailed-exitcode -out=tfplan > plan.txt 2>&1
+        EXIT_CODE=$?
+        cat plan.txt
+        echo "exitcode=$EXIT_CODE" >> $GITHUB_OUTPUT
+        if [ $EXIT_CODE -eq 1 ]; then
+          exit 1
+        fi
+      continue-on-error: true
+
+    - name: Comment plan on pull request
+      if: always() && steps.init.outcome == 'success'
+      uses: actions/github-script@v7
+      env:
+        FMT_OUTCOME: ${{ "{{" }} steps.fmt.outcome {{ "}}" }}
+        VALIDATE_OUTCOME: ${{ "{{" }} steps.validate.outcome {{ "}}" }}
+        PLAN_OUTCOME: ${{ "{{" }} steps.plan.outcome {{ "}}" }}
+        PLAN_EXITCODE: ${{ "{{" }} steps.plan.outputs.exitcode {{ "}}" }}
+      with:
+        script: |
+          const fs = require('fs');
+          const path = require('path');
+          const marker = '<!-- gcp-wif terraform plan: {{ .Terraform.WorkingDirectory }} -->';
+          const planFile = path.join(process.env.TF_WORKING_DIR, 'plan.txt');
+
+          let plan = fs.existsSync(planFile) ? fs.readFileSync(planFile, 'utf8') : 'The plan did not run.';
+          const limit = 60000;
+          if (plan.length > limit) {
+            plan = '... plan truncated, see the workflow run for the full output ...\n' + plan.slice(plan.length - limit);
+          }
+
+          const result = { '0': 'no changes', '2': 'changes detected' }[process.env.PLAN_EXITCODE] || 'failed';
+          const runUrl = context.serverUrl + '/' + context.repo.owner + '/' + context.repo.repo + '/actions/runs/' + context.runId;
+          const body = [
+            marker,
+            '## Terraform plan: ' + result,
+            '',
+            '| Step | Outcome |',
+            '| --- | --- |',
+            '| fmt | ' + process.env.FMT_OUTCOME + ' |',
+            '| validate | ' + process.env.VALIDATE_OUTCOME + ' |',
+            '| plan | ' + (process.env.PLAN_OUTCOME || 'skipped') + ' |',
+            '',
+            '<details><summary>Show plan</summary>',
+            '',
+            '` + "```" + `',
+            plan,
+            '` + "```" + `',
+            '</details>',
+            '',
+            'Working directory: {{ .Terraform.WorkingDirectory }} · Workflow run: [#' + context.runNumber + '](' + runUrl + ')',
+          ].join('\n');
+
+          // Update the previous plan comment instead of adding one per push
+          const comments = await github.paginate(github.rest.issues.listComments, {
+            owner: context.repo.owner,
+            repo: context.repo.repo,
+            issue_number: context.issue.number,
+          });
+          const previous = comments.find(comment => comment.body && comment.body.includes(marker));
+          if (previous) {
+            await github.rest.issues.updateComment({
+              owner: context.repo.owner,
+              repo: context.repo.repo,
+              comment_id: previous.id,
+              body: body,
+            });
+          } else {
+            await github.rest.issues.createComment({
+              owner: context.repo.owner,
+              repo: context.repo.repo,
+              issue_number: context.issue.number,
+              body: body,
+            });
+          }
+
+    - name: Fail on terraform errors
+      if: steps.fmt.outcome == 'failure' || steps.validate.outcome == 'failure' || steps.plan.outcome == 'failure'
+      run: |
+        echo "::error::terraform fmt, validate or plan failed; see the plan comment for details"
+        exit 1
+{{ end }}
+{{ block "apply-job" . }}  # Apply job: runs on {{ .Terraform.ApplyBranch }} after the environment approval
+  apply:
+    name: Terraform apply
+    if: github.event_name != 'pull_request' && github.ref == 'refs/heads/{{ .Terraform.ApplyBranch }}'
+    runs-on: ubuntu-latest{{ if .Advanced.Timeout }}
+    timeout-minutes: {{ .TimeoutMinutes }}{{ end }}
+    environment: {{ .Terraform.ApplyEnvironment }}
+
+    # Applies of the same state are serialized
+    concurrency:
+      group: terraform-apply-{{ .Terraform.StatePrefix }}
+      cancel-in-progress: false
+
+    permissions:
+      contents: read
+      id-token: write
+
+    defaults:
+      run:
+        working-directory: {{ .Terraform.WorkingDirectory }}
+
+    steps:
+    - name: Checkout code
+      uses: actions/checkout@v4{{ .NotifyStart }}
+
+    - name: Authenticate to Google Cloud (apply)
+      uses: google-github-actions/auth@v2
+      with:
+        workload_identity_provider: ${{ "{{" }} env.APPLY_WORKLOAD_IDENTITY_PROVIDER {{ "}}" }}
+        service_account: ${{ "{{" }} env.APPLY_SERVICE_ACCOUNT {{ "}}" }}
+
+    - name: Set up Terraform
+      uses: hashicorp/setup-terraform@v3
+      with:
+        terraform_version: {{ .Terraform.Version }}
+        terraform_wrapper: false
+
+    - name: Terraform init
+      run: terraform init -backend-config="bucket=$TF_STATE_BUCKET" -backend-config="prefix=$TF_STATE_PREFIX"
+
+    - name: Terraform plan
+      run: terraform plan -no-color -out=tfplan
+
+    - name: Terraform apply
+      run: terraform apply -no-color -auto-approve tfplan{{ .NotifySuccess }}{{ .NotifyFailure }}
+{{ end }}{{ block "extra-jobs" . }}{{ end }}`
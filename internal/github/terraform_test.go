@@ -0,0 +1,100 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func testTerraformConfig() *WorkflowConfig {
+	cfg := testWorkflowConfig(DefaultWorkflowConfig())
+	cfg.Kind = WorkflowKindTerraform
+	cfg.ServiceName = ""
+	cfg.Terraform = TerraformConfig{
+		WorkingDirectory:             "infra/",
+		StateBucket:                  "my-project-tfstate",
+		PlanServiceAccountEmail:      "deployer-plan@my-project.iam.gserviceaccount.com",
+		PlanWorkloadIdentityProvider: "projects/123/locations/global/workloadIdentityPools/github/providers/github-plan",
+	}
+	return cfg
+}
+
+func TestTerraformWorkflow(t *testing.T) {
+	cfg := testTerraformConfig()
+
+	content, err := cfg.GenerateWorkflow()
+	if err != nil {
+		t.Fatalf("Failed to generate workflow: %v", err)
+	}
+	if err := cfg.ValidateWorkflowContent(content); err != nil {
+		t.Errorf("Generated workflow failed validation: %v", err)
+	}
+
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+	if len(workflow.Jobs) != 2 || workflow.Jobs[0].ID != "plan" || workflow.Jobs[1].ID != "apply" {
+		t.Fatalf("Expected plan and apply jobs, got %+v", workflow.Jobs)
+	}
+	plan, apply := workflow.Jobs[0], workflow.Jobs[1]
+	if apply.Environment != "production" {
+		t.Errorf("Expected apply to run in the production environment, got %q", apply.Environment)
+	}
+	if plan.Permissions["pull-requests"] != "write" || apply.Permissions["pull-requests"] != "" {
+		t.Error("Expected only the plan job to be able to comment on pull requests")
+	}
+
+	for _, expected := range []string{
+		`- "infra/**"`,
+		"working-directory: infra",
+		"PLAN_SERVICE_ACCOUNT: deployer-plan@my-project.iam.gserviceaccount.com",
+		"APPLY_SERVICE_ACCOUNT: deployer@my-project.iam.gserviceaccount.com",
+		"workload_identity_provider: ${{ env.PLAN_WORKLOAD_IDENTITY_PROVIDER }}",
+		"workload_identity_provider: ${{ env.APPLY_WORKLOAD_IDENTITY_PROVIDER }}",
+		`-backend-config="bucket=$TF_STATE_BUCKET"`,
+		"terraform plan -lock=false",
+		"github.rest.issues.createComment",
+		"environment: production",
+		"github.ref == 'refs/heads/main'",
+		"terraform apply -no-color -auto-approve tfplan",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("Expected terraform workflow to contain %q", expected)
+		}
+	}
+	if strings.Contains(content, "docker/build-push-action") || strings.Contains(content, "deploy-cloudrun") {
+		t.Error("Expected terraform workflow not to build or deploy a service")
+	}
+
+	if findings, _ := LintWorkflow("terraform.yml", content, LintExpectations{}); len(findings) > 0 {
+		t.Errorf("Expected no lint findings, got %+v", findings)
+	}
+}
+
+func TestTerraformValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(cfg *WorkflowConfig)
+		message string
+	}{
+		{"unknown kind", func(cfg *WorkflowConfig) { cfg.Kind, cfg.ServiceName = "pulumi", "my-service" }, "Unknown workflow kind"},
+		{"missing bucket", func(cfg *WorkflowConfig) { cfg.Terraform.StateBucket = "" }, "state bucket is required"},
+		{"bucket url", func(cfg *WorkflowConfig) { cfg.Terraform.StateBucket = "gs://state" }, "not a gs:// URL"},
+		{"outside repository", func(cfg *WorkflowConfig) { cfg.Terraform.WorkingDirectory = "../infra" }, "relative to the repository root"},
+		{"missing plan account", func(cfg *WorkflowConfig) { cfg.Terraform.PlanServiceAccountEmail = "" }, "plan service account email is required"},
+		{"shared account", func(cfg *WorkflowConfig) {
+			cfg.Terraform.PlanServiceAccountEmail = cfg.ServiceAccountEmail
+		}, "separate service accounts"},
+		{"shared provider", func(cfg *WorkflowConfig) {
+			cfg.Terraform.PlanWorkloadIdentityProvider = cfg.WorkloadIdentityProvider
+		}, "separate workload identity providers"},
+	}
+
+	for _, test := range tests {
+		cfg := testTerraformConfig()
+		test.setup(cfg)
+		if err := cfg.ValidateConfig(); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.message, err)
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
+	"text/template/parse"
 	"time"
 
 	"github.com/Fordjour12/gcp-wif/internal/errors"
@@ -24,6 +26,17 @@ type WorkflowConfig struct {
 	Author      string `json:"author,omitempty"`
 	Version     string `json:"version,omitempty"`
 
+	// Workflow kind: deploy (default) builds and deploys the service; terraform plans on
+	// pull requests and applies on the apply branch
+	Kind      string          `json:"kind,omitempty"`
+	Terraform TerraformConfig `json:"terraform,omitempty"`
+
+	// Template selection: the catalog template to render, values for its declared inputs,
+	// and the directories searched before the built-in templates
+	Template           string            `json:"template,omitempty"`
+	TemplateInputs     map[string]string `json:"template_inputs,omitempty"`
+	TemplateSearchPath []string          `json:"template_search_path,omitempty"`
+
 	// Trigger configuration
 	Triggers WorkflowTriggers `json:"triggers"`
 
@@ -33,12 +46,24 @@ type WorkflowConfig struct {
 	ServiceAccountEmail      string `json:"service_account_email"`
 	WorkloadIdentityProvider string `json:"workload_identity_provider"`
 
+	// UseActionsVariables makes the workflow read the project ID and identity from the
+	// repository and environment Actions variables (vars.*) instead of writing them out
+	UseActionsVariables bool `json:"use_actions_variables,omitempty"`
+
 	// Repository configuration
 	Repository string   `json:"repository"`
 	Branches   []string `json:"branches,omitempty"`
 	Tags       []string `json:"tags,omitempty"`
 
-	// Cloud Run configuration
+	// Deployment target: cloud-run (default), gke, cloud-functions, app-engine or
+	// firebase-hosting, with the settings of the non-default targets
+	Target         string               `json:"target,omitempty"`
+	GKE            GKEConfig            `json:"gke,omitempty"`
+	CloudFunctions CloudFunctionsConfig `json:"cloud_functions,omitempty"`
+	AppEngine      AppEngineConfig      `json:"app_engine,omitempty"`
+	Firebase       FirebaseConfig       `json:"firebase,omitempty"`
+
+	// Service configuration
 	ServiceName  string            `json:"service_name"`
 	Region       string            `json:"region"`
 	Registry     string            `json:"registry,omitempty"`
@@ -108,6 +133,15 @@ type SecurityConfig struct {
 	RequiredChecks       []string `json:"required_checks,omitempty"`
 	BlockForkedRepos     bool     `json:"block_forked_repos"`
 	RequireSignedCommits bool     `json:"require_signed_commits,omitempty"`
+	// PinActions renders third-party actions pinned to the commit SHAs in ActionLockFile
+	PinActions     bool   `json:"pin_actions,omitempty"`
+	ActionLockFile string `json:"action_lock_file,omitempty"`
+	// Supply-chain attestations of the pushed image: an SPDX SBOM, a cosign keyless
+	// signature of the digest, SLSA provenance and a Binary Authorization attestation
+	GenerateSBOM        bool                      `json:"generate_sbom,omitempty"`
+	SignImages          bool                      `json:"sign_images,omitempty"`
+	SLSAProvenance      bool                      `json:"slsa_provenance,omitempty"`
+	BinaryAuthorization BinaryAuthorizationConfig `json:"binary_authorization,omitempty"`
 }
 
 // AdvancedWorkflowConfig defines advanced workflow settings
@@ -118,7 +152,11 @@ type AdvancedWorkflowConfig struct {
 	Environments      map[string]Environment `json:"environments,omitempty"`
 	NotificationHooks []NotificationHook     `json:"notification_hooks,omitempty"`
 	HealthChecks      []HealthCheck          `json:"health_checks,omitempty"`
+	Canary            CanaryConfig           `json:"canary,omitempty"`
 	ArtifactRetention string                 `json:"artifact_retention,omitempty"`
+	// Pipeline lists environments in promotion order. Each gets its own deploy job that
+	// waits for the previous environment's deployment.
+	Pipeline []string `json:"pipeline,omitempty"`
 }
 
 // ConcurrencyConfig defines concurrency settings
@@ -144,13 +182,24 @@ type Environment struct {
 	Variables  map[string]string     `json:"variables,omitempty"`
 	Secrets    map[string]string     `json:"secrets,omitempty"`
 	Protection EnvironmentProtection `json:"protection,omitempty"`
+
+	// Deployment overrides used by the environment's pipeline job; empty values fall back
+	// to the workflow-level settings
+	Region                   string `json:"region,omitempty"`
+	ServiceAccountEmail      string `json:"service_account_email,omitempty"`
+	WorkloadIdentityProvider string `json:"workload_identity_provider,omitempty"`
 }
 
 // EnvironmentProtection defines environment protection rules
 type EnvironmentProtection struct {
-	RequiredReviewers []string `json:"required_reviewers,omitempty"`
-	WaitTimer         int      `json:"wait_timer,omitempty"` // minutes
+	RequiredReviewers []string `json:"required_reviewers,omitempty"` // @user, org/team or @org/team
+	WaitTimer         int      `json:"wait_timer,omitempty"`         // minutes
 	PreventSelfReview bool     `json:"prevent_self_review,omitempty"`
+
+	// Deployment branch policy: only protected branches, or only branches matching the
+	// patterns. Any branch can deploy when neither is set.
+	ProtectedBranches  bool     `json:"protected_branches,omitempty"`
+	DeploymentBranches []string `json:"deployment_branches,omitempty"`
 }
 
 // NotificationHook defines notification configuration
@@ -346,9 +395,10 @@ func (w *WorkflowConfig) GenerateWorkflow() (string, error) {
 			"Invalid workflow configuration")
 	}
 
-	tmpl := template.New("workflow").Funcs(template.FuncMap{
+	funcs := template.FuncMap{
 		"join":      strings.Join,
 		"quote":     func(s string) string { return fmt.Sprintf(`"%s"`, s) },
+		"yamlQuote": strconv.Quote,
 		"contains":  strings.Contains,
 		"hasPrefix": strings.HasPrefix,
 		"now":       func() string { return time.Now().Format("2006-01-02T15:04:05Z") },
@@ -368,18 +418,118 @@ func (w *WorkflowConfig) GenerateWorkflow() (string, error) {
 			}
 			return strings.Join(lines, "\n")
 		},
-	})
+	}
+	tmpl := template.New("workflow").Option("missingkey=error").Funcs(funcs)
 
-	workflowTemplate := w.buildWorkflowTemplate()
+	catalog, err := LoadTemplateCatalog(w.GetTemplateSearchPath())
+	if err != nil {
+		return "", err
+	}
+	chain, err := catalog.Resolve(w.GetTemplateName())
+	if err != nil {
+		return "", err
+	}
+	inputs, err := ResolveTemplateInputs(chain, w.TemplateInputs)
+	if err != nil {
+		return "", err
+	}
 
-	parsedTemplate, err := tmpl.Parse(workflowTemplate)
+	// The deployment target's steps are parsed first so they fill the root template's empty
+	// "deploy-steps" block while templates can still override them
+	target, err := w.GetDeploymentTarget()
 	if err != nil {
-		return "", errors.WrapError(err, errors.ErrorTypeValidation, "WORKFLOW_TEMPLATE_PARSE_FAILED",
-			"Failed to parse workflow template")
+		return "", err
+	}
+	if _, err := tmpl.New("target:" + target.Name()).Parse(`{{ define "deploy-steps" }}` + target.DeploySteps() + `{{ end }}`); err != nil {
+		return "", errors.NewInternalError(fmt.Sprintf("Failed to parse deploy steps of target %s", target.Name()), err)
+	}
+
+	// The root template is executed; descendants only contribute block definitions, each
+	// replacing the definition inherited from its parent
+	var parsedTemplate *template.Template
+	for i, t := range chain {
+		current := tmpl
+		if i > 0 {
+			current = tmpl.New(fmt.Sprintf("%s#%d", t.Name, i))
+		}
+		if _, err := current.Parse(t.Body); err != nil {
+			return "", errors.WrapError(err, errors.ErrorTypeValidation, "WORKFLOW_TEMPLATE_PARSE_FAILED",
+				fmt.Sprintf("Failed to parse workflow template '%s'", t.Name))
+		}
+		if i == 0 {
+			parsedTemplate = current
+			continue
+		}
+
+		// text/template keeps an existing definition when it is redefined with an empty
+		// body, so blocks a descendant empties out are replaced with an explicit no-op
+		standalone, err := template.New(t.Name).Funcs(funcs).Parse(t.Body)
+		if err != nil {
+			return "", errors.WrapError(err, errors.ErrorTypeValidation, "WORKFLOW_TEMPLATE_PARSE_FAILED",
+				fmt.Sprintf("Failed to parse workflow template '%s'", t.Name))
+		}
+		for _, def := range standalone.Templates() {
+			if def.Name() != t.Name && def.Tree != nil && parse.IsEmptyTree(def.Tree.Root) {
+				if _, err := tmpl.New(def.Name()).Parse(`{{ "" }}`); err != nil {
+					return "", errors.NewInternalError("Failed to clear workflow template block", err)
+				}
+			}
+		}
 	}
 
 	// Prepare comprehensive template data
 	data := w.buildTemplateData()
+	data["Template"] = chain[len(chain)-1].Name
+	data["Inputs"] = inputs
+	data["DeployTarget"] = target.Name()
+	data["BuildsContainer"] = target.BuildsContainer()
+	data["FailureLogFilter"] = target.FailureLogFilter(w)
+	data["Canary"] = w.canarySettings()
+	data["SupplyChain"] = w.supplyChainSettings()
+	data["ActionsVariables"] = w.UseActionsVariables
+
+	// A matrix strategy fans the deploy job out, overriding REGION and SERVICE_NAME per entry
+	strategy, err := w.matrixStrategyYAML()
+	if err != nil {
+		return "", err
+	}
+	data["Strategy"] = strategy
+	data["MatrixEnv"] = w.matrixEnv()
+	jobEnv := make(map[string]string)
+	for name, key := range w.matrixEnv() {
+		jobEnv[name] = fmt.Sprintf("${{ matrix.%s }}", key)
+	}
+	data["JobEnv"] = jobEnv
+
+	subject := "${{ env.SERVICE_NAME }}"
+	if w.IsTerraform() {
+		subject = "terraform in ${{ env.TF_WORKING_DIR }}"
+	}
+	data["NotifyStart"] = w.notificationSteps(NotificationEventStart, subject)
+	data["NotifySuccess"] = w.notificationSteps(NotificationEventSuccess, subject)
+	data["NotifyFailure"] = w.notificationSteps(NotificationEventFailure, subject)
+
+	// An environment pipeline renders the deploy job once per environment
+	data["DeployJobID"] = DefaultDeployJobID
+	data["DeployNeeds"] = "security-checks"
+	data["BuildJob"] = w.buildJobID()
+	data["BuildJobEnv"] = w.buildJobEnv()
+	data["ImageDigest"] = "${{ steps.build.outputs.digest }}"
+	if build := w.buildJobID(); build != "" {
+		data["DeployNeeds"] = fmt.Sprintf("[security-checks, %s]", build)
+		data["ImageDigest"] = fmt.Sprintf("${{ needs.%s.outputs.image-digest }}", build)
+	}
+	data["DeployEnvironment"] = ""
+	data["DeployJobIDs"] = w.DeployJobIDs()
+	data["EnvironmentRef"] = "${{ needs.security-checks.outputs.environment }}"
+	data["DeployJobs"] = ""
+	if len(w.Advanced.Pipeline) > 0 {
+		jobs, err := w.renderPipelineJobs(tmpl, data)
+		if err != nil {
+			return "", err
+		}
+		data["DeployJobs"] = jobs
+	}
 
 	var output strings.Builder
 	if err := parsedTemplate.Execute(&output, data); err != nil {
@@ -387,11 +537,53 @@ func (w *WorkflowConfig) GenerateWorkflow() (string, error) {
 			"Failed to execute workflow template")
 	}
 
+	content := output.String()
+	if w.Security.PinActions {
+		lock, err := LoadActionLock(w.GetActionLockFile())
+		if err != nil {
+			return "", err
+		}
+		if content, _, err = PinWorkflowActions(content, lock); err != nil {
+			return "", err
+		}
+	}
+
 	logger.Info("GitHub Actions workflow generated successfully",
 		"name", w.Name,
-		"triggers", fmt.Sprintf("push:%t pr:%t manual:%t", w.Triggers.Push.Enabled, w.Triggers.PullRequest.Enabled, w.Triggers.Manual))
+		"kind", w.GetKind(),
+		"template", w.GetTemplateName(),
+		"target", target.Name(),
+		"triggers", fmt.Sprintf("push:%t pr:%t manual:%t", w.Triggers.Push.Enabled, w.Triggers.PullRequest.Enabled, w.Triggers.Manual),
+		"pinned", w.Security.PinActions)
+
+	return content, nil
+}
+
+// GetTemplateName returns the name of the template used for generation
+func (w *WorkflowConfig) GetTemplateName() string {
+	if w.Template != "" {
+		return w.Template
+	}
+	if w.IsTerraform() {
+		return TerraformTemplateName
+	}
+	return DefaultTemplateName
+}
 
-	return output.String(), nil
+// GetTemplateSearchPath returns the directories searched for templates before the built-ins
+func (w *WorkflowConfig) GetTemplateSearchPath() []string {
+	if len(w.TemplateSearchPath) > 0 {
+		return w.TemplateSearchPath
+	}
+	return DefaultTemplateSearchPath()
+}
+
+// GetActionLockFile returns the action lock file used when pinning actions
+func (w *WorkflowConfig) GetActionLockFile() string {
+	if w.Security.ActionLockFile != "" {
+		return w.Security.ActionLockFile
+	}
+	return DefaultActionLockFile
 }
 
 // buildTemplateData prepares comprehensive template data for workflow generation
@@ -432,19 +624,25 @@ func (w *WorkflowConfig) buildTemplateData() map[string]interface{} {
 		"EnvironmentName":          w.getEnvironmentName(),
 		"EnvironmentURL":           w.getEnvironmentURL(),
 		"HealthCheckCommands":      w.GenerateHealthCheckCommands("$SERVICE_URL"),
+		"GKE":                      w.gkeSettings(),
+		"CloudFunctions":           w.cloudFunctionsSettings(),
+		"AppEngine":                w.appEngineSettings(),
+		"Firebase":                 w.firebaseSettings(),
+		"Terraform":                w.TerraformSettings(),
+		"TerraformPaths":           w.terraformPathFilter(),
 	}
 }
 
-// buildWorkflowTemplate builds the comprehensive workflow template
-func (w *WorkflowConfig) buildWorkflowTemplate() string {
-	return `# {{ .Description }}
-# Generated on {{ now }} by GCP WIF CLI Tool v{{ .Version }}
+// standardTemplate is the built-in base template. Each section is a named block that
+// templates extending it can override with {{ define "<block>" }}.
+const standardTemplate = `{{ block "header" . }}# {{ .Description }}
+# Generated by GCP WIF CLI Tool v{{ .Version }}
 # Repository: {{ .Repository }}
 # Project: {{ .ProjectID }}
 
-name: {{ .Name }}
+name: {{ .Name }}{{ end }}
 
-on:{{ if .Triggers.Push.Enabled }}
+{{ block "triggers" . }}on:{{ if .Triggers.Push.Enabled }}
   push:{{ if .Triggers.Push.Branches }}
     branches: {{ range .Triggers.Push.Branches }}
       - {{ quote . }}{{ end }}{{ end }}{{ if .Triggers.Push.Tags }}
@@ -486,17 +684,17 @@ on:{{ if .Triggers.Push.Enabled }}
   release:
     types: [published]{{ end }}{{ if .Triggers.Schedule }}
   schedule:{{ range .Triggers.Schedule }}
-    - cron: {{ quote .Cron }}  # {{ .Description }}{{ end }}{{ end }}
+    - cron: {{ quote .Cron }}  # {{ .Description }}{{ end }}{{ end }}{{ end }}
 
-{{ if .Advanced.Concurrency.Group }}
+{{ block "concurrency" . }}{{ if .Advanced.Concurrency.Group }}
 concurrency:
   group: {{ .Advanced.Concurrency.Group }}
   cancel-in-progress: {{ .Advanced.Concurrency.CancelInProgress }}
-{{ end }}
+{{ end }}{{ end }}
 
-env:
+{{ block "env" . }}env:
   # GCP Configuration
-  PROJECT_ID: {{ .ProjectID }}{{ if .ProjectNumber }}
+  PROJECT_ID: {{ if .ActionsVariables }}${{ "{{" }} vars.PROJECT_ID {{ "}}" }}{{ else }}{{ .ProjectID }}{{ end }}{{ if .ProjectNumber }}
   PROJECT_NUMBER: {{ .ProjectNumber }}{{ end }}
   REGION: {{ .Region }}
   SERVICE_NAME: {{ .ServiceName }}
@@ -508,20 +706,22 @@ env:
   DOCKERFILE_PATH: {{ .DockerfilePath }}
   BUILD_CONTEXT: {{ .BuildContext }}
   
-  # Workload Identity Configuration
+  # Workload Identity Configuration{{ if .ActionsVariables }} (from repository and environment Actions variables)
+  WORKLOAD_IDENTITY_PROVIDER: ${{ "{{" }} vars.WORKLOAD_IDENTITY_PROVIDER {{ "}}" }}
+  SERVICE_ACCOUNT: ${{ "{{" }} vars.SERVICE_ACCOUNT {{ "}}" }}{{ else }}
   WORKLOAD_IDENTITY_PROVIDER: {{ .WorkloadIdentityProvider }}
-  SERVICE_ACCOUNT: {{ .ServiceAccountEmail }}
+  SERVICE_ACCOUNT: {{ .ServiceAccountEmail }}{{ end }}
   
   # Security Configuration
   MAX_TOKEN_LIFETIME: {{ .Security.MaxTokenLifetime }}{{ if .Port }}
   
   # Application Configuration
   PORT: {{ .Port }}{{ end }}{{ if .EnvVars }}{{ range $key, $value := .EnvVars }}
-  {{ $key }}: {{ $value }}{{ end }}{{ end }}
+  {{ $key }}: {{ yamlQuote $value }}{{ end }}{{ end }}{{ end }}
 
 jobs:
-  # Security and validation job
-  security-checks:{{ if .Security.RequireApproval }}
+{{ block "security-job" . }}  # Security and validation job
+  security-checks:{{ if and .Security.RequireApproval (not .Advanced.Pipeline) }}
     environment: {{ .EnvironmentName }}{{ end }}
     runs-on: ubuntu-latest
     outputs:
@@ -588,17 +788,54 @@ jobs:
         
         echo "deploy=$SHOULD_DEPLOY" >> $GITHUB_OUTPUT
         echo "Deployment decision: $SHOULD_DEPLOY"
-
-  # Main deployment job
-  deploy:
+{{ end }}
+{{ if .BuildJob }}{{ block "build-job" . }}  # Build and push the image once for every matrix entry of the deploy job
+  {{ .BuildJob }}:
     needs: security-checks
-    if: needs.security-checks.outputs.should-deploy == 'true'{{ if .Security.RequireApproval }}
+    if: needs.security-checks.outputs.should-deploy == 'true'
+    runs-on: ubuntu-latest{{ if .Advanced.Timeout }}
+    timeout-minutes: {{ .TimeoutMinutes }}{{ end }}{{ if .BuildJobEnv }}
+    env:{{ range $name, $value := .BuildJobEnv }}
+      {{ $name }}: {{ $value }}{{ end }}{{ end }}
+    
+    permissions:
+      contents: read
+      id-token: write
+    
+    outputs:
+      image-digest: ${{ "{{" }} steps.build.outputs.digest {{ "}}" }}
+      image: ${{ "{{" }} env.REGISTRY {{ "}}" }}/${{ "{{" }} env.IMAGE_NAME {{ "}}" }}
+    
+    steps:
+    - name: Checkout code
+      uses: actions/checkout@v4
+
+    - name: Authenticate to Google Cloud
+      id: auth
+      uses: google-github-actions/auth@v2
+      with:
+        token_format: access_token
+        workload_identity_provider: ${{ "{{" }} env.WORKLOAD_IDENTITY_PROVIDER {{ "}}" }}
+        service_account: ${{ "{{" }} env.SERVICE_ACCOUNT {{ "}}" }}
+        access_token_lifetime: ${{ "{{" }} env.MAX_TOKEN_LIFETIME {{ "}}" }}
+
+{{ template "container-build-steps" . }}{{ end }}{{ end }}{{ if .DeployJobs }}{{ .DeployJobs }}{{ else }}{{ block "deploy-job" . }}  # {{ if .DeployEnvironment }}Deploy to {{ .DeployEnvironment }}{{ else }}Main deployment job{{ end }}
+  {{ .DeployJobID }}:
+    needs: {{ .DeployNeeds }}
+    if: needs.security-checks.outputs.should-deploy == 'true'{{ if .DeployEnvironment }}
+    environment:
+      name: {{ .DeployEnvironment }}{{ if .EnvironmentURL }}
+      url: ${{ "{{" }} steps.deploy.outputs.url {{ "}}" }}{{ end }}{{ else if .Security.RequireApproval }}
     environment: 
       name: ${{ "{{" }} needs.security-checks.outputs.environment {{ "}}" }}{{ if .EnvironmentURL }}
       url: ${{ "{{" }} steps.deploy.outputs.url {{ "}}" }}{{ end }}{{ end }}
     
     runs-on: ubuntu-latest{{ if .Advanced.Timeout }}
-    timeout-minutes: {{ .TimeoutMinutes }}{{ end }}
+    timeout-minutes: {{ .TimeoutMinutes }}{{ end }}{{ if .Strategy }}
+    strategy:
+{{ indent 6 .Strategy }}{{ end }}{{ if .JobEnv }}
+    env:{{ range $name, $value := .JobEnv }}
+      {{ $name }}: {{ $value }}{{ end }}{{ end }}
     
     # Enhanced permissions for Workload Identity Federation
     permissions:
@@ -610,12 +847,13 @@ jobs:
     
     outputs:
       deployment-url: ${{ "{{" }} steps.deploy.outputs.url {{ "}}" }}
-      image-digest: ${{ "{{" }} steps.build.outputs.digest {{ "}}" }}
+      image-digest: {{ .ImageDigest }}{{ if .SupplyChain }}
+      image: ${{ "{{" }} env.REGISTRY {{ "}}" }}/${{ "{{" }} env.IMAGE_NAME {{ "}}" }}{{ end }}
       deployment-id: ${{ "{{" }} steps.deploy.outputs.deployment_id {{ "}}" }}
     
     steps:
     - name: Checkout code
-      uses: actions/checkout@v4
+      uses: actions/checkout@v4{{ .NotifyStart }}
 
     - name: Set up build environment
       run: |
@@ -632,7 +870,7 @@ jobs:
           "build_time": "$(date -u +%Y-%m-%dT%H:%M:%SZ)",
           "workflow_run": "${{ "{{" }} github.run_id {{ "}}" }}",
           "actor": "${{ "{{" }} github.actor {{ "}}" }}",
-          "environment": "${{ "{{" }} needs.security-checks.outputs.environment {{ "}}" }}"
+          "environment": "{{ .EnvironmentRef }}"
         }
         EOF
 
@@ -654,16 +892,16 @@ jobs:
       run: |
         echo "Verifying Google Cloud authentication..."
         gcloud auth list
-        gcloud config list project
+        gcloud config list project{{ if .BuildsContainer }}
         
-        # Verify access to required services
+        # Verify access to required services{{ if eq .DeployTarget "cloud-run" }}
         echo "Verifying access to Cloud Run..."
         gcloud run services list --region=$REGION --limit=1 || echo "No services found (expected for first deployment)"
-        
+        {{ end }}
         echo "Verifying access to Artifact Registry..."
-        gcloud artifacts repositories list --location=$REGION || echo "No repositories found"
+        gcloud artifacts repositories list --location=$REGION || echo "No repositories found"{{ end }}
 
-    # Set up Docker Buildx for advanced features
+{{ if and .BuildsContainer (not .BuildJob) }}{{ block "container-build-steps" . }}    # Set up Docker Buildx for advanced features
     - name: Set up Docker Buildx
       uses: docker/setup-buildx-action@v3
       with:
@@ -702,34 +940,38 @@ jobs:
           org.opencontainers.image.revision=${{ "{{" }} github.sha {{ "}}" }}
           org.opencontainers.image.created=${{ "{{" }} steps.build.outputs.metadata['org.opencontainers.image.created'] {{ "}}" }}
 
-    # Deploy to Cloud Run with comprehensive configuration
-    - name: Deploy to Cloud Run
-      id: deploy
-      uses: google-github-actions/deploy-cloudrun@v2
+{{ with .SupplyChain }}{{ if .SBOM }}    - name: Generate SBOM
+      uses: anchore/sbom-action@v0
       with:
-        service: ${{ "{{" }} env.SERVICE_NAME {{ "}}" }}
-        region: ${{ "{{" }} env.REGION {{ "}}" }}
-        image: ${{ "{{" }} env.REGISTRY {{ "}}" }}/${{ "{{" }} env.IMAGE_NAME {{ "}}" }}:${{ "{{" }} env.IMAGE_TAG {{ "}}" }}{{ if .Port }}
-        port: ${{ "{{" }} env.PORT {{ "}}" }}{{ end }}{{ if .EnvVars }}
-        env_vars: |{{ range $key, $value := .EnvVars }}
-          {{ $key }}={{ $value }}{{ end }}{{ end }}{{ if .Secrets }}
-        secrets: |{{ range $key, $value := .Secrets }}
-          {{ $key }}=${{ "{{" }} secrets.{{ $value }} {{ "}}" }}{{ end }}{{ end }}{{ if .CPULimit }}
-        cpu: {{ .CPULimit }}{{ end }}{{ if .MemoryLimit }}
-        memory: {{ .MemoryLimit }}{{ end }}{{ if .MaxInstances }}
-        max_instances: {{ .MaxInstances }}{{ end }}{{ if .MinInstances }}
-        min_instances: {{ .MinInstances }}{{ end }}
-        flags: |
-          --max-instances={{ .MaxInstances | default 100 }}
-          --min-instances={{ .MinInstances | default 0 }}
-          --concurrency=1000
-          --timeout=300
-          --allow-unauthenticated{{ if .Security.RequireApproval }}
-          --ingress=internal{{ else }}
-          --ingress=all{{ end }}
+        image: ${{ "{{" }} env.REGISTRY {{ "}}" }}/${{ "{{" }} env.IMAGE_NAME {{ "}}" }}@${{ "{{" }} steps.build.outputs.digest {{ "}}" }}
+        format: spdx-json
+        output-file: sbom.spdx.json
+
+{{ end }}{{ if .Sign }}    - name: Install cosign
+      uses: sigstore/cosign-installer@v3
+
+    # Keyless signing with the workflow's OIDC identity
+    - name: Sign container image
+      env:
+        IMAGE: ${{ "{{" }} env.REGISTRY {{ "}}" }}/${{ "{{" }} env.IMAGE_NAME {{ "}}" }}@${{ "{{" }} steps.build.outputs.digest {{ "}}" }}
+      run: |
+        cosign sign --yes "$IMAGE"{{ if .SBOM }}
+        cosign attest --yes --type spdxjson --predicate sbom.spdx.json "$IMAGE"{{ end }}
+
+{{ end }}{{ if .Attestor }}    - name: Create Binary Authorization attestation
+      env:
+        IMAGE: ${{ "{{" }} env.REGISTRY {{ "}}" }}/${{ "{{" }} env.IMAGE_NAME {{ "}}" }}@${{ "{{" }} steps.build.outputs.digest {{ "}}" }}
+      run: |
+        gcloud container binauthz attestations sign-and-create \
+          --artifact-url="$IMAGE" \
+          --attestor="{{ .Attestor }}" \
+          --keyversion="{{ .KeyVersion }}"
+
+{{ end }}{{ end }}{{ end }}{{ end }}{{ block "deploy-steps" . }}{{ end }}
 
     # Health check and verification
-    - name: Verify deployment health
+    - name: Verify deployment health{{ if ne .DeployTarget "cloud-run" }}
+      if: steps.deploy.outputs.url != ''{{ end }}
       run: |
         echo "Verifying deployment health..."
         SERVICE_URL="${{ "{{" }} steps.deploy.outputs.url {{ "}}" }}"
@@ -746,8 +988,8 @@ jobs:
       run: |
         echo "Deployment completed successfully!"
         echo "Service URL: ${{ "{{" }} steps.deploy.outputs.url {{ "}}" }}"
-        echo "Image digest: ${{ "{{" }} steps.build.outputs.digest {{ "}}" }}"
-        echo "Environment: ${{ "{{" }} needs.security-checks.outputs.environment {{ "}}" }}"
+        echo "Image digest: {{ .ImageDigest }}"
+        echo "Environment: {{ .EnvironmentRef }}"
 
     - name: Comment on PR with deployment info
       if: github.event_name == 'pull_request'
@@ -755,8 +997,8 @@ jobs:
       with:
         script: |
           const deploymentUrl = '${{ "{{" }} steps.deploy.outputs.url {{ "}}" }}';
-          const environment = '${{ "{{" }} needs.security-checks.outputs.environment {{ "}}" }}';
-          const imageDigest = '${{ "{{" }} steps.build.outputs.digest {{ "}}" }}';
+          const environment = '{{ .EnvironmentRef }}';
+          const imageDigest = '{{ .ImageDigest }}';
           
           const comment = ` + "`" + `## 🚀 Deployment Status
           
@@ -775,20 +1017,30 @@ jobs:
             body: comment
           });
 
-    - name: Handle deployment failure
+{{ if .Canary }}    - name: Roll back canary traffic
+      if: failure() && steps.deploy.outcome == 'success' && steps.previous.outputs.revision != ''
+      env:
+        PREVIOUS_REVISION: ${{ "{{" }} steps.previous.outputs.revision {{ "}}" }}
+      run: |
+        echo "::warning::Routing all traffic back to $PREVIOUS_REVISION"
+        gcloud run services update-traffic $SERVICE_NAME \
+          --region=$REGION \
+          --to-revisions=$PREVIOUS_REVISION=100
+
+{{ end }}    - name: Handle deployment failure
       if: failure()
       run: |
-        echo "::error::Deployment failed"
+        echo "::error::Deployment failed"{{ if .FailureLogFilter }}
         
         # Get service logs for debugging
-        gcloud logging read "resource.type=cloud_run_revision AND resource.labels.service_name=$SERVICE_NAME" \
+        gcloud logging read "{{ .FailureLogFilter }}" \
           --limit=50 \
           --format="table(timestamp,severity,textPayload)" \
-          --project=$PROJECT_ID || echo "Could not retrieve logs"
-
-  # Cleanup job (runs on failure)
+          --project=$PROJECT_ID || echo "Could not retrieve logs"{{ end }}{{ .NotifySuccess }}{{ .NotifyFailure }}
+{{ end }}{{ end }}
+{{ block "cleanup-job" . }}  # Cleanup job (runs on failure)
   cleanup:
-    needs: [security-checks, deploy]
+    needs: [security-checks, {{ join .DeployJobIDs ", " }}]
     if: failure() && needs.security-checks.outputs.should-deploy == 'true'
     runs-on: ubuntu-latest
     
@@ -811,8 +1063,21 @@ jobs:
         # gcloud run services update-traffic $SERVICE_NAME --to-revisions=PREVIOUS=100 --region=$REGION
         
         echo "Cleanup completed"
-`
-}
+{{ end }}{{ with .SupplyChain }}{{ if .Provenance }}
+  # SLSA provenance for the pushed image, attested by the official generator
+  {{ .ProvenanceJobID }}:
+    needs: [{{ .ProvenanceNeeds }}]
+    if: needs.{{ .ProvenanceNeeds }}.outputs.image-digest != ''
+    permissions:
+      actions: read
+      id-token: write
+    uses: {{ .Generator }}
+    with:
+      image: ${{ "{{" }} needs.{{ .ProvenanceNeeds }}.outputs.image {{ "}}" }}
+      digest: ${{ "{{" }} needs.{{ .ProvenanceNeeds }}.outputs.image-digest {{ "}}" }}
+      gcp-workload-identity-provider: {{ .ProvenanceProvider }}
+      gcp-service-account: {{ .ProvenanceServiceAccount }}
+{{ end }}{{ end }}{{ block "extra-jobs" . }}{{ end }}`
 
 // WriteWorkflowFileOptions defines options for writing workflow files
 type WriteWorkflowFileOptions struct {
@@ -821,6 +1086,10 @@ type WriteWorkflowFileOptions struct {
 	DryRun            bool   `json:"dry_run,omitempty"`
 	Validate          bool   `json:"validate,omitempty"`
 	BackupSuffix      string `json:"backup_suffix,omitempty"`
+	// Merge keeps edits made to an existing workflow file since it was last generated,
+	// three-way merging them into the new content
+	Merge           bool             `json:"merge,omitempty"`
+	ResolveConflict ConflictResolver `json:"-"`
 }
 
 // DefaultWriteOptions returns default options for writing workflow files
@@ -866,11 +1135,33 @@ func (w *WorkflowConfig) WriteWorkflowFileWithOptions(content string, options Wr
 		fileExists = true
 		logger.Debug("Workflow file already exists", "path", filePath)
 
-		if !options.OverwriteExisting && !options.DryRun {
-			return fmt.Errorf("workflow file already exists at %s (use overwrite option to replace)", filePath)
+		if !options.OverwriteExisting && !options.Merge && !options.DryRun {
+			return fmt.Errorf("workflow file already exists at %s (use overwrite option to replace or merge option to keep edits)", filePath)
 		}
 	}
 
+	// Merge edits made since the file was last generated; the generated content is still
+	// recorded as the base of the next merge
+	generated := content
+	var merge MergeResult
+	if options.Merge && fileExists {
+		var err error
+		if merge, err = w.mergeWithWorkflowFile(filePath, generated, options.ResolveConflict); err != nil {
+			return err
+		}
+		content = merge.Content
+		if options.Validate && len(merge.Conflicts) == 0 {
+			if err := w.ValidateWorkflowContent(content); err != nil {
+				return fmt.Errorf("merged workflow content validation failed: %w", err)
+			}
+		}
+		logger.Info("Merged edits into regenerated workflow",
+			"path", filePath,
+			"edits_kept", merge.Merged,
+			"resolved", merge.Resolved,
+			"conflicts", len(merge.Conflicts))
+	}
+
 	// Create backup if requested and file exists
 	if options.CreateBackup && fileExists && !options.DryRun {
 		if err := w.createWorkflowFileBackup(filePath, options.BackupSuffix); err != nil {
@@ -887,7 +1178,8 @@ func (w *WorkflowConfig) WriteWorkflowFileWithOptions(content string, options Wr
 			"path", filePath,
 			"size", len(content),
 			"exists", fileExists,
-			"backup", options.CreateBackup && fileExists)
+			"backup", options.CreateBackup && fileExists,
+			"conflicts", len(merge.Conflicts))
 		return nil
 	}
 
@@ -901,6 +1193,13 @@ func (w *WorkflowConfig) WriteWorkflowFileWithOptions(content string, options Wr
 		"size", len(content),
 		"backup_created", options.CreateBackup && fileExists)
 
+	if err := w.recordWorkflowBase(generated); err != nil {
+		logger.Warn("Failed to record generated workflow, later edits cannot be merged", "error", err)
+	}
+	if len(merge.Conflicts) > 0 {
+		return &WorkflowMergeConflictError{Path: filePath, Conflicts: merge.Conflicts}
+	}
+
 	return nil
 }
 
@@ -927,46 +1226,37 @@ func (w *WorkflowConfig) createWorkflowFileBackup(filePath, suffix string) error
 	return nil
 }
 
-// ValidateWorkflowContent validates the generated workflow content
+// ValidateWorkflowContent parses the generated workflow, validates it against the GitHub
+// Actions workflow schema and checks that it authenticates with Workload Identity Federation.
+// Schema problems are returned as a *WorkflowSchemaError with line and column information.
 func (w *WorkflowConfig) ValidateWorkflowContent(content string) error {
 	// Check if content is not empty
 	if strings.TrimSpace(content) == "" {
 		return fmt.Errorf("workflow content is empty")
 	}
 
-	// Basic YAML structure validation
-	lines := strings.Split(content, "\n")
-	if len(lines) < 10 {
-		return fmt.Errorf("workflow content appears too short (less than 10 lines)")
-	}
-
-	// Check for required sections
-	requiredSections := []string{
-		"name:",
-		"on:",
-		"jobs:",
-	}
-
-	for _, section := range requiredSections {
-		if !strings.Contains(content, section) {
-			return fmt.Errorf("workflow content missing required section: %s", section)
-		}
-	}
-
-	// Check for required WIF elements
-	requiredWifElements := []string{
-		"google-github-actions/auth@v2",
-		"workload_identity_provider",
-		"service_account",
+	workflow, err := ParseWorkflow(content)
+	if err != nil {
+		return err
 	}
 
-	for _, element := range requiredWifElements {
-		if !strings.Contains(content, element) {
-			return fmt.Errorf("workflow content missing required WIF element: %s", element)
+	// Check for the WIF authentication step
+	for _, job := range workflow.Jobs {
+		for _, step := range job.Steps {
+			if !strings.HasPrefix(step.Uses, "google-github-actions/auth@") {
+				continue
+			}
+			for _, input := range []string{"workload_identity_provider", "service_account"} {
+				if step.With[input] == "" {
+					return fmt.Errorf("line %d: authentication step in job %s is missing required WIF input: %s",
+						step.Line, job.ID, input)
+				}
+			}
+			return nil
 		}
 	}
 
-	return nil
+	return fmt.Errorf("workflow content missing required WIF element: google-github-actions/auth step")
 }
 
 // GetWorkflowFileInfo returns information about the workflow file
@@ -1107,10 +1397,10 @@ func (w *WorkflowConfig) ValidateConfig() error {
 	if w.WorkloadIdentityProvider == "" {
 		return errors.NewValidationError("Workflow: Workload identity provider is required", "workflow.workload_identity_provider", "REQUIRED")
 	}
-	if w.ServiceName == "" {
+	if w.ServiceName == "" && !w.IsTerraform() {
 		return errors.NewValidationError("Workflow: Service name is required", "workflow.service_name", "REQUIRED")
 	}
-	if w.Region == "" {
+	if w.Region == "" && !w.IsTerraform() {
 		return errors.NewValidationError("Workflow: Region is required", "workflow.region", "REQUIRED")
 	}
 	if w.Name == "" {
@@ -1123,6 +1413,35 @@ func (w *WorkflowConfig) ValidateConfig() error {
 		return errors.NewValidationError("Workflow: Workflow path is required", "workflow.path", "REQUIRED")
 	}
 
+	if err := w.validateMatrixStrategy(); err != nil {
+		return err
+	}
+	if err := w.ValidateNotificationHooks(); err != nil {
+		return err
+	}
+	if err := w.validatePipeline(); err != nil {
+		return err
+	}
+	if err := w.validateCanary(); err != nil {
+		return err
+	}
+	if err := w.validateSupplyChain(); err != nil {
+		return err
+	}
+	if w.UseActionsVariables && w.IsTerraform() {
+		return errors.NewValidationError("Workflow: Actions variables are not supported for terraform workflows", "workflow.use_actions_variables", "INVALID")
+	}
+
+	// Terraform workflows define their own triggers and do not build or deploy a service
+	switch w.GetKind() {
+	case WorkflowKindDeploy:
+	case WorkflowKindTerraform:
+		return w.validateTerraformConfig()
+	default:
+		return errors.NewValidationError(fmt.Sprintf("Workflow: Unknown workflow kind '%s' (expected %s or %s)", w.Kind, WorkflowKindDeploy, WorkflowKindTerraform),
+			"workflow.kind", "INVALID")
+	}
+
 	// Validate Triggers
 	if !w.Triggers.Push.Enabled && !w.Triggers.PullRequest.Enabled && !w.Triggers.Manual && !w.Triggers.Release && len(w.Triggers.Schedule) == 0 {
 		return errors.NewValidationError("Workflow: At least one trigger (push, pull_request, manual, release, or schedule) must be enabled.", "workflow.triggers", "AT_LEAST_ONE_REQUIRED")
@@ -1135,11 +1454,20 @@ func (w *WorkflowConfig) ValidateConfig() error {
 		// Or ensure it's always set to a default if empty before this validation.
 	}
 
+	// Validate the deployment target and its settings
+	target, err := w.GetDeploymentTarget()
+	if err != nil {
+		return err
+	}
+	if err := target.Validate(w); err != nil {
+		return err
+	}
+
 	// Validate DockerfilePath and BuildContext if Docker build is implied
-	if w.DockerfilePath == "" {
+	if target.BuildsContainer() && w.DockerfilePath == "" {
 		return errors.NewValidationError("Workflow: Dockerfile path is required for building images.", "workflow.dockerfile_path", "REQUIRED")
 	}
-	if w.BuildContext == "" {
+	if target.BuildsContainer() && w.BuildContext == "" {
 		return errors.NewValidationError("Workflow: Build context is required for building images.", "workflow.build_context", "REQUIRED")
 	}
 
@@ -1377,10 +1705,24 @@ func (w *WorkflowConfig) ValidateEnvironments() error {
 			}
 		}
 
+		if len(env.Protection.RequiredReviewers) > MaxEnvironmentReviewers {
+			return fmt.Errorf("environment '%s' has %d required reviewers (GitHub allows at most %d)", envName, len(env.Protection.RequiredReviewers), MaxEnvironmentReviewers)
+		}
+
 		// Validate wait timer
 		if env.Protection.WaitTimer < 0 || env.Protection.WaitTimer > 43200 { // Max 30 days in minutes
 			return fmt.Errorf("environment '%s' has invalid wait timer %d (must be 0-43200 minutes)", envName, env.Protection.WaitTimer)
 		}
+
+		// GitHub accepts either branch policy, not both
+		if env.Protection.ProtectedBranches && len(env.Protection.DeploymentBranches) > 0 {
+			return fmt.Errorf("environment '%s' sets both protected branches and deployment branches (choose one)", envName)
+		}
+		for _, branch := range env.Protection.DeploymentBranches {
+			if strings.TrimSpace(branch) == "" {
+				return fmt.Errorf("environment '%s' has an empty deployment branch", envName)
+			}
+		}
 	}
 	return nil
 }
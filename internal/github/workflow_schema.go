@@ -0,0 +1,844 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParsedWorkflow is a typed view of a GitHub Actions workflow file
+type ParsedWorkflow struct {
+	Name     string   `json:"name,omitempty"`
+	Triggers []string `json:"triggers"`
+	// Inputs maps workflow_dispatch and workflow_call input names to their types
+	Inputs      map[string]string `json:"inputs,omitempty"`
+	Permissions map[string]string `json:"permissions,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Jobs        []*WorkflowJob    `json:"jobs"`
+
+	// Root is the parsed YAML document, kept for callers that need raw access
+	Root *yaml.Node `json:"-"`
+}
+
+// WorkflowJob is a job of a parsed workflow
+type WorkflowJob struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name,omitempty"`
+	RunsOn         []string          `json:"runs_on,omitempty"`
+	Needs          []string          `json:"needs,omitempty"`
+	If             string            `json:"if,omitempty"`
+	Environment    string            `json:"environment,omitempty"`
+	Permissions    map[string]string `json:"permissions,omitempty"`
+	Env            map[string]string `json:"env,omitempty"`
+	Uses           string            `json:"uses,omitempty"` // Reusable workflow reference
+	TimeoutMinutes string            `json:"timeout_minutes,omitempty"`
+	Matrix         []string          `json:"matrix,omitempty"` // Matrix variable names of the job's strategy
+	Steps          []*WorkflowStep   `json:"steps,omitempty"`
+	Line           int               `json:"line"`
+	Column         int               `json:"column"`
+}
+
+// WorkflowStep is a step of a parsed workflow job
+type WorkflowStep struct {
+	ID     string            `json:"id,omitempty"`
+	Name   string            `json:"name,omitempty"`
+	If     string            `json:"if,omitempty"`
+	Uses   string            `json:"uses,omitempty"`
+	Run    string            `json:"run,omitempty"`
+	With   map[string]string `json:"with,omitempty"`
+	Env    map[string]string `json:"env,omitempty"`
+	Line   int               `json:"line"`
+	Column int               `json:"column"`
+}
+
+// Job returns the job with the given ID, or nil
+func (p *ParsedWorkflow) Job(id string) *WorkflowJob {
+	for _, job := range p.Jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// WorkflowIssue is a schema problem found at a position in a workflow file
+type WorkflowIssue struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// String formats the issue as "line L, column C: path: message"
+func (i WorkflowIssue) String() string {
+	location := fmt.Sprintf("line %d", i.Line)
+	if i.Column > 0 {
+		location += fmt.Sprintf(", column %d", i.Column)
+	}
+	if i.Path != "" {
+		return fmt.Sprintf("%s: %s: %s", location, i.Path, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", location, i.Message)
+}
+
+// WorkflowSchemaError reports every schema issue found in a workflow
+type WorkflowSchemaError struct {
+	Issues []WorkflowIssue
+}
+
+// Error implements the error interface
+func (e *WorkflowSchemaError) Error() string {
+	lines := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		lines = append(lines, issue.String())
+	}
+	return fmt.Sprintf("workflow has %d schema error(s):\n  %s", len(e.Issues), strings.Join(lines, "\n  "))
+}
+
+var (
+	workflowIdentifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	yamlErrorLineRegex      = regexp.MustCompile(`line (\d+)`)
+
+	workflowTopLevelKeys = keySet("name", "run-name", "on", "permissions", "env", "defaults", "concurrency", "jobs")
+	workflowJobKeys      = keySet("name", "permissions", "needs", "if", "runs-on", "environment", "concurrency",
+		"outputs", "env", "defaults", "steps", "timeout-minutes", "strategy", "continue-on-error",
+		"container", "services", "uses", "with", "secrets")
+	workflowStepKeys = keySet("id", "if", "name", "uses", "run", "working-directory", "shell", "with", "env",
+		"continue-on-error", "timeout-minutes")
+	workflowEvents = keySet("branch_protection_rule", "check_run", "check_suite", "create", "delete",
+		"deployment", "deployment_status", "discussion", "discussion_comment", "fork", "gollum",
+		"issue_comment", "issues", "label", "merge_group", "milestone", "page_build", "public",
+		"pull_request", "pull_request_review", "pull_request_review_comment", "pull_request_target",
+		"push", "registry_package", "release", "repository_dispatch", "schedule", "status", "watch",
+		"workflow_call", "workflow_dispatch", "workflow_run")
+	workflowPermissionScopes = keySet("actions", "attestations", "checks", "contents", "deployments",
+		"discussions", "id-token", "issues", "models", "packages", "pages", "pull-requests",
+		"repository-projects", "security-events", "statuses")
+)
+
+func keySet(keys ...string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
+
+// ParseWorkflow parses workflow YAML into a typed model and validates it against the GitHub
+// Actions workflow schema. The model is returned even when schema issues are found, in
+// which case the error is a *WorkflowSchemaError. YAML syntax errors return a nil model.
+func ParseWorkflow(content string) (*ParsedWorkflow, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		issue := WorkflowIssue{Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if match := yamlErrorLineRegex.FindStringSubmatch(err.Error()); match != nil {
+			issue.Line, _ = strconv.Atoi(match[1])
+		}
+		return nil, &WorkflowSchemaError{Issues: []WorkflowIssue{issue}}
+	}
+
+	parser := &workflowParser{}
+	workflow := parser.parse(&document)
+
+	if len(parser.issues) > 0 {
+		sort.SliceStable(parser.issues, func(i, j int) bool {
+			if parser.issues[i].Line != parser.issues[j].Line {
+				return parser.issues[i].Line < parser.issues[j].Line
+			}
+			return parser.issues[i].Column < parser.issues[j].Column
+		})
+		return workflow, &WorkflowSchemaError{Issues: parser.issues}
+	}
+	return workflow, nil
+}
+
+// ValidateWorkflowSchema returns the schema issues found in workflow YAML
+func ValidateWorkflowSchema(content string) []WorkflowIssue {
+	if _, err := ParseWorkflow(content); err != nil {
+		if schemaErr, ok := err.(*WorkflowSchemaError); ok {
+			return schemaErr.Issues
+		}
+		return []WorkflowIssue{{Message: err.Error()}}
+	}
+	return nil
+}
+
+// workflowParser walks the YAML node tree, building the model and collecting issues
+type workflowParser struct {
+	issues []WorkflowIssue
+}
+
+func (p *workflowParser) addIssue(node *yaml.Node, path, format string, args ...interface{}) {
+	p.issues = append(p.issues, WorkflowIssue{
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// mapping returns the key/value pairs of a mapping node, reporting duplicate and unknown keys.
+// allowed may be nil to accept any key.
+func (p *workflowParser) mapping(node *yaml.Node, path string, allowed map[string]bool) [][2]*yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		p.addIssue(node, path, "expected a mapping")
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if seen[key.Value] {
+			p.addIssue(key, path, "duplicate key '%s'", key.Value)
+			continue
+		}
+		seen[key.Value] = true
+		if allowed != nil && !allowed[key.Value] {
+			p.addIssue(key, path, "unknown key '%s'", key.Value)
+		}
+		pairs = append(pairs, [2]*yaml.Node{key, value})
+	}
+	return pairs
+}
+
+// stringMap converts a mapping of scalars, validating expressions in the values
+func (p *workflowParser) stringMap(node *yaml.Node, path string) map[string]string {
+	values := make(map[string]string)
+	for _, pair := range p.mapping(node, path, nil) {
+		p.checkExpressions(pair[1], joinWorkflowPath(path, pair[0].Value))
+		values[pair[0].Value] = pair[1].Value
+	}
+	return values
+}
+
+func (p *workflowParser) parse(document *yaml.Node) *ParsedWorkflow {
+	workflow := &ParsedWorkflow{Root: document}
+	if len(document.Content) == 0 {
+		p.addIssue(document, "", "workflow is empty")
+		return workflow
+	}
+	root := document.Content[0]
+
+	var onNode, jobsNode *yaml.Node
+	for _, pair := range p.mapping(root, "", workflowTopLevelKeys) {
+		key, value := pair[0], pair[1]
+		switch key.Value {
+		case "name":
+			workflow.Name = value.Value
+		case "run-name":
+			p.checkExpressions(value, key.Value)
+		case "on":
+			onNode = value
+		case "permissions":
+			workflow.Permissions = p.parsePermissions(value, key.Value)
+		case "env":
+			workflow.Env = p.stringMap(value, key.Value)
+			p.checkMatrixReferences(value, key.Value, nil, true, false)
+		case "concurrency", "defaults":
+			p.checkExpressions(value, key.Value)
+			p.checkMatrixReferences(value, key.Value, nil, true, false)
+		case "jobs":
+			jobsNode = value
+		}
+	}
+
+	if onNode == nil {
+		p.addIssue(root, "", "missing required key 'on'")
+	} else {
+		workflow.Triggers = p.parseTriggers(onNode)
+		workflow.Inputs = workflowInputs(onNode)
+	}
+	if jobsNode == nil {
+		p.addIssue(root, "", "missing required key 'jobs'")
+	} else {
+		workflow.Jobs = p.parseJobs(jobsNode)
+	}
+
+	return workflow
+}
+
+func (p *workflowParser) parseTriggers(node *yaml.Node) []string {
+	var events []*yaml.Node
+	switch node.Kind {
+	case yaml.ScalarNode:
+		events = append(events, node)
+	case yaml.SequenceNode:
+		events = append(events, node.Content...)
+	case yaml.MappingNode:
+		for _, pair := range p.mapping(node, "on", nil) {
+			events = append(events, pair[0])
+			if pair[0].Value == "schedule" {
+				p.checkSchedule(pair[1])
+			}
+		}
+	default:
+		p.addIssue(node, "on", "expected an event name, list or mapping")
+	}
+
+	var triggers []string
+	for _, event := range events {
+		if !workflowEvents[event.Value] {
+			p.addIssue(event, "on", "unknown event '%s'", event.Value)
+			continue
+		}
+		triggers = append(triggers, event.Value)
+	}
+	if len(triggers) == 0 && len(events) == 0 {
+		p.addIssue(node, "on", "at least one event is required")
+	}
+	return triggers
+}
+
+// workflowInputs collects the declared inputs of workflow_dispatch and workflow_call events
+func workflowInputs(onNode *yaml.Node) map[string]string {
+	if onNode.Kind != yaml.MappingNode {
+		return nil
+	}
+	inputs := make(map[string]string)
+	for i := 0; i+1 < len(onNode.Content); i += 2 {
+		event, config := onNode.Content[i].Value, onNode.Content[i+1]
+		if (event != "workflow_dispatch" && event != "workflow_call") || config.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(config.Content); j += 2 {
+			if config.Content[j].Value != "inputs" || config.Content[j+1].Kind != yaml.MappingNode {
+				continue
+			}
+			declared := config.Content[j+1]
+			for k := 0; k+1 < len(declared.Content); k += 2 {
+				inputType := "string"
+				definition := declared.Content[k+1]
+				for m := 0; m+1 < len(definition.Content); m += 2 {
+					if definition.Content[m].Value == "type" {
+						inputType = definition.Content[m+1].Value
+					}
+				}
+				inputs[declared.Content[k].Value] = inputType
+			}
+		}
+	}
+	return inputs
+}
+
+func (p *workflowParser) checkSchedule(node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		p.addIssue(node, "on.schedule", "expected a list of cron entries")
+		return
+	}
+	for i, entry := range node.Content {
+		path := fmt.Sprintf("on.schedule[%d]", i)
+		cron := ""
+		for _, pair := range p.mapping(entry, path, keySet("cron")) {
+			cron = pair[1].Value
+			if len(strings.Fields(cron)) != 5 {
+				p.addIssue(pair[1], path+".cron", "cron expression '%s' must have 5 fields", cron)
+			}
+		}
+		if cron == "" && entry.Kind == yaml.MappingNode {
+			p.addIssue(entry, path, "missing required key 'cron'")
+		}
+	}
+}
+
+func (p *workflowParser) parsePermissions(node *yaml.Node, path string) map[string]string {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value != "read-all" && node.Value != "write-all" {
+			p.addIssue(node, path, "permissions must be 'read-all', 'write-all' or a mapping of scopes")
+		}
+		return map[string]string{"*": node.Value}
+	}
+
+	permissions := make(map[string]string)
+	for _, pair := range p.mapping(node, path, workflowPermissionScopes) {
+		scope, level := pair[0].Value, pair[1].Value
+		switch {
+		case level != "read" && level != "write" && level != "none":
+			p.addIssue(pair[1], joinWorkflowPath(path, scope), "permission level must be read, write or none, got '%s'", level)
+		case scope == "id-token" && level == "read":
+			p.addIssue(pair[1], joinWorkflowPath(path, scope), "id-token permission must be write or none")
+		}
+		permissions[scope] = level
+	}
+	return permissions
+}
+
+func (p *workflowParser) parseJobs(node *yaml.Node) []*WorkflowJob {
+	pairs := p.mapping(node, "jobs", nil)
+	if node.Kind == yaml.MappingNode && len(pairs) == 0 {
+		p.addIssue(node, "jobs", "at least one job is required")
+	}
+
+	jobs := make([]*WorkflowJob, 0, len(pairs))
+	needsNodes := make(map[string]*yaml.Node)
+	for _, pair := range pairs {
+		id := pair[0].Value
+		if !workflowIdentifierRegex.MatchString(id) {
+			p.addIssue(pair[0], "jobs", "invalid job id '%s': must start with a letter or '_' and contain only alphanumerics, '-' or '_'", id)
+		}
+		job, needs := p.parseJob(id, pair[0], pair[1])
+		jobs = append(jobs, job)
+		if needs != nil {
+			needsNodes[id] = needs
+		}
+	}
+
+	p.checkNeeds(jobs, needsNodes)
+	return jobs
+}
+
+func (p *workflowParser) parseJob(id string, keyNode, node *yaml.Node) (*WorkflowJob, *yaml.Node) {
+	path := "jobs." + id
+	job := &WorkflowJob{ID: id, Line: keyNode.Line, Column: keyNode.Column}
+
+	var stepsNode, needsNode, strategyNode *yaml.Node
+	for _, pair := range p.mapping(node, path, workflowJobKeys) {
+		key, value := pair[0], pair[1]
+		keyPath := joinWorkflowPath(path, key.Value)
+		switch key.Value {
+		case "name":
+			job.Name = value.Value
+			p.checkExpressions(value, keyPath)
+		case "runs-on":
+			job.RunsOn = p.scalarList(value, keyPath)
+		case "needs":
+			needsNode = value
+			job.Needs = p.scalarList(value, keyPath)
+		case "if":
+			job.If = value.Value
+			p.checkCondition(value, keyPath)
+		case "environment":
+			if value.Kind == yaml.MappingNode {
+				for _, envPair := range p.mapping(value, keyPath, keySet("name", "url")) {
+					p.checkExpressions(envPair[1], joinWorkflowPath(keyPath, envPair[0].Value))
+					if envPair[0].Value == "name" {
+						job.Environment = envPair[1].Value
+					}
+				}
+			} else {
+				job.Environment = value.Value
+				p.checkExpressions(value, keyPath)
+			}
+		case "permissions":
+			job.Permissions = p.parsePermissions(value, keyPath)
+		case "env":
+			job.Env = p.stringMap(value, keyPath)
+		case "uses":
+			job.Uses = value.Value
+		case "timeout-minutes":
+			job.TimeoutMinutes = value.Value
+			p.checkNumberOrExpression(value, keyPath)
+		case "steps":
+			stepsNode = value
+		case "strategy":
+			strategyNode = value
+		default:
+			p.checkExpressions(value, keyPath)
+		}
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return job, needsNode
+	}
+
+	// Expressions may only reference the variables the job's matrix defines
+	var matrix []string
+	dynamic := false
+	if strategyNode != nil {
+		job.Matrix, dynamic = p.parseStrategy(strategyNode, path+".strategy")
+		matrix = append([]string{}, job.Matrix...)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key != "strategy" {
+			p.checkMatrixReferences(node.Content[i+1], joinWorkflowPath(path, key), matrix, !dynamic, key == "if")
+		}
+	}
+
+	if job.Uses != "" {
+		if stepsNode != nil {
+			p.addIssue(stepsNode, path, "a job calling a reusable workflow cannot define steps")
+		}
+		return job, needsNode
+	}
+	if len(job.RunsOn) == 0 {
+		p.addIssue(keyNode, path, "missing required key 'runs-on'")
+	}
+	if stepsNode == nil {
+		p.addIssue(keyNode, path, "missing required key 'steps'")
+		return job, needsNode
+	}
+	job.Steps = p.parseSteps(stepsNode, path+".steps")
+	return job, needsNode
+}
+
+// parseStrategy validates a job strategy and returns its matrix variables. dynamic is true
+// when the matrix is computed by an expression, so its variables are not known statically.
+func (p *workflowParser) parseStrategy(node *yaml.Node, path string) (keys []string, dynamic bool) {
+	hasMatrix := false
+	for _, pair := range p.mapping(node, path, keySet("matrix", "fail-fast", "max-parallel")) {
+		key, value := pair[0], pair[1]
+		keyPath := joinWorkflowPath(path, key.Value)
+		switch key.Value {
+		case "max-parallel":
+			p.checkNumberOrExpression(value, keyPath)
+		case "fail-fast":
+			p.checkExpressions(value, keyPath)
+		case "matrix":
+			hasMatrix = true
+			p.checkExpressions(value, keyPath)
+			if value.Kind == yaml.ScalarNode && strings.Contains(value.Value, "${{") {
+				return nil, true
+			}
+			for _, entry := range p.mapping(value, keyPath, nil) {
+				name, values := entry[0].Value, entry[1]
+				entryPath := joinWorkflowPath(keyPath, name)
+				if values.Kind == yaml.ScalarNode && strings.Contains(values.Value, "${{") {
+					if name == "include" {
+						dynamic = true
+					} else if name != "exclude" && !containsString(keys, name) {
+						keys = append(keys, name)
+					}
+					continue
+				}
+
+				switch name {
+				case "include", "exclude":
+					if values.Kind != yaml.SequenceNode {
+						p.addIssue(values, entryPath, "expected a list of mappings")
+						continue
+					}
+					for _, item := range values.Content {
+						if item.Kind != yaml.MappingNode {
+							p.addIssue(item, entryPath, "expected a mapping")
+							continue
+						}
+						for i := 0; name == "include" && i+1 < len(item.Content); i += 2 {
+							if !containsString(keys, item.Content[i].Value) {
+								keys = append(keys, item.Content[i].Value)
+							}
+						}
+					}
+				default:
+					if values.Kind != yaml.SequenceNode || len(values.Content) == 0 {
+						p.addIssue(values, entryPath, "matrix variable '%s' must be a non-empty list", name)
+					}
+					if !containsString(keys, name) {
+						keys = append(keys, name)
+					}
+				}
+			}
+		}
+	}
+	if node.Kind == yaml.MappingNode && !hasMatrix {
+		p.addIssue(node, path, "missing required key 'matrix'")
+	}
+	sort.Strings(keys)
+	return keys, dynamic
+}
+
+// checkMatrixReferences reports matrix.<key> references in a node's expressions. A nil
+// matrix means the node is outside a matrix job; checkKeys is false for a matrix computed
+// by an expression, whose keys are not known statically.
+func (p *workflowParser) checkMatrixReferences(node *yaml.Node, path string, matrix []string, checkKeys bool, condition bool) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		for _, key := range matrixReferences(node.Value, condition) {
+			switch {
+			case matrix == nil:
+				p.addIssue(node, path, "matrix.%s is only available in jobs with a matrix strategy", key)
+			case checkKeys && !containsString(matrix, key):
+				p.addIssue(node, path, "matrix.%s is not defined by the job's matrix (defines: %s)", key, strings.Join(matrix, ", "))
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			p.checkMatrixReferences(node.Content[i+1], joinWorkflowPath(path, key), matrix, checkKeys, key == "if")
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			p.checkMatrixReferences(item, fmt.Sprintf("%s[%d]", path, i), matrix, checkKeys, false)
+		}
+	}
+}
+
+func (p *workflowParser) parseSteps(node *yaml.Node, path string) []*WorkflowStep {
+	if node.Kind != yaml.SequenceNode {
+		p.addIssue(node, path, "expected a list of steps")
+		return nil
+	}
+	if len(node.Content) == 0 {
+		p.addIssue(node, path, "at least one step is required")
+	}
+
+	stepIDs := make(map[string]bool)
+	steps := make([]*WorkflowStep, 0, len(node.Content))
+	for i, stepNode := range node.Content {
+		stepPath := fmt.Sprintf("%s[%d]", path, i)
+		step := &WorkflowStep{Line: stepNode.Line, Column: stepNode.Column}
+
+		var withNode *yaml.Node
+		for _, pair := range p.mapping(stepNode, stepPath, workflowStepKeys) {
+			key, value := pair[0], pair[1]
+			keyPath := joinWorkflowPath(stepPath, key.Value)
+			switch key.Value {
+			case "id":
+				step.ID = value.Value
+				if !workflowIdentifierRegex.MatchString(step.ID) {
+					p.addIssue(value, keyPath, "invalid step id '%s'", step.ID)
+				} else if stepIDs[step.ID] {
+					p.addIssue(value, keyPath, "duplicate step id '%s'", step.ID)
+				}
+				stepIDs[step.ID] = true
+			case "name":
+				step.Name = value.Value
+				p.checkExpressions(value, keyPath)
+			case "if":
+				step.If = value.Value
+				p.checkCondition(value, keyPath)
+			case "uses":
+				step.Uses = value.Value
+				p.checkUses(value, keyPath)
+			case "run":
+				step.Run = value.Value
+				p.checkExpressions(value, keyPath)
+			case "with":
+				withNode = value
+				step.With = p.stringMap(value, keyPath)
+			case "env":
+				step.Env = p.stringMap(value, keyPath)
+			case "timeout-minutes":
+				p.checkNumberOrExpression(value, keyPath)
+			default:
+				p.checkExpressions(value, keyPath)
+			}
+		}
+
+		if stepNode.Kind == yaml.MappingNode {
+			switch {
+			case step.Uses != "" && step.Run != "":
+				p.addIssue(stepNode, stepPath, "a step cannot define both 'uses' and 'run'")
+			case step.Uses == "" && step.Run == "":
+				p.addIssue(stepNode, stepPath, "a step must define either 'uses' or 'run'")
+			case step.Run != "" && withNode != nil:
+				p.addIssue(withNode, stepPath, "'with' is only valid for steps that use an action")
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// checkNeeds verifies that needs references exist and do not form cycles
+func (p *workflowParser) checkNeeds(jobs []*WorkflowJob, needsNodes map[string]*yaml.Node) {
+	known := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		known[job.ID] = true
+	}
+
+	graph := make(map[string][]string)
+	for _, job := range jobs {
+		node := needsNodes[job.ID]
+		for _, need := range job.Needs {
+			switch {
+			case need == job.ID:
+				p.addIssue(node, "jobs."+job.ID+".needs", "job cannot depend on itself")
+			case !known[need]:
+				p.addIssue(node, "jobs."+job.ID+".needs", "unknown job '%s'", need)
+			default:
+				graph[job.ID] = append(graph[job.ID], need)
+			}
+		}
+	}
+
+	// Depth-first search for cycles, reporting each cycle once at the job that closes it
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var visit func(id string, trail []string)
+	visit = func(id string, trail []string) {
+		state[id] = visiting
+		trail = append(trail, id)
+		for _, need := range graph[id] {
+			switch state[need] {
+			case visiting:
+				start := 0
+				for i, t := range trail {
+					if t == need {
+						start = i
+					}
+				}
+				cycle := append(append([]string{}, trail[start:]...), need)
+				p.addIssue(needsNodes[id], "jobs."+id+".needs", "dependency cycle: %s", strings.Join(cycle, " -> "))
+			case unvisited:
+				visit(need, trail)
+			}
+		}
+		state[id] = done
+	}
+	for _, job := range jobs {
+		if state[job.ID] == unvisited {
+			visit(job.ID, nil)
+		}
+	}
+}
+
+// scalarList accepts a scalar or a list of scalars
+func (p *workflowParser) scalarList(node *yaml.Node, path string) []string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		p.checkExpressions(node, path)
+		if node.Value == "" {
+			return nil
+		}
+		return []string{node.Value}
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				p.addIssue(item, path, "expected a string")
+				continue
+			}
+			p.checkExpressions(item, path)
+			values = append(values, item.Value)
+		}
+		return values
+	case yaml.MappingNode:
+		// runs-on may select runners by group and labels
+		if strings.HasSuffix(path, "runs-on") {
+			var values []string
+			for _, pair := range p.mapping(node, path, keySet("group", "labels")) {
+				values = append(values, p.scalarList(pair[1], joinWorkflowPath(path, pair[0].Value))...)
+			}
+			return values
+		}
+	}
+	p.addIssue(node, path, "expected a string or a list of strings")
+	return nil
+}
+
+// checkUses validates the form of an action reference
+func (p *workflowParser) checkUses(node *yaml.Node, path string) {
+	uses := node.Value
+	switch {
+	case strings.HasPrefix(uses, "./"), strings.HasPrefix(uses, "docker://"):
+		return
+	case !strings.Contains(uses, "@"):
+		p.addIssue(node, path, "action reference '%s' must include a version (owner/repo@ref)", uses)
+	case strings.Count(strings.SplitN(uses, "@", 2)[0], "/") < 1:
+		p.addIssue(node, path, "action reference '%s' must be owner/repo[/path]@ref", uses)
+	}
+}
+
+func (p *workflowParser) checkNumberOrExpression(node *yaml.Node, path string) {
+	if strings.Contains(node.Value, "${{") {
+		p.checkExpressions(node, path)
+		return
+	}
+	if _, err := strconv.ParseFloat(node.Value, 64); err != nil {
+		p.addIssue(node, path, "expected a number, got '%s'", node.Value)
+	}
+}
+
+// checkCondition validates an if condition, which is an expression with or without ${{ }}
+func (p *workflowParser) checkCondition(node *yaml.Node, path string) {
+	if strings.Contains(node.Value, "${{") {
+		p.checkExpressions(node, path)
+		return
+	}
+	if strings.TrimSpace(node.Value) == "" {
+		p.addIssue(node, path, "condition is empty")
+		return
+	}
+	if err := checkExpressionSyntax(node.Value); err != nil {
+		p.addIssue(node, path, "invalid expression: %v", err)
+	}
+}
+
+// checkExpressions validates every ${{ }} expression in a node and its children
+func (p *workflowParser) checkExpressions(node *yaml.Node, path string) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		rest := node.Value
+		for {
+			start := strings.Index(rest, "${{")
+			if start < 0 {
+				return
+			}
+			end := strings.Index(rest[start:], "}}")
+			if end < 0 {
+				p.addIssue(node, path, "unterminated expression: missing '}}'")
+				return
+			}
+			expression := rest[start+3 : start+end]
+			if strings.TrimSpace(expression) == "" {
+				p.addIssue(node, path, "empty expression '${{ }}'")
+			} else if err := checkExpressionSyntax(expression); err != nil {
+				p.addIssue(node, path, "invalid expression '${{%s}}': %v", expression, err)
+			}
+			rest = rest[start+end+2:]
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			p.checkExpressions(node.Content[i+1], joinWorkflowPath(path, node.Content[i].Value))
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			p.checkExpressions(item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// checkExpressionSyntax checks that string literals are closed and brackets are balanced
+func checkExpressionSyntax(expression string) error {
+	var stack []rune
+	closing := map[rune]rune{')': '(', ']': '['}
+	runes := []rune(expression)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\'':
+			// String literals use single quotes; '' escapes a quote
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					closed = true
+					break
+				}
+			}
+			if !closed {
+				return fmt.Errorf("unterminated string literal")
+			}
+		case '"':
+			return fmt.Errorf("string literals must use single quotes")
+		case '(', '[':
+			stack = append(stack, r)
+		case ')', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != closing[r] {
+				return fmt.Errorf("unbalanced '%c'", r)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("unclosed '%c'", stack[len(stack)-1])
+	}
+	return nil
+}
+
+func joinWorkflowPath(base, key string) string {
+	if base == "" {
+		return key
+	}
+	return base + "." + key
+}
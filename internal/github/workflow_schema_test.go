@@ -0,0 +1,210 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func testWorkflowConfig(base *WorkflowConfig) *WorkflowConfig {
+	base.ProjectID = "my-project"
+	base.ServiceAccountEmail = "deployer@my-project.iam.gserviceaccount.com"
+	base.WorkloadIdentityProvider = "projects/123/locations/global/workloadIdentityPools/github/providers/github"
+	base.ServiceName = "my-service"
+	base.Region = "us-central1"
+	base.Repository = "myorg/myrepo"
+	base.EnvVars = map[string]string{"DATABASE_URL": "postgres://db: 5432", "GREETING": `say "hi"`}
+	return base
+}
+
+func TestGeneratedWorkflowsPassSchema(t *testing.T) {
+	configs := map[string]*WorkflowConfig{
+		"default":     DefaultWorkflowConfig(),
+		"production":  DefaultProductionWorkflowConfig(),
+		"staging":     DefaultStagingWorkflowConfig(),
+		"development": DefaultDevelopmentWorkflowConfig(),
+	}
+
+	for name, base := range configs {
+		cfg := testWorkflowConfig(base)
+		content, err := cfg.GenerateWorkflow()
+		if err != nil {
+			t.Fatalf("%s: failed to generate workflow: %v", name, err)
+		}
+		if err := cfg.ValidateWorkflowContent(content); err != nil {
+			t.Errorf("%s: generated workflow failed validation: %v", name, err)
+		}
+	}
+}
+
+func TestParseWorkflow(t *testing.T) {
+	workflow, err := ParseWorkflow(`name: Deploy
+on: [push, workflow_dispatch]
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+  deploy:
+    needs: build
+    runs-on: [self-hosted, linux]
+    permissions:
+      id-token: write
+    steps:
+      - id: auth
+        uses: google-github-actions/auth@v2
+        with:
+          workload_identity_provider: ${{ vars.PROVIDER }}
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(workflow.Triggers) != 2 || len(workflow.Jobs) != 2 {
+		t.Fatalf("Unexpected workflow: %+v", workflow)
+	}
+	deploy := workflow.Job("deploy")
+	if deploy == nil || deploy.Needs[0] != "build" || len(deploy.RunsOn) != 2 || deploy.Permissions["id-token"] != "write" {
+		t.Fatalf("Unexpected deploy job: %+v", deploy)
+	}
+	if step := deploy.Steps[0]; step.ID != "auth" || step.Line != 14 || step.With["workload_identity_provider"] != "${{ vars.PROVIDER }}" {
+		t.Errorf("Unexpected step: %+v", step)
+	}
+}
+
+func TestParseWorkflowIssues(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{
+			name: "unknown needs",
+			content: `on: push
+jobs:
+  deploy:
+    needs: [build]
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hi
+`,
+			line: 4, column: 12, message: "unknown job 'build'",
+		},
+		{
+			name: "uses and run",
+			content: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        run: echo hi
+`,
+			line: 6, column: 9, message: "both 'uses' and 'run'",
+		},
+		{
+			name: "unterminated expression",
+			content: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ github.sha }
+`,
+			line: 6, column: 14, message: "unterminated expression",
+		},
+		{
+			name: "bad indentation",
+			content: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+     steps:
+      - run: echo hi
+`,
+			line: 5, message: "mapping values are not allowed",
+		},
+		{
+			name: "dependency cycle",
+			content: `on: push
+jobs:
+  a:
+    needs: b
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo a
+  b:
+    needs: a
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo b
+`,
+			line: 9, column: 12, message: "dependency cycle: a -> b -> a",
+		},
+		{
+			name: "invalid job id",
+			content: `on: push
+jobs:
+  1build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hi
+`,
+			line: 3, column: 3, message: "invalid job id '1build'",
+		},
+		{
+			name: "missing version",
+			content: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout
+`,
+			line: 6, column: 15, message: "must include a version",
+		},
+	}
+
+	for _, test := range tests {
+		issues := ValidateWorkflowSchema(test.content)
+		found := false
+		for _, issue := range issues {
+			if strings.Contains(issue.Message, test.message) {
+				found = true
+				if issue.Line != test.line || (test.column != 0 && issue.Column != test.column) {
+					t.Errorf("%s: expected issue at %d:%d, got %s", test.name, test.line, test.column, issue)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected issue containing %q, got %v", test.name, test.message, issues)
+		}
+	}
+}
+
+func TestCheckExpressionSyntax(t *testing.T) {
+	valid := []string{
+		"github.ref == 'refs/heads/main'",
+		"contains(github.event.head_commit.message, '[skip ci]')",
+		"format('it''s {0}', github.actor)",
+		"steps.build.outputs.metadata['org.opencontainers.image.created']",
+	}
+	for _, expression := range valid {
+		if err := checkExpressionSyntax(expression); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", expression, err)
+		}
+	}
+
+	invalid := []string{
+		"contains(github.ref, 'main'",
+		"github.ref == 'main",
+		`github.ref == "main"`,
+		"fromJSON(x))",
+	}
+	for _, expression := range invalid {
+		if err := checkExpressionSyntax(expression); err == nil {
+			t.Errorf("Expected %q to be invalid", expression)
+		}
+	}
+}
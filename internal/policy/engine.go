@@ -0,0 +1,435 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/config"
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	keyReference  = "$key"
+	rootReference = "$root."
+)
+
+// Documents are the inputs rules are evaluated against, as generic JSON-like values
+type Documents struct {
+	Config   interface{}
+	Workflow interface{}
+}
+
+// Violation is a rule that did not hold for a resource
+type Violation struct {
+	RuleID      string  `json:"rule_id"`
+	Policy      string  `json:"policy"`
+	Severity    string  `json:"severity"`
+	Target      string  `json:"target"`
+	Resource    string  `json:"resource,omitempty"`
+	Message     string  `json:"message"`
+	Remediation string  `json:"remediation,omitempty"`
+	Waived      bool    `json:"waived"`
+	Waiver      *Waiver `json:"waiver,omitempty"`
+}
+
+// Result is the outcome of evaluating a policy set
+type Result struct {
+	RulesEvaluated int         `json:"rules_evaluated"`
+	Violations     []Violation `json:"violations"`
+	// ExpiredWaivers no longer suppress violations and should be renewed or removed
+	ExpiredWaivers []Waiver `json:"expired_waivers,omitempty"`
+	// Skipped lists rules that could not be evaluated, e.g. workflow rules without a workflow
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Active returns violations that are not covered by a waiver
+func (r *Result) Active() []Violation {
+	var active []Violation
+	for _, violation := range r.Violations {
+		if !violation.Waived {
+			active = append(active, violation)
+		}
+	}
+	return active
+}
+
+// Waived returns violations suppressed by a waiver
+func (r *Result) Waived() []Violation {
+	var waived []Violation
+	for _, violation := range r.Violations {
+		if violation.Waived {
+			waived = append(waived, violation)
+		}
+	}
+	return waived
+}
+
+// HasErrors reports whether any unwaived error-severity violation was found
+func (r *Result) HasErrors() bool {
+	for _, violation := range r.Active() {
+		if violation.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigDocument converts the configuration to the generic form addressed by rule paths,
+// using the configuration's JSON field names
+func ConfigDocument(cfg *config.Config) (interface{}, error) {
+	return normalize(cfg)
+}
+
+// WorkflowDocument parses workflow YAML into the generic form addressed by rule paths
+func WorkflowDocument(content string) (interface{}, error) {
+	var document interface{}
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeValidation, "POLICY_WORKFLOW_PARSE_FAILED",
+			"Failed to parse workflow for policy evaluation")
+	}
+	return normalize(document)
+}
+
+// EvaluateConfig evaluates the policy set against a configuration and, when workflow rules
+// are present, against the workflow generated from it
+func (s *PolicySet) EvaluateConfig(cfg *config.Config, now time.Time) (*Result, error) {
+	configDocument, err := ConfigDocument(cfg)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeInternal, "POLICY_CONFIG_CONVERT_FAILED",
+			"Failed to prepare configuration for policy evaluation")
+	}
+	documents := Documents{Config: configDocument}
+
+	if s.hasTarget(TargetWorkflow) {
+		content, err := cfg.Workflow.GenerateWorkflow()
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrorTypeValidation, "POLICY_WORKFLOW_GENERATE_FAILED",
+				"Failed to generate workflow for policy evaluation")
+		}
+		if documents.Workflow, err = WorkflowDocument(content); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Evaluate(documents, now), nil
+}
+
+// Evaluate checks every rule against the documents and applies unexpired waivers
+func (s *PolicySet) Evaluate(documents Documents, now time.Time) *Result {
+	logger := logging.WithField("function", "Evaluate")
+	result := &Result{}
+
+	for _, policy := range s.Policies {
+		for _, rule := range policy.Rules {
+			document := documents.Config
+			if rule.Target == TargetWorkflow {
+				document = documents.Workflow
+			}
+			if document == nil {
+				result.Skipped = append(result.Skipped, rule.ID)
+				continue
+			}
+
+			result.RulesEvaluated++
+			for _, violation := range evaluateRule(rule, document) {
+				violation.Policy = policy.Name
+				result.Violations = append(result.Violations, violation)
+			}
+		}
+	}
+
+	expired := make(map[string]bool)
+	for i := range result.Violations {
+		violation := &result.Violations[i]
+		for _, policy := range s.Policies {
+			for _, waiver := range policy.Waivers {
+				if waiver.Rule != violation.RuleID || (waiver.Resource != "" && waiver.Resource != violation.Resource) {
+					continue
+				}
+				if !now.Before(waiver.expiresAt) {
+					key := waiver.Rule + "|" + waiver.Resource
+					if !expired[key] {
+						expired[key] = true
+						result.ExpiredWaivers = append(result.ExpiredWaivers, waiver)
+					}
+					continue
+				}
+				waiver := waiver
+				violation.Waived = true
+				violation.Waiver = &waiver
+			}
+		}
+	}
+
+	logger.Debug("Policies evaluated",
+		"rules", result.RulesEvaluated,
+		"violations", len(result.Violations),
+		"skipped", len(result.Skipped))
+	return result
+}
+
+// hasTarget reports whether any rule is evaluated against the target
+func (s *PolicySet) hasTarget(target string) bool {
+	for _, policy := range s.Policies {
+		for _, rule := range policy.Rules {
+			if rule.Target == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// match is a value selected by a path together with its concrete location
+type match struct {
+	path  string
+	key   string
+	value interface{}
+}
+
+// evaluateRule returns a violation for every subject that satisfies the rule's when
+// conditions but not its require conditions
+func evaluateRule(rule Rule, document interface{}) []Violation {
+	subjects := []match{{value: document}}
+	if rule.ForEach != "" {
+		subjects = selectPath(document, rule.ForEach)
+	}
+
+	var violations []Violation
+	for _, subject := range subjects {
+		applies := true
+		for _, condition := range rule.When {
+			if !condition.holds(document, subject) {
+				applies = false
+				break
+			}
+		}
+		if !applies {
+			continue
+		}
+
+		for _, condition := range rule.Require {
+			if condition.holds(document, subject) {
+				continue
+			}
+			message := rule.Message
+			if message == "" {
+				message = fmt.Sprintf("%s %s", condition.Path, condition.Op)
+				if condition.Value != nil {
+					message += fmt.Sprintf(" %v", condition.Value)
+				}
+				message += " is required"
+			}
+			violations = append(violations, Violation{
+				RuleID:      rule.ID,
+				Severity:    rule.Severity,
+				Target:      rule.Target,
+				Resource:    subject.path,
+				Message:     strings.ReplaceAll(message, keyReference, subject.key),
+				Remediation: rule.Remediation,
+			})
+			break
+		}
+	}
+	return violations
+}
+
+// holds evaluates the condition for a subject. Positive operators require at least one
+// selected value; negated operators also hold when nothing is selected.
+func (c Condition) holds(document interface{}, subject match) bool {
+	var values []interface{}
+	switch {
+	case c.Path == keyReference:
+		values = []interface{}{subject.key}
+	case strings.HasPrefix(c.Path, rootReference):
+		for _, m := range selectPath(document, strings.TrimPrefix(c.Path, rootReference)) {
+			values = append(values, m.value)
+		}
+	default:
+		for _, m := range selectPath(subject.value, c.Path) {
+			values = append(values, m.value)
+		}
+	}
+
+	expected, _ := normalize(c.Value)
+
+	switch c.Op {
+	case OpExists:
+		return countNonNil(values) > 0
+	case OpNotExists:
+		return countNonNil(values) == 0
+	case OpEmpty:
+		return all(values, isEmpty)
+	case OpNotEmpty:
+		return len(values) > 0 && all(values, func(v interface{}) bool { return !isEmpty(v) })
+	case OpNotEquals:
+		return all(values, func(v interface{}) bool { return !reflect.DeepEqual(v, expected) })
+	case OpNotIn:
+		return all(values, func(v interface{}) bool { return !contains(expected, v) })
+	case OpNotContains:
+		return all(values, func(v interface{}) bool { return !contains(v, expected) })
+	}
+
+	if len(values) == 0 {
+		return false
+	}
+	return all(values, func(v interface{}) bool {
+		switch c.Op {
+		case OpEquals:
+			return reflect.DeepEqual(v, expected)
+		case OpIn:
+			return contains(expected, v)
+		case OpContains:
+			return contains(v, expected)
+		case OpMatches:
+			s, ok := v.(string)
+			return ok && c.pattern.MatchString(s)
+		case OpGreaterThan, OpGreaterOrEq, OpLessThan, OpLessOrEq:
+			return compareNumbers(c.Op, v, expected)
+		}
+		return false
+	})
+}
+
+// selectPath returns the values addressed by a dot-separated path, expanding "*" segments
+func selectPath(root interface{}, path string) []match {
+	matches := []match{{value: root}}
+	if path == "" {
+		return matches
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		var next []match
+		for _, current := range matches {
+			switch node := current.value.(type) {
+			case map[string]interface{}:
+				if segment == "*" {
+					keys := make([]string, 0, len(node))
+					for key := range node {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, match{path: joinPath(current.path, key), key: key, value: node[key]})
+					}
+				} else if value, ok := node[segment]; ok {
+					next = append(next, match{path: joinPath(current.path, segment), key: segment, value: value})
+				}
+			case []interface{}:
+				if segment == "*" {
+					for i, value := range node {
+						key := strconv.Itoa(i)
+						next = append(next, match{path: joinPath(current.path, key), key: key, value: value})
+					}
+				} else if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(node) {
+					next = append(next, match{path: joinPath(current.path, segment), key: segment, value: node[i]})
+				}
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+func joinPath(base, segment string) string {
+	if base == "" {
+		return segment
+	}
+	return base + "." + segment
+}
+
+// normalize converts a value to the types produced by encoding/json so that values from
+// YAML policies, YAML workflows and Go structs compare equal
+func normalize(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+func all(values []interface{}, predicate func(interface{}) bool) bool {
+	for _, value := range values {
+		if !predicate(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func countNonNil(values []interface{}) int {
+	count := 0
+	for _, value := range values {
+		if value != nil {
+			count++
+		}
+	}
+	return count
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// contains reports whether a list holds an element equal to item, or a string holds item
+// as a substring
+func contains(collection, item interface{}) bool {
+	switch c := collection.(type) {
+	case []interface{}:
+		for _, element := range c {
+			if reflect.DeepEqual(element, item) {
+				return true
+			}
+		}
+	case string:
+		s, ok := item.(string)
+		return ok && strings.Contains(c, s)
+	}
+	return false
+}
+
+func compareNumbers(op string, actual, expected interface{}) bool {
+	a, ok := actual.(float64)
+	if !ok {
+		return false
+	}
+	b, ok := expected.(float64)
+	if !ok {
+		return false
+	}
+	switch op {
+	case OpGreaterThan:
+		return a > b
+	case OpGreaterOrEq:
+		return a >= b
+	case OpLessThan:
+		return a < b
+	case OpLessOrEq:
+		return a <= b
+	}
+	return false
+}
@@ -0,0 +1,304 @@
+// Package policy provides a declarative rules engine that evaluates organization
+// policy files against the WIF configuration and the generated workflow.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/errors"
+	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPolicyDir is searched for policy files when none are specified explicitly
+const DefaultPolicyDir = ".gcp-wif/policies"
+
+// Severity levels of policy rules
+const (
+	SeverityError   = "error"   // Fails validation
+	SeverityWarning = "warning" // Reported but does not fail validation
+	SeverityInfo    = "info"    // Informational
+)
+
+// Targets that rules can be evaluated against
+const (
+	TargetConfig   = "config"   // The configuration, addressed by its JSON field names
+	TargetWorkflow = "workflow" // The generated GitHub Actions workflow YAML
+)
+
+// Comparison operators supported in rule conditions
+const (
+	OpEquals      = "equals"
+	OpNotEquals   = "not_equals"
+	OpExists      = "exists"
+	OpNotExists   = "not_exists"
+	OpEmpty       = "empty"
+	OpNotEmpty    = "not_empty"
+	OpIn          = "in"
+	OpNotIn       = "not_in"
+	OpContains    = "contains"
+	OpNotContains = "not_contains"
+	OpMatches     = "matches"
+	OpGreaterThan = "gt"
+	OpGreaterOrEq = "gte"
+	OpLessThan    = "lt"
+	OpLessOrEq    = "lte"
+)
+
+var validOperators = map[string]bool{
+	OpEquals: true, OpNotEquals: true, OpExists: true, OpNotExists: true,
+	OpEmpty: true, OpNotEmpty: true, OpIn: true, OpNotIn: true,
+	OpContains: true, OpNotContains: true, OpMatches: true,
+	OpGreaterThan: true, OpGreaterOrEq: true, OpLessThan: true, OpLessOrEq: true,
+}
+
+// Policy is a single organization policy file
+type Policy struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Rules       []Rule   `json:"rules" yaml:"rules"`
+	Waivers     []Waiver `json:"waivers,omitempty" yaml:"waivers,omitempty"`
+
+	// Source is the file the policy was loaded from
+	Source string `json:"-" yaml:"-"`
+}
+
+// Rule is a declarative check. When ForEach is set the rule is evaluated once per element
+// selected by that path and condition paths are relative to the element; "$key" refers to
+// the element's map key or index and a "$root." prefix addresses the whole document.
+type Rule struct {
+	ID          string      `json:"id" yaml:"id"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Severity    string      `json:"severity" yaml:"severity"`
+	Target      string      `json:"target,omitempty" yaml:"target,omitempty"`
+	ForEach     string      `json:"for_each,omitempty" yaml:"for_each,omitempty"`
+	When        []Condition `json:"when,omitempty" yaml:"when,omitempty"`
+	Require     []Condition `json:"require" yaml:"require"`
+	Message     string      `json:"message,omitempty" yaml:"message,omitempty"`
+	Remediation string      `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+}
+
+// Condition compares the values selected by a dot-separated path. Path segments may be
+// "*" to select every map value or list element.
+type Condition struct {
+	Path  string      `json:"path" yaml:"path"`
+	Op    string      `json:"op" yaml:"op"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Waiver suppresses violations of a rule until it expires. Resource optionally limits the
+// waiver to one ForEach element, e.g. "environments.production".
+type Waiver struct {
+	Rule          string `json:"rule" yaml:"rule"`
+	Resource      string `json:"resource,omitempty" yaml:"resource,omitempty"`
+	Justification string `json:"justification" yaml:"justification"`
+	Expires       string `json:"expires" yaml:"expires"`
+	ApprovedBy    string `json:"approved_by,omitempty" yaml:"approved_by,omitempty"`
+
+	expiresAt time.Time
+}
+
+// ExpiresAt returns the parsed expiration time of the waiver
+func (w Waiver) ExpiresAt() time.Time {
+	return w.expiresAt
+}
+
+// PolicySet is the combination of all loaded policies
+type PolicySet struct {
+	Policies []*Policy
+}
+
+// LoadPolicyFile loads and validates a policy file. The format is chosen by extension:
+// .json files are parsed as JSON, everything else as YAML.
+func LoadPolicyFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "POLICY_READ_FAILED",
+			fmt.Sprintf("Failed to read policy file: %s", path))
+	}
+
+	policy := &Policy{Source: path}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, policy)
+	} else {
+		err = yaml.Unmarshal(data, policy)
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, errors.ErrorTypeConfiguration, "POLICY_PARSE_FAILED",
+			fmt.Sprintf("Failed to parse policy file: %s", path))
+	}
+
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// LoadPolicies loads policy files. Directories are expanded to the .yaml, .yml and .json
+// files they contain. Rule IDs must be unique across all policies.
+func LoadPolicies(paths []string) (*PolicySet, error) {
+	logger := logging.WithField("function", "LoadPolicies")
+
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "POLICY_NOT_FOUND",
+				fmt.Sprintf("Policy path not found: %s", path))
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, errors.WrapError(err, errors.ErrorTypeFileSystem, "POLICY_DIR_READ_FAILED",
+				fmt.Sprintf("Failed to read policy directory: %s", path))
+		}
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+	}
+	sort.Strings(files)
+
+	set := &PolicySet{}
+	ruleSources := make(map[string]string)
+	for _, file := range files {
+		policy, err := LoadPolicyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range policy.Rules {
+			if source, ok := ruleSources[rule.ID]; ok {
+				return nil, errors.NewConfigurationError(
+					fmt.Sprintf("Duplicate policy rule ID '%s' in %s", rule.ID, file),
+					fmt.Sprintf("The rule is already defined in %s", source),
+					"Rule IDs must be unique across all policy files")
+			}
+			ruleSources[rule.ID] = file
+		}
+		set.Policies = append(set.Policies, policy)
+	}
+
+	logger.Debug("Policies loaded", "files", len(files))
+	return set, nil
+}
+
+// DiscoverPolicyPaths returns the explicitly requested policy paths, or the default policy
+// directory when it exists and none were requested
+func DiscoverPolicyPaths(paths []string) []string {
+	if len(paths) > 0 {
+		return paths
+	}
+	if info, err := os.Stat(DefaultPolicyDir); err == nil && info.IsDir() {
+		return []string{DefaultPolicyDir}
+	}
+	return nil
+}
+
+// RuleCount returns the number of rules across all policies
+func (s *PolicySet) RuleCount() int {
+	count := 0
+	for _, policy := range s.Policies {
+		count += len(policy.Rules)
+	}
+	return count
+}
+
+// validate checks rule and waiver definitions and prepares them for evaluation
+func (p *Policy) validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return errors.NewConfigurationError(
+			fmt.Sprintf("Invalid policy %s: %s", p.Source, fmt.Sprintf(format, args...)))
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.ID == "" {
+			return invalid("rule %d has no id", i+1)
+		}
+		if rule.Severity == "" {
+			rule.Severity = SeverityError
+		}
+		if rule.Severity != SeverityError && rule.Severity != SeverityWarning && rule.Severity != SeverityInfo {
+			return invalid("rule %s has unknown severity '%s' (use error, warning or info)", rule.ID, rule.Severity)
+		}
+		if rule.Target == "" {
+			rule.Target = TargetConfig
+		}
+		if rule.Target != TargetConfig && rule.Target != TargetWorkflow {
+			return invalid("rule %s has unknown target '%s' (use config or workflow)", rule.ID, rule.Target)
+		}
+		if len(rule.Require) == 0 {
+			return invalid("rule %s has no require conditions", rule.ID)
+		}
+		for _, conditions := range [][]Condition{rule.When, rule.Require} {
+			for j := range conditions {
+				if err := conditions[j].prepare(); err != nil {
+					return invalid("rule %s: %v", rule.ID, err)
+				}
+			}
+		}
+	}
+
+	for i := range p.Waivers {
+		waiver := &p.Waivers[i]
+		if waiver.Rule == "" {
+			return invalid("waiver %d has no rule", i+1)
+		}
+		if strings.TrimSpace(waiver.Justification) == "" {
+			return invalid("waiver for %s has no justification", waiver.Rule)
+		}
+		expiresAt, err := parseWaiverExpiry(waiver.Expires)
+		if err != nil {
+			return invalid("waiver for %s has invalid expires '%s' (use YYYY-MM-DD or RFC3339)", waiver.Rule, waiver.Expires)
+		}
+		waiver.expiresAt = expiresAt
+	}
+
+	return nil
+}
+
+// prepare validates the condition and compiles its pattern
+func (c *Condition) prepare() error {
+	if c.Path == "" {
+		return fmt.Errorf("condition has no path")
+	}
+	if !validOperators[c.Op] {
+		return fmt.Errorf("condition on %s has unknown operator '%s'", c.Path, c.Op)
+	}
+	if c.Op == OpMatches {
+		pattern, ok := c.Value.(string)
+		if !ok {
+			return fmt.Errorf("matches condition on %s requires a string pattern", c.Path)
+		}
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern for %s: %v", c.Path, err)
+		}
+		c.pattern = compiled
+	}
+	return nil
+}
+
+// parseWaiverExpiry accepts a date (valid through the end of that day, UTC) or an RFC3339 time
+func parseWaiverExpiry(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
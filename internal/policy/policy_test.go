@@ -0,0 +1,203 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Fordjour12/gcp-wif/internal/config"
+)
+
+const productionPolicy = `
+name: org-baseline
+rules:
+  - id: prod-approval
+    description: Production environments require approval and may only deploy from main
+    severity: error
+    for_each: environments.*
+    when:
+      - path: type
+        op: equals
+        value: production
+    require:
+      - path: security.require_approval
+        op: equals
+        value: true
+      - path: security.restrict_branches
+        op: equals
+        value: [main]
+    message: "Environment $key must require approval and restrict deployments to main"
+  - id: region
+    severity: warning
+    require:
+      - path: project.region
+        op: in
+        value: [us-central1, europe-west1]
+waivers:
+  - rule: prod-approval
+    resource: environments.prod-eu
+    justification: Migration tracked in OPS-42
+    expires: 2025-07-01
+`
+
+func writePolicy(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	return path
+}
+
+func TestEvaluateConfig(t *testing.T) {
+	set, err := LoadPolicies([]string{writePolicy(t, "baseline.yaml", productionPolicy)})
+	if err != nil {
+		t.Fatalf("Failed to load policies: %v", err)
+	}
+
+	cfg := &config.Config{
+		Project: config.ProjectConfig{ID: "my-project", Region: "asia-east1"},
+		Environments: map[string]config.EnvironmentConfig{
+			"prod": {Type: "production", Security: config.EnvSecurityConfig{
+				RequireApproval: true, RestrictBranches: []string{"main"}}},
+			"prod-us": {Type: "production", Security: config.EnvSecurityConfig{
+				RestrictBranches: []string{"main"}}},
+			"prod-eu": {Type: "production"},
+			"dev":     {Type: "development"},
+		},
+	}
+
+	result, err := set.EvaluateConfig(cfg, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to evaluate policies: %v", err)
+	}
+
+	if result.RulesEvaluated != 2 {
+		t.Errorf("Expected 2 rules evaluated, got %d", result.RulesEvaluated)
+	}
+	if len(result.Violations) != 3 {
+		t.Fatalf("Expected 3 violations, got %+v", result.Violations)
+	}
+
+	active := result.Active()
+	if len(active) != 2 || active[0].Resource != "environments.prod-us" || active[1].RuleID != "region" {
+		t.Errorf("Unexpected active violations: %+v", active)
+	}
+	if active[0].Message != "Environment prod-us must require approval and restrict deployments to main" {
+		t.Errorf("Unexpected message: %s", active[0].Message)
+	}
+	if waived := result.Waived(); len(waived) != 1 || waived[0].Resource != "environments.prod-eu" {
+		t.Errorf("Expected prod-eu to be waived, got %+v", waived)
+	}
+	if !result.HasErrors() {
+		t.Error("Expected error-severity violations")
+	}
+
+	// Once the waiver expires the violation is reported again
+	result, err = set.EvaluateConfig(cfg, time.Date(2025, 7, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Failed to evaluate policies: %v", err)
+	}
+	if len(result.Waived()) != 0 || len(result.ExpiredWaivers) != 1 {
+		t.Errorf("Expected the waiver to be expired, got waived=%d expired=%d",
+			len(result.Waived()), len(result.ExpiredWaivers))
+	}
+}
+
+func TestEvaluateWorkflowDocument(t *testing.T) {
+	policy := writePolicy(t, "workflow.json", `{
+  "name": "workflow",
+  "rules": [{
+    "id": "pinned-checkout",
+    "target": "workflow",
+    "for_each": "jobs.*.steps.*",
+    "when": [{"path": "uses", "op": "matches", "value": "^actions/checkout@"}],
+    "require": [{"path": "uses", "op": "not_equals", "value": "actions/checkout@main"}]
+  }, {
+    "id": "timeout",
+    "target": "workflow",
+    "for_each": "jobs.*",
+    "require": [{"path": "timeout-minutes", "op": "lte", "value": 30}]
+  }]
+}`)
+	set, err := LoadPolicies([]string{filepath.Dir(policy)})
+	if err != nil {
+		t.Fatalf("Failed to load policies: %v", err)
+	}
+
+	document, err := WorkflowDocument(`
+jobs:
+  build:
+    timeout-minutes: 60
+    steps:
+      - uses: actions/checkout@main
+      - uses: actions/setup-go@v5
+`)
+	if err != nil {
+		t.Fatalf("Failed to parse workflow: %v", err)
+	}
+
+	result := set.Evaluate(Documents{Workflow: document}, time.Now())
+	if len(result.Violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %+v", result.Violations)
+	}
+	if result.Violations[0].Resource != "jobs.build.steps.0" || result.Violations[1].Resource != "jobs.build" {
+		t.Errorf("Unexpected violation resources: %+v", result.Violations)
+	}
+
+	// Config rules are skipped when no configuration document is provided
+	if len(set.Evaluate(Documents{}, time.Now()).Skipped) != 2 {
+		t.Error("Expected rules to be skipped without documents")
+	}
+}
+
+func TestLoadPoliciesValidation(t *testing.T) {
+	tests := map[string]string{
+		"unknown operator": `
+rules:
+  - id: r1
+    require:
+      - {path: project.id, op: approximately, value: x}
+`,
+		"missing justification": `
+rules:
+  - id: r1
+    require:
+      - {path: project.id, op: exists}
+waivers:
+  - {rule: r1, expires: 2030-01-01}
+`,
+		"invalid expiry": `
+rules:
+  - id: r1
+    require:
+      - {path: project.id, op: exists}
+waivers:
+  - {rule: r1, justification: legacy, expires: next-quarter}
+`,
+		"invalid pattern": `
+rules:
+  - id: r1
+    require:
+      - {path: project.id, op: matches, value: "("}
+`,
+	}
+
+	for name, content := range tests {
+		if _, err := LoadPolicies([]string{writePolicy(t, "policy.yaml", content)}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	dir := t.TempDir()
+	rule := "rules:\n  - id: dup\n    require:\n      - {path: project.id, op: exists}\n"
+	for _, name := range []string{"a.yaml", "b.yml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(rule), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := LoadPolicies([]string{dir}); err == nil {
+		t.Error("Expected error for duplicate rule IDs")
+	}
+}
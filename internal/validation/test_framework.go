@@ -4,21 +4,25 @@ package validation
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Fordjour12/gcp-wif/internal/config"
 	"github.com/Fordjour12/gcp-wif/internal/gcp"
 	"github.com/Fordjour12/gcp-wif/internal/github"
 	"github.com/Fordjour12/gcp-wif/internal/logging"
+	"github.com/Fordjour12/gcp-wif/internal/policy"
 )
 
 // TestFramework provides comprehensive testing and validation capabilities
 type TestFramework struct {
-	config *config.Config
-	logger *logging.Logger
-	ctx    context.Context
+	config   *config.Config
+	policies *policy.PolicySet
+	logger   *logging.Logger
+	ctx      context.Context
 }
 
 // NewTestFramework creates a new testing framework instance
@@ -30,6 +34,12 @@ func NewTestFramework(cfg *config.Config) *TestFramework {
 	}
 }
 
+// WithPolicies enables the Policy test suite for the given organization policies
+func (tf *TestFramework) WithPolicies(policies *policy.PolicySet) *TestFramework {
+	tf.policies = policies
+	return tf
+}
+
 // TestSuite represents a collection of related tests
 type TestSuite struct {
 	Name        string                 `json:"name"`
@@ -54,6 +64,14 @@ type Test struct {
 	SkipReason  string        `json:"skip_reason,omitempty"`
 }
 
+// TestWarnings is returned by a test function that passes with warnings
+type TestWarnings []string
+
+// Error implements the error interface
+func (w TestWarnings) Error() string {
+	return strings.Join(w, "; ")
+}
+
 // TestResult represents the result of a test execution
 type TestResult struct {
 	Success      bool              `json:"success"`
@@ -140,7 +158,7 @@ const (
 
 // GetAllTestSuites returns all available test suites
 func (tf *TestFramework) GetAllTestSuites() []TestSuite {
-	return []TestSuite{
+	suites := []TestSuite{
 		tf.CreateConfigurationTestSuite(),
 		tf.CreateGCPTestSuite(),
 		tf.CreateGitHubTestSuite(),
@@ -150,6 +168,10 @@ func (tf *TestFramework) GetAllTestSuites() []TestSuite {
 		tf.CreatePerformanceTestSuite(),
 		tf.CreateResilienceTestSuite(),
 	}
+	if tf.policies != nil {
+		suites = append(suites, tf.CreatePolicyTestSuite())
+	}
+	return suites
 }
 
 // ExecuteTestSuite executes a specific test suite
@@ -329,6 +351,11 @@ func (tf *TestFramework) executeTest(test Test, options TestOptions) TestResult
 
 	duration := time.Since(startTime)
 
+	var warnings TestWarnings
+	if stderrors.As(err, &warnings) {
+		err = nil
+	}
+
 	result := TestResult{
 		Success:  err == nil,
 		Duration: duration,
@@ -336,6 +363,7 @@ func (tf *TestFramework) executeTest(test Test, options TestOptions) TestResult
 			"category": test.Category,
 			"severity": test.Severity,
 		},
+		Warnings: warnings,
 	}
 
 	if err != nil {
@@ -898,10 +926,109 @@ func (tf *TestFramework) CreateSecurityTestSuite() TestSuite {
 					return nil
 				},
 			},
+			{
+				Name:        "service_account_keys",
+				Description: "Verify the federated service account has no active user-managed keys",
+				Category:    CategorySecurity,
+				Severity:    SeverityHigh,
+				Function: func() error {
+					client, err := gcp.NewClient(tf.ctx, tf.config.Project.ID)
+					if err != nil {
+						return fmt.Errorf("failed to create GCP client: %w", err)
+					}
+					defer client.Close()
+
+					// Nothing to check before setup has created the service account
+					if info, err := client.GetServiceAccountInfo(tf.config.ServiceAccount.Name); err == nil && !info.Exists {
+						return nil
+					}
+
+					email := tf.config.GetServiceAccountEmail()
+					keys, err := client.ListServiceAccountKeys(email)
+					if err != nil {
+						return err
+					}
+					if active := gcp.ActiveServiceAccountKeys(keys); len(active) > 0 {
+						return fmt.Errorf("service account %s has %d active user-managed key(s); revoke them with 'gcp-wif keys revoke'", email, len(active))
+					}
+					return nil
+				},
+			},
 		},
 	}
 }
 
+// CreatePolicyTestSuite creates one test per organization policy rule
+func (tf *TestFramework) CreatePolicyTestSuite() TestSuite {
+	suite := TestSuite{
+		Name:        "Policy",
+		Description: "Organization policy-as-code rules",
+	}
+	if tf.policies == nil {
+		return suite
+	}
+
+	// Rules are evaluated together once; each test reports the violations of its rule
+	var once sync.Once
+	var evaluated *policy.Result
+	var evaluateErr error
+	evaluate := func() (*policy.Result, error) {
+		once.Do(func() {
+			evaluated, evaluateErr = tf.policies.EvaluateConfig(tf.config, time.Now())
+		})
+		return evaluated, evaluateErr
+	}
+
+	severities := map[string]string{
+		policy.SeverityError:   SeverityHigh,
+		policy.SeverityWarning: SeverityMedium,
+		policy.SeverityInfo:    SeverityInfo,
+	}
+
+	for _, p := range tf.policies.Policies {
+		for _, rule := range p.Rules {
+			rule := rule
+			description := rule.Description
+			if description == "" {
+				description = fmt.Sprintf("Enforce policy rule %s from %s", rule.ID, p.Source)
+			}
+			suite.Tests = append(suite.Tests, Test{
+				Name:        "policy_" + rule.ID,
+				Description: description,
+				Category:    CategorySecurity,
+				Severity:    severities[rule.Severity],
+				Function: func() error {
+					result, err := evaluate()
+					if err != nil {
+						return err
+					}
+					var messages []string
+					for _, violation := range result.Active() {
+						if violation.RuleID != rule.ID {
+							continue
+						}
+						if violation.Resource != "" {
+							messages = append(messages, fmt.Sprintf("%s: %s", violation.Resource, violation.Message))
+						} else {
+							messages = append(messages, violation.Message)
+						}
+					}
+					if len(messages) == 0 {
+						return nil
+					}
+					// Only error-severity rules fail; warning and info violations are reported
+					if rule.Severity != policy.SeverityError {
+						return TestWarnings(messages)
+					}
+					return fmt.Errorf("policy %s violated: %s", rule.ID, strings.Join(messages, "; "))
+				},
+			})
+		}
+	}
+
+	return suite
+}
+
 // CreatePerformanceTestSuite creates performance validation tests
 func (tf *TestFramework) CreatePerformanceTestSuite() TestSuite {
 	return TestSuite{